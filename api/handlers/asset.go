@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"errors"
+	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/drama-generator/backend/application/services"
 	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/storage"
 	"github.com/drama-generator/backend/pkg/config"
 	"github.com/drama-generator/backend/pkg/logger"
 	"github.com/drama-generator/backend/pkg/response"
@@ -14,14 +17,21 @@ import (
 )
 
 type AssetHandler struct {
-	assetService *services.AssetService
-	log          *logger.Logger
+	assetService  *services.AssetService
+	uploadService *services.UploadService
+	log           *logger.Logger
 }
 
-func NewAssetHandler(db *gorm.DB, cfg *config.Config, log *logger.Logger) *AssetHandler {
+func NewAssetHandler(db *gorm.DB, cfg *config.Config, log *logger.Logger, fileStorage storage.Storage) *AssetHandler {
+	uploadService, err := services.NewUploadService(cfg, log)
+	if err != nil {
+		log.Errorw("Failed to init upload service for assets", "error", err)
+	}
+
 	return &AssetHandler{
-		assetService: services.NewAssetService(db, log),
-		log:          log,
+		assetService:  services.NewAssetService(db, fileStorage, cfg.Storage.LocalPath, cfg.Storage.BaseURL, log),
+		uploadService: uploadService,
+		log:           log,
 	}
 }
 
@@ -35,7 +45,124 @@ func (h *AssetHandler) CreateAsset(c *gin.Context) {
 
 	asset, err := h.assetService.CreateAsset(&req)
 	if err != nil {
-		h.log.Errorw("Failed to create asset", "error", err)
+		h.respondAssetError(c, err, "Failed to create asset")
+		return
+	}
+
+	response.Success(c, asset)
+}
+
+// UploadAsset 上传文件并创建素材，自动探测媒体元数据
+func (h *AssetHandler) UploadAsset(c *gin.Context) {
+	if h.uploadService == nil {
+		response.InternalError(c, "上传服务不可用")
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "请选择文件")
+		return
+	}
+	defer file.Close()
+
+	contentType := header.Header.Get("Content-Type")
+	var assetType models.AssetType
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		assetType = models.AssetTypeImage
+	case strings.HasPrefix(contentType, "video/"):
+		assetType = models.AssetTypeVideo
+	case strings.HasPrefix(contentType, "audio/"):
+		assetType = models.AssetTypeAudio
+	default:
+		response.BadRequest(c, "只支持图片、视频、音频文件")
+		return
+	}
+
+	// 检查文件大小 (500MB)
+	if header.Size > 500*1024*1024 {
+		response.BadRequest(c, "文件大小不能超过500MB")
+		return
+	}
+
+	result, err := h.uploadService.UploadFile(file, header.Filename, contentType, "assets")
+	if err != nil {
+		h.log.Errorw("Failed to upload asset file", "error", err)
+		response.InternalError(c, "上传失败")
+		return
+	}
+
+	name := c.PostForm("name")
+	if name == "" {
+		name = header.Filename
+	}
+	req := &services.CreateAssetRequest{
+		Name:      name,
+		Type:      assetType,
+		URL:       result.URL,
+		LocalPath: &result.LocalPath,
+		MimeType:  &contentType,
+	}
+	if dramaID := c.PostForm("drama_id"); dramaID != "" {
+		req.DramaID = &dramaID
+	}
+	if category := c.PostForm("category"); category != "" {
+		req.Category = &category
+	}
+	if description := c.PostForm("description"); description != "" {
+		req.Description = &description
+	}
+
+	asset, err := h.assetService.CreateAsset(req)
+	if err != nil {
+		h.log.Errorw("Failed to create uploaded asset", "error", err)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, asset)
+}
+
+// RefreshAssetMetadata 重新探测素材元数据
+func (h *AssetHandler) RefreshAssetMetadata(c *gin.Context) {
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的ID")
+		return
+	}
+
+	asset, err := h.assetService.RefreshAssetMetadata(uint(assetID))
+	if err != nil {
+		if err.Error() == "asset not found" {
+			response.NotFound(c, "素材不存在")
+			return
+		}
+		h.log.Errorw("Failed to refresh asset metadata", "error", err)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, asset)
+}
+
+// GenerateAssetPreviews 重新生成素材缩略图、封面帧、雪碧图或波形
+func (h *AssetHandler) GenerateAssetPreviews(c *gin.Context) {
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的ID")
+		return
+	}
+
+	asset, err := h.assetService.GenerateAssetPreviews(uint(assetID))
+	if err != nil {
+		if err.Error() == "asset not found" {
+			response.NotFound(c, "素材不存在")
+			return
+		}
+		h.log.Errorw("Failed to generate asset previews", "error", err)
 		response.InternalError(c, err.Error())
 		return
 	}
@@ -59,14 +186,28 @@ func (h *AssetHandler) UpdateAsset(c *gin.Context) {
 
 	asset, err := h.assetService.UpdateAsset(uint(assetID), &req)
 	if err != nil {
-		h.log.Errorw("Failed to update asset", "error", err)
-		response.InternalError(c, err.Error())
+		h.respondAssetError(c, err, "Failed to update asset")
 		return
 	}
 
 	response.Success(c, asset)
 }
 
+// respondAssetError 标签不存在或不属于素材所在剧本属于请求参数错误
+func (h *AssetHandler) respondAssetError(c *gin.Context, err error, message string) {
+	switch {
+	case err.Error() == "asset not found":
+		response.NotFound(c, "素材不存在")
+	case err.Error() == "drama not found":
+		response.NotFound(c, "剧本不存在")
+	case strings.HasPrefix(err.Error(), "tag not found"), strings.HasSuffix(err.Error(), "does not belong to this drama"):
+		response.BadRequest(c, err.Error())
+	default:
+		h.log.Errorw(message, "error", err)
+		response.InternalError(c, err.Error())
+	}
+}
+
 func (h *AssetHandler) GetAsset(c *gin.Context) {
 
 	assetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -133,6 +274,11 @@ func (h *AssetHandler) ListAssets(c *gin.Context) {
 		}
 	}
 
+	if tagMatch := c.Query("tag_match"); tagMatch != "" && tagMatch != "any" && tagMatch != "all" {
+		response.BadRequest(c, "tag_match 只能为 any 或 all")
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 
@@ -150,6 +296,7 @@ func (h *AssetHandler) ListAssets(c *gin.Context) {
 		Type:         assetType,
 		Category:     c.Query("category"),
 		TagIDs:       tagIDs,
+		TagMatch:     c.DefaultQuery("tag_match", "any"),
 		IsFavorite:   isFavorite,
 		Search:       c.Query("search"),
 		Page:         page,
@@ -174,15 +321,54 @@ func (h *AssetHandler) DeleteAsset(c *gin.Context) {
 		return
 	}
 
-	if err := h.assetService.DeleteAsset(uint(assetID)); err != nil {
+	force := c.Query("force") == "true"
+	report, err := h.assetService.DeleteAsset(uint(assetID), force)
+	if err != nil {
+		var inUse *services.AssetInUseError
+		if errors.As(err, &inUse) {
+			response.ErrorWithDetails(c, http.StatusConflict, "ASSET_IN_USE", "素材正在被使用，确认删除请传 force=true", inUse.Report.Usages)
+			return
+		}
+		if err.Error() == "asset not found" {
+			response.NotFound(c, "素材不存在")
+			return
+		}
 		h.log.Errorw("Failed to delete asset", "error", err)
 		response.InternalError(c, err.Error())
 		return
 	}
 
+	if report != nil && report.InUse {
+		response.SuccessWithMessage(c, "素材已删除，但仍有引用指向该素材", report)
+		return
+	}
+
 	response.Success(c, nil)
 }
 
+// GetAssetUsages 查询素材被哪些分镜、时间线、合成任务、角色、场景等引用
+func (h *AssetHandler) GetAssetUsages(c *gin.Context) {
+
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的ID")
+		return
+	}
+
+	report, err := h.assetService.GetAssetUsages(uint(assetID))
+	if err != nil {
+		if err.Error() == "asset not found" {
+			response.NotFound(c, "素材不存在")
+			return
+		}
+		h.log.Errorw("Failed to get asset usages", "error", err)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, report)
+}
+
 func (h *AssetHandler) ImportFromImageGen(c *gin.Context) {
 
 	imageGenID, err := strconv.ParseUint(c.Param("image_gen_id"), 10, 32)
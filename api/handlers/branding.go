@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type BrandingHandler struct {
+	brandingService *services.BrandingService
+	log             *logger.Logger
+}
+
+func NewBrandingHandler(db *gorm.DB, log *logger.Logger) *BrandingHandler {
+	return &BrandingHandler{
+		brandingService: services.NewBrandingService(db, log),
+		log:             log,
+	}
+}
+
+// GetBranding 获取剧本品牌设置，未设置时返回 null
+func (h *BrandingHandler) GetBranding(c *gin.Context) {
+	dramaID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的剧本ID")
+		return
+	}
+
+	branding, err := h.brandingService.GetBranding(uint(dramaID))
+	if err != nil {
+		h.log.Errorw("Failed to get branding", "error", err, "drama_id", dramaID)
+		response.InternalError(c, "获取失败")
+		return
+	}
+
+	response.Success(c, branding)
+}
+
+// UpdateBranding 创建或更新剧本品牌设置
+func (h *BrandingHandler) UpdateBranding(c *gin.Context) {
+	dramaID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的剧本ID")
+		return
+	}
+
+	var req services.UpdateBrandingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	branding, err := h.brandingService.UpdateBranding(uint(dramaID), &req)
+	if err != nil {
+		if err.Error() == "drama not found" {
+			response.NotFound(c, "剧本不存在")
+			return
+		}
+		if strings.HasPrefix(err.Error(), "invalid") || strings.Contains(err.Error(), "must be") {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		h.log.Errorw("Failed to update branding", "error", err, "drama_id", dramaID)
+		response.InternalError(c, "保存失败")
+		return
+	}
+
+	response.Success(c, branding)
+}
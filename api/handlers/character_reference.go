@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	services2 "github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// GenerateReferenceSheet 生成角色参考图（三视图与表情特写），返回异步任务ID
+func (h *CharacterLibraryHandler) GenerateReferenceSheet(c *gin.Context) {
+
+	characterID := c.Param("id")
+
+	var req services2.GenerateReferenceSheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	taskID, err := h.libraryService.GenerateReferenceSheet(characterID, h.imageService, &req)
+	if err != nil {
+		if err.Error() == "character not found" {
+			response.NotFound(c, "角色不存在")
+			return
+		}
+		if err.Error() == "unauthorized" {
+			response.Forbidden(c, "无权限")
+			return
+		}
+		if strings.HasPrefix(err.Error(), "invalid") {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		h.log.Errorw("Failed to generate character reference sheet", "error", err)
+		response.InternalError(c, "生成失败")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"task_id": taskID,
+		"status":  "pending",
+		"message": "角色参考图生成任务已创建，正在后台处理...",
+	})
+}
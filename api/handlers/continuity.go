@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ContinuityHandler struct {
+	continuityService *services.ContinuityService
+	log               *logger.Logger
+}
+
+func NewContinuityHandler(db *gorm.DB, cfg *config.Config, log *logger.Logger) *ContinuityHandler {
+	return &ContinuityHandler{
+		continuityService: services.NewContinuityService(db, cfg, log),
+		log:               log,
+	}
+}
+
+// CheckDrama 创建连贯性检查任务，请求体可选
+func (h *ContinuityHandler) CheckDrama(c *gin.Context) {
+	dramaID := c.Param("id")
+
+	var req services.CheckContinuityRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	taskID, err := h.continuityService.CheckDrama(dramaID, &req)
+	if err != nil {
+		if err.Error() == "drama not found" {
+			response.NotFound(c, "剧本不存在")
+			return
+		}
+		h.log.Errorw("Failed to start continuity check", "error", err, "drama_id", dramaID)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"task_id": taskID,
+		"status":  "pending",
+		"message": "连贯性检查任务已创建，正在后台处理...",
+	})
+}
+
+// ListDramaIssues 按章节列出剧本的连贯性问题，可按 status 过滤
+func (h *ContinuityHandler) ListDramaIssues(c *gin.Context) {
+	dramaID := c.Param("id")
+
+	episodes, err := h.continuityService.ListDramaIssues(dramaID, c.Query("status"))
+	if err != nil {
+		if err.Error() == "drama not found" {
+			response.NotFound(c, "剧本不存在")
+			return
+		}
+		h.log.Errorw("Failed to list continuity issues", "error", err, "drama_id", dramaID)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, episodes)
+}
+
+// ListEpisodeIssues 列出章节的连贯性问题，可按 status 过滤
+func (h *ContinuityHandler) ListEpisodeIssues(c *gin.Context) {
+	episodeID := c.Param("episode_id")
+
+	issues, err := h.continuityService.ListEpisodeIssues(episodeID, c.Query("status"))
+	if err != nil {
+		if err.Error() == "episode not found" {
+			response.NotFound(c, "章节不存在")
+			return
+		}
+		h.log.Errorw("Failed to list continuity issues", "error", err, "episode_id", episodeID)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, issues)
+}
+
+// UpdateIssue 复核问题，status 为 open / resolved / ignored
+func (h *ContinuityHandler) UpdateIssue(c *gin.Context) {
+	issueID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的问题ID")
+		return
+	}
+
+	var req struct {
+		Status string `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	issue, err := h.continuityService.UpdateIssueStatus(uint(issueID), req.Status)
+	if err != nil {
+		h.respondIssueError(c, err, issueID)
+		return
+	}
+
+	response.Success(c, issue)
+}
+
+// FixIssue 自动修复未关联角色/道具的问题
+func (h *ContinuityHandler) FixIssue(c *gin.Context) {
+	issueID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的问题ID")
+		return
+	}
+
+	issue, err := h.continuityService.FixIssue(uint(issueID))
+	if err != nil {
+		h.respondIssueError(c, err, issueID)
+		return
+	}
+
+	response.Success(c, issue)
+}
+
+func (h *ContinuityHandler) respondIssueError(c *gin.Context, err error, issueID uint64) {
+	switch err.Error() {
+	case "issue not found":
+		response.NotFound(c, "问题不存在")
+	case "invalid status":
+		response.BadRequest(c, "status 必须是 open、resolved 或 ignored")
+	case "issue cannot be fixed automatically":
+		response.BadRequest(c, "该问题无法自动修复，请手动修改分镜")
+	default:
+		h.log.Errorw("Failed to update continuity issue", "error", err, "issue_id", issueID)
+		response.InternalError(c, err.Error())
+	}
+}
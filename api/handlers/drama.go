@@ -5,6 +5,7 @@ import (
 
 	"github.com/drama-generator/backend/application/services"
 	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/storage"
 	"github.com/drama-generator/backend/pkg/config"
 	"github.com/drama-generator/backend/pkg/logger"
 	"github.com/drama-generator/backend/pkg/response"
@@ -16,14 +17,16 @@ type DramaHandler struct {
 	db                *gorm.DB
 	dramaService      *services.DramaService
 	videoMergeService *services.VideoMergeService
+	cloneService      *services.DramaCloneService
 	log               *logger.Logger
 }
 
-func NewDramaHandler(db *gorm.DB, cfg *config.Config, log *logger.Logger, transferService *services.ResourceTransferService) *DramaHandler {
+func NewDramaHandler(db *gorm.DB, cfg *config.Config, log *logger.Logger, transferService *services.ResourceTransferService, fileStorage storage.Storage) *DramaHandler {
 	return &DramaHandler{
 		db:                db,
 		dramaService:      services.NewDramaService(db, cfg, log),
-		videoMergeService: services.NewVideoMergeService(db, transferService, cfg.Storage.LocalPath, cfg.Storage.BaseURL, log),
+		videoMergeService: services.NewVideoMergeService(db, transferService, fileStorage, cfg.Storage.LocalPath, cfg.Storage.BaseURL, log),
+		cloneService:      services.NewDramaCloneService(db, log),
 		log:               log,
 	}
 }
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DramaBundleHandler struct {
+	bundleService *services.DramaBundleService
+	log           *logger.Logger
+}
+
+func NewDramaBundleHandler(db *gorm.DB, cfg *config.Config, fileStorage storage.Storage, log *logger.Logger) *DramaBundleHandler {
+	return &DramaBundleHandler{
+		bundleService: services.NewDramaBundleService(db, cfg, fileStorage, log),
+		log:           log,
+	}
+}
+
+// ExportDrama 导出剧本为 zip 包（manifest.json + 媒体文件）
+func (h *DramaBundleHandler) ExportDrama(c *gin.Context) {
+	dramaID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的剧本ID")
+		return
+	}
+
+	// 先写入临时文件，导出失败时仍可返回错误信息
+	tmp, err := os.CreateTemp("", "drama-export-*.zip")
+	if err != nil {
+		response.InternalError(c, err.Error())
+		return
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if _, err := h.bundleService.ExportDrama(uint(dramaID), tmp); err != nil {
+		if err.Error() == "drama not found" {
+			response.NotFound(c, "剧本不存在")
+			return
+		}
+		h.log.Errorw("Failed to export drama", "error", err, "drama_id", dramaID)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	c.FileAttachment(tmp.Name(), fmt.Sprintf("drama_%d.zip", dramaID))
+}
+
+// ImportDrama 从上传的 zip 包导入剧本（表单字段 file），创建新的剧本
+func (h *DramaBundleHandler) ImportDrama(c *gin.Context) {
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "请选择文件")
+		return
+	}
+	defer file.Close()
+
+	drama, err := h.bundleService.ImportDrama(file, header.Size)
+	if err != nil {
+		h.log.Errorw("Failed to import drama", "error", err, "filename", header.Filename)
+		if strings.HasPrefix(err.Error(), "invalid bundle") || strings.HasPrefix(err.Error(), "unsupported bundle") {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Created(c, drama)
+}
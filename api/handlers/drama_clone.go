@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// CloneDrama 复制剧本（角色、场景、道具、章节、分镜等），可选不复制已生成的视频
+func (h *DramaHandler) CloneDrama(c *gin.Context) {
+	dramaID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的剧本ID")
+		return
+	}
+
+	var req services.CloneDramaRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	drama, err := h.cloneService.CloneDrama(uint(dramaID), &req)
+	if err != nil {
+		if err.Error() == "drama not found" {
+			response.NotFound(c, "剧本不存在")
+			return
+		}
+		response.InternalError(c, "复制失败")
+		return
+	}
+
+	response.Created(c, drama)
+}
+
+// CloneEpisode 复制章节到同一剧本或其他剧本（target_drama_id）
+func (h *DramaHandler) CloneEpisode(c *gin.Context) {
+	episodeID, err := strconv.ParseUint(c.Param("episode_id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的章节ID")
+		return
+	}
+
+	var req services.CloneEpisodeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	episode, err := h.cloneService.CloneEpisode(uint(episodeID), &req)
+	if err != nil {
+		switch err.Error() {
+		case "episode not found":
+			response.NotFound(c, "章节不存在")
+		case "target drama not found":
+			response.NotFound(c, "目标剧本不存在")
+		default:
+			response.InternalError(c, "复制失败")
+		}
+		return
+	}
+
+	response.Created(c, episode)
+}
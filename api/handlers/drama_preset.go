@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DramaPresetHandler struct {
+	presetService *services.DramaPresetService
+	log           *logger.Logger
+}
+
+func NewDramaPresetHandler(db *gorm.DB, log *logger.Logger) *DramaPresetHandler {
+	return &DramaPresetHandler{
+		presetService: services.NewDramaPresetService(db, log),
+		log:           log,
+	}
+}
+
+// GetPreset 获取剧本生成预设，未设置时返回 null
+func (h *DramaPresetHandler) GetPreset(c *gin.Context) {
+	dramaID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的剧本ID")
+		return
+	}
+
+	preset, err := h.presetService.GetPreset(uint(dramaID))
+	if err != nil {
+		h.log.Errorw("Failed to get drama preset", "error", err, "drama_id", dramaID)
+		response.InternalError(c, "获取失败")
+		return
+	}
+
+	response.Success(c, preset)
+}
+
+// UpdatePreset 创建或更新剧本生成预设，未传的字段保持不变
+func (h *DramaPresetHandler) UpdatePreset(c *gin.Context) {
+	dramaID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的剧本ID")
+		return
+	}
+
+	var req services.UpdateDramaPresetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	preset, err := h.presetService.UpdatePreset(uint(dramaID), &req)
+	if err != nil {
+		if err.Error() == "drama not found" {
+			response.NotFound(c, "剧本不存在")
+			return
+		}
+		if strings.HasPrefix(err.Error(), "invalid") || strings.Contains(err.Error(), "must be") {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		h.log.Errorw("Failed to update drama preset", "error", err, "drama_id", dramaID)
+		response.InternalError(c, "保存失败")
+		return
+	}
+
+	response.Success(c, preset)
+}
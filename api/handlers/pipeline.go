@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type PipelineHandler struct {
+	pipelineService *services.PipelineService
+	log             *logger.Logger
+}
+
+func NewPipelineHandler(db *gorm.DB, cfg *config.Config, transferService *services.ResourceTransferService, fileStorage storage.Storage, log *logger.Logger) *PipelineHandler {
+	return &PipelineHandler{
+		pipelineService: services.NewPipelineService(db, cfg, transferService, fileStorage, log),
+		log:             log,
+	}
+}
+
+// StartPipeline 启动章节一键生产流水线，请求体可选
+func (h *PipelineHandler) StartPipeline(c *gin.Context) {
+	episodeID := c.Param("episode_id")
+
+	var req services.PipelineOptions
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	pipeline, err := h.pipelineService.StartPipeline(episodeID, &req)
+	if err != nil {
+		if err.Error() == "episode not found" {
+			response.NotFound(c, "章节不存在")
+			return
+		}
+		if err.Error() == "pipeline already running" || strings.HasPrefix(err.Error(), "unknown stage") {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		h.log.Errorw("Failed to start pipeline", "error", err, "episode_id", episodeID)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, pipeline)
+}
+
+// GetEpisodePipeline 获取章节最近一次的流水线
+func (h *PipelineHandler) GetEpisodePipeline(c *gin.Context) {
+	episodeID := c.Param("episode_id")
+
+	pipeline, err := h.pipelineService.GetLatestPipeline(episodeID)
+	if err != nil {
+		if err.Error() == "pipeline not found" {
+			response.NotFound(c, "流水线不存在")
+			return
+		}
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, pipeline)
+}
+
+// GetPipeline 获取流水线及各阶段状态
+func (h *PipelineHandler) GetPipeline(c *gin.Context) {
+	h.handlePipelineAction(c, "get", h.pipelineService.GetPipeline)
+}
+
+// ResumePipeline 从失败或取消的阶段继续执行
+func (h *PipelineHandler) ResumePipeline(c *gin.Context) {
+	h.handlePipelineAction(c, "resume", h.pipelineService.ResumePipeline)
+}
+
+// ApprovePipeline 确认暂停的阶段并继续
+func (h *PipelineHandler) ApprovePipeline(c *gin.Context) {
+	h.handlePipelineAction(c, "approve", h.pipelineService.ApprovePipeline)
+}
+
+// CancelPipeline 取消流水线
+func (h *PipelineHandler) CancelPipeline(c *gin.Context) {
+	h.handlePipelineAction(c, "cancel", h.pipelineService.CancelPipeline)
+}
+
+func (h *PipelineHandler) handlePipelineAction(c *gin.Context, action string, fn func(uint) (*models.EpisodePipeline, error)) {
+	pipelineID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的流水线ID")
+		return
+	}
+
+	pipeline, err := fn(uint(pipelineID))
+	if err != nil {
+		if err.Error() == "pipeline not found" {
+			response.NotFound(c, "流水线不存在")
+			return
+		}
+		if strings.HasPrefix(err.Error(), "pipeline is") || strings.HasSuffix(err.Error(), "is still stopping") {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		h.log.Errorw("Pipeline action failed", "error", err, "action", action, "pipeline_id", pipelineID)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, pipeline)
+}
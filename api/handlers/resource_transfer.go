@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type ResourceTransferHandler struct {
+	transferService *services.ResourceTransferService
+	taskService     *services.TaskService
+	log             *logger.Logger
+}
+
+func NewResourceTransferHandler(db *gorm.DB, transferService *services.ResourceTransferService, log *logger.Logger) *ResourceTransferHandler {
+	return &ResourceTransferHandler{
+		transferService: transferService,
+		taskService:     services.NewTaskService(db, log),
+		log:             log,
+	}
+}
+
+// ListTransfers 查询转存失败或源URL已过期的资源（?status=failed|expired&drama_id=）
+func (h *ResourceTransferHandler) ListTransfers(c *gin.Context) {
+	dramaID, _ := strconv.ParseUint(c.Query("drama_id"), 10, 32)
+
+	transfers, err := h.transferService.ListTransfers(c.Query("status"), uint(dramaID))
+	if err != nil {
+		h.log.Errorw("Failed to list resource transfers", "error", err)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, transfers)
+}
+
+// RunTransfer 立即转存没有本地副本的资源（异步任务），?hours=N 只扫描最近 N 小时更新的记录
+func (h *ResourceTransferHandler) RunTransfer(c *gin.Context) {
+	var since *time.Time
+	if hours, err := strconv.Atoi(c.Query("hours")); err == nil && hours > 0 {
+		t := time.Now().Add(-time.Duration(hours) * time.Hour)
+		since = &t
+	}
+
+	task, err := h.taskService.CreateTask("resource_transfer", "storage")
+	if err != nil {
+		h.log.Errorw("Failed to create resource transfer task", "error", err)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	go func() {
+		h.taskService.UpdateTaskStatus(task.ID, "processing", 10, "正在转存资源")
+		stats, err := h.transferService.TransferPending(since, 0)
+		if err != nil {
+			h.log.Errorw("Resource transfer failed", "task_id", task.ID, "error", err)
+			h.taskService.UpdateTaskError(task.ID, err)
+			return
+		}
+		h.taskService.UpdateTaskResult(task.ID, stats)
+	}()
+
+	response.Success(c, gin.H{
+		"task_id": task.ID,
+		"status":  "pending",
+		"message": "资源转存任务已创建，正在后台处理...",
+	})
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/response"
+	"github.com/drama-generator/backend/pkg/screenplay"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxScreenplayFileSize 单个剧本文件大小上限
+const maxScreenplayFileSize = 10 << 20
+
+type ScreenplayHandler struct {
+	screenplayService *services.ScreenplayService
+	log               *logger.Logger
+}
+
+func NewScreenplayHandler(db *gorm.DB, cfg *config.Config, log *logger.Logger) *ScreenplayHandler {
+	return &ScreenplayHandler{
+		screenplayService: services.NewScreenplayService(db, cfg.Storage.LocalPath, cfg.Storage.BaseURL, log),
+		log:               log,
+	}
+}
+
+// ImportScreenplay 导入 Fountain / FDX 剧本文件（表单字段 file，可多个）
+// 可选表单字段：format 指定格式（默认按扩展名）；replace=true 替换现有章节
+func (h *ScreenplayHandler) ImportScreenplay(c *gin.Context) {
+	dramaID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的剧本ID")
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["file"]) == 0 {
+		response.BadRequest(c, "请选择文件")
+		return
+	}
+
+	var format screenplay.Format
+	if f := c.PostForm("format"); f != "" {
+		if format, err = screenplay.ParseFormat(f); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	files := make([]services.ScreenplayFile, 0, len(form.File["file"]))
+	for _, header := range form.File["file"] {
+		if header.Size > maxScreenplayFileSize {
+			response.BadRequest(c, "文件过大: "+header.Filename)
+			return
+		}
+		f, err := header.Open()
+		if err != nil {
+			response.InternalError(c, err.Error())
+			return
+		}
+		data, err := io.ReadAll(f)
+		f.Close()
+		if err != nil {
+			response.InternalError(c, err.Error())
+			return
+		}
+		files = append(files, services.ScreenplayFile{Name: header.Filename, Data: data, Format: format})
+	}
+
+	replace, _ := strconv.ParseBool(c.PostForm("replace"))
+	result, err := h.screenplayService.ImportScreenplay(uint(dramaID), files, replace)
+	if err != nil {
+		if err.Error() == "drama not found" {
+			response.NotFound(c, "剧本不存在")
+			return
+		}
+		if strings.HasPrefix(err.Error(), "invalid screenplay") {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		h.log.Errorw("Failed to import screenplay", "error", err, "drama_id", dramaID)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Created(c, result)
+}
+
+// GetEpisodeScreenplay 获取章节的结构化剧本（场景、动作、对白）
+func (h *ScreenplayHandler) GetEpisodeScreenplay(c *gin.Context) {
+	episodeID := c.Param("episode_id")
+
+	sp, err := h.screenplayService.GetEpisodeScreenplay(episodeID)
+	if err != nil {
+		if err.Error() == "screenplay not found" {
+			response.NotFound(c, "该章节没有导入的剧本")
+			return
+		}
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, sp)
+}
+
+// ExportEpisodeScreenplay 下载章节剧本（format=pdf|fountain|fdx，默认 pdf）
+func (h *ScreenplayHandler) ExportEpisodeScreenplay(c *gin.Context) {
+	h.export(c, "", h.screenplayService.ExportEpisodeScreenplay)
+}
+
+// ExportShootingScript 下载章节分镜表（format=pdf|fountain|fdx，默认 pdf），PDF 包含每个镜头的首帧图片
+func (h *ScreenplayHandler) ExportShootingScript(c *gin.Context) {
+	h.export(c, "_shooting_script", h.screenplayService.ExportShootingScript)
+}
+
+func (h *ScreenplayHandler) export(c *gin.Context, suffix string, render func(uint, screenplay.Format) ([]byte, error)) {
+	episodeID, err := strconv.ParseUint(c.Param("episode_id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的章节ID")
+		return
+	}
+
+	format, err := screenplay.ParseFormat(c.DefaultQuery("format", string(screenplay.FormatPDF)))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	data, err := render(uint(episodeID), format)
+	if err != nil {
+		switch err.Error() {
+		case "episode not found":
+			response.NotFound(c, "章节不存在")
+		case "episode has no script":
+			response.BadRequest(c, "章节没有剧本内容")
+		case "episode has no storyboards":
+			response.BadRequest(c, "章节没有分镜")
+		default:
+			h.log.Errorw("Failed to export screenplay", "error", err, "episode_id", episodeID, "format", format)
+			response.InternalError(c, err.Error())
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=episode_%d%s%s", episodeID, suffix, format.Extension()))
+	c.Data(http.StatusOK, format.ContentType(), data)
+}
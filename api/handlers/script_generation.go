@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"errors"
+	"io"
+	"strconv"
+
 	"github.com/drama-generator/backend/application/services"
 	"github.com/drama-generator/backend/pkg/config"
 	"github.com/drama-generator/backend/pkg/logger"
@@ -45,3 +49,95 @@ func (h *ScriptGenerationHandler) GenerateCharacters(c *gin.Context) {
 		"message": "角色生成任务已创建，正在后台处理...",
 	})
 }
+
+// GenerateOutline 生成全剧梗概与分集大纲
+func (h *ScriptGenerationHandler) GenerateOutline(c *gin.Context) {
+	var req services.GenerateOutlineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	taskID, err := h.scriptService.GenerateOutline(&req)
+	if err != nil {
+		if err.Error() == "drama not found" {
+			response.NotFound(c, "剧本不存在")
+			return
+		}
+		h.log.Errorw("Failed to generate outline", "error", err, "drama_id", req.DramaID)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"task_id": taskID,
+		"status":  "pending",
+		"message": "大纲生成任务已创建，正在后台处理...",
+	})
+}
+
+// GenerateEpisodeScripts 按集数范围依次生成章节剧本
+func (h *ScriptGenerationHandler) GenerateEpisodeScripts(c *gin.Context) {
+	var req services.GenerateEpisodeScriptsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	taskID, err := h.scriptService.GenerateEpisodeScripts(&req)
+	if err != nil {
+		h.respondEpisodeScriptError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"task_id": taskID,
+		"status":  "pending",
+		"message": "剧本生成任务已创建，正在后台处理...",
+	})
+}
+
+// GenerateEpisodeScript 重新生成单个章节的剧本
+func (h *ScriptGenerationHandler) GenerateEpisodeScript(c *gin.Context) {
+	episodeID, err := strconv.ParseUint(c.Param("episode_id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的章节ID")
+		return
+	}
+
+	var req struct {
+		Model       string  `json:"model"`
+		Temperature float64 `json:"temperature"`
+	}
+	// 请求体可选
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	taskID, err := h.scriptService.GenerateEpisodeScript(uint(episodeID), req.Model, req.Temperature)
+	if err != nil {
+		h.respondEpisodeScriptError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"task_id": taskID,
+		"status":  "pending",
+		"message": "剧本生成任务已创建，正在后台处理...",
+	})
+}
+
+func (h *ScriptGenerationHandler) respondEpisodeScriptError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "drama not found":
+		response.NotFound(c, "剧本不存在")
+	case "episode not found":
+		response.NotFound(c, "章节不存在")
+	case "no episodes to generate":
+		response.BadRequest(c, "没有需要生成剧本的章节，请先生成大纲或设置 overwrite")
+	default:
+		h.log.Errorw("Failed to generate episode scripts", "error", err)
+		response.InternalError(c, err.Error())
+	}
+}
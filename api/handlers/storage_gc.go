@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type StorageGCHandler struct {
+	gcService *services.StorageGCService
+	log       *logger.Logger
+}
+
+func NewStorageGCHandler(db *gorm.DB, cfg *config.Config, log *logger.Logger) *StorageGCHandler {
+	return &StorageGCHandler{
+		gcService: services.NewStorageGCService(db, cfg, log),
+		log:       log,
+	}
+}
+
+// GetGCReport 预览清理结果（dry-run），不移动或删除任何文件
+func (h *StorageGCHandler) GetGCReport(c *gin.Context) {
+	opts := services.StorageGCOptions{DryRun: true}
+	opts.MinAgeDays, _ = strconv.Atoi(c.Query("min_age_days"))
+	opts.PurgeAfterDays, _ = strconv.Atoi(c.Query("purge_after_days"))
+	opts.MaxListed, _ = strconv.Atoi(c.Query("max_listed"))
+
+	report, err := h.gcService.Run(opts)
+	if err != nil {
+		if err.Error() == "storage gc already running" {
+			response.ErrorWithDetails(c, http.StatusConflict, "CONFLICT", "存储清理任务正在运行", nil)
+			return
+		}
+		if err.Error() == "storage gc only supports local storage" {
+			response.BadRequest(c, "存储清理仅支持本地存储")
+			return
+		}
+		h.log.Errorw("Failed to build storage gc report", "error", err)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// RunGC 执行存储清理（异步任务）：孤儿文件移入隔离目录，隔离期满的文件删除
+func (h *StorageGCHandler) RunGC(c *gin.Context) {
+	var opts services.StorageGCOptions
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&opts); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	taskID, err := h.gcService.StartGC(opts)
+	if err != nil {
+		if err.Error() == "storage gc only supports local storage" {
+			response.BadRequest(c, "存储清理仅支持本地存储")
+			return
+		}
+		h.log.Errorw("Failed to start storage gc", "error", err)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"task_id": taskID,
+		"status":  "pending",
+		"message": "存储清理任务已创建，正在后台处理...",
+	})
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type StreamingHandler struct {
+	db          *gorm.DB
+	hlsService  *services.HLSService
+	fileStorage storage.Storage
+	baseURL     string
+	log         *logger.Logger
+}
+
+func NewStreamingHandler(db *gorm.DB, cfg *config.Config, fileStorage storage.Storage, log *logger.Logger) *StreamingHandler {
+	return &StreamingHandler{
+		db:          db,
+		hlsService:  services.NewHLSService(db, fileStorage, cfg.Storage.LocalPath, cfg.Storage.BaseURL, log),
+		fileStorage: fileStorage,
+		baseURL:     cfg.Storage.BaseURL,
+		log:         log,
+	}
+}
+
+// PackageEpisodeHLS 将剧集成片打包为多码率 HLS（异步任务）
+func (h *StreamingHandler) PackageEpisodeHLS(c *gin.Context) {
+	episodeID, err := strconv.ParseUint(c.Param("episode_id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的剧集ID")
+		return
+	}
+
+	var settings services.HLSSettings
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&settings); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+
+	taskID, err := h.hlsService.PackageEpisode(uint(episodeID), &settings)
+	if err != nil {
+		switch {
+		case err.Error() == "episode not found":
+			response.NotFound(c, "剧集不存在")
+		case err.Error() == "episode has no finalized video":
+			response.BadRequest(c, "该剧集还没有生成视频")
+		case strings.HasPrefix(err.Error(), "unsupported rendition"):
+			response.BadRequest(c, err.Error())
+		default:
+			h.log.Errorw("Failed to start HLS packaging", "error", err, "episode_id", episodeID)
+			response.InternalError(c, err.Error())
+		}
+		return
+	}
+
+	response.Success(c, gin.H{
+		"task_id": taskID,
+		"status":  "pending",
+		"message": "HLS打包任务已创建，正在后台处理...",
+	})
+}
+
+// GetDramaPlaylist 获取剧本已定稿剧集的播放列表
+func (h *StreamingHandler) GetDramaPlaylist(c *gin.Context) {
+	dramaID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的剧本ID")
+		return
+	}
+
+	playlist, err := h.hlsService.GetDramaPlaylist(uint(dramaID))
+	if err != nil {
+		if err.Error() == "drama not found" {
+			response.NotFound(c, "剧本不存在")
+			return
+		}
+		h.log.Errorw("Failed to get drama playlist", "error", err, "drama_id", dramaID)
+		response.InternalError(c, "获取失败")
+		return
+	}
+
+	response.Success(c, playlist)
+}
+
+// StreamEpisodeVideo 以支持 Range 请求的方式播放剧集成片，远程视频直接重定向
+func (h *StreamingHandler) StreamEpisodeVideo(c *gin.Context) {
+	var episode models.Episode
+	if err := h.db.Where("id = ?", c.Param("episode_id")).First(&episode).Error; err != nil {
+		response.NotFound(c, "剧集不存在")
+		return
+	}
+	if episode.VideoURL == nil || *episode.VideoURL == "" {
+		response.BadRequest(c, "该剧集还没有生成视频")
+		return
+	}
+
+	videoURL := *episode.VideoURL
+	if strings.HasPrefix(videoURL, "http://") || strings.HasPrefix(videoURL, "https://") {
+		if h.baseURL == "" || !strings.HasPrefix(videoURL, h.baseURL+"/") {
+			c.Redirect(http.StatusFound, videoURL)
+			return
+		}
+		videoURL = strings.TrimPrefix(videoURL, h.baseURL+"/")
+	}
+
+	// 存储相对路径通过存储后端取得本地副本（S3 时按需拉取）
+	videoPath := videoURL
+	if !filepath.IsAbs(videoPath) {
+		videoPath = h.fileStorage.GetAbsolutePath(strings.TrimPrefix(videoURL, "/static/"))
+	}
+	if _, err := os.Stat(videoPath); err != nil {
+		response.NotFound(c, "视频文件不存在")
+		return
+	}
+
+	// c.File 基于 http.ServeContent，支持 Range 与条件请求
+	c.File(videoPath)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/response"
+	"github.com/drama-generator/backend/pkg/subtitle"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SubtitleHandler struct {
+	subtitleService *services.SubtitleService
+	log             *logger.Logger
+}
+
+func NewSubtitleHandler(db *gorm.DB, fileStorage storage.Storage, log *logger.Logger) *SubtitleHandler {
+	return &SubtitleHandler{
+		subtitleService: services.NewSubtitleService(db, fileStorage, log),
+		log:             log,
+	}
+}
+
+// GetEpisodeSubtitles 下载剧集字幕（format=srt|vtt）
+func (h *SubtitleHandler) GetEpisodeSubtitles(c *gin.Context) {
+	episodeID, err := strconv.ParseUint(c.Param("episode_id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的剧集ID")
+		return
+	}
+
+	format, err := subtitle.ParseFormat(c.DefaultQuery("format", "srt"))
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	content, err := h.subtitleService.RenderEpisodeSubtitles(uint(episodeID), format)
+	if err != nil {
+		if err.Error() == "episode not found" {
+			response.NotFound(c, "剧集不存在")
+			return
+		}
+		h.log.Errorw("Failed to render subtitles", "error", err, "episode_id", episodeID)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=episode_%d%s", episodeID, format.Extension()))
+	c.Data(http.StatusOK, format.ContentType(), []byte(content))
+}
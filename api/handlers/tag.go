@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/response"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type TagHandler struct {
+	tagService *services.TagService
+	log        *logger.Logger
+}
+
+func NewTagHandler(db *gorm.DB, log *logger.Logger) *TagHandler {
+	return &TagHandler{
+		tagService: services.NewTagService(db, log),
+		log:        log,
+	}
+}
+
+// ListTags 获取标签列表（含素材数量）
+// drama_id 为空时返回全局标签；include_global=false 时只返回剧本标签
+func (h *TagHandler) ListTags(c *gin.Context) {
+	req := &services.ListTagsRequest{
+		IncludeGlobal: c.DefaultQuery("include_global", "true") != "false",
+	}
+	if dramaIDStr := c.Query("drama_id"); dramaIDStr != "" {
+		id, err := strconv.ParseUint(dramaIDStr, 10, 32)
+		if err != nil {
+			response.BadRequest(c, "无效的剧本ID")
+			return
+		}
+		dramaID := uint(id)
+		req.DramaID = &dramaID
+	}
+
+	tags, err := h.tagService.ListTags(req)
+	if err != nil {
+		h.log.Errorw("Failed to list tags", "error", err)
+		response.InternalError(c, "获取标签失败")
+		return
+	}
+
+	response.Success(c, tags)
+}
+
+func (h *TagHandler) CreateTag(c *gin.Context) {
+	var req services.CreateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	tag, err := h.tagService.CreateTag(&req)
+	if err != nil {
+		h.respondError(c, err, "创建标签失败")
+		return
+	}
+
+	response.Created(c, tag)
+}
+
+func (h *TagHandler) UpdateTag(c *gin.Context) {
+	tagID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的ID")
+		return
+	}
+
+	var req services.UpdateTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	tag, err := h.tagService.UpdateTag(uint(tagID), &req)
+	if err != nil {
+		h.respondError(c, err, "更新标签失败")
+		return
+	}
+
+	response.Success(c, tag)
+}
+
+func (h *TagHandler) DeleteTag(c *gin.Context) {
+	tagID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "无效的ID")
+		return
+	}
+
+	if err := h.tagService.DeleteTag(uint(tagID)); err != nil {
+		h.respondError(c, err, "删除标签失败")
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+func (h *TagHandler) respondError(c *gin.Context, err error, message string) {
+	switch {
+	case err.Error() == "tag not found":
+		response.NotFound(c, "标签不存在")
+	case err.Error() == "drama not found":
+		response.NotFound(c, "剧本不存在")
+	case strings.HasPrefix(err.Error(), "tag already exists"), err.Error() == "tag name is required":
+		response.BadRequest(c, err.Error())
+	default:
+		h.log.Errorw(message, "error", err)
+		response.InternalError(c, message)
+	}
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"strings"
+
 	"github.com/drama-generator/backend/application/services"
 	"github.com/drama-generator/backend/pkg/logger"
 	"github.com/drama-generator/backend/pkg/response"
@@ -55,3 +57,25 @@ func (h *TaskHandler) GetResourceTasks(c *gin.Context) {
 
 	response.Success(c, tasks)
 }
+
+// CancelTask 取消未完成的任务
+func (h *TaskHandler) CancelTask(c *gin.Context) {
+	taskID := c.Param("task_id")
+
+	task, err := h.taskService.CancelTask(taskID)
+	if err != nil {
+		if err.Error() == "task not found" {
+			response.NotFound(c, "任务不存在")
+			return
+		}
+		if strings.HasPrefix(err.Error(), "task already") {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		h.log.Errorw("Failed to cancel task", "error", err, "task_id", taskID)
+		response.InternalError(c, err.Error())
+		return
+	}
+
+	response.Success(c, task)
+}
@@ -1,32 +1,40 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"path/filepath"
 	"time"
 
+	"github.com/drama-generator/backend/application/services"
 	"github.com/drama-generator/backend/pkg/ai/tts"
 	"github.com/gin-gonic/gin"
 )
 
 // TTSHandler TTS处理器
 type TTSHandler struct {
-	service    *tts.TTSService
-	outputPath string
+	service       *tts.TTSService
+	presetService *services.DramaPresetService
+	dialogueAudio *services.DialogueAudioService
+	outputPath    string
 }
 
 // NewTTSHandler 创建TTS处理器
-func NewTTSHandler(service *tts.TTSService, outputPath string) *TTSHandler {
+func NewTTSHandler(service *tts.TTSService, presetService *services.DramaPresetService, dialogueAudio *services.DialogueAudioService, outputPath string) *TTSHandler {
 	return &TTSHandler{
-		service:    service,
-		outputPath: outputPath,
+		service:       service,
+		presetService: presetService,
+		dialogueAudio: dialogueAudio,
+		outputPath:    outputPath,
 	}
 }
 
 // GenerateRequest TTS生成请求
 type GenerateRequest struct {
-	Provider   string  `json:"provider" binding:"required"`
-	Voice      string  `json:"voice" binding:"required"`
+	DramaID    uint    `json:"drama_id"` // 可选，provider/voice 为空时使用该剧本的生成预设；未传时取 storyboard_id 所属剧本
+	StoryboardID uint  `json:"storyboard_id"` // 可选，指定后保存为该分镜的对白素材，成片混音时使用
+	Provider   string  `json:"provider"`
+	Voice      string  `json:"voice"`
 	Text       string  `json:"text" binding:"required"`
 	Speed      float64 `json:"speed"`   // 0.5-2.0, 默认1.0
 	Pitch      float64 `json:"pitch"`   // 0.5-2.0, 默认1.0
@@ -39,6 +47,7 @@ type GenerateResponse struct {
 	Success    bool   `json:"success"`
 	AudioData  string `json:"audio_data,omitempty"`
 	FilePath   string `json:"file_path,omitempty"`
+	AssetID    uint   `json:"asset_id,omitempty"` // 指定 storyboard_id 时创建的对白素材
 	Duration   int    `json:"duration,omitempty"`
 	Provider   string `json:"provider"`
 	Voice      string `json:"voice"`
@@ -62,6 +71,37 @@ func (h *TTSHandler) Generate(c *gin.Context) {
 		return
 	}
 
+	if req.StoryboardID != 0 {
+		dramaID, err := h.dialogueAudio.StoryboardDramaID(req.StoryboardID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.DramaID == 0 {
+			req.DramaID = dramaID
+		}
+	}
+
+	if req.DramaID != 0 && (req.Provider == "" || req.Voice == "") {
+		preset, err := h.presetService.GetPreset(req.DramaID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if preset != nil {
+			if req.Provider == "" {
+				req.Provider = preset.TTSProvider
+			}
+			if req.Voice == "" {
+				req.Voice = preset.TTSVoice
+			}
+		}
+	}
+	if req.Provider == "" || req.Voice == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "provider and voice are required"})
+		return
+	}
+
 	// 设置默认值
 	if req.Speed == 0 {
 		req.Speed = 1.0
@@ -75,7 +115,11 @@ func (h *TTSHandler) Generate(c *gin.Context) {
 
 	// 生成输出路径
 	outputPath := ""
-	if req.SaveToFile {
+	dialoguePath := ""
+	if req.StoryboardID != 0 {
+		dialoguePath = h.dialogueAudio.DialogueOutputPath(req.StoryboardID, fmt.Sprintf("%d.%s", time.Now().UnixNano(), req.Format))
+		outputPath = filepath.Join(h.outputPath, dialoguePath)
+	} else if req.SaveToFile {
 		outputPath = filepath.Join(h.outputPath, "tts", req.Provider, req.Voice, 
 			string(rune('a'+int(time.Now().Unix()%26)))+".mp3")
 	}
@@ -93,10 +137,22 @@ func (h *TTSHandler) Generate(c *gin.Context) {
 		return
 	}
 
+	// 对白音频登记为分镜素材
+	var assetID uint
+	if dialoguePath != "" && resp.Success {
+		asset, err := h.dialogueAudio.RegisterDialogue(req.StoryboardID, dialoguePath, req.Text, resp.Duration)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		assetID = asset.ID
+	}
+
 	c.JSON(http.StatusOK, GenerateResponse{
 		Success:    resp.Success,
 		AudioData:  resp.AudioData,
 		FilePath:   resp.FilePath,
+		AssetID:    assetID,
 		Duration:   resp.Duration,
 		Provider:   resp.Provider,
 		Voice:      req.Voice,
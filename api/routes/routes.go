@@ -1,6 +1,8 @@
 package routes
 
 import (
+	"mime"
+
 	handlers2 "github.com/drama-generator/backend/api/handlers"
 	middlewares2 "github.com/drama-generator/backend/api/middlewares"
 	services2 "github.com/drama-generator/backend/application/services"
@@ -13,13 +15,17 @@ import (
 	"gorm.io/gorm"
 )
 
-func SetupRouter(cfg *config.Config, db *gorm.DB, log *logger.Logger, localStorage interface{}) *gin.Engine {
+func SetupRouter(cfg *config.Config, db *gorm.DB, log *logger.Logger, fileStorage storage2.Storage) *gin.Engine {
 	r := gin.New()
 
 	r.Use(gin.Recovery())
 	r.Use(middlewares2.LoggerMiddleware(log))
 	r.Use(middlewares2.CORSMiddleware(cfg.Server.CORSOrigins))
 
+	// HLS 播放列表与分片的 MIME 类型
+	mime.AddExtensionType(".m3u8", "application/vnd.apple.mpegurl")
+	mime.AddExtensionType(".ts", "video/mp2t")
+
 	// 静态文件服务（用户上传的文件）
 	r.Static("/static", cfg.Storage.LocalPath)
 
@@ -32,19 +38,18 @@ func SetupRouter(cfg *config.Config, db *gorm.DB, log *logger.Logger, localStora
 	})
 
 	aiService := services2.NewAIService(db, log)
-	localStoragePtr := localStorage.(*storage2.LocalStorage)
-	transferService := services2.NewResourceTransferService(db, log)
+	transferService := services2.NewResourceTransferService(db, fileStorage, log)
 	promptI18n := services2.NewPromptI18n(cfg)
-	dramaHandler := handlers2.NewDramaHandler(db, cfg, log, nil)
+	dramaHandler := handlers2.NewDramaHandler(db, cfg, log, nil, fileStorage)
 	aiConfigHandler := handlers2.NewAIConfigHandler(db, cfg, log)
 	scriptGenHandler := handlers2.NewScriptGenerationHandler(db, cfg, log)
-	imageGenService := services2.NewImageGenerationService(db, cfg, transferService, localStoragePtr, log)
-	imageGenHandler := handlers2.NewImageGenerationHandler(db, cfg, log, transferService, localStoragePtr)
-	videoGenHandler := handlers2.NewVideoGenerationHandler(db, transferService, localStoragePtr, aiService, log, promptI18n)
-	videoMergeHandler := handlers2.NewVideoMergeHandler(db, nil, cfg.Storage.LocalPath, cfg.Storage.BaseURL, log)
-	assetHandler := handlers2.NewAssetHandler(db, cfg, log)
+	imageGenService := services2.NewImageGenerationService(db, cfg, transferService, fileStorage, log)
+	imageGenHandler := handlers2.NewImageGenerationHandler(db, cfg, log, transferService, fileStorage)
+	videoGenHandler := handlers2.NewVideoGenerationHandler(db, transferService, fileStorage, aiService, log, promptI18n)
+	videoMergeHandler := handlers2.NewVideoMergeHandler(db, nil, fileStorage, cfg.Storage.LocalPath, cfg.Storage.BaseURL, log)
+	assetHandler := handlers2.NewAssetHandler(db, cfg, log, fileStorage)
 	characterLibraryService := services2.NewCharacterLibraryService(db, log, cfg)
-	characterLibraryHandler := handlers2.NewCharacterLibraryHandler(db, cfg, log, transferService, localStoragePtr)
+	characterLibraryHandler := handlers2.NewCharacterLibraryHandler(db, cfg, log, transferService, fileStorage)
 	uploadHandler, err := handlers2.NewUploadHandler(cfg, log, characterLibraryService)
 	if err != nil {
 		log.Fatalw("Failed to create upload handler", "error", err)
@@ -57,6 +62,17 @@ func SetupRouter(cfg *config.Config, db *gorm.DB, log *logger.Logger, localStora
 	audioExtractionHandler := handlers2.NewAudioExtractionHandler(log, cfg.Storage.LocalPath)
 	settingsHandler := handlers2.NewSettingsHandler(cfg, log)
 	propHandler := handlers2.NewPropHandler(db, cfg, log, aiService, imageGenService)
+	subtitleHandler := handlers2.NewSubtitleHandler(db, fileStorage, log)
+	brandingHandler := handlers2.NewBrandingHandler(db, log)
+	dramaPresetHandler := handlers2.NewDramaPresetHandler(db, log)
+	streamingHandler := handlers2.NewStreamingHandler(db, cfg, fileStorage, log)
+	tagHandler := handlers2.NewTagHandler(db, log)
+	storageGCHandler := handlers2.NewStorageGCHandler(db, cfg, log)
+	resourceTransferHandler := handlers2.NewResourceTransferHandler(db, transferService, log)
+	dramaBundleHandler := handlers2.NewDramaBundleHandler(db, cfg, fileStorage, log)
+	pipelineHandler := handlers2.NewPipelineHandler(db, cfg, transferService, fileStorage, log)
+	screenplayHandler := handlers2.NewScreenplayHandler(db, cfg, log)
+	continuityHandler := handlers2.NewContinuityHandler(db, cfg, log)
 
 	// NewAPI统一接口
 	newAPIClient := newapi.NewClient("https://api.newapi.com", "")
@@ -67,7 +83,8 @@ func SetupRouter(cfg *config.Config, db *gorm.DB, log *logger.Logger, localStora
 	// 注册TTS客户端 (需要配置API Key)
 	// ttsService.RegisterClient("azure", tts.NewAzureTTSClient("your-api-key", "eastus"))
 	// ttsService.RegisterClient("alibaba", tts.NewAlibabaTTSClient("your-api-key", "your-app-key"))
-	ttsHandler := handlers2.NewTTSHandler(ttsService, cfg.Storage.LocalPath)
+	dialogueAudioService := services2.NewDialogueAudioService(db, fileStorage, cfg.Storage.LocalPath, log)
+	ttsHandler := handlers2.NewTTSHandler(ttsService, services2.NewDramaPresetService(db, log), dialogueAudioService, cfg.Storage.LocalPath)
 
 	api := r.Group("/api/v1")
 	{
@@ -78,6 +95,7 @@ func SetupRouter(cfg *config.Config, db *gorm.DB, log *logger.Logger, localStora
 			dramas.GET("", dramaHandler.ListDramas)
 			dramas.POST("", dramaHandler.CreateDrama)
 			dramas.GET("/stats", dramaHandler.GetDramaStats) // 统计接口放在/:id之前
+			dramas.POST("/import", dramaBundleHandler.ImportDrama)
 			dramas.GET("/:id", dramaHandler.GetDrama)
 			dramas.PUT("/:id", dramaHandler.UpdateDrama)
 			dramas.DELETE("/:id", dramaHandler.DeleteDrama)
@@ -88,6 +106,16 @@ func SetupRouter(cfg *config.Config, db *gorm.DB, log *logger.Logger, localStora
 			dramas.PUT("/:id/episodes", dramaHandler.SaveEpisodes)
 			dramas.PUT("/:id/progress", dramaHandler.SaveProgress)
 			dramas.GET("/:id/props", propHandler.ListProps) // Added prop list route
+			dramas.GET("/:id/branding", brandingHandler.GetBranding)
+			dramas.PUT("/:id/branding", brandingHandler.UpdateBranding)
+			dramas.GET("/:id/preset", dramaPresetHandler.GetPreset)
+			dramas.PUT("/:id/preset", dramaPresetHandler.UpdatePreset)
+			dramas.GET("/:id/playlist", streamingHandler.GetDramaPlaylist)
+			dramas.GET("/:id/export", dramaBundleHandler.ExportDrama)
+			dramas.POST("/:id/clone", dramaHandler.CloneDrama)
+			dramas.POST("/:id/screenplay/import", screenplayHandler.ImportScreenplay)
+			dramas.POST("/:id/continuity/check", continuityHandler.CheckDrama)
+			dramas.GET("/:id/continuity/issues", continuityHandler.ListDramaIssues)
 		}
 
 		aiConfigs := api.Group("/ai-configs")
@@ -103,6 +131,8 @@ func SetupRouter(cfg *config.Config, db *gorm.DB, log *logger.Logger, localStora
 		generation := api.Group("/generation")
 		{
 			generation.POST("/characters", scriptGenHandler.GenerateCharacters)
+			generation.POST("/outline", scriptGenHandler.GenerateOutline)
+			generation.POST("/episode-scripts", scriptGenHandler.GenerateEpisodeScripts)
 		}
 
 		// NewAPI统一接口路由
@@ -139,6 +169,7 @@ func SetupRouter(cfg *config.Config, db *gorm.DB, log *logger.Logger, localStora
 			characters.DELETE("/:id", characterLibraryHandler.DeleteCharacter)
 			characters.POST("/batch-generate-images", characterLibraryHandler.BatchGenerateCharacterImages)
 			characters.POST("/:id/generate-image", characterLibraryHandler.GenerateCharacterImage)
+			characters.POST("/:id/generate-reference-sheet", characterLibraryHandler.GenerateReferenceSheet)
 			characters.POST("/:id/upload-image", uploadHandler.UploadCharacterImage)
 			characters.PUT("/:id/image", characterLibraryHandler.UploadCharacterImage)
 			characters.PUT("/:id/image-from-library", characterLibraryHandler.ApplyLibraryItemToCharacter)
@@ -169,6 +200,33 @@ func SetupRouter(cfg *config.Config, db *gorm.DB, log *logger.Logger, localStora
 			episodes.GET("/:episode_id/storyboards", sceneHandler.GetStoryboardsForEpisode)
 			episodes.POST("/:episode_id/finalize", dramaHandler.FinalizeEpisode)
 			episodes.GET("/:episode_id/download", dramaHandler.DownloadEpisodeVideo)
+			episodes.GET("/:episode_id/subtitles", subtitleHandler.GetEpisodeSubtitles)
+			episodes.GET("/:episode_id/stream", streamingHandler.StreamEpisodeVideo)
+			episodes.POST("/:episode_id/hls", streamingHandler.PackageEpisodeHLS)
+			episodes.POST("/:episode_id/clone", dramaHandler.CloneEpisode)
+			episodes.POST("/:episode_id/pipeline", pipelineHandler.StartPipeline)
+			episodes.GET("/:episode_id/pipeline", pipelineHandler.GetEpisodePipeline)
+			episodes.GET("/:episode_id/screenplay", screenplayHandler.GetEpisodeScreenplay)
+			episodes.GET("/:episode_id/screenplay/export", screenplayHandler.ExportEpisodeScreenplay)
+			episodes.GET("/:episode_id/shooting-script", screenplayHandler.ExportShootingScript)
+			episodes.POST("/:episode_id/script/generate", scriptGenHandler.GenerateEpisodeScript)
+			episodes.GET("/:episode_id/continuity/issues", continuityHandler.ListEpisodeIssues)
+		}
+
+		// 一键生产流水线
+		pipelines := api.Group("/pipelines")
+		{
+			pipelines.GET("/:id", pipelineHandler.GetPipeline)
+			pipelines.POST("/:id/resume", pipelineHandler.ResumePipeline)
+			pipelines.POST("/:id/approve", pipelineHandler.ApprovePipeline)
+			pipelines.POST("/:id/cancel", pipelineHandler.CancelPipeline)
+		}
+
+		// 连贯性问题复核
+		continuityIssues := api.Group("/continuity-issues")
+		{
+			continuityIssues.PUT("/:id", continuityHandler.UpdateIssue)
+			continuityIssues.POST("/:id/fix", continuityHandler.FixIssue)
 		}
 
 		// 任务路由
@@ -176,6 +234,7 @@ func SetupRouter(cfg *config.Config, db *gorm.DB, log *logger.Logger, localStora
 		{
 			tasks.GET("/:task_id", taskHandler.GetTaskStatus)
 			tasks.GET("", taskHandler.GetResourceTasks)
+			tasks.POST("/:task_id/cancel", taskHandler.CancelTask)
 		}
 
 		// 场景路由
@@ -224,13 +283,25 @@ func SetupRouter(cfg *config.Config, db *gorm.DB, log *logger.Logger, localStora
 		{
 			assets.GET("", assetHandler.ListAssets)
 			assets.POST("", assetHandler.CreateAsset)
+			assets.POST("/upload", assetHandler.UploadAsset)
 			assets.GET("/:id", assetHandler.GetAsset)
 			assets.PUT("/:id", assetHandler.UpdateAsset)
 			assets.DELETE("/:id", assetHandler.DeleteAsset)
+			assets.GET("/:id/usages", assetHandler.GetAssetUsages)
+			assets.POST("/:id/probe", assetHandler.RefreshAssetMetadata)
+			assets.POST("/:id/previews", assetHandler.GenerateAssetPreviews)
 			assets.POST("/import/image/:image_gen_id", assetHandler.ImportFromImageGen)
 			assets.POST("/import/video/:video_gen_id", assetHandler.ImportFromVideoGen)
 		}
 
+		tags := api.Group("/tags")
+		{
+			tags.GET("", tagHandler.ListTags)
+			tags.POST("", tagHandler.CreateTag)
+			tags.PUT("/:id", tagHandler.UpdateTag)
+			tags.DELETE("/:id", tagHandler.DeleteTag)
+		}
+
 		storyboards := api.Group("/storyboards")
 		{
 			storyboards.GET("/episode/:episode_id/generate", storyboardHandler.GenerateStoryboard)
@@ -253,6 +324,14 @@ func SetupRouter(cfg *config.Config, db *gorm.DB, log *logger.Logger, localStora
 			settings.GET("/language", settingsHandler.GetLanguage)
 			settings.PUT("/language", settingsHandler.UpdateLanguage)
 		}
+
+		admin := api.Group("/admin")
+		{
+			admin.GET("/storage/gc", storageGCHandler.GetGCReport)
+			admin.POST("/storage/gc", storageGCHandler.RunGC)
+			admin.GET("/resource-transfers", resourceTransferHandler.ListTransfers)
+			admin.POST("/resource-transfers/run", resourceTransferHandler.RunTransfer)
+		}
 	}
 
 	// 前端静态文件服务（放在API路由之后，避免冲突）
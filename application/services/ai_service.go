@@ -425,6 +425,18 @@ func (s *AIService) GenerateText(prompt string, systemPrompt string, options ...
 	return client.GenerateText(prompt, systemPrompt, options...)
 }
 
+// GenerateTextWithModel 使用指定的文本模型生成，模型为空或没有对应配置时使用默认配置
+func (s *AIService) GenerateTextWithModel(model string, prompt string, systemPrompt string, options ...func(*ai.ChatCompletionRequest)) (string, error) {
+	if model != "" {
+		client, err := s.GetAIClientForModel("text", model)
+		if err == nil {
+			return client.GenerateText(prompt, systemPrompt, options...)
+		}
+		s.log.Warnw("Failed to get client for specified model, using default", "model", model, "error", err)
+	}
+	return s.GenerateText(prompt, systemPrompt, options...)
+}
+
 func (s *AIService) GenerateImage(prompt string, size string, n int) ([]string, error) {
 	client, err := s.GetAIClient("image")
 	if err != nil {
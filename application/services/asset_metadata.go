@@ -0,0 +1,277 @@
+package services
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	models "github.com/drama-generator/backend/domain/models"
+	"gorm.io/gorm"
+)
+
+// AssetBackfillStats 素材回填统计
+type AssetBackfillStats struct {
+	Total   int `json:"total"`
+	Success int `json:"success"`
+	Failed  int `json:"failed"`
+}
+
+// mediaSource 返回可供探测的路径，优先使用本地文件
+func (s *AssetService) mediaSource(asset *models.Asset) (path string, local bool) {
+	if asset.LocalPath != nil && *asset.LocalPath != "" {
+		p := resolveMediaPath(s.storagePath, s.baseURL, *asset.LocalPath)
+		if _, err := os.Stat(p); err == nil {
+			return p, true
+		}
+	}
+	p := resolveMediaPath(s.storagePath, s.baseURL, asset.URL)
+	if strings.HasPrefix(p, "http://") || strings.HasPrefix(p, "https://") {
+		return p, false
+	}
+	if _, err := os.Stat(p); err == nil {
+		return p, true
+	}
+	return "", false
+}
+
+// ProbeAsset 探测素材文件并填充尺寸、时长、编码等元数据（不保存）
+// 图片优先使用 Go 图像解码器读取头信息，其余类型及解码失败时使用 ffprobe
+func (s *AssetService) ProbeAsset(asset *models.Asset) error {
+	path, local := s.mediaSource(asset)
+	if path == "" {
+		return fmt.Errorf("asset file not found")
+	}
+
+	ext := strings.ToLower(filepath.Ext(strings.Split(path, "?")[0]))
+	if local {
+		if stat, err := os.Stat(path); err == nil {
+			size := stat.Size()
+			asset.FileSize = &size
+		}
+	}
+
+	if asset.Type == models.AssetTypeImage {
+		if err := s.probeImage(asset, path, local); err == nil {
+			s.markProbed(asset, ext)
+			return nil
+		}
+	}
+
+	if asset.Type == models.AssetTypeSubtitle {
+		s.markProbed(asset, ext)
+		return nil
+	}
+
+	info, err := s.ffmpeg.Probe(path)
+	if err != nil {
+		return err
+	}
+
+	if info.Width > 0 && info.Height > 0 {
+		asset.Width = &info.Width
+		asset.Height = &info.Height
+	}
+	if info.Duration > 0 && asset.Type != models.AssetTypeImage {
+		duration := int(math.Round(info.Duration))
+		asset.Duration = &duration
+	}
+	if asset.FileSize == nil && info.Size > 0 {
+		asset.FileSize = &info.Size
+	}
+	if info.Bitrate > 0 {
+		asset.Bitrate = &info.Bitrate
+	}
+	if info.FPS > 0 && asset.Type == models.AssetTypeVideo {
+		fps := math.Round(info.FPS*100) / 100
+		asset.FPS = &fps
+	}
+
+	codec := info.VideoCodec
+	if asset.Type == models.AssetTypeAudio || codec == "" {
+		codec = info.AudioCodec
+	}
+	if codec != "" {
+		asset.Codec = &codec
+	}
+	if info.AudioCodec != "" && asset.Type != models.AssetTypeAudio {
+		asset.AudioCodec = &info.AudioCodec
+	}
+	if asset.Type != models.AssetTypeImage {
+		hasAudio := info.HasAudio
+		asset.HasAudio = &hasAudio
+	}
+
+	// ffprobe 的格式名为逗号分隔列表（如 mov,mp4,m4a），优先取与扩展名一致的项
+	format := strings.Split(info.FormatName, ",")[0]
+	for _, name := range strings.Split(info.FormatName, ",") {
+		if "."+name == ext {
+			format = name
+			break
+		}
+	}
+	if format != "" && asset.Format == nil {
+		asset.Format = &format
+	}
+
+	s.markProbed(asset, ext)
+	return nil
+}
+
+// probeImage 读取图片头信息获取尺寸与格式
+func (s *AssetService) probeImage(asset *models.Asset, path string, local bool) error {
+	var reader io.Reader
+	if local {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+	} else {
+		client := &http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Get(path)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unexpected status: %d", resp.StatusCode)
+		}
+		if resp.ContentLength > 0 {
+			size := resp.ContentLength
+			asset.FileSize = &size
+		}
+		reader = resp.Body
+	}
+
+	config, format, err := image.DecodeConfig(reader)
+	if err != nil {
+		return err
+	}
+
+	asset.Width = &config.Width
+	asset.Height = &config.Height
+	asset.Format = &format
+	mimeType := "image/" + format
+	asset.MimeType = &mimeType
+	return nil
+}
+
+// markProbed 补全 MIME 类型并记录探测时间
+func (s *AssetService) markProbed(asset *models.Asset, ext string) {
+	if asset.MimeType == nil || *asset.MimeType == "" {
+		mimeType := mime.TypeByExtension(ext)
+		if mimeType == "" && asset.Format != nil {
+			mimeType = string(asset.Type) + "/" + *asset.Format
+		}
+		if idx := strings.Index(mimeType, ";"); idx != -1 {
+			mimeType = mimeType[:idx]
+		}
+		if mimeType != "" {
+			asset.MimeType = &mimeType
+		}
+	}
+	if asset.Format == nil && ext != "" {
+		format := strings.TrimPrefix(ext, ".")
+		asset.Format = &format
+	}
+	now := time.Now()
+	asset.ProbedAt = &now
+}
+
+// applyMetadata 创建/导入素材时探测本地文件的元数据，失败不影响创建
+// 远程文件需要下载或 ffprobe 访问 URL，不在请求内探测，由 generatePreviewsAsync 在后台补全
+func (s *AssetService) applyMetadata(asset *models.Asset) {
+	if _, local := s.mediaSource(asset); !local {
+		return
+	}
+	if err := s.ProbeAsset(asset); err != nil {
+		s.log.Warnw("Failed to probe asset metadata", "name", asset.Name, "url", asset.URL, "error", err)
+	}
+}
+
+// RefreshAssetMetadata 重新探测并保存素材元数据
+func (s *AssetService) RefreshAssetMetadata(assetID uint) (*models.Asset, error) {
+	var asset models.Asset
+	if err := s.db.Where("id = ?", assetID).First(&asset).Error; err != nil {
+		return nil, fmt.Errorf("asset not found")
+	}
+
+	if err := s.ProbeAsset(&asset); err != nil {
+		return nil, fmt.Errorf("failed to probe asset: %w", err)
+	}
+	if err := s.saveMetadata(&asset); err != nil {
+		return nil, err
+	}
+
+	return &asset, nil
+}
+
+// BackfillMetadata 为尚未探测过的素材补全元数据，force 为 true 时重新探测全部素材
+func (s *AssetService) BackfillMetadata(force bool, batchSize int) (*AssetBackfillStats, error) {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	query := s.db.Model(&models.Asset{})
+	if !force {
+		query = query.Where("probed_at IS NULL")
+	}
+
+	stats := &AssetBackfillStats{}
+	var assets []models.Asset
+	result := query.FindInBatches(&assets, batchSize, func(tx *gorm.DB, batch int) error {
+		for i := range assets {
+			stats.Total++
+			if err := s.ProbeAsset(&assets[i]); err != nil {
+				s.log.Warnw("Backfill probe failed", "asset_id", assets[i].ID, "error", err)
+				stats.Failed++
+				continue
+			}
+			if err := s.saveMetadata(&assets[i]); err != nil {
+				s.log.Errorw("Backfill save failed", "asset_id", assets[i].ID, "error", err)
+				stats.Failed++
+				continue
+			}
+			stats.Success++
+		}
+		s.log.Infow("Metadata backfill batch done", "batch", batch, "total", stats.Total, "failed", stats.Failed)
+		return nil
+	})
+	if result.Error != nil {
+		return stats, result.Error
+	}
+
+	return stats, nil
+}
+
+func (s *AssetService) saveMetadata(asset *models.Asset) error {
+	err := s.db.Model(&models.Asset{}).Where("id = ?", asset.ID).Updates(map[string]interface{}{
+		"width":       asset.Width,
+		"height":      asset.Height,
+		"duration":    asset.Duration,
+		"file_size":   asset.FileSize,
+		"mime_type":   asset.MimeType,
+		"format":      asset.Format,
+		"codec":       asset.Codec,
+		"audio_codec": asset.AudioCodec,
+		"fps":         asset.FPS,
+		"bitrate":     asset.Bitrate,
+		"has_audio":   asset.HasAudio,
+		"probed_at":   asset.ProbedAt,
+	}).Error
+	if err != nil {
+		return fmt.Errorf("failed to save asset metadata: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	models "github.com/drama-generator/backend/domain/models"
+)
+
+// 预览资源尺寸
+const (
+	previewThumbnailWidth = 320
+	previewPosterWidth    = 1280
+	previewSpriteFrames   = 10
+	previewSpriteColumns  = 5
+	previewSpriteWidth    = 160
+	previewWaveformWidth  = 800
+	previewWaveformHeight = 120
+	previewPeakCount      = 800
+)
+
+// assetPreviewLocks 按素材串行化预览生成，避免后台生成与手动重新生成同时写同一素材的预览
+var assetPreviewLocks sync.Map
+
+// GenerateAssetPreviews 重新生成并保存素材预览资源
+// 新预览写入带时间戳的新文件，保存成功后才删除旧预览，生成失败时素材仍指向原有预览
+func (s *AssetService) GenerateAssetPreviews(assetID uint) (*models.Asset, error) {
+	mu, _ := assetPreviewLocks.LoadOrStore(assetID, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	defer mu.(*sync.Mutex).Unlock()
+
+	var asset models.Asset
+	if err := s.db.Where("id = ?", assetID).First(&asset).Error; err != nil {
+		return nil, fmt.Errorf("asset not found")
+	}
+
+	relDir := filepath.Join("previews", fmt.Sprintf("asset_%d", asset.ID))
+	staleKeys := s.previewKeys(&asset, relDir)
+
+	generated, err := s.generatePreviews(&asset, relDir)
+	if err != nil {
+		s.removePreviews(generated)
+		return nil, err
+	}
+
+	if err := s.db.Model(&models.Asset{}).Where("id = ?", asset.ID).Updates(map[string]interface{}{
+		"thumbnail_url": asset.ThumbnailURL,
+		"poster_url":    asset.PosterURL,
+		"sprite_url":    asset.SpriteURL,
+		"sprite_info":   asset.SpriteInfo,
+		"waveform_url":  asset.WaveformURL,
+		"peaks_url":     asset.PeaksURL,
+	}).Error; err != nil {
+		s.removePreviews(generated)
+		return nil, fmt.Errorf("failed to save asset previews: %w", err)
+	}
+
+	var unused []string
+	for _, key := range staleKeys {
+		if !s.previewInUse(&asset, key) {
+			unused = append(unused, key)
+		}
+	}
+	s.removePreviews(unused)
+
+	return &asset, nil
+}
+
+// removePreviews 删除预览文件：存储后端中的对象及 ffmpeg 在本地存储目录生成的文件
+func (s *AssetService) removePreviews(keys []string) {
+	for _, key := range keys {
+		if err := s.fileStorage.Delete(key); err != nil {
+			s.log.Warnw("Failed to delete preview", "key", key, "error", err)
+		}
+		os.Remove(filepath.Join(s.storagePath, filepath.FromSlash(key)))
+	}
+}
+
+// generatePreviewsAsync 创建/导入素材后在后台探测尚未探测的元数据（远程文件）并生成预览
+func (s *AssetService) generatePreviewsAsync(asset *models.Asset) {
+	probe := asset.ProbedAt == nil
+	if !probe && asset.Type == models.AssetTypeSubtitle {
+		return
+	}
+	assetID := asset.ID
+	assetType := asset.Type
+	go func() {
+		if probe {
+			if _, err := s.RefreshAssetMetadata(assetID); err != nil {
+				s.log.Warnw("Failed to probe asset metadata", "asset_id", assetID, "error", err)
+			}
+		}
+		if assetType == models.AssetTypeSubtitle {
+			return
+		}
+		if _, err := s.GenerateAssetPreviews(assetID); err != nil {
+			s.log.Warnw("Failed to generate asset previews", "asset_id", assetID, "error", err)
+		}
+	}()
+}
+
+// generatePreviews 按素材类型在 relDir 下生成缩略图、封面帧、雪碧图或波形，返回已生成文件的存储 key（失败时用于清理）
+func (s *AssetService) generatePreviews(asset *models.Asset, relDir string) (generated []string, err error) {
+	source, _ := s.mediaSource(asset)
+	if source == "" {
+		return nil, fmt.Errorf("asset file not found")
+	}
+
+	// ffmpeg 在本地存储目录生成预览文件，再写入存储后端
+	if err := os.MkdirAll(filepath.Join(s.storagePath, relDir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create preview directory: %w", err)
+	}
+
+	stamp := time.Now().UnixNano()
+	relFile := func(name string) string {
+		rel := filepath.Join(relDir, fmt.Sprintf("%d_%s", stamp, name))
+		generated = append(generated, filepath.ToSlash(rel))
+		return rel
+	}
+
+	switch asset.Type {
+	case models.AssetTypeImage:
+		thumb := relFile("thumb.jpg")
+		if err := s.ffmpeg.ResizeImage(source, filepath.Join(s.storagePath, thumb), previewThumbnailWidth); err != nil {
+			return generated, err
+		}
+		if asset.ThumbnailURL, err = s.publishPreview(thumb); err != nil {
+			return generated, err
+		}
+
+	case models.AssetTypeVideo:
+		at := 1.0
+		if asset.Duration != nil && *asset.Duration > 0 {
+			at = math.Min(1, float64(*asset.Duration)/2)
+		}
+
+		poster := relFile("poster.jpg")
+		if err := s.ffmpeg.ExtractFrame(source, filepath.Join(s.storagePath, poster), at, previewPosterWidth); err != nil {
+			return generated, err
+		}
+		if asset.PosterURL, err = s.publishPreview(poster); err != nil {
+			return generated, err
+		}
+
+		thumb := relFile("thumb.jpg")
+		if err := s.ffmpeg.ResizeImage(filepath.Join(s.storagePath, poster), filepath.Join(s.storagePath, thumb), previewThumbnailWidth); err != nil {
+			return generated, err
+		}
+		if asset.ThumbnailURL, err = s.publishPreview(thumb); err != nil {
+			return generated, err
+		}
+
+		sprite := relFile("sprite.jpg")
+		info, err := s.ffmpeg.GenerateSprite(source, filepath.Join(s.storagePath, sprite), previewSpriteFrames, previewSpriteColumns, previewSpriteWidth)
+		if err != nil {
+			// 雪碧图失败不影响封面
+			s.log.Warnw("Failed to generate sprite sheet", "asset_id", asset.ID, "error", err)
+			break
+		}
+		spriteURL, err := s.publishPreview(sprite)
+		if err != nil {
+			return generated, err
+		}
+		infoJSON, _ := json.Marshal(info)
+		asset.SpriteURL = spriteURL
+		asset.SpriteInfo = infoJSON
+
+	case models.AssetTypeAudio:
+		waveform := relFile("waveform.png")
+		if err := s.ffmpeg.GenerateWaveformImage(source, filepath.Join(s.storagePath, waveform), previewWaveformWidth, previewWaveformHeight, ""); err != nil {
+			return generated, err
+		}
+		if asset.WaveformURL, err = s.publishPreview(waveform); err != nil {
+			return generated, err
+		}
+		asset.ThumbnailURL = asset.WaveformURL
+
+		peaks, err := s.ffmpeg.ExtractPeaks(source, previewPeakCount)
+		if err != nil {
+			return generated, err
+		}
+		peaksJSON, err := json.Marshal(peaks)
+		if err != nil {
+			return generated, err
+		}
+		peaksKey := filepath.ToSlash(relFile("peaks.json"))
+		if err := s.fileStorage.Put(peaksKey, bytes.NewReader(peaksJSON), "application/json"); err != nil {
+			return generated, fmt.Errorf("failed to write peaks: %w", err)
+		}
+		peaksURL := s.fileStorage.GetURL(peaksKey)
+		asset.PeaksURL = &peaksURL
+
+	default:
+		return nil, fmt.Errorf("previews not supported for asset type: %s", asset.Type)
+	}
+
+	s.log.Infow("Asset previews generated", "asset_id", asset.ID, "type", asset.Type)
+	return generated, nil
+}
+
+// BackfillPreviews 为缺少预览资源的素材生成预览，force 为 true 时全部重新生成
+func (s *AssetService) BackfillPreviews(force bool, batchSize int) (*AssetBackfillStats, error) {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+
+	query := s.db.Model(&models.Asset{}).Where("type IN ?", []models.AssetType{models.AssetTypeImage, models.AssetTypeVideo, models.AssetTypeAudio})
+	if !force {
+		query = query.Where("(type = ? AND (thumbnail_url IS NULL OR thumbnail_url = '' OR thumbnail_url NOT LIKE ?)) OR (type = ? AND poster_url IS NULL) OR (type = ? AND waveform_url IS NULL)",
+			models.AssetTypeImage, "%/previews/%", models.AssetTypeVideo, models.AssetTypeAudio)
+	}
+
+	var ids []uint
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	stats := &AssetBackfillStats{}
+	for _, id := range ids {
+		stats.Total++
+		if _, err := s.GenerateAssetPreviews(id); err != nil {
+			s.log.Warnw("Backfill previews failed", "asset_id", id, "error", err)
+			stats.Failed++
+			continue
+		}
+		stats.Success++
+		if stats.Total%batchSize == 0 {
+			s.log.Infow("Preview backfill progress", "total", stats.Total, "failed", stats.Failed)
+		}
+	}
+
+	return stats, nil
+}
+
+// publishPreview 将本地存储目录 relPath 处生成的预览文件写入存储后端，返回访问URL
+func (s *AssetService) publishPreview(relPath string) (*string, error) {
+	url, err := publishMedia(s.fileStorage, s.storagePath, relPath)
+	if err != nil {
+		return nil, err
+	}
+	return &url, nil
+}
+
+// previewURLs 素材当前的预览资源URL
+func previewURLs(asset *models.Asset) []*string {
+	return []*string{asset.ThumbnailURL, asset.PosterURL, asset.SpriteURL, asset.WaveformURL, asset.PeaksURL}
+}
+
+// previewKeys 素材预览目录下已有预览资源的存储 key
+func (s *AssetService) previewKeys(asset *models.Asset, relDir string) []string {
+	prefix := filepath.ToSlash(relDir) + "/"
+	var keys []string
+	for _, url := range previewURLs(asset) {
+		if url == nil {
+			continue
+		}
+		if key, ok := s.fileStorage.KeyFromURL(*url); ok && strings.HasPrefix(key, prefix) && !containsString(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// previewInUse 重新生成后 key 是否仍被素材的预览资源引用
+func (s *AssetService) previewInUse(asset *models.Asset, key string) bool {
+	for _, url := range previewURLs(asset) {
+		if url == nil {
+			continue
+		}
+		if current, ok := s.fileStorage.KeyFromURL(*url); ok && current == key {
+			return true
+		}
+	}
+	return false
+}
@@ -7,21 +7,28 @@ import (
 
 	models "github.com/drama-generator/backend/domain/models"
 	"github.com/drama-generator/backend/infrastructure/external/ffmpeg"
+	"github.com/drama-generator/backend/infrastructure/storage"
 	"github.com/drama-generator/backend/pkg/logger"
 	"gorm.io/gorm"
 )
 
 type AssetService struct {
-	db     *gorm.DB
-	log    *logger.Logger
-	ffmpeg *ffmpeg.FFmpeg
+	db          *gorm.DB
+	log         *logger.Logger
+	ffmpeg      *ffmpeg.FFmpeg
+	fileStorage storage.Storage
+	storagePath string
+	baseURL     string
 }
 
-func NewAssetService(db *gorm.DB, log *logger.Logger) *AssetService {
+func NewAssetService(db *gorm.DB, fileStorage storage.Storage, storagePath, baseURL string, log *logger.Logger) *AssetService {
 	return &AssetService{
-		db:     db,
-		log:    log,
-		ffmpeg: ffmpeg.NewFFmpeg(log),
+		db:          db,
+		log:         log,
+		ffmpeg:      ffmpeg.NewFFmpeg(log, mediaCache(fileStorage)),
+		fileStorage: fileStorage,
+		storagePath: storagePath,
+		baseURL:     baseURL,
 	}
 }
 
@@ -50,7 +57,7 @@ type UpdateAssetRequest struct {
 	Description  *string `json:"description"`
 	Category     *string `json:"category"`
 	ThumbnailURL *string `json:"thumbnail_url"`
-	TagIDs       []uint  `json:"tag_ids"`
+	TagIDs       []uint  `json:"tag_ids"` // 为 null 时不修改，空数组清空标签
 	IsFavorite   *bool   `json:"is_favorite"`
 }
 
@@ -61,6 +68,7 @@ type ListAssetsRequest struct {
 	Type         *models.AssetType `json:"type"`
 	Category     string            `json:"category"`
 	TagIDs       []uint            `json:"tag_ids"`
+	TagMatch     string            `json:"tag_match"` // any（默认）或 all
 	IsFavorite   *bool             `json:"is_favorite"`
 	Search       string            `json:"search"`
 	Page         int               `json:"page"`
@@ -84,6 +92,11 @@ func (s *AssetService) CreateAsset(req *CreateAssetRequest) (*models.Asset, erro
 		}
 	}
 
+	tags, err := resolveAssetTags(s.db, dramaID, req.TagIDs)
+	if err != nil {
+		return nil, err
+	}
+
 	asset := &models.Asset{
 		DramaID:      dramaID,
 		Name:         req.Name,
@@ -101,11 +114,14 @@ func (s *AssetService) CreateAsset(req *CreateAssetRequest) (*models.Asset, erro
 		Format:       req.Format,
 		ImageGenID:   req.ImageGenID,
 		VideoGenID:   req.VideoGenID,
+		Tags:         tags,
 	}
+	s.applyMetadata(asset)
 
-	if err := s.db.Create(asset).Error; err != nil {
+	if err := s.db.Omit("Tags.*").Create(asset).Error; err != nil {
 		return nil, fmt.Errorf("failed to create asset: %w", err)
 	}
+	s.generatePreviewsAsync(asset)
 
 	return asset, nil
 }
@@ -116,6 +132,16 @@ func (s *AssetService) UpdateAsset(assetID uint, req *UpdateAssetRequest) (*mode
 		return nil, fmt.Errorf("asset not found")
 	}
 
+	// 先校验标签，避免标签非法时其他字段已被部分更新
+	var tags []models.Tag
+	if req.TagIDs != nil {
+		resolved, err := resolveAssetTags(s.db, asset.DramaID, req.TagIDs)
+		if err != nil {
+			return nil, err
+		}
+		tags = resolved
+	}
+
 	updates := make(map[string]interface{})
 	if req.Name != nil {
 		updates["name"] = *req.Name
@@ -139,7 +165,13 @@ func (s *AssetService) UpdateAsset(assetID uint, req *UpdateAssetRequest) (*mode
 		}
 	}
 
-	if err := s.db.First(&asset, assetID).Error; err != nil {
+	if req.TagIDs != nil {
+		if err := s.db.Model(&asset).Omit("Tags.*").Association("Tags").Replace(tags); err != nil {
+			return nil, fmt.Errorf("failed to update asset tags: %w", err)
+		}
+	}
+
+	if err := s.db.Preload("Tags").First(&asset, assetID).Error; err != nil {
 		return nil, err
 	}
 
@@ -148,7 +180,7 @@ func (s *AssetService) UpdateAsset(assetID uint, req *UpdateAssetRequest) (*mode
 
 func (s *AssetService) GetAsset(assetID uint) (*models.Asset, error) {
 	var asset models.Asset
-	if err := s.db.Where("id = ? ", assetID).First(&asset).Error; err != nil {
+	if err := s.db.Preload("Tags").Where("id = ? ", assetID).First(&asset).Error; err != nil {
 		return nil, err
 	}
 
@@ -186,6 +218,22 @@ func (s *AssetService) ListAssets(req *ListAssetsRequest) ([]models.Asset, int64
 		query = query.Where("is_favorite = ?", *req.IsFavorite)
 	}
 
+	if len(req.TagIDs) > 0 {
+		if req.TagMatch == "all" {
+			// 必须包含全部标签
+			tagIDs := uniqueUints(req.TagIDs)
+			query = query.Where("id IN (?)", s.db.Table("asset_tags").
+				Select("asset_id").
+				Where("tag_id IN ?", tagIDs).
+				Group("asset_id").
+				Having("COUNT(DISTINCT tag_id) = ?", len(tagIDs)))
+		} else {
+			query = query.Where("id IN (?)", s.db.Table("asset_tags").
+				Select("asset_id").
+				Where("tag_id IN ?", req.TagIDs))
+		}
+	}
+
 	if req.Search != "" {
 		searchTerm := "%" + strings.ToLower(req.Search) + "%"
 		query = query.Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ?", searchTerm, searchTerm)
@@ -198,7 +246,7 @@ func (s *AssetService) ListAssets(req *ListAssetsRequest) ([]models.Asset, int64
 
 	var assets []models.Asset
 	offset := (req.Page - 1) * req.PageSize
-	if err := query.Order("created_at DESC").
+	if err := query.Preload("Tags").Order("created_at DESC").
 		Offset(offset).Limit(req.PageSize).Find(&assets).Error; err != nil {
 		return nil, 0, err
 	}
@@ -206,15 +254,45 @@ func (s *AssetService) ListAssets(req *ListAssetsRequest) ([]models.Asset, int64
 	return assets, total, nil
 }
 
-func (s *AssetService) DeleteAsset(assetID uint) error {
+func uniqueUints(values []uint) []uint {
+	seen := make(map[uint]bool, len(values))
+	result := make([]uint, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// DeleteAsset 删除素材。素材仍被引用时返回 AssetInUseError，force 为 true 时仍删除并返回引用列表
+func (s *AssetService) DeleteAsset(assetID uint, force bool) (*AssetUsageReport, error) {
+	report, err := s.GetAssetUsages(assetID)
+	if err != nil {
+		return nil, err
+	}
+	if report.InUse && !force {
+		return report, &AssetInUseError{Report: report}
+	}
+
+	var asset models.Asset
+	if err := s.db.Select("id", "local_path").Where("id = ?", assetID).First(&asset).Error; err != nil {
+		return nil, fmt.Errorf("asset not found")
+	}
 	result := s.db.Where("id = ?", assetID).Delete(&models.Asset{})
 	if result.Error != nil {
-		return result.Error
+		return nil, result.Error
 	}
 	if result.RowsAffected == 0 {
-		return fmt.Errorf("asset not found")
+		return nil, fmt.Errorf("asset not found")
+	}
+	releaseStorageKey(s.db, s.log, asset.LocalPath)
+
+	if report.InUse {
+		s.log.Warnw("Asset deleted while in use", "asset_id", assetID, "usages", len(report.Usages))
 	}
-	return nil
+	return report, nil
 }
 
 func (s *AssetService) ImportFromImageGen(imageGenID uint) (*models.Asset, error) {
@@ -236,11 +314,15 @@ func (s *AssetService) ImportFromImageGen(imageGenID uint) (*models.Asset, error
 		ImageGenID: &imageGenID,
 		Width:      imageGen.Width,
 		Height:     imageGen.Height,
+		LocalPath:  imageGen.LocalPath,
 	}
+	s.applyMetadata(asset)
 
 	if err := s.db.Create(asset).Error; err != nil {
 		return nil, fmt.Errorf("failed to create asset: %w", err)
 	}
+	retainStorageKey(s.db, s.log, asset.LocalPath)
+	s.generatePreviewsAsync(asset)
 
 	return asset, nil
 }
@@ -282,10 +364,13 @@ func (s *AssetService) ImportFromVideoGen(videoGenID uint) (*models.Asset, error
 	if videoGen.FirstFrameURL != nil {
 		asset.ThumbnailURL = videoGen.FirstFrameURL
 	}
+	s.applyMetadata(asset)
 
 	if err := s.db.Create(asset).Error; err != nil {
 		return nil, fmt.Errorf("failed to create asset: %w", err)
 	}
+	retainStorageKey(s.db, s.log, asset.LocalPath)
+	s.generatePreviewsAsync(asset)
 
 	return asset, nil
 }
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+func TestAssetImportAndDeleteTrackBlobRefs(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		drama, _ := createTestEpisode(t, db)
+		log := logger.NewLogger(false)
+
+		// 转存时去重存储为生成记录计了一次引用
+		key := "videos/ab/abcdef.mp4"
+		blob := &models.StorageBlob{Hash: "abcdef", Key: key, Size: 1, RefCount: 1}
+		mustCreate(t, db, blob)
+		videoURL := cfg.Storage.BaseURL + "/" + key
+		videoGen := &models.VideoGeneration{DramaID: drama.ID, Provider: "test", Prompt: "clip",
+			Status: models.VideoStatusCompleted, VideoURL: &videoURL, LocalPath: &key}
+		mustCreate(t, db, videoGen)
+
+		refCount := func() int {
+			var b models.StorageBlob
+			if err := db.First(&b, blob.ID).Error; err != nil {
+				t.Fatalf("load blob: %v", err)
+			}
+			return b.RefCount
+		}
+
+		assetService := NewAssetService(db, testStorage(t, cfg), cfg.Storage.LocalPath, cfg.Storage.BaseURL, log)
+		asset, err := assetService.ImportFromVideoGen(videoGen.ID)
+		if err != nil {
+			t.Fatalf("import from video generation: %v", err)
+		}
+		if got := refCount(); got != 2 {
+			t.Fatalf("expected 2 refs after import, got %d", got)
+		}
+
+		if _, err := assetService.DeleteAsset(asset.ID, true); err != nil {
+			t.Fatalf("delete asset: %v", err)
+		}
+		if got := refCount(); got != 1 {
+			t.Fatalf("expected 1 ref after deleting asset, got %d", got)
+		}
+
+		videoService := &VideoGenerationService{db: db, log: log}
+		if err := videoService.DeleteVideoGeneration(videoGen.ID); err != nil {
+			t.Fatalf("delete video generation: %v", err)
+		}
+		if err := videoService.DeleteVideoGeneration(videoGen.ID); err != nil {
+			t.Fatalf("delete video generation again: %v", err)
+		}
+		if got := refCount(); got != 0 {
+			t.Fatalf("expected 0 refs after deleting generation, got %d", got)
+		}
+	})
+}
+
+func TestFailedPreviewRegenerationKeepsExistingPreviews(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		sourceKey := "images/source.png"
+		writeTestFile(t, filepath.Join(cfg.Storage.LocalPath, sourceKey))
+		asset := &models.Asset{Name: "still", Type: models.AssetTypeImage, URL: cfg.Storage.BaseURL + "/" + sourceKey, LocalPath: &sourceKey}
+		mustCreate(t, db, asset)
+		thumbKey := fmt.Sprintf("previews/asset_%d/1_thumb.jpg", asset.ID)
+		thumbURL := cfg.Storage.BaseURL + "/" + thumbKey
+		writeTestFile(t, filepath.Join(cfg.Storage.LocalPath, thumbKey))
+		if err := db.Model(asset).Update("thumbnail_url", thumbURL).Error; err != nil {
+			t.Fatalf("set thumbnail: %v", err)
+		}
+
+		// 源文件不是有效图片，缩略图生成失败：原有预览文件与记录都应保留，且不残留新文件
+		assetService := newTestAssetService(t, db, cfg)
+		if _, err := assetService.GenerateAssetPreviews(asset.ID); err == nil {
+			t.Fatal("expected preview generation to fail")
+		}
+
+		var saved models.Asset
+		if err := db.First(&saved, asset.ID).Error; err != nil {
+			t.Fatalf("load asset: %v", err)
+		}
+		if saved.ThumbnailURL == nil || *saved.ThumbnailURL != thumbURL {
+			t.Fatalf("thumbnail url changed: %v", saved.ThumbnailURL)
+		}
+		entries, err := os.ReadDir(filepath.Join(cfg.Storage.LocalPath, filepath.Dir(thumbKey)))
+		if err != nil {
+			t.Fatalf("read preview dir: %v", err)
+		}
+		if len(entries) != 1 || entries[0].Name() != "1_thumb.jpg" {
+			t.Fatalf("expected only the existing preview to remain, got %v", entries)
+		}
+	})
+}
+
+func TestCreateAssetProbesRemoteFilesInBackground(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+			png.Encode(w, image.NewRGBA(image.Rect(0, 0, 4, 3)))
+		}))
+		defer server.Close()
+
+		// 远程文件尚不可读时，创建请求不应等待探测
+		assetService := newTestAssetService(t, db, cfg)
+		asset, err := assetService.CreateAsset(&CreateAssetRequest{Name: "remote", Type: models.AssetTypeImage, URL: server.URL + "/still.png"})
+		if err != nil {
+			t.Fatalf("create asset: %v", err)
+		}
+		if asset.ProbedAt != nil {
+			t.Fatal("remote asset probed inside the request")
+		}
+		close(release)
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			var saved models.Asset
+			if err := db.First(&saved, asset.ID).Error; err != nil {
+				t.Fatalf("load asset: %v", err)
+			}
+			if saved.ProbedAt != nil {
+				if saved.Width == nil || *saved.Width != 4 || *saved.Height != 3 {
+					t.Fatalf("unexpected probed size: %v x %v", saved.Width, saved.Height)
+				}
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("remote asset was not probed in background")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	models "github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/database"
+	"gorm.io/datatypes"
+)
+
+// 素材引用来源
+const (
+	AssetUsageStoryboard   = "storyboard"
+	AssetUsageTimelineClip = "timeline_clip"
+	AssetUsageVideoMerge   = "video_merge"
+	AssetUsageCharacter    = "character"
+	AssetUsageScene        = "scene"
+	AssetUsageProp         = "prop"
+	AssetUsageEpisode      = "episode"
+	AssetUsageBranding     = "drama_branding"
+)
+
+// AssetUsage 素材的一处引用
+type AssetUsage struct {
+	Type      string `json:"type"`
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Field     string `json:"field"` // 引用字段，如 video_url、scenes、asset_id
+	DramaID   uint   `json:"drama_id,omitempty"`
+	EpisodeID *uint  `json:"episode_id,omitempty"`
+}
+
+// AssetUsageReport 素材引用汇总
+type AssetUsageReport struct {
+	AssetID uint         `json:"asset_id"`
+	InUse   bool         `json:"in_use"`
+	Usages  []AssetUsage `json:"usages"`
+}
+
+// AssetInUseError 删除仍被引用的素材时返回
+type AssetInUseError struct {
+	Report *AssetUsageReport
+}
+
+func (e *AssetInUseError) Error() string {
+	return fmt.Sprintf("asset is in use (%d references)", len(e.Report.Usages))
+}
+
+// assetReferences 收集素材（及其来源生成记录）可能被引用的全部URL/路径形式
+func (s *AssetService) assetReferences(asset *models.Asset) []string {
+	seen := make(map[string]bool)
+	var refs []string
+	add := func(value *string) {
+		if value == nil {
+			return
+		}
+		v := strings.TrimSpace(*value)
+		if v == "" || seen[v] {
+			return
+		}
+		seen[v] = true
+		refs = append(refs, v)
+	}
+	addPath := func(localPath *string) {
+		if localPath == nil || *localPath == "" {
+			return
+		}
+		add(localPath)
+		rel := strings.TrimPrefix(*localPath, "/")
+		if filepath.IsAbs(*localPath) {
+			if r, err := filepath.Rel(s.storagePath, *localPath); err == nil && !strings.HasPrefix(r, "..") {
+				rel = r
+			}
+		}
+		rel = filepath.ToSlash(rel)
+		abs := filepath.Join(s.storagePath, rel)
+		url := fmt.Sprintf("%s/%s", s.baseURL, rel)
+		add(&rel)
+		add(&abs)
+		add(&url)
+	}
+
+	add(&asset.URL)
+	addPath(asset.LocalPath)
+
+	if asset.ImageGenID != nil {
+		var imageGen models.ImageGeneration
+		if err := s.db.Where("id = ?", *asset.ImageGenID).First(&imageGen).Error; err == nil {
+			add(imageGen.ImageURL)
+			addPath(imageGen.LocalPath)
+		}
+	}
+	if asset.VideoGenID != nil {
+		var videoGen models.VideoGeneration
+		if err := s.db.Where("id = ?", *asset.VideoGenID).First(&videoGen).Error; err == nil {
+			add(videoGen.VideoURL)
+			addPath(videoGen.LocalPath)
+		}
+	}
+
+	return refs
+}
+
+// GetAssetUsages 查找引用该素材的分镜、时间线、视频合成、角色、场景等
+func (s *AssetService) GetAssetUsages(assetID uint) (*AssetUsageReport, error) {
+	var asset models.Asset
+	if err := s.db.Where("id = ?", assetID).First(&asset).Error; err != nil {
+		return nil, fmt.Errorf("asset not found")
+	}
+
+	refs := s.assetReferences(&asset)
+	usages := []AssetUsage{}
+
+	// 分镜：直接引用视频/合成图
+	var storyboards []models.Storyboard
+	if err := s.db.Where("video_url IN ? OR composed_image IN ?", refs, refs).Find(&storyboards).Error; err != nil {
+		return nil, err
+	}
+	for _, sb := range storyboards {
+		field := "video_url"
+		if sb.VideoURL == nil || !containsString(refs, *sb.VideoURL) {
+			field = "composed_image"
+		}
+		episodeID := sb.EpisodeID
+		usages = append(usages, AssetUsage{Type: AssetUsageStoryboard, ID: sb.ID, Name: storyboardName(&sb), Field: field, EpisodeID: &episodeID})
+	}
+
+	// 分镜：素材挂在分镜下，定稿时作为该镜头的视频来源
+	if asset.StoryboardID != nil && asset.Type == models.AssetTypeVideo {
+		var sb models.Storyboard
+		if err := s.db.Where("id = ?", *asset.StoryboardID).First(&sb).Error; err == nil && !containsUsage(usages, AssetUsageStoryboard, sb.ID) {
+			episodeID := sb.EpisodeID
+			usages = append(usages, AssetUsage{Type: AssetUsageStoryboard, ID: sb.ID, Name: storyboardName(&sb), Field: "storyboard_id", EpisodeID: &episodeID})
+		}
+	}
+
+	// 时间线片段
+	if s.db.Migrator().HasTable(&models.TimelineClip{}) {
+		var clips []models.TimelineClip
+		if err := s.db.Where("asset_id = ?", asset.ID).Find(&clips).Error; err != nil {
+			return nil, err
+		}
+		for _, clip := range clips {
+			usages = append(usages, AssetUsage{Type: AssetUsageTimelineClip, ID: clip.ID, Name: clip.Name, Field: "asset_id"})
+		}
+	}
+
+	// 视频合成：场景列表中的视频地址，或混音选项中的背景音乐
+	// JSON 列的序列化格式因数据库而异（MySQL 会在冒号后加空格且不转义 &），
+	// LIKE 只用与格式无关的片段粗筛，再解析 JSON 精确比对
+	scenesText, optionsText := database.AsText(s.db, "scenes"), database.AsText(s.db, "options")
+	conditions := []string{optionsText + " LIKE ?"}
+	args := []interface{}{fmt.Sprintf("%%bgm_asset_id%%%d%%", asset.ID)}
+	for _, ref := range refs {
+		if fragment := jsonLikeFragment(ref); fragment != "" {
+			conditions = append(conditions, scenesText+" LIKE ?")
+			args = append(args, "%"+fragment+"%")
+		}
+	}
+	var merges []models.VideoMerge
+	if err := s.db.Where(strings.Join(conditions, " OR "), args...).Find(&merges).Error; err != nil {
+		return nil, err
+	}
+	for _, m := range merges {
+		var field string
+		switch {
+		case mergeScenesReference(m.Scenes, refs):
+			field = "scenes"
+		case mergeBGMAssetID(m.Options) == asset.ID:
+			field = "options.audio_mix.bgm_asset_id"
+		default:
+			continue
+		}
+		episodeID := m.EpisodeID
+		usages = append(usages, AssetUsage{Type: AssetUsageVideoMerge, ID: m.ID, Name: m.Title, Field: field, DramaID: m.DramaID, EpisodeID: &episodeID})
+	}
+
+	// 角色、场景、道具图片
+	var characters []models.Character
+	if err := s.db.Where("image_url IN ? OR local_path IN ?", refs, refs).Find(&characters).Error; err != nil {
+		return nil, err
+	}
+	for _, ch := range characters {
+		usages = append(usages, AssetUsage{Type: AssetUsageCharacter, ID: ch.ID, Name: ch.Name, Field: "image_url", DramaID: ch.DramaID})
+	}
+
+	var scenes []models.Scene
+	if err := s.db.Where("image_url IN ? OR local_path IN ?", refs, refs).Find(&scenes).Error; err != nil {
+		return nil, err
+	}
+	for _, sc := range scenes {
+		usages = append(usages, AssetUsage{Type: AssetUsageScene, ID: sc.ID, Name: sc.Location, Field: "image_url", DramaID: sc.DramaID, EpisodeID: sc.EpisodeID})
+	}
+
+	var props []models.Prop
+	if err := s.db.Where("image_url IN ? OR local_path IN ?", refs, refs).Find(&props).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range props {
+		usages = append(usages, AssetUsage{Type: AssetUsageProp, ID: p.ID, Name: p.Name, Field: "image_url", DramaID: p.DramaID})
+	}
+
+	// 剧集成片与封面
+	var episodes []models.Episode
+	if err := s.db.Where("video_url IN ? OR thumbnail IN ?", refs, refs).Find(&episodes).Error; err != nil {
+		return nil, err
+	}
+	for _, ep := range episodes {
+		field := "video_url"
+		if ep.VideoURL == nil || !containsString(refs, *ep.VideoURL) {
+			field = "thumbnail"
+		}
+		episodeID := ep.ID
+		usages = append(usages, AssetUsage{Type: AssetUsageEpisode, ID: ep.ID, Name: ep.Title, Field: field, DramaID: ep.DramaID, EpisodeID: &episodeID})
+	}
+
+	// 品牌设置中的片头、片尾、水印
+	var brandings []models.DramaBranding
+	if err := s.db.Where("intro_video IN ? OR outro_video IN ? OR logo_image IN ?", refs, refs, refs).Find(&brandings).Error; err != nil {
+		return nil, err
+	}
+	for _, b := range brandings {
+		field := "logo_image"
+		if b.IntroVideo != nil && containsString(refs, *b.IntroVideo) {
+			field = "intro_video"
+		} else if b.OutroVideo != nil && containsString(refs, *b.OutroVideo) {
+			field = "outro_video"
+		}
+		usages = append(usages, AssetUsage{Type: AssetUsageBranding, ID: b.ID, Name: "branding", Field: field, DramaID: b.DramaID})
+	}
+
+	return &AssetUsageReport{
+		AssetID: asset.ID,
+		InUse:   len(usages) > 0,
+		Usages:  usages,
+	}, nil
+}
+
+func storyboardName(sb *models.Storyboard) string {
+	if sb.Title != nil && *sb.Title != "" {
+		return *sb.Title
+	}
+	return fmt.Sprintf("镜头 %d", sb.StoryboardNumber)
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func containsUsage(usages []AssetUsage, usageType string, id uint) bool {
+	for _, u := range usages {
+		if u.Type == usageType && u.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeScenesReference 合成场景列表中是否有片段使用了 refs 中的视频
+func mergeScenesReference(scenes datatypes.JSON, refs []string) bool {
+	var clips []models.SceneClip
+	if err := json.Unmarshal(scenes, &clips); err != nil {
+		return false
+	}
+	for _, clip := range clips {
+		if containsString(refs, strings.TrimSpace(clip.VideoURL)) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeBGMAssetID 合成选项中指定的背景音乐素材ID，未指定时返回 0
+func mergeBGMAssetID(options datatypes.JSON) uint {
+	var opts MergeOutputOptions
+	if len(options) == 0 || json.Unmarshal(options, &opts) != nil || opts.AudioMix == nil || opts.AudioMix.BGMAssetID == nil {
+		return 0
+	}
+	return *opts.AudioMix.BGMAssetID
+}
+
+// jsonLikeFragment 返回引用中最长的一段不含 JSON 转义差异字符（" \ & < >）的片段，
+// 各数据库序列化 JSON 时这部分文本保持不变，可用于 LIKE 粗筛
+func jsonLikeFragment(value string) string {
+	longest := ""
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool {
+		return strings.ContainsRune(`"\&<>`, r)
+	}) {
+		if len(part) > len(longest) {
+			longest = part
+		}
+	}
+	return longest
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/drama-generator/backend/domain/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestAssetUsagesInJSONColumns(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		drama, episode := createTestEpisode(t, db)
+
+		// JSON 列按文本匹配素材引用
+		videoURL := cfg.Storage.BaseURL + "/videos/clip.mp4"
+		asset := &models.Asset{Name: "clip", Type: models.AssetTypeVideo, URL: videoURL}
+		mustCreate(t, db, asset)
+		merge := &models.VideoMerge{EpisodeID: episode.ID, DramaID: drama.ID, Provider: "ffmpeg", Status: "completed",
+			Scenes: datatypes.JSON(`[{"scene_id":1,"video_url":"` + videoURL + `"}]`)}
+		mustCreate(t, db, merge)
+		report, err := newTestAssetService(t, db, cfg).GetAssetUsages(asset.ID)
+		if err != nil {
+			t.Fatalf("asset usages: %v", err)
+		}
+		if !containsUsage(report.Usages, AssetUsageVideoMerge, merge.ID) {
+			t.Fatalf("video merge usage not found: %+v", report.Usages)
+		}
+
+		// MySQL 风格的序列化：冒号后带空格，& 不转义
+		signedURL := "https://cdn.example.com/videos/signed.mp4?a=1&b=2"
+		signed := &models.Asset{Name: "signed", Type: models.AssetTypeVideo, URL: signedURL}
+		bgm := &models.Asset{Name: "bgm", Type: models.AssetTypeAudio, URL: cfg.Storage.BaseURL + "/audio/bgm.mp3"}
+		mustCreate(t, db, signed, bgm)
+		formatted := &models.VideoMerge{EpisodeID: episode.ID, DramaID: drama.ID, Provider: "ffmpeg", Status: "completed",
+			Scenes:  datatypes.JSON(`[{"scene_id": 1, "video_url": "` + signedURL + `"}]`),
+			Options: datatypes.JSON(fmt.Sprintf(`{"audio_mix": {"bgm_asset_id": %d}}`, bgm.ID))}
+		mustCreate(t, db, formatted)
+		service := newTestAssetService(t, db, cfg)
+		for _, a := range []*models.Asset{signed, bgm} {
+			report, err := service.GetAssetUsages(a.ID)
+			if err != nil {
+				t.Fatalf("asset usages: %v", err)
+			}
+			if !containsUsage(report.Usages, AssetUsageVideoMerge, formatted.ID) {
+				t.Fatalf("asset %s: formatted merge usage not found: %+v", a.Name, report.Usages)
+			}
+			if containsUsage(report.Usages, AssetUsageVideoMerge, merge.ID) {
+				t.Fatalf("asset %s: unrelated merge reported: %+v", a.Name, report.Usages)
+			}
+		}
+	})
+}
+
+func TestJSONLikeFragment(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"http://localhost/static/videos/a.mp4", "http://localhost/static/videos/a.mp4"},
+		{"https://cdn.example.com/videos/signed.mp4?a=1&b=2", "https://cdn.example.com/videos/signed.mp4?a=1"},
+		{`a"b<c>d\e`, "a"},
+		{"&&", ""},
+	}
+	for _, tt := range tests {
+		if got := jsonLikeFragment(tt.value); got != tt.want {
+			t.Errorf("jsonLikeFragment(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
@@ -16,7 +16,7 @@ type AudioExtractionService struct {
 
 func NewAudioExtractionService(log *logger.Logger) *AudioExtractionService {
 	return &AudioExtractionService{
-		ffmpeg: ffmpeg.NewFFmpeg(log),
+		ffmpeg: ffmpeg.NewFFmpeg(log, nil),
 		log:    log,
 	}
 }
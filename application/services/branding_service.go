@@ -0,0 +1,265 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	models "github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/external/ffmpeg"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type BrandingService struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewBrandingService(db *gorm.DB, log *logger.Logger) *BrandingService {
+	return &BrandingService{
+		db:  db,
+		log: log,
+	}
+}
+
+type UpdateBrandingRequest struct {
+	Enabled           *bool    `json:"enabled"`
+	IntroVideo        *string  `json:"intro_video"`
+	OutroVideo        *string  `json:"outro_video"`
+	LogoImage         *string  `json:"logo_image"`
+	WatermarkPosition *string  `json:"watermark_position"`
+	WatermarkOpacity  *float64 `json:"watermark_opacity"`
+	WatermarkScale    *float64 `json:"watermark_scale"`
+	WatermarkMargin   *int     `json:"watermark_margin"`
+	TitleCardText     *string  `json:"title_card_text"`
+	TitleCardDuration *float64 `json:"title_card_duration"`
+	TitleCardFontFile *string  `json:"title_card_font_file"`
+	TitleCardColor    *string  `json:"title_card_color"`
+	TitleCardBgColor  *string  `json:"title_card_bg_color"`
+	CoverTime         *float64 `json:"cover_time"`
+}
+
+var watermarkPositions = map[string]bool{
+	"top-left": true, "top-right": true, "bottom-left": true, "bottom-right": true, "center": true,
+}
+
+// GetBranding 获取剧本品牌设置，未设置时返回 nil
+func (s *BrandingService) GetBranding(dramaID uint) (*models.DramaBranding, error) {
+	var branding models.DramaBranding
+	if err := s.db.Where("drama_id = ?", dramaID).First(&branding).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &branding, nil
+}
+
+// UpdateBranding 创建或更新剧本品牌设置
+func (s *BrandingService) UpdateBranding(dramaID uint, req *UpdateBrandingRequest) (*models.DramaBranding, error) {
+	var drama models.Drama
+	if err := s.db.Where("id = ?", dramaID).First(&drama).Error; err != nil {
+		return nil, fmt.Errorf("drama not found")
+	}
+
+	if req.WatermarkPosition != nil && !watermarkPositions[*req.WatermarkPosition] {
+		return nil, fmt.Errorf("invalid watermark position: %s", *req.WatermarkPosition)
+	}
+	if req.WatermarkOpacity != nil && (*req.WatermarkOpacity <= 0 || *req.WatermarkOpacity > 1) {
+		return nil, fmt.Errorf("watermark opacity must be in (0, 1]")
+	}
+	if req.WatermarkScale != nil && (*req.WatermarkScale <= 0 || *req.WatermarkScale > 1) {
+		return nil, fmt.Errorf("watermark scale must be in (0, 1]")
+	}
+
+	branding, err := s.GetBranding(dramaID)
+	if err != nil {
+		return nil, err
+	}
+	if branding == nil {
+		branding = &models.DramaBranding{
+			DramaID:           dramaID,
+			Enabled:           true,
+			WatermarkPosition: "top-right",
+			WatermarkOpacity:  0.8,
+			WatermarkScale:    0.15,
+			WatermarkMargin:   24,
+			TitleCardDuration: 3,
+			TitleCardColor:    "#FFFFFF",
+			TitleCardBgColor:  "#000000",
+			CoverTime:         1,
+		}
+	}
+
+	if req.Enabled != nil {
+		branding.Enabled = *req.Enabled
+	}
+	if req.IntroVideo != nil {
+		branding.IntroVideo = emptyToNil(*req.IntroVideo)
+	}
+	if req.OutroVideo != nil {
+		branding.OutroVideo = emptyToNil(*req.OutroVideo)
+	}
+	if req.LogoImage != nil {
+		branding.LogoImage = emptyToNil(*req.LogoImage)
+	}
+	if req.WatermarkPosition != nil {
+		branding.WatermarkPosition = *req.WatermarkPosition
+	}
+	if req.WatermarkOpacity != nil {
+		branding.WatermarkOpacity = *req.WatermarkOpacity
+	}
+	if req.WatermarkScale != nil {
+		branding.WatermarkScale = *req.WatermarkScale
+	}
+	if req.WatermarkMargin != nil {
+		branding.WatermarkMargin = *req.WatermarkMargin
+	}
+	if req.TitleCardText != nil {
+		branding.TitleCardText = emptyToNil(*req.TitleCardText)
+	}
+	if req.TitleCardDuration != nil {
+		branding.TitleCardDuration = *req.TitleCardDuration
+	}
+	if req.TitleCardFontFile != nil {
+		branding.TitleCardFontFile = emptyToNil(*req.TitleCardFontFile)
+	}
+	if req.TitleCardColor != nil {
+		branding.TitleCardColor = *req.TitleCardColor
+	}
+	if req.TitleCardBgColor != nil {
+		branding.TitleCardBgColor = *req.TitleCardBgColor
+	}
+	if req.CoverTime != nil {
+		branding.CoverTime = *req.CoverTime
+	}
+
+	if err := s.db.Save(branding).Error; err != nil {
+		return nil, fmt.Errorf("failed to save branding: %w", err)
+	}
+	return branding, nil
+}
+
+func emptyToNil(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// renderTitleCardText 替换标题卡占位符
+func renderTitleCardText(template string, drama *models.Drama, episode *models.Episode) string {
+	return strings.NewReplacer(
+		"{drama}", drama.Title,
+		"{episode}", strconv.Itoa(episode.EpisodeNum),
+		"{title}", episode.Title,
+	).Replace(template)
+}
+
+// titleCardOptions 由品牌设置生成标题卡参数
+func (s *VideoMergeService) titleCardOptions(branding *models.DramaBranding, drama *models.Drama, episode *models.Episode) *ffmpeg.TitleCardOptions {
+	if branding == nil || branding.TitleCardText == nil {
+		return nil
+	}
+	card := &ffmpeg.TitleCardOptions{
+		Text:      renderTitleCardText(*branding.TitleCardText, drama, episode),
+		Duration:  branding.TitleCardDuration,
+		FontColor: branding.TitleCardColor,
+		BgColor:   branding.TitleCardBgColor,
+	}
+	if branding.TitleCardFontFile != nil {
+		card.FontFile = *branding.TitleCardFontFile
+	}
+	return card
+}
+
+// applyBranding 为成片添加片头、标题卡、片尾和水印，返回新的相对路径和正片起始时间（秒）
+func (s *VideoMergeService) applyBranding(videoMerge *models.VideoMerge, branding *models.DramaBranding, mergedRelPath string) (string, float64, error) {
+	var episode models.Episode
+	if err := s.db.Preload("Drama").Where("id = ?", videoMerge.EpisodeID).First(&episode).Error; err != nil {
+		return "", 0, fmt.Errorf("episode not found")
+	}
+
+	opts := &ffmpeg.BrandingOptions{
+		VideoPath: filepath.Join(s.storagePath, mergedRelPath),
+		TitleCard: s.titleCardOptions(branding, &episode.Drama, &episode),
+	}
+	if branding.IntroVideo != nil {
+		opts.IntroPath = storageMediaPath(s.fileStorage, s.storagePath, s.baseURL, *branding.IntroVideo)
+	}
+	if branding.OutroVideo != nil {
+		opts.OutroPath = storageMediaPath(s.fileStorage, s.storagePath, s.baseURL, *branding.OutroVideo)
+	}
+	if branding.LogoImage != nil {
+		opts.Watermark = &ffmpeg.WatermarkOptions{
+			ImagePath: storageMediaPath(s.fileStorage, s.storagePath, s.baseURL, *branding.LogoImage),
+			Position:  branding.WatermarkPosition,
+			Opacity:   branding.WatermarkOpacity,
+			Scale:     branding.WatermarkScale,
+			Margin:    branding.WatermarkMargin,
+		}
+	}
+
+	if opts.IntroPath == "" && opts.OutroPath == "" && opts.Watermark == nil && opts.TitleCard == nil {
+		return mergedRelPath, 0, nil
+	}
+
+	mainOffset, err := s.ffmpeg.MainOffset(opts)
+	if err != nil {
+		return "", 0, err
+	}
+
+	fileName := fmt.Sprintf("merged_%d_branded.mp4", time.Now().UnixNano())
+	relPath := filepath.Join("videos", "merged", fileName)
+	opts.OutputPath = filepath.Join(s.storagePath, relPath)
+
+	if _, err := s.ffmpeg.ApplyBranding(opts); err != nil {
+		return "", 0, err
+	}
+
+	s.log.Infow("Branding applied to episode", "merge_id", videoMerge.ID, "output", relPath, "main_offset", mainOffset)
+	return relPath, mainOffset, nil
+}
+
+// generateCover 从正片截取封面，写入 Episode.Thumbnail，剧本无封面或为第一集时同步写入 Drama.Thumbnail
+func (s *VideoMergeService) generateCover(videoMerge *models.VideoMerge, branding *models.DramaBranding, mainRelPath string) error {
+	var episode models.Episode
+	if err := s.db.Preload("Drama").Where("id = ?", videoMerge.EpisodeID).First(&episode).Error; err != nil {
+		return fmt.Errorf("episode not found")
+	}
+
+	coverTime := 1.0
+	var title *ffmpeg.TitleCardOptions
+	if branding != nil && branding.Enabled {
+		if branding.CoverTime > 0 {
+			coverTime = branding.CoverTime
+		}
+		title = s.titleCardOptions(branding, &episode.Drama, &episode)
+	}
+
+	relPath := filepath.Join("covers", fmt.Sprintf("episode_%d_%d.jpg", episode.ID, time.Now().UnixNano()))
+	if _, err := s.ffmpeg.ExtractCover(filepath.Join(s.storagePath, mainRelPath), filepath.Join(s.storagePath, relPath), coverTime, title); err != nil {
+		return err
+	}
+
+	coverURL, err := publishMedia(s.fileStorage, s.storagePath, relPath)
+	if err != nil {
+		return err
+	}
+	if err := s.db.Model(&models.Episode{}).Where("id = ?", episode.ID).Update("thumbnail", coverURL).Error; err != nil {
+		return fmt.Errorf("failed to update episode thumbnail: %w", err)
+	}
+	if episode.Drama.Thumbnail == nil || *episode.Drama.Thumbnail == "" || episode.EpisodeNum == 1 {
+		if err := s.db.Model(&models.Drama{}).Where("id = ?", episode.DramaID).Update("thumbnail", coverURL).Error; err != nil {
+			return fmt.Errorf("failed to update drama thumbnail: %w", err)
+		}
+	}
+
+	s.log.Infow("Episode cover generated", "episode_id", episode.ID, "cover", coverURL)
+	return nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+func TestUpdateBrandingPersistsZeroValues(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		drama, _ := createTestEpisode(t, db)
+		brandingService := NewBrandingService(db, logger.NewLogger(false))
+
+		// 新建记录时显式传入的零值不能被数据库默认值覆盖
+		enabled, margin, coverTime := false, 0, 0.0
+		if _, err := brandingService.UpdateBranding(drama.ID, &UpdateBrandingRequest{
+			Enabled:         &enabled,
+			WatermarkMargin: &margin,
+			CoverTime:       &coverTime,
+		}); err != nil {
+			t.Fatalf("update branding: %v", err)
+		}
+
+		branding, err := brandingService.GetBranding(drama.ID)
+		if err != nil || branding == nil {
+			t.Fatalf("get branding: %v", err)
+		}
+		if branding.Enabled || branding.WatermarkMargin != 0 || branding.CoverTime != 0 {
+			t.Fatalf("zero values not persisted: %+v", branding)
+		}
+		// 未传入的字段使用代码中的默认值
+		if branding.WatermarkPosition != "top-right" || branding.WatermarkOpacity != 0.8 {
+			t.Fatalf("defaults not applied: %+v", branding)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	models "github.com/drama-generator/backend/domain/models"
@@ -68,7 +69,8 @@ func (s *CharacterLibraryService) ListLibraryItems(query *CharacterLibraryQuery)
 	}
 
 	if query.Keyword != "" {
-		db = db.Where("name LIKE ? OR description LIKE ?", "%"+query.Keyword+"%", "%"+query.Keyword+"%")
+		keyword := "%" + strings.ToLower(query.Keyword) + "%"
+		db = db.Where("LOWER(name) LIKE ? OR LOWER(description) LIKE ?", keyword, keyword)
 	}
 
 	// 获取总数
@@ -322,33 +324,20 @@ func (s *CharacterLibraryService) GenerateCharacterImage(characterID string, ima
 		return nil, err
 	}
 
-	// 构建生成提示词 - 使用详细的外貌描述，添加干净背景要求
-	prompt := ""
-
-	// 优先使用appearance字段，它包含了最详细的外貌描述
-	if character.Appearance != nil && *character.Appearance != "" {
-		prompt = *character.Appearance
-	} else if character.Description != nil && *character.Description != "" {
-		prompt = *character.Description
-	} else {
-		prompt = character.Name
-	}
-
-	// 使用已经加载的 drama 的 style 信息
-	if drama.Style != "" && drama.Style != "realistic" {
-		prompt += ", " + drama.Style
-	}
+	prompt := characterImagePrompt(&character, &drama)
 	// 调用图片生成服务
 	dramaIDStr := fmt.Sprintf("%d", character.DramaID)
 	imageType := "character"
+	// 默认 2560x1440（3,686,400像素，满足API最低要求），剧本预设了画面比例时按预设
+	imageSize := presetImageSize(loadDramaPreset(s.db, character.DramaID), "2560x1440")
 	req := &GenerateImageRequest{
 		DramaID:     dramaIDStr,
 		CharacterID: &character.ID,
 		ImageType:   imageType,
 		Prompt:      prompt,
-		Provider:    "openai",    // 或从配置读取
-		Model:       modelName,   // 使用用户指定的模型
-		Size:        "2560x1440", // 3,686,400像素，满足API最低要求（16:9比例）
+		Provider:    "openai",  // 或从配置读取
+		Model:       modelName, // 使用用户指定的模型
+		Size:        imageSize,
 		Quality:     "standard",
 	}
 
@@ -366,6 +355,25 @@ func (s *CharacterLibraryService) GenerateCharacterImage(characterID string, ima
 	return imageGen, nil
 }
 
+// characterImagePrompt 构建角色形象的生成提示词，使用详细的外貌描述和剧本风格
+func characterImagePrompt(character *models.Character, drama *models.Drama) string {
+	prompt := ""
+
+	// 优先使用appearance字段，它包含了最详细的外貌描述
+	if character.Appearance != nil && *character.Appearance != "" {
+		prompt = *character.Appearance
+	} else if character.Description != nil && *character.Description != "" {
+		prompt = *character.Description
+	} else {
+		prompt = character.Name
+	}
+
+	if drama.Style != "" && drama.Style != "realistic" {
+		prompt += ", " + drama.Style
+	}
+	return prompt
+}
+
 // waitAndUpdateCharacterImage 后台异步等待图片生成完成并更新角色image_url
 func (s *CharacterLibraryService) waitAndUpdateCharacterImage(characterID uint, imageGenID uint) {
 	maxAttempts := 60
@@ -537,7 +545,8 @@ func (s *CharacterLibraryService) processCharacterExtraction(taskID string, epis
 	prompt := s.promptI18n.GetCharacterExtractionPrompt(drama.Style)
 	userPrompt := fmt.Sprintf("【剧本内容】\n%s", script)
 
-	response, err := s.aiService.GenerateText(userPrompt, prompt, ai.WithMaxTokens(3000))
+	textModel := loadDramaPreset(s.db, episode.DramaID).TextModel
+	response, err := s.aiService.GenerateTextWithModel(textModel, userPrompt, prompt, ai.WithMaxTokens(3000))
 	if err != nil {
 		s.taskService.UpdateTaskError(taskID, err)
 		return
@@ -0,0 +1,414 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	models "github.com/drama-generator/backend/domain/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// maxReferenceImages 自动附加角色参考图后单次生成使用的参考图上限，超出部分的图片服务商大多会拒绝或忽略
+const maxReferenceImages = 6
+
+// characterViews 分视角生成时的视角及顺序
+var characterViews = []string{
+	models.CharacterViewFront,
+	models.CharacterViewSide,
+	models.CharacterViewBack,
+	models.CharacterViewCloseup,
+}
+
+// characterReferenceMu 多个视角的图片可能同时完成，串行化对 reference_images 的读改写
+var characterReferenceMu sync.Mutex
+
+// parseCharacterReferenceImages 解析 Character.ReferenceImages，兼容手动上传的字符串数组
+func parseCharacterReferenceImages(raw datatypes.JSON) []models.CharacterReferenceImage {
+	if len(raw) == 0 {
+		return nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil
+	}
+
+	refs := make([]models.CharacterReferenceImage, 0, len(items))
+	for _, item := range items {
+		var url string
+		if err := json.Unmarshal(item, &url); err == nil {
+			if url != "" {
+				refs = append(refs, models.CharacterReferenceImage{ImageURL: url})
+			}
+			continue
+		}
+		var ref models.CharacterReferenceImage
+		if err := json.Unmarshal(item, &ref); err == nil && (ref.ImageURL != "" || ref.LocalPath != "") {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// referenceSource 生成时使用的图片地址，优先本地路径（服务商返回的URL会过期）
+func referenceSource(ref models.CharacterReferenceImage) string {
+	if ref.LocalPath != "" {
+		return ref.LocalPath
+	}
+	return ref.ImageURL
+}
+
+// characterMainImage 角色主形象图
+func characterMainImage(character models.Character) string {
+	if character.LocalPath != nil && *character.LocalPath != "" {
+		return *character.LocalPath
+	}
+	if character.ImageURL != nil {
+		return *character.ImageURL
+	}
+	return ""
+}
+
+// characterViewImage 返回指定视角的参考图，不存在时返回空字符串
+func characterViewImage(refs []models.CharacterReferenceImage, view string) string {
+	for _, ref := range refs {
+		if ref.View == view {
+			return referenceSource(ref)
+		}
+	}
+	return ""
+}
+
+// characterConsistencyImages 生成分镜图片时代表该角色的参考图：
+// 有合成设定图时只用设定图，否则用正面与面部特写，都没有时退回手动上传的第一张参考图或角色主形象
+func characterConsistencyImages(character models.Character) []string {
+	refs := parseCharacterReferenceImages(character.ReferenceImages)
+	if sheet := characterViewImage(refs, models.CharacterViewSheet); sheet != "" {
+		return []string{sheet}
+	}
+
+	var images []string
+	for _, view := range []string{models.CharacterViewFront, models.CharacterViewCloseup} {
+		if image := characterViewImage(refs, view); image != "" {
+			images = append(images, image)
+		}
+	}
+	if len(images) > 0 {
+		return images
+	}
+	if image := characterViewImage(refs, ""); image != "" {
+		return []string{image}
+	}
+	if image := characterMainImage(character); image != "" {
+		return []string{image}
+	}
+	return nil
+}
+
+// characterSubjectImage 主体参考只接受一张图，优先正面，其次面部特写、设定图
+func characterSubjectImage(character models.Character) string {
+	refs := parseCharacterReferenceImages(character.ReferenceImages)
+	for _, view := range []string{models.CharacterViewFront, models.CharacterViewCloseup, models.CharacterViewSheet, ""} {
+		if image := characterViewImage(refs, view); image != "" {
+			return image
+		}
+	}
+	return characterMainImage(character)
+}
+
+// storyboardCharacters 分镜关联的角色
+func storyboardCharacters(db *gorm.DB, storyboardID uint) []models.Character {
+	var storyboard models.Storyboard
+	if err := db.Preload("Characters").Where("id = ?", storyboardID).First(&storyboard).Error; err != nil {
+		return nil
+	}
+	return storyboard.Characters
+}
+
+// storyboardCharacterReferenceImages 分镜关联角色的参考图，按角色顺序展开
+func storyboardCharacterReferenceImages(db *gorm.DB, storyboardID uint) []string {
+	var images []string
+	for _, character := range storyboardCharacters(db, storyboardID) {
+		images = append(images, characterConsistencyImages(character)...)
+	}
+	return images
+}
+
+// storyboardSubjectReference 分镜第一个有形象图的角色的主体参考图
+func storyboardSubjectReference(db *gorm.DB, storyboardID uint) string {
+	for _, character := range storyboardCharacters(db, storyboardID) {
+		if image := characterSubjectImage(character); image != "" {
+			return image
+		}
+	}
+	return ""
+}
+
+// mergeReferenceImages 在已有参考图后追加新的参考图，去重并限制总数；已有的参考图始终保留
+func mergeReferenceImages(images, extra []string, limit int) []string {
+	seen := make(map[string]bool, len(images))
+	for _, image := range images {
+		seen[image] = true
+	}
+	for _, image := range extra {
+		if len(images) >= limit {
+			break
+		}
+		if image == "" || seen[image] {
+			continue
+		}
+		seen[image] = true
+		images = append(images, image)
+	}
+	return images
+}
+
+// saveCharacterReferenceImage 保存生成完成的角色参考图：替换同视角的旧图，合成设定图与分视角图互相替换，手动上传的参考图保留
+// 角色还没有主形象时，用正面图（或设定图）作为主形象
+func saveCharacterReferenceImage(db *gorm.DB, characterID uint, view, imageURL string, localPath *string) error {
+	characterReferenceMu.Lock()
+	defer characterReferenceMu.Unlock()
+
+	var character models.Character
+	if err := db.Where("id = ?", characterID).First(&character).Error; err != nil {
+		return err
+	}
+
+	ref := models.CharacterReferenceImage{View: view, ImageURL: imageURL}
+	if localPath != nil {
+		ref.LocalPath = *localPath
+	}
+
+	refs := []models.CharacterReferenceImage{ref}
+	for _, existing := range parseCharacterReferenceImages(character.ReferenceImages) {
+		replaced := existing.View == view || view == models.CharacterViewSheet || existing.View == models.CharacterViewSheet
+		if existing.View != "" && replaced {
+			continue
+		}
+		refs = append(refs, existing)
+	}
+	sort.SliceStable(refs, func(i, j int) bool {
+		return characterViewOrder(refs[i].View) < characterViewOrder(refs[j].View)
+	})
+
+	data, err := json.Marshal(refs)
+	if err != nil {
+		return err
+	}
+	updates := map[string]interface{}{"reference_images": datatypes.JSON(data)}
+	if characterMainImage(character) == "" && (view == models.CharacterViewFront || view == models.CharacterViewSheet) {
+		updates["image_url"] = imageURL
+		if localPath != nil {
+			updates["local_path"] = *localPath
+		}
+	}
+	return db.Model(&models.Character{}).Where("id = ?", characterID).Updates(updates).Error
+}
+
+// characterViewOrder 参考图排序：正面、侧面、背面、特写、设定图，手动上传的排在最后
+func characterViewOrder(view string) int {
+	for i, v := range characterViews {
+		if v == view {
+			return i
+		}
+	}
+	if view == models.CharacterViewSheet {
+		return len(characterViews)
+	}
+	return len(characterViews) + 1
+}
+
+// GenerateReferenceSheetRequest 角色参考图生成请求
+type GenerateReferenceSheetRequest struct {
+	Mode  string   `json:"mode"`  // sheet（默认）：所有视角合成一张设定图；views：每个视角单独一张
+	Views []string `json:"views"` // views 模式生成的视角，默认 front、side、back、closeup
+	Model string   `json:"model"` // 图片模型，为空时使用剧本预设或默认配置
+}
+
+// GenerateReferenceSheet 创建角色参考图（三视图与表情特写）生成任务，完成的图片保存到角色的 reference_images
+func (s *CharacterLibraryService) GenerateReferenceSheet(characterID string, imageService *ImageGenerationService, req *GenerateReferenceSheetRequest) (string, error) {
+	var character models.Character
+	if err := s.db.Where("id = ?", characterID).First(&character).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("character not found")
+		}
+		return "", err
+	}
+
+	var drama models.Drama
+	if err := s.db.Where("id = ? ", character.DramaID).First(&drama).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("unauthorized")
+		}
+		return "", err
+	}
+
+	var views []string
+	switch req.Mode {
+	case "", "sheet":
+		views = []string{models.CharacterViewSheet}
+	case "views":
+		requested := req.Views
+		if len(requested) == 0 {
+			requested = characterViews
+		}
+		for _, view := range requested {
+			if characterViewOrder(view) >= len(characterViews) {
+				return "", fmt.Errorf("invalid view: %s", view)
+			}
+		}
+		// 按固定顺序生成，正面图排在最前
+		for _, view := range characterViews {
+			if containsString(requested, view) {
+				views = append(views, view)
+			}
+		}
+	default:
+		return "", fmt.Errorf("invalid mode: %s", req.Mode)
+	}
+
+	task, err := s.taskService.CreateTask("character_reference_sheet", characterID)
+	if err != nil {
+		s.log.Errorw("Failed to create character reference sheet task", "error", err)
+		return "", fmt.Errorf("创建任务失败: %w", err)
+	}
+
+	go s.processReferenceSheet(task.ID, &character, &drama, imageService, views, req.Model)
+
+	s.log.Infow("Character reference sheet task created", "task_id", task.ID, "character_id", characterID, "views", views)
+	return task.ID, nil
+}
+
+// processReferenceSheet 生成参考图并等待完成；角色已有主形象时作为各视角的参考图
+func (s *CharacterLibraryService) processReferenceSheet(taskID string, character *models.Character, drama *models.Drama, imageService *ImageGenerationService, views []string, model string) {
+	s.taskService.UpdateTaskStatus(taskID, "processing", 0, "正在生成角色参考图...")
+
+	base := characterMainImage(*character)
+	var generated []models.ImageGeneration
+
+	// 没有主形象时先生成正面图，其余视角以正面图为参考，保证各视角是同一个人
+	if base == "" && len(views) > 1 && views[0] == models.CharacterViewFront {
+		imageGen, err := s.generateReferenceView(imageService, character, drama, views[0], base, model)
+		if err != nil {
+			s.taskService.UpdateTaskError(taskID, err)
+			return
+		}
+		done, err := s.waitReferenceImages(taskID, []uint{imageGen.ID}, len(views))
+		if err != nil {
+			s.taskService.UpdateTaskError(taskID, err)
+			return
+		}
+		generated = append(generated, done...)
+		if done[0].LocalPath != nil && *done[0].LocalPath != "" {
+			base = *done[0].LocalPath
+		} else if done[0].ImageURL != nil {
+			base = *done[0].ImageURL
+		}
+		views = views[1:]
+	}
+
+	ids := make([]uint, 0, len(views))
+	for _, view := range views {
+		imageGen, err := s.generateReferenceView(imageService, character, drama, view, base, model)
+		if err != nil {
+			s.taskService.UpdateTaskError(taskID, err)
+			return
+		}
+		ids = append(ids, imageGen.ID)
+	}
+	done, err := s.waitReferenceImages(taskID, ids, len(generated)+len(ids))
+	if err != nil {
+		s.taskService.UpdateTaskError(taskID, err)
+		return
+	}
+	generated = append(generated, done...)
+
+	images := make([]models.CharacterReferenceImage, 0, len(generated))
+	for _, imageGen := range generated {
+		ref := models.CharacterReferenceImage{View: *imageGen.FrameType}
+		if imageGen.ImageURL != nil {
+			ref.ImageURL = *imageGen.ImageURL
+		}
+		if imageGen.LocalPath != nil {
+			ref.LocalPath = *imageGen.LocalPath
+		}
+		images = append(images, ref)
+	}
+	s.taskService.UpdateTaskResult(taskID, map[string]interface{}{
+		"character_id":     character.ID,
+		"reference_images": images,
+	})
+	s.log.Infow("Character reference sheet completed", "task_id", taskID, "character_id", character.ID, "count", len(images))
+}
+
+// generateReferenceView 创建单个视角的图片生成记录，全身视角为竖图，设定图与表情特写为横图
+func (s *CharacterLibraryService) generateReferenceView(imageService *ImageGenerationService, character *models.Character, drama *models.Drama, view, base, model string) (*models.ImageGeneration, error) {
+	size := "1440x2560"
+	if view == models.CharacterViewSheet || view == models.CharacterViewCloseup {
+		size = "2560x1440"
+	}
+	frameType := view
+	req := &GenerateImageRequest{
+		DramaID:     fmt.Sprintf("%d", character.DramaID),
+		CharacterID: &character.ID,
+		ImageType:   string(models.ImageTypeCharacterReference),
+		FrameType:   &frameType,
+		Prompt:      characterImagePrompt(character, drama) + ", " + s.promptI18n.GetCharacterReferencePrompt(view),
+		Model:       model,
+		Size:        size,
+		Quality:     "standard",
+	}
+	if base != "" {
+		req.ReferenceImages = []string{base}
+	}
+
+	imageGen, err := imageService.GenerateImage(req)
+	if err != nil {
+		s.log.Errorw("Failed to generate character reference image", "error", err, "character_id", character.ID, "view", view)
+		return nil, fmt.Errorf("%s 参考图生成失败: %w", view, err)
+	}
+	return imageGen, nil
+}
+
+// waitReferenceImages 等待图片生成记录全部结束，total 为本次任务的图片总数（用于进度）
+func (s *CharacterLibraryService) waitReferenceImages(taskID string, ids []uint, total int) ([]models.ImageGeneration, error) {
+	offset := total - len(ids)
+	deadline := time.Now().Add(10 * time.Minute)
+	for {
+		if s.taskService.IsTaskCancelled(taskID) {
+			return nil, fmt.Errorf("任务已取消")
+		}
+
+		var records []models.ImageGeneration
+		if err := s.db.Where("id IN ?", ids).Order("id ASC").Find(&records).Error; err != nil {
+			return nil, err
+		}
+		finished := 0
+		for _, record := range records {
+			switch record.Status {
+			case models.ImageStatusCompleted:
+				finished++
+			case models.ImageStatusFailed:
+				msg := ""
+				if record.ErrorMsg != nil {
+					msg = *record.ErrorMsg
+				}
+				return nil, fmt.Errorf("%s 参考图生成失败: %s", getString(record.FrameType), msg)
+			}
+		}
+
+		s.taskService.UpdateTaskStatus(taskID, "processing", (offset+finished)*100/total,
+			fmt.Sprintf("角色参考图: %d/%d 完成", offset+finished, total))
+		if len(records) == len(ids) && finished == len(ids) {
+			return records, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("角色参考图生成超时")
+		}
+		time.Sleep(5 * time.Second)
+	}
+}
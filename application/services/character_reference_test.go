@@ -0,0 +1,132 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/drama-generator/backend/domain/models"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestMergeReferenceImages(t *testing.T) {
+	tests := []struct {
+		name   string
+		images []string
+		extra  []string
+		limit  int
+		want   []string
+	}{
+		{"append", []string{"a"}, []string{"b", "c"}, 6, []string{"a", "b", "c"}},
+		{"dedup and skip empty", []string{"a"}, []string{"a", "", "b", "b"}, 6, []string{"a", "b"}},
+		{"limit extra", []string{"a"}, []string{"b", "c", "d"}, 3, []string{"a", "b", "c"}},
+		{"keep existing over limit", []string{"a", "b", "c"}, []string{"d"}, 2, []string{"a", "b", "c"}},
+		{"no existing", nil, []string{"a"}, 6, []string{"a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeReferenceImages(tt.images, tt.extra, tt.limit); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("mergeReferenceImages() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCharacterReferenceImages(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want []models.CharacterReferenceImage
+	}{
+		{"empty", ``, nil},
+		{"invalid", `{"view":"front"}`, nil},
+		{"legacy strings", `["a.png","","b.png"]`, []models.CharacterReferenceImage{{ImageURL: "a.png"}, {ImageURL: "b.png"}}},
+		{"objects", `[{"view":"front","image_url":"f.png","local_path":"images/f.png"},{"view":"side","image_url":""}]`,
+			[]models.CharacterReferenceImage{{View: "front", ImageURL: "f.png", LocalPath: "images/f.png"}}},
+		{"mixed", `["a.png",{"view":"sheet","local_path":"images/s.png"}]`,
+			[]models.CharacterReferenceImage{{ImageURL: "a.png"}, {View: "sheet", LocalPath: "images/s.png"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseCharacterReferenceImages(datatypes.JSON(tt.raw))
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseCharacterReferenceImages() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveCharacterReferenceImage(t *testing.T) {
+	ref := func(view, url string) models.CharacterReferenceImage {
+		return models.CharacterReferenceImage{View: view, ImageURL: url}
+	}
+	tests := []struct {
+		name      string
+		existing  string
+		mainImage string
+		view      string
+		wantRefs  []models.CharacterReferenceImage
+		wantMain  string
+	}{
+		{
+			name:     "first front view becomes main image",
+			existing: `["manual.png"]`,
+			view:     models.CharacterViewFront,
+			wantRefs: []models.CharacterReferenceImage{ref("front", "new.png"), ref("", "manual.png")},
+			wantMain: "new.png",
+		},
+		{
+			name:      "replace same view and keep order",
+			existing:  `[{"view":"front","image_url":"old-front.png"},{"view":"closeup","image_url":"closeup.png"}]`,
+			mainImage: "main.png",
+			view:      models.CharacterViewSide,
+			wantRefs:  []models.CharacterReferenceImage{ref("front", "old-front.png"), ref("side", "new.png"), ref("closeup", "closeup.png")},
+			wantMain:  "main.png",
+		},
+		{
+			name:      "sheet replaces views",
+			existing:  `[{"view":"front","image_url":"front.png"},"manual.png"]`,
+			mainImage: "main.png",
+			view:      models.CharacterViewSheet,
+			wantRefs:  []models.CharacterReferenceImage{ref("sheet", "new.png"), ref("", "manual.png")},
+			wantMain:  "main.png",
+		},
+		{
+			name:     "view replaces sheet",
+			existing: `[{"view":"sheet","image_url":"sheet.png"}]`,
+			view:     models.CharacterViewCloseup,
+			wantRefs: []models.CharacterReferenceImage{ref("closeup", "new.png")},
+		},
+	}
+
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		drama, _ := createTestEpisode(t, db)
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				character := &models.Character{DramaID: drama.ID, Name: tt.name, ReferenceImages: datatypes.JSON(tt.existing)}
+				if tt.mainImage != "" {
+					character.ImageURL = &tt.mainImage
+				}
+				mustCreate(t, db, character)
+
+				if err := saveCharacterReferenceImage(db, character.ID, tt.view, "new.png", nil); err != nil {
+					t.Fatalf("save reference image: %v", err)
+				}
+
+				var saved models.Character
+				if err := db.First(&saved, character.ID).Error; err != nil {
+					t.Fatalf("load character: %v", err)
+				}
+				if got := parseCharacterReferenceImages(saved.ReferenceImages); !reflect.DeepEqual(got, tt.wantRefs) {
+					t.Fatalf("reference images = %+v, want %+v", got, tt.wantRefs)
+				}
+				if got := characterMainImage(saved); got != tt.wantMain {
+					t.Fatalf("main image = %q, want %q", got, tt.wantMain)
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,667 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/ai"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/subtitle"
+	"github.com/drama-generator/backend/pkg/utils"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ContinuityService 连贯性检查：在生成图片和视频之前发现分镜之间的不一致
+type ContinuityService struct {
+	db          *gorm.DB
+	aiService   *AIService
+	taskService *TaskService
+	promptI18n  *PromptI18n
+	log         *logger.Logger
+}
+
+func NewContinuityService(db *gorm.DB, cfg *config.Config, log *logger.Logger) *ContinuityService {
+	return &ContinuityService{
+		db:          db,
+		aiService:   NewAIService(db, log),
+		taskService: NewTaskService(db, log),
+		promptI18n:  NewPromptI18n(cfg),
+		log:         log,
+	}
+}
+
+// CheckContinuityRequest 连贯性检查选项
+type CheckContinuityRequest struct {
+	SkipAI bool   `json:"skip_ai"` // 只做规则检查，不检查角色退场与服装变化
+	Model  string `json:"model"`
+}
+
+// EpisodeContinuityIssues 单个章节的连贯性问题
+type EpisodeContinuityIssues struct {
+	EpisodeID     uint                     `json:"episode_id"`
+	EpisodeNumber int                      `json:"episode_number"`
+	Title         string                   `json:"title"`
+	Issues        []models.ContinuityIssue `json:"issues"`
+}
+
+// continuityShot 检查时使用的分镜数据
+type continuityShot struct {
+	storyboard   models.Storyboard
+	characterIDs map[uint]bool
+	propIDs      map[uint]bool
+	speakers     []string
+	timeOfDay    string
+}
+
+// continuityExit 角色退场的位置
+type continuityExit struct {
+	episodeNum       int
+	storyboardNumber int
+	reason           string
+}
+
+type continuityAIResult struct {
+	Exits []struct {
+		Character        string `json:"character"`
+		StoryboardNumber int    `json:"storyboard_number"`
+		Reason           string `json:"reason"`
+	} `json:"exits"`
+	CostumeChanges []struct {
+		Character        string `json:"character"`
+		StoryboardNumber int    `json:"storyboard_number"`
+		Description      string `json:"description"`
+	} `json:"costume_changes"`
+}
+
+// CheckDrama 创建连贯性检查任务，按集数顺序检查全部章节（角色退场需要跨集追踪）
+func (s *ContinuityService) CheckDrama(dramaID string, req *CheckContinuityRequest) (string, error) {
+	var drama models.Drama
+	if err := s.db.Where("id = ?", dramaID).First(&drama).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("drama not found")
+		}
+		return "", err
+	}
+	if req.Model == "" {
+		req.Model = loadDramaPreset(s.db, drama.ID).TextModel
+	}
+
+	task, err := s.taskService.CreateTask("continuity_check", dramaID)
+	if err != nil {
+		s.log.Errorw("Failed to create continuity check task", "error", err)
+		return "", fmt.Errorf("创建任务失败: %w", err)
+	}
+
+	go s.processCheck(task.ID, &drama, req)
+
+	s.log.Infow("Continuity check task created", "task_id", task.ID, "drama_id", dramaID)
+	return task.ID, nil
+}
+
+func (s *ContinuityService) processCheck(taskID string, drama *models.Drama, req *CheckContinuityRequest) {
+	s.taskService.UpdateTaskStatus(taskID, "processing", 0, "正在检查连贯性...")
+
+	var episodes []models.Episode
+	if err := s.db.Where("drama_id = ?", drama.ID).Order("episode_number ASC").Find(&episodes).Error; err != nil {
+		s.taskService.UpdateTaskError(taskID, err)
+		return
+	}
+	var characters []models.Character
+	if err := s.db.Where("drama_id = ?", drama.ID).Order("sort_order ASC, id ASC").Find(&characters).Error; err != nil {
+		s.taskService.UpdateTaskError(taskID, err)
+		return
+	}
+	var props []models.Prop
+	if err := s.db.Where("drama_id = ?", drama.ID).Find(&props).Error; err != nil {
+		s.taskService.UpdateTaskError(taskID, err)
+		return
+	}
+
+	exits := make(map[uint]continuityExit)
+	var issues []models.ContinuityIssue
+	aiChecked := !req.SkipAI
+	for i := range episodes {
+		if s.taskService.IsTaskCancelled(taskID) {
+			return
+		}
+		s.taskService.UpdateTaskStatus(taskID, "processing", i*100/len(episodes),
+			fmt.Sprintf("正在检查第%d集（%d/%d）", episodes[i].EpisodeNum, i+1, len(episodes)))
+
+		shots, err := s.loadShots(episodes[i].ID)
+		if err != nil {
+			s.taskService.UpdateTaskError(taskID, err)
+			return
+		}
+		check := &episodeCheck{drama: drama, episode: &episodes[i], shots: shots}
+		check.checkUnlinkedCharacters(characters)
+		check.checkUnlinkedProps(props)
+		check.checkTimeOfDay()
+
+		if !req.SkipAI && len(shots) > 0 {
+			result, err := s.analyzeEpisode(characters, exits, shots, req.Model)
+			if err != nil {
+				// AI 检查失败不影响规则检查的结果
+				s.log.Warnw("Continuity AI check failed", "error", err, "episode_id", episodes[i].ID)
+				aiChecked = false
+			} else {
+				check.applyAIResult(result, characters, exits)
+			}
+		}
+		check.checkAfterExit(characters, exits)
+		issues = append(issues, check.issues...)
+	}
+
+	saved, err := s.saveIssues(drama.ID, issues)
+	if err != nil {
+		s.log.Errorw("Failed to save continuity issues", "error", err, "task_id", taskID)
+		s.taskService.UpdateTaskError(taskID, fmt.Errorf("保存检查结果失败: %w", err))
+		return
+	}
+
+	byType := make(map[string]int)
+	for _, issue := range saved {
+		byType[issue.Type]++
+	}
+	s.taskService.UpdateTaskResult(taskID, map[string]interface{}{
+		"episodes":   len(episodes),
+		"issues":     len(saved),
+		"by_type":    byType,
+		"ai_checked": aiChecked,
+	})
+	s.log.Infow("Continuity check completed", "task_id", taskID, "drama_id", drama.ID, "issues", len(saved))
+}
+
+// loadShots 加载章节分镜及其关联的角色、道具
+func (s *ContinuityService) loadShots(episodeID uint) ([]*continuityShot, error) {
+	var storyboards []models.Storyboard
+	err := s.db.Preload("Background").Preload("Characters").Preload("Props").
+		Where("episode_id = ?", episodeID).Order("storyboard_number ASC").Find(&storyboards).Error
+	if err != nil {
+		return nil, err
+	}
+
+	shots := make([]*continuityShot, 0, len(storyboards))
+	for _, sb := range storyboards {
+		shots = append(shots, newContinuityShot(sb))
+	}
+	return shots, nil
+}
+
+// newContinuityShot 从已预加载背景、角色、道具的分镜构建检查数据
+func newContinuityShot(sb models.Storyboard) *continuityShot {
+	shot := &continuityShot{storyboard: sb, characterIDs: map[uint]bool{}, propIDs: map[uint]bool{}}
+	for _, c := range sb.Characters {
+		shot.characterIDs[c.ID] = true
+	}
+	for _, p := range sb.Props {
+		shot.propIDs[p.ID] = true
+	}
+	shot.speakers = subtitle.Speakers(subtitle.ParseDialogue(getString(sb.Dialogue)))
+	shot.timeOfDay = timeOfDay(shotTime(shot))
+	return shot
+}
+
+// analyzeEpisode 由 AI 找出本集退场的角色与服装不一致的镜头
+func (s *ContinuityService) analyzeEpisode(characters []models.Character, exits map[uint]continuityExit, shots []*continuityShot, model string) (*continuityAIResult, error) {
+	type characterInput struct {
+		Name       string `json:"name"`
+		Appearance string `json:"appearance,omitempty"`
+	}
+	type shotInput struct {
+		Number      int      `json:"number"`
+		Location    string   `json:"location,omitempty"`
+		Time        string   `json:"time,omitempty"`
+		Characters  []string `json:"characters,omitempty"`
+		Action      string   `json:"action,omitempty"`
+		Dialogue    string   `json:"dialogue,omitempty"`
+		Description string   `json:"description,omitempty"`
+		ImagePrompt string   `json:"image_prompt,omitempty"`
+	}
+	input := struct {
+		Characters  []characterInput `json:"characters"`
+		Exited      []string         `json:"exited"`
+		Storyboards []shotInput      `json:"storyboards"`
+	}{Exited: []string{}}
+
+	for _, c := range characters {
+		input.Characters = append(input.Characters, characterInput{Name: c.Name, Appearance: getString(c.Appearance)})
+		if _, ok := exits[c.ID]; ok {
+			input.Exited = append(input.Exited, c.Name)
+		}
+	}
+	for _, shot := range shots {
+		sb := shot.storyboard
+		in := shotInput{
+			Number:      sb.StoryboardNumber,
+			Location:    getString(sb.Location),
+			Time:        getString(sb.Time),
+			Action:      getString(sb.Action),
+			Dialogue:    getString(sb.Dialogue),
+			Description: getString(sb.Description),
+			ImagePrompt: getString(sb.ImagePrompt),
+		}
+		for _, c := range sb.Characters {
+			in.Characters = append(in.Characters, c.Name)
+		}
+		input.Storyboards = append(input.Storyboards, in)
+	}
+
+	data, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := s.aiService.GenerateTextWithModel(model, string(data), s.promptI18n.GetContinuityCheckPrompt(), ai.WithTemperature(0.2))
+	if err != nil {
+		return nil, err
+	}
+
+	var result continuityAIResult
+	if err := utils.SafeParseAIJSON(text, &result); err != nil {
+		return nil, fmt.Errorf("解析AI返回结果失败: %w", err)
+	}
+	return &result, nil
+}
+
+// saveIssues 替换剧本的检查结果，保留已复核（已解决、已忽略）的问题且不再重复报告
+func (s *ContinuityService) saveIssues(dramaID uint, issues []models.ContinuityIssue) ([]models.ContinuityIssue, error) {
+	reviewed := []string{models.ContinuityStatusResolved, models.ContinuityStatusIgnored}
+	var saved []models.ContinuityIssue
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var kept []string
+		if err := tx.Model(&models.ContinuityIssue{}).
+			Where("drama_id = ? AND status IN ?", dramaID, reviewed).
+			Pluck("fingerprint", &kept).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("drama_id = ? AND status NOT IN ?", dramaID, reviewed).
+			Delete(&models.ContinuityIssue{}).Error; err != nil {
+			return err
+		}
+
+		for _, issue := range issues {
+			if containsString(kept, issue.Fingerprint) {
+				continue
+			}
+			if err := tx.Create(&issue).Error; err != nil {
+				return err
+			}
+			saved = append(saved, issue)
+		}
+		return nil
+	})
+	return saved, err
+}
+
+// ListDramaIssues 按章节列出剧本的连贯性问题，status 为空时返回全部
+func (s *ContinuityService) ListDramaIssues(dramaID string, status string) ([]EpisodeContinuityIssues, error) {
+	var drama models.Drama
+	if err := s.db.Where("id = ?", dramaID).First(&drama).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("drama not found")
+		}
+		return nil, err
+	}
+
+	var episodes []models.Episode
+	if err := s.db.Where("drama_id = ?", drama.ID).Order("episode_number ASC").Find(&episodes).Error; err != nil {
+		return nil, err
+	}
+	query := s.db.Where("drama_id = ?", drama.ID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var issues []models.ContinuityIssue
+	if err := query.Order("episode_id ASC, id ASC").Find(&issues).Error; err != nil {
+		return nil, err
+	}
+
+	byEpisode := make(map[uint][]models.ContinuityIssue)
+	for _, issue := range issues {
+		byEpisode[issue.EpisodeID] = append(byEpisode[issue.EpisodeID], issue)
+	}
+	result := make([]EpisodeContinuityIssues, 0, len(episodes))
+	for _, ep := range episodes {
+		if len(byEpisode[ep.ID]) == 0 {
+			continue
+		}
+		result = append(result, EpisodeContinuityIssues{
+			EpisodeID:     ep.ID,
+			EpisodeNumber: ep.EpisodeNum,
+			Title:         ep.Title,
+			Issues:        byEpisode[ep.ID],
+		})
+	}
+	return result, nil
+}
+
+// ListEpisodeIssues 列出章节的连贯性问题
+func (s *ContinuityService) ListEpisodeIssues(episodeID string, status string) ([]models.ContinuityIssue, error) {
+	var episode models.Episode
+	if err := s.db.Where("id = ?", episodeID).First(&episode).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("episode not found")
+		}
+		return nil, err
+	}
+
+	query := s.db.Where("episode_id = ?", episode.ID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	issues := []models.ContinuityIssue{}
+	if err := query.Order("id ASC").Find(&issues).Error; err != nil {
+		return nil, err
+	}
+	return issues, nil
+}
+
+// UpdateIssueStatus 复核问题：open / resolved / ignored
+func (s *ContinuityService) UpdateIssueStatus(issueID uint, status string) (*models.ContinuityIssue, error) {
+	switch status {
+	case models.ContinuityStatusOpen, models.ContinuityStatusResolved, models.ContinuityStatusIgnored:
+	default:
+		return nil, fmt.Errorf("invalid status")
+	}
+
+	var issue models.ContinuityIssue
+	if err := s.db.First(&issue, issueID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("issue not found")
+		}
+		return nil, err
+	}
+	if err := s.db.Model(&issue).Update("status", status).Error; err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// FixIssue 自动修复未关联角色/道具的问题：把角色或道具关联到分镜并标记为已解决
+func (s *ContinuityService) FixIssue(issueID uint) (*models.ContinuityIssue, error) {
+	var issue models.ContinuityIssue
+	if err := s.db.First(&issue, issueID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("issue not found")
+		}
+		return nil, err
+	}
+
+	var details struct {
+		CharacterID uint `json:"character_id"`
+		PropID      uint `json:"prop_id"`
+	}
+	_ = json.Unmarshal(issue.Details, &details)
+
+	var table string
+	var link interface{}
+	switch {
+	case issue.StoryboardID == nil:
+	case issue.Type == models.ContinuityIssueUnlinkedCharacter && details.CharacterID != 0:
+		table, link = "storyboard_characters", &StoryboardCharacterLink{StoryboardID: *issue.StoryboardID, CharacterID: details.CharacterID}
+	case issue.Type == models.ContinuityIssueUnlinkedProp && details.PropID != 0:
+		table, link = "storyboard_props", &StoryboardPropLink{StoryboardID: *issue.StoryboardID, PropID: details.PropID}
+	}
+	if link == nil {
+		return nil, fmt.Errorf("issue cannot be fixed automatically")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(table).Clauses(clause.OnConflict{DoNothing: true}).Create(link).Error; err != nil {
+			return err
+		}
+		return tx.Model(&issue).Update("status", models.ContinuityStatusResolved).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &issue, nil
+}
+
+// episodeCheck 单个章节的检查过程
+type episodeCheck struct {
+	drama   *models.Drama
+	episode *models.Episode
+	shots   []*continuityShot
+	issues  []models.ContinuityIssue
+}
+
+func (c *episodeCheck) add(shot *continuityShot, issueType, severity, subject, message string, details map[string]interface{}) {
+	issue := models.ContinuityIssue{
+		DramaID:     c.drama.ID,
+		EpisodeID:   c.episode.ID,
+		Type:        issueType,
+		Severity:    severity,
+		Status:      models.ContinuityStatusOpen,
+		Message:     message,
+		Fingerprint: fmt.Sprintf("%s:%d:%s", issueType, c.episode.ID, subject),
+	}
+	if shot != nil {
+		id := shot.storyboard.ID
+		issue.StoryboardID = &id
+		issue.Fingerprint = fmt.Sprintf("%s:%d:%d:%s", issueType, c.episode.ID, id, subject)
+		details["storyboard_number"] = shot.storyboard.StoryboardNumber
+	}
+	if data, err := json.Marshal(details); err == nil {
+		issue.Details = datatypes.JSON(data)
+	}
+	// 指纹超长时截断，保证能写入 varchar(255)
+	for len(issue.Fingerprint) > 255 {
+		_, size := utf8.DecodeLastRuneInString(issue.Fingerprint)
+		issue.Fingerprint = issue.Fingerprint[:len(issue.Fingerprint)-size]
+	}
+	c.issues = append(c.issues, issue)
+}
+
+// checkUnlinkedCharacters 对白的说话人或动作描述中出现的角色没有关联到分镜
+func (c *episodeCheck) checkUnlinkedCharacters(characters []models.Character) {
+	for _, shot := range c.shots {
+		for _, speaker := range shot.speakers {
+			character := findCharacterByName(characters, speaker)
+			if character == nil && isNarrator(speaker) {
+				continue
+			}
+			if character == nil {
+				c.add(shot, models.ContinuityIssueUnlinkedCharacter, "warning", "speaker:"+speaker,
+					fmt.Sprintf("镜头%d：对白说话人「%s」不是剧中角色，可能是角色改名或笔误", shot.storyboard.StoryboardNumber, speaker),
+					map[string]interface{}{"character": speaker})
+				continue
+			}
+			if !shot.characterIDs[character.ID] {
+				c.add(shot, models.ContinuityIssueUnlinkedCharacter, "warning", character.Name,
+					fmt.Sprintf("镜头%d：「%s」有对白，但没有关联到该分镜", shot.storyboard.StoryboardNumber, character.Name),
+					map[string]interface{}{"character_id": character.ID, "character": character.Name})
+			}
+		}
+
+		action := strings.ToLower(getString(shot.storyboard.Action))
+		for _, character := range characters {
+			if shot.characterIDs[character.ID] || containsString(shot.speakers, character.Name) || !mentionsName(action, character.Name) {
+				continue
+			}
+			c.add(shot, models.ContinuityIssueUnlinkedCharacter, "warning", character.Name,
+				fmt.Sprintf("镜头%d：动作描述中出现「%s」，但没有关联到该分镜", shot.storyboard.StoryboardNumber, character.Name),
+				map[string]interface{}{"character_id": character.ID, "character": character.Name})
+		}
+	}
+}
+
+// checkUnlinkedProps 动作、对白或描述中提到的道具没有关联到分镜
+func (c *episodeCheck) checkUnlinkedProps(props []models.Prop) {
+	for _, shot := range c.shots {
+		sb := shot.storyboard
+		text := strings.ToLower(strings.Join([]string{getString(sb.Title), getString(sb.Action), getString(sb.Dialogue), getString(sb.Description)}, "\n"))
+		for _, prop := range props {
+			if shot.propIDs[prop.ID] || !mentionsName(text, prop.Name) {
+				continue
+			}
+			c.add(shot, models.ContinuityIssueUnlinkedProp, "warning", prop.Name,
+				fmt.Sprintf("镜头%d：使用了道具「%s」，但没有关联到该分镜", sb.StoryboardNumber, prop.Name),
+				map[string]interface{}{"prop_id": prop.ID, "prop": prop.Name})
+		}
+	}
+}
+
+// checkTimeOfDay 同一场景连续的镜头中时间段发生变化（如白天变成夜晚）
+func (c *episodeCheck) checkTimeOfDay() {
+	for i := 1; i < len(c.shots); i++ {
+		prev, shot := c.shots[i-1], c.shots[i]
+		if !sameSequence(prev, shot) || prev.timeOfDay == "" || shot.timeOfDay == "" || prev.timeOfDay == shot.timeOfDay {
+			continue
+		}
+		prevTime, curTime := shotTime(prev), shotTime(shot)
+		c.add(shot, models.ContinuityIssueTimeOfDayChange, "warning", prev.timeOfDay+">"+shot.timeOfDay,
+			fmt.Sprintf("镜头%d：与上一镜头（%d）是同一场景，但时间从「%s」变成了「%s」",
+				shot.storyboard.StoryboardNumber, prev.storyboard.StoryboardNumber, prevTime, curTime),
+			map[string]interface{}{
+				"previous_storyboard_number": prev.storyboard.StoryboardNumber,
+				"previous_time":              prevTime,
+				"time":                       curTime,
+			})
+	}
+}
+
+// applyAIResult 记录本集退场的角色，添加服装不一致的问题
+func (c *episodeCheck) applyAIResult(result *continuityAIResult, characters []models.Character, exits map[uint]continuityExit) {
+	for _, exit := range result.Exits {
+		character := findCharacterByName(characters, exit.Character)
+		if character == nil {
+			continue
+		}
+		if _, ok := exits[character.ID]; !ok {
+			exits[character.ID] = continuityExit{episodeNum: c.episode.EpisodeNum, storyboardNumber: exit.StoryboardNumber, reason: exit.Reason}
+		}
+	}
+
+	for _, change := range result.CostumeChanges {
+		character := findCharacterByName(characters, change.Character)
+		shot := c.shotByNumber(change.StoryboardNumber)
+		if character == nil || shot == nil {
+			continue
+		}
+		c.add(shot, models.ContinuityIssueCostumeChange, "warning", character.Name,
+			fmt.Sprintf("镜头%d：「%s」的造型与设定不一致：%s", shot.storyboard.StoryboardNumber, character.Name, change.Description),
+			map[string]interface{}{
+				"character_id": character.ID,
+				"character":    character.Name,
+				"appearance":   getString(character.Appearance),
+				"description":  change.Description,
+			})
+	}
+}
+
+// checkAfterExit 已退场的角色在之后的镜头中仍有出场或对白
+func (c *episodeCheck) checkAfterExit(characters []models.Character, exits map[uint]continuityExit) {
+	for _, character := range characters {
+		exit, ok := exits[character.ID]
+		if !ok {
+			continue
+		}
+		for _, shot := range c.shots {
+			if exit.episodeNum == c.episode.EpisodeNum && shot.storyboard.StoryboardNumber <= exit.storyboardNumber {
+				continue
+			}
+			if !shot.characterIDs[character.ID] && !containsString(shot.speakers, character.Name) {
+				continue
+			}
+			c.add(shot, models.ContinuityIssueCharacterAfterExit, "error", character.Name,
+				fmt.Sprintf("镜头%d：「%s」已在第%d集镜头%d退场（%s），此处再次出现；如为回忆或闪回请忽略",
+					shot.storyboard.StoryboardNumber, character.Name, exit.episodeNum, exit.storyboardNumber, exit.reason),
+				map[string]interface{}{
+					"character_id":        character.ID,
+					"character":           character.Name,
+					"exit_episode_number": exit.episodeNum,
+					"exit_storyboard":     exit.storyboardNumber,
+					"exit_reason":         exit.reason,
+				})
+		}
+	}
+}
+
+func (c *episodeCheck) shotByNumber(number int) *continuityShot {
+	i := sort.Search(len(c.shots), func(i int) bool { return c.shots[i].storyboard.StoryboardNumber >= number })
+	if i < len(c.shots) && c.shots[i].storyboard.StoryboardNumber == number {
+		return c.shots[i]
+	}
+	return nil
+}
+
+// sameSequence 两个相邻镜头是否属于同一场景：同一场景背景，或没有背景时地点相同
+func sameSequence(a, b *continuityShot) bool {
+	if a.storyboard.SceneID != nil && b.storyboard.SceneID != nil {
+		return *a.storyboard.SceneID == *b.storyboard.SceneID
+	}
+	la, lb := strings.TrimSpace(getString(a.storyboard.Location)), strings.TrimSpace(getString(b.storyboard.Location))
+	return la != "" && strings.EqualFold(la, lb)
+}
+
+func shotTime(shot *continuityShot) string {
+	if t := getString(shot.storyboard.Time); t != "" {
+		return t
+	}
+	if shot.storyboard.Background != nil {
+		return shot.storyboard.Background.Time
+	}
+	return ""
+}
+
+// timeOfDayKeywords 时间描述归类，先匹配更具体的时段
+var timeOfDayKeywords = []struct {
+	period   string
+	keywords []string
+}{
+	{"dawn", []string{"黎明", "清晨", "拂晓", "破晓", "凌晨", "dawn", "sunrise", "early morning"}},
+	{"dusk", []string{"黄昏", "傍晚", "日落", "夕阳", "dusk", "sunset", "twilight"}},
+	{"night", []string{"夜", "晚上", "午夜", "深夜", "night", "evening", "midnight"}},
+	{"day", []string{"日", "白天", "早上", "上午", "中午", "下午", "午后", "day", "morning", "noon", "afternoon"}},
+}
+
+// timeOfDay 把时间描述归为 dawn / day / dusk / night，无法识别时返回空字符串
+func timeOfDay(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	if t == "" {
+		return ""
+	}
+	for _, group := range timeOfDayKeywords {
+		for _, kw := range group.keywords {
+			if strings.Contains(t, kw) {
+				return group.period
+			}
+		}
+	}
+	return ""
+}
+
+// findCharacterByName 按名称匹配角色（不区分大小写）
+func findCharacterByName(characters []models.Character, name string) *models.Character {
+	name = strings.TrimSpace(name)
+	for i := range characters {
+		if strings.EqualFold(characters[i].Name, name) {
+			return &characters[i]
+		}
+	}
+	return nil
+}
+
+// mentionsName 文本（已转小写）中是否提到名称，单字名称误报太多不检查
+func mentionsName(text, name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return utf8.RuneCountInString(name) >= 2 && strings.Contains(text, name)
+}
+
+// isNarrator 旁白、独白等非角色的说话人
+func isNarrator(speaker string) bool {
+	switch strings.ToLower(strings.TrimSpace(speaker)) {
+	case "旁白", "独白", "画外音", "narrator", "narration", "v.o.", "vo":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+func textPtr(s string) *string { return &s }
+
+// runEpisodeCheck 对单集分镜执行检查，返回问题的 指纹 → 详情
+func runEpisodeCheck(t *testing.T, storyboards []models.Storyboard, check func(*episodeCheck)) map[string]map[string]interface{} {
+	t.Helper()
+	c := &episodeCheck{drama: &models.Drama{ID: 1}, episode: &models.Episode{ID: 10, EpisodeNum: 2}}
+	for _, sb := range storyboards {
+		c.shots = append(c.shots, newContinuityShot(sb))
+	}
+	check(c)
+
+	found := make(map[string]map[string]interface{})
+	for _, issue := range c.issues {
+		var details map[string]interface{}
+		if err := json.Unmarshal(issue.Details, &details); err != nil {
+			t.Fatalf("decode details: %v", err)
+		}
+		found[issue.Fingerprint] = details
+	}
+	return found
+}
+
+func assertIssues(t *testing.T, found map[string]map[string]interface{}, want []string) {
+	t.Helper()
+	if len(found) != len(want) {
+		t.Fatalf("expected issues %v, got %v", want, found)
+	}
+	for _, fp := range want {
+		if _, ok := found[fp]; !ok {
+			t.Fatalf("missing issue %q, got %v", fp, found)
+		}
+	}
+}
+
+func TestCheckUnlinkedCharacters(t *testing.T) {
+	ling := models.Character{ID: 1, Name: "Ling"}
+	wei := models.Character{ID: 2, Name: "Wei"}
+	characters := []models.Character{ling, wei, {ID: 3, Name: "K"}}
+
+	tests := []struct {
+		name string
+		sb   models.Storyboard
+		want []string
+	}{
+		{"linked speaker", models.Storyboard{ID: 5, Dialogue: textPtr(`Ling："Go."`), Characters: []models.Character{ling}}, nil},
+		{"speaker not linked", models.Storyboard{ID: 5, Dialogue: textPtr(`ling："Go."`)}, []string{"unlinked_character:10:5:Ling"}},
+		{"unknown speaker", models.Storyboard{ID: 5, Dialogue: textPtr(`Lin："Go."`)}, []string{"unlinked_character:10:5:speaker:Lin"}},
+		{"narrator", models.Storyboard{ID: 5, Dialogue: textPtr(`旁白："Night falls."`)}, nil},
+		{"mentioned in action", models.Storyboard{ID: 5, Action: textPtr("WEI opens the door"), Characters: []models.Character{ling}}, []string{"unlinked_character:10:5:Wei"}},
+		{"mentioned speaker reported once", models.Storyboard{ID: 5, Action: textPtr("Wei shouts"), Dialogue: textPtr(`Wei："Stop!"`)}, []string{"unlinked_character:10:5:Wei"}},
+		{"single rune name ignored", models.Storyboard{ID: 5, Action: textPtr("keys jingle")}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := runEpisodeCheck(t, []models.Storyboard{tt.sb}, func(c *episodeCheck) { c.checkUnlinkedCharacters(characters) })
+			assertIssues(t, found, tt.want)
+		})
+	}
+
+	found := runEpisodeCheck(t, []models.Storyboard{{ID: 5, StoryboardNumber: 3, Dialogue: textPtr(`Wei："Stop!"`)}},
+		func(c *episodeCheck) { c.checkUnlinkedCharacters(characters) })
+	if details := found["unlinked_character:10:5:Wei"]; details["character_id"] != float64(wei.ID) || details["storyboard_number"] != float64(3) {
+		t.Fatalf("unexpected details %v", details)
+	}
+}
+
+func TestCheckUnlinkedProps(t *testing.T) {
+	sword := models.Prop{ID: 7, Name: "Sword"}
+	props := []models.Prop{sword, {ID: 8, Name: "印"}}
+
+	tests := []struct {
+		name string
+		sb   models.Storyboard
+		want []string
+	}{
+		{"linked prop", models.Storyboard{ID: 5, Action: textPtr("draws the sword"), Props: []models.Prop{sword}}, nil},
+		{"mentioned in title", models.Storyboard{ID: 5, Title: textPtr("The Sword")}, []string{"unlinked_prop:10:5:Sword"}},
+		{"mentioned in dialogue", models.Storyboard{ID: 5, Dialogue: textPtr(`Ling："Give me the sword."`)}, []string{"unlinked_prop:10:5:Sword"}},
+		{"mentioned in description", models.Storyboard{ID: 5, Description: textPtr("a sword on the wall")}, []string{"unlinked_prop:10:5:Sword"}},
+		{"single rune name ignored", models.Storyboard{ID: 5, Action: textPtr("盖上印章")}, nil},
+		{"not mentioned", models.Storyboard{ID: 5, Action: textPtr("walks away")}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := runEpisodeCheck(t, []models.Storyboard{tt.sb}, func(c *episodeCheck) { c.checkUnlinkedProps(props) })
+			assertIssues(t, found, tt.want)
+		})
+	}
+}
+
+func TestCheckTimeOfDay(t *testing.T) {
+	scene, other := uint(1), uint(2)
+	nightBackground := &models.Scene{ID: scene, Time: "深夜"}
+
+	tests := []struct {
+		name string
+		sbs  []models.Storyboard
+		want []string
+	}{
+		{"same scene day to night", []models.Storyboard{
+			{ID: 5, SceneID: &scene, Time: textPtr("白天")},
+			{ID: 6, SceneID: &scene, Time: textPtr("晚上")},
+		}, []string{"time_of_day_change:10:6:day>night"}},
+		{"falls back to background time", []models.Storyboard{
+			{ID: 5, SceneID: &scene, Time: textPtr("Morning")},
+			{ID: 6, SceneID: &scene, Background: nightBackground},
+		}, []string{"time_of_day_change:10:6:day>night"}},
+		{"dawn is not day", []models.Storyboard{
+			{ID: 5, SceneID: &scene, Time: textPtr("early morning")},
+			{ID: 6, SceneID: &scene, Time: textPtr("morning")},
+		}, []string{"time_of_day_change:10:6:dawn>day"}},
+		{"same period", []models.Storyboard{
+			{ID: 5, SceneID: &scene, Time: textPtr("午夜")},
+			{ID: 6, SceneID: &scene, Time: textPtr("night")},
+		}, nil},
+		{"different scenes", []models.Storyboard{
+			{ID: 5, SceneID: &scene, Time: textPtr("白天")},
+			{ID: 6, SceneID: &other, Time: textPtr("晚上")},
+		}, nil},
+		{"same location without scene", []models.Storyboard{
+			{ID: 5, Location: textPtr("Station"), Time: textPtr("day")},
+			{ID: 6, Location: textPtr(" station "), Time: textPtr("dusk")},
+		}, []string{"time_of_day_change:10:6:day>dusk"}},
+		{"unknown time", []models.Storyboard{
+			{ID: 5, SceneID: &scene, Time: textPtr("白天")},
+			{ID: 6, SceneID: &scene, Time: textPtr("later")},
+		}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := runEpisodeCheck(t, tt.sbs, func(c *episodeCheck) { c.checkTimeOfDay() })
+			assertIssues(t, found, tt.want)
+		})
+	}
+}
+
+func TestCheckAfterExit(t *testing.T) {
+	ling := models.Character{ID: 1, Name: "Ling"}
+	characters := []models.Character{ling, {ID: 2, Name: "Wei"}}
+	shots := []models.Storyboard{
+		{ID: 5, StoryboardNumber: 1, Characters: []models.Character{ling}},
+		{ID: 6, StoryboardNumber: 2, Dialogue: textPtr(`Ling："Goodbye."`)},
+		{ID: 7, StoryboardNumber: 3, Action: textPtr("Wei stands alone")},
+	}
+
+	tests := []struct {
+		name string
+		exit continuityExit
+		want []string
+	}{
+		{"exit in earlier episode", continuityExit{episodeNum: 1, storyboardNumber: 9},
+			[]string{"character_after_exit:10:5:Ling", "character_after_exit:10:6:Ling"}},
+		{"exit in this episode", continuityExit{episodeNum: 2, storyboardNumber: 1},
+			[]string{"character_after_exit:10:6:Ling"}},
+		{"exit after last appearance", continuityExit{episodeNum: 2, storyboardNumber: 2}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exits := map[uint]continuityExit{ling.ID: tt.exit}
+			found := runEpisodeCheck(t, shots, func(c *episodeCheck) { c.checkAfterExit(characters, exits) })
+			assertIssues(t, found, tt.want)
+		})
+	}
+}
+
+func TestSaveIssuesKeepsReviewedIssues(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		drama, episode := createTestEpisode(t, db)
+		service := NewContinuityService(db, testConfig(t), logger.NewLogger(false))
+		issue := func(fingerprint string) models.ContinuityIssue {
+			return models.ContinuityIssue{DramaID: drama.ID, EpisodeID: episode.ID, Type: models.ContinuityIssueUnlinkedProp,
+				Severity: "warning", Status: models.ContinuityStatusOpen, Message: fingerprint, Fingerprint: fingerprint}
+		}
+
+		first, err := service.saveIssues(drama.ID, []models.ContinuityIssue{issue("open"), issue("resolved"), issue("ignored")})
+		if err != nil || len(first) != 3 {
+			t.Fatalf("save issues: %v %d", err, len(first))
+		}
+		for _, saved := range first[1:] {
+			if _, err := service.UpdateIssueStatus(saved.ID, saved.Fingerprint); err != nil {
+				t.Fatalf("update status: %v", err)
+			}
+		}
+
+		// 重新检查：已复核的问题不再重复报告，未复核的问题被替换
+		second, err := service.saveIssues(drama.ID, []models.ContinuityIssue{issue("open"), issue("resolved"), issue("ignored"), issue("new")})
+		if err != nil {
+			t.Fatalf("save issues again: %v", err)
+		}
+		if len(second) != 2 || second[0].Fingerprint != "open" || second[1].Fingerprint != "new" {
+			t.Fatalf("unexpected new issues: %+v", second)
+		}
+
+		var all []models.ContinuityIssue
+		db.Where("drama_id = ?", drama.ID).Order("id").Find(&all)
+		statuses := make(map[string]string)
+		for _, saved := range all {
+			statuses[saved.Fingerprint] = saved.Status
+		}
+		want := map[string]string{"open": "open", "resolved": "resolved", "ignored": "ignored", "new": "open"}
+		if len(all) != len(want) {
+			t.Fatalf("expected %d issues, got %+v", len(want), all)
+		}
+		for fp, status := range want {
+			if statuses[fp] != status {
+				t.Fatalf("issue %s status = %q, want %q", fp, statuses[fp], status)
+			}
+		}
+		if all[0].ID == first[0].ID {
+			t.Fatal("open issue should be replaced on re-check")
+		}
+	})
+}
+
+func TestFixIssueLinksStoryboard(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		drama, episode := createTestEpisode(t, db)
+		character := &models.Character{DramaID: drama.ID, Name: "Ling"}
+		prop := &models.Prop{DramaID: drama.ID, Name: "Sword"}
+		sb := &models.Storyboard{EpisodeID: episode.ID, StoryboardNumber: 1}
+		mustCreate(t, db, character, prop, sb)
+		service := NewContinuityService(db, testConfig(t), logger.NewLogger(false))
+		create := func(issueType string, storyboardID *uint, details string) *models.ContinuityIssue {
+			issue := &models.ContinuityIssue{DramaID: drama.ID, EpisodeID: episode.ID, StoryboardID: storyboardID, Type: issueType,
+				Severity: "warning", Status: models.ContinuityStatusOpen, Message: "m", Fingerprint: issueType + details, Details: []byte(details)}
+			mustCreate(t, db, issue)
+			return issue
+		}
+
+		characterIssue := create(models.ContinuityIssueUnlinkedCharacter, &sb.ID, fmt.Sprintf(`{"character_id":%d}`, character.ID))
+		propIssue := create(models.ContinuityIssueUnlinkedProp, &sb.ID, fmt.Sprintf(`{"prop_id":%d}`, prop.ID))
+		for _, issue := range []*models.ContinuityIssue{characterIssue, propIssue, characterIssue} {
+			// 重复修复时关联已存在，不应报错
+			if _, err := service.FixIssue(issue.ID); err != nil {
+				t.Fatalf("fix issue %s: %v", issue.Type, err)
+			}
+		}
+
+		var linked models.Storyboard
+		db.Preload("Characters").Preload("Props").First(&linked, sb.ID)
+		if len(linked.Characters) != 1 || linked.Characters[0].ID != character.ID || len(linked.Props) != 1 || linked.Props[0].ID != prop.ID {
+			t.Fatalf("storyboard links not created: %+v %+v", linked.Characters, linked.Props)
+		}
+		var fixed models.ContinuityIssue
+		db.First(&fixed, propIssue.ID)
+		if fixed.Status != models.ContinuityStatusResolved {
+			t.Fatalf("fixed issue status = %q", fixed.Status)
+		}
+
+		for name, issue := range map[string]*models.ContinuityIssue{
+			"unknown speaker": create(models.ContinuityIssueUnlinkedCharacter, &sb.ID, `{"character":"Lin"}`),
+			"no storyboard":   create(models.ContinuityIssueUnlinkedProp, nil, fmt.Sprintf(`{"prop_id":%d}`, prop.ID)),
+			"time of day":     create(models.ContinuityIssueTimeOfDayChange, &sb.ID, `{}`),
+		} {
+			if _, err := service.FixIssue(issue.ID); err == nil || err.Error() != "issue cannot be fixed automatically" {
+				t.Fatalf("%s: expected cannot fix error, got %v", name, err)
+			}
+		}
+		if _, err := service.FixIssue(9999); err == nil || err.Error() != "issue not found" {
+			t.Fatalf("expected issue not found, got %v", err)
+		}
+	})
+}
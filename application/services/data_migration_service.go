@@ -2,14 +2,14 @@ package services
 
 import (
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/config"
 	"github.com/drama-generator/backend/pkg/logger"
 
 	"gorm.io/gorm"
@@ -19,16 +19,27 @@ type DataMigrationService struct {
 	db          *gorm.DB
 	log         *logger.Logger
 	storageRoot string
+	store       *storage.DedupStorage
 	urlMapping  map[string]string // 原始URL -> 本地路径的映射
 }
 
-func NewDataMigrationService(db *gorm.DB, log *logger.Logger) *DataMigrationService {
+func NewDataMigrationService(db *gorm.DB, cfg config.StorageConfig, log *logger.Logger) (*DataMigrationService, error) {
+	storageRoot := cfg.LocalPath
+	if storageRoot == "" {
+		storageRoot = "data/storage"
+	}
+	localStorage, err := storage.NewLocalStorage(storageRoot, cfg.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
 	return &DataMigrationService{
 		db:          db,
 		log:         log,
-		storageRoot: "data/storage",
+		storageRoot: storageRoot,
+		store:       storage.NewDedupStorage(localStorage, db),
 		urlMapping:  make(map[string]string),
-	}
+	}, nil
 }
 
 // MigrateLocalPaths 迁移所有表中 local_path 为空的数据
@@ -379,151 +390,24 @@ func (s *DataMigrationService) downloadOrGetCached(url, subDir, prefix string) (
 		return localPath, nil
 	}
 
-	// 2. 如果缓存中没有，则下载文件
-	var localPath string
-	var err error
-
-	// 根据子目录判断是图片还是视频
-	if subDir == "videos" {
-		localPath, err = s.downloadAndSaveVideo(url, subDir, prefix)
-	} else {
-		localPath, err = s.downloadAndSaveImage(url, subDir, prefix)
+	// 2. 已经是本地路径，直接返回
+	if url == "" {
+		return "", fmt.Errorf("URL 为空")
+	}
+	if strings.HasPrefix(url, "/static/") || strings.HasPrefix(url, "data/") {
+		return url, nil
 	}
 
+	// 3. 经去重存储下载：相同URL不重复下载，相同内容只保存一份
+	result, err := s.store.DownloadFromURLWithPath(url, subDir)
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("下载文件失败: %w", err)
 	}
+	localPath := result.RelativePath
 
-	// 3. 将 URL 和本地路径的映射关系存入缓存
+	// 4. 将 URL 和本地路径的映射关系存入缓存
 	s.urlMapping[url] = localPath
-	s.log.Infow("已缓存 URL 映射", "url", url, "local_path", localPath)
+	s.log.Infow("已缓存 URL 映射", "resource", prefix, "url", url, "local_path", localPath)
 
 	return localPath, nil
 }
-
-// downloadAndSaveImage 下载并保存图片
-func (s *DataMigrationService) downloadAndSaveImage(imageURL, subDir, prefix string) (string, error) {
-	if imageURL == "" {
-		return "", fmt.Errorf("图片 URL 为空")
-	}
-
-	// 如果已经是本地路径，直接返回
-	if strings.HasPrefix(imageURL, "/static/") || strings.HasPrefix(imageURL, "data/") {
-		return imageURL, nil
-	}
-
-	// 从 URL 中提取文件扩展名（去掉查询参数）
-	ext := s.extractFileExtension(imageURL)
-
-	// 生成文件名
-	timestamp := time.Now().Unix()
-	filename := fmt.Sprintf("%s_%d%s", prefix, timestamp, ext)
-	relativePath := filepath.Join(subDir, filename)
-	fullPath := filepath.Join(s.storageRoot, relativePath)
-
-	// 下载文件
-	if err := s.downloadFile(imageURL, fullPath); err != nil {
-		return "", fmt.Errorf("下载文件失败: %w", err)
-	}
-
-	// 返回相对路径（用于存储到数据库）
-	return relativePath, nil
-}
-
-// downloadAndSaveVideo 下载并保存视频
-func (s *DataMigrationService) downloadAndSaveVideo(videoURL, subDir, prefix string) (string, error) {
-	if videoURL == "" {
-		return "", fmt.Errorf("视频 URL 为空")
-	}
-
-	// 如果已经是本地路径，直接返回
-	if strings.HasPrefix(videoURL, "/static/") || strings.HasPrefix(videoURL, "data/") {
-		return videoURL, nil
-	}
-
-	// 从 URL 中提取文件扩展名（去掉查询参数）
-	ext := s.extractFileExtension(videoURL)
-	if ext == "" || ext == ".jpeg" || ext == ".jpg" || ext == ".png" {
-		ext = ".mp4" // 视频默认扩展名
-	}
-
-	// 生成文件名
-	timestamp := time.Now().Unix()
-	filename := fmt.Sprintf("%s_%d%s", prefix, timestamp, ext)
-	relativePath := filepath.Join(subDir, filename)
-	fullPath := filepath.Join(s.storageRoot, relativePath)
-
-	// 下载文件
-	if err := s.downloadFile(videoURL, fullPath); err != nil {
-		return "", fmt.Errorf("下载文件失败: %w", err)
-	}
-
-	// 返回相对路径（用于存储到数据库）
-	return relativePath, nil
-}
-
-// extractFileExtension 从 URL 中提取文件扩展名（去掉查询参数）
-func (s *DataMigrationService) extractFileExtension(url string) string {
-	// 去掉查询参数
-	if idx := strings.Index(url, "?"); idx != -1 {
-		url = url[:idx]
-	}
-	
-	// 去掉 fragment
-	if idx := strings.Index(url, "#"); idx != -1 {
-		url = url[:idx]
-	}
-	
-	// 获取文件扩展名
-	ext := filepath.Ext(url)
-	if ext == "" {
-		// 如果没有扩展名，默认返回 .jpg
-		return ".jpg"
-	}
-	
-	// 转换为小写
-	ext = strings.ToLower(ext)
-	
-	// 验证扩展名是否合理（限制长度）
-	if len(ext) > 10 {
-		return ".jpg"
-	}
-	
-	return ext
-}
-
-// downloadFile 下载文件到指定路径
-func (s *DataMigrationService) downloadFile(url, filepath string) error {
-	s.log.Infow("开始下载文件", "url", url, "filepath", filepath)
-
-	// 创建 HTTP 请求
-	client := &http.Client{
-		Timeout: 60 * time.Second,
-	}
-
-	resp, err := client.Get(url)
-	if err != nil {
-		return fmt.Errorf("HTTP 请求失败: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP 状态码错误: %d", resp.StatusCode)
-	}
-
-	// 创建文件
-	out, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("创建文件失败: %w", err)
-	}
-	defer out.Close()
-
-	// 复制内容
-	written, err := io.Copy(out, resp.Body)
-	if err != nil {
-		return fmt.Errorf("写入文件失败: %w", err)
-	}
-
-	s.log.Infow("文件下载成功", "filepath", filepath, "size", written)
-	return nil
-}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/database"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	_ "modernc.org/sqlite"
+)
+
+// 设置 TEST_POSTGRES_DSN（如 "host=localhost user=postgres password=postgres dbname=drama_test sslmode=disable"）
+// 后同一组用例也会在 PostgreSQL 上运行，每次运行使用独立的 schema
+const testPostgresDSNEnv = "TEST_POSTGRES_DSN"
+
+// testDatabases 返回执行过全部迁移的测试数据库
+func testDatabases(t *testing.T) map[string]*gorm.DB {
+	gormConfig := &gorm.Config{Logger: gormlogger.Discard}
+	dbs := make(map[string]*gorm.DB)
+
+	sqliteDB, err := gorm.Open(sqlite.Dialector{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")}, gormConfig)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	// 与 database.NewDatabase 一致：SQLite 单写入，限制为 1 个连接
+	sqlDB, err := sqliteDB.DB()
+	if err != nil {
+		t.Fatalf("sqlite handle: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	dbs["sqlite"] = sqliteDB
+
+	if dsn := os.Getenv(testPostgresDSNEnv); dsn != "" {
+		admin, err := gorm.Open(postgres.Open(dsn), gormConfig)
+		if err != nil {
+			t.Fatalf("open postgres: %v", err)
+		}
+		schema := fmt.Sprintf("test_%d", time.Now().UnixNano())
+		if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+			t.Fatalf("create schema: %v", err)
+		}
+		t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+		pgDB, err := gorm.Open(postgres.Open(dsn+" search_path="+schema), gormConfig)
+		if err != nil {
+			t.Fatalf("open postgres schema: %v", err)
+		}
+		dbs["postgres"] = pgDB
+	}
+
+	for name, db := range dbs {
+		if _, err := database.MigrateUp(db); err != nil {
+			t.Fatalf("%s: migrate up: %v", name, err)
+		}
+		if err := database.CheckMigrations(db); err != nil {
+			t.Fatalf("%s: check migrations: %v", name, err)
+		}
+	}
+	return dbs
+}
+
+// forEachDatabase 在每个测试数据库上运行同一组用例
+func forEachDatabase(t *testing.T, fn func(t *testing.T, db *gorm.DB)) {
+	for name, db := range testDatabases(t) {
+		db := db
+		t.Run(name, func(t *testing.T) { fn(t, db) })
+	}
+}
+
+// testConfig 使用临时目录作为本地存储
+func testConfig(t *testing.T) *config.Config {
+	return &config.Config{Storage: config.StorageConfig{LocalPath: t.TempDir(), BaseURL: "http://localhost:5678/static"}}
+}
+
+// testStorage 在测试配置的本地目录上创建存储
+func testStorage(t *testing.T, cfg *config.Config) storage.Storage {
+	t.Helper()
+	fileStorage, err := storage.NewLocalStorage(cfg.Storage.LocalPath, cfg.Storage.BaseURL)
+	if err != nil {
+		t.Fatalf("local storage: %v", err)
+	}
+	return fileStorage
+}
+
+// mustCreate 依次创建记录，失败时终止测试
+func mustCreate(t *testing.T, db *gorm.DB, values ...interface{}) {
+	t.Helper()
+	for _, value := range values {
+		if err := db.Create(value).Error; err != nil {
+			t.Fatalf("create %T: %v", value, err)
+		}
+	}
+}
+
+// newTestAssetService 使用测试配置的本地存储创建素材服务
+func newTestAssetService(t *testing.T, db *gorm.DB, cfg *config.Config) *AssetService {
+	return NewAssetService(db, testStorage(t, cfg), cfg.Storage.LocalPath, cfg.Storage.BaseURL, logger.NewLogger(false))
+}
+
+// newTestVideoMergeService 使用测试配置的本地存储创建视频合成服务
+func newTestVideoMergeService(t *testing.T, db *gorm.DB, cfg *config.Config) *VideoMergeService {
+	fileStorage := testStorage(t, cfg)
+	return NewVideoMergeService(db, NewResourceTransferService(db, fileStorage, logger.NewLogger(false)), fileStorage,
+		cfg.Storage.LocalPath, cfg.Storage.BaseURL, logger.NewLogger(false))
+}
+
+// newTestPipelineService 使用测试配置的本地存储创建流水线服务
+func newTestPipelineService(t *testing.T, db *gorm.DB, cfg *config.Config) *PipelineService {
+	fileStorage := testStorage(t, cfg)
+	return NewPipelineService(db, cfg, NewResourceTransferService(db, fileStorage, logger.NewLogger(false)), fileStorage, logger.NewLogger(false))
+}
+
+// createTestEpisode 创建一部剧和它的第一集
+func createTestEpisode(t *testing.T, db *gorm.DB) (*models.Drama, *models.Episode) {
+	t.Helper()
+	drama := &models.Drama{Title: "Midnight Train", Status: "draft"}
+	mustCreate(t, db, drama)
+	episode := &models.Episode{DramaID: drama.ID, EpisodeNum: 1, Title: "Departure"}
+	mustCreate(t, db, episode)
+	return drama, episode
+}
+
+func writeTestFile(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().AddDate(0, 0, -30)
+	if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, old, old)
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+
+	models "github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// DialogueAudioService 将 TTS 生成的对白音频登记为分镜素材，供成片混音使用
+type DialogueAudioService struct {
+	db          *gorm.DB
+	fileStorage storage.Storage
+	storagePath string
+	log         *logger.Logger
+}
+
+func NewDialogueAudioService(db *gorm.DB, fileStorage storage.Storage, storagePath string, log *logger.Logger) *DialogueAudioService {
+	return &DialogueAudioService{
+		db:          db,
+		fileStorage: fileStorage,
+		storagePath: storagePath,
+		log:         log,
+	}
+}
+
+// DialogueOutputPath 分镜对白音频在本地存储目录中的相对路径
+func (s *DialogueAudioService) DialogueOutputPath(storyboardID uint, fileName string) string {
+	return filepath.Join("audio", "dialogue", fmt.Sprintf("storyboard_%d", storyboardID), fileName)
+}
+
+// StoryboardDramaID 检查分镜是否存在并返回其所属剧本 ID，生成语音前调用以免产生无主文件
+func (s *DialogueAudioService) StoryboardDramaID(storyboardID uint) (uint, error) {
+	var storyboard models.Storyboard
+	if err := s.db.Select("id", "episode_id").Where("id = ?", storyboardID).First(&storyboard).Error; err != nil {
+		return 0, fmt.Errorf("storyboard not found")
+	}
+	var episode models.Episode
+	if err := s.db.Select("id", "drama_id").Where("id = ?", storyboard.EpisodeID).First(&episode).Error; err != nil {
+		return 0, fmt.Errorf("episode not found")
+	}
+	return episode.DramaID, nil
+}
+
+// RegisterDialogue 将本地存储目录 relPath 处的对白音频写入存储后端，并创建分镜的对白素材
+// durationMs 为 TTS 返回的时长（毫秒），未知时传 0
+func (s *DialogueAudioService) RegisterDialogue(storyboardID uint, relPath, text string, durationMs int) (*models.Asset, error) {
+	var storyboard models.Storyboard
+	if err := s.db.Where("id = ?", storyboardID).First(&storyboard).Error; err != nil {
+		return nil, fmt.Errorf("storyboard not found")
+	}
+	var episode models.Episode
+	if err := s.db.Select("id", "drama_id").Where("id = ?", storyboard.EpisodeID).First(&episode).Error; err != nil {
+		return nil, fmt.Errorf("episode not found")
+	}
+
+	url, err := publishMedia(s.fileStorage, s.storagePath, relPath)
+	if err != nil {
+		return nil, err
+	}
+
+	key := filepath.ToSlash(relPath)
+	category := models.AssetCategoryDialogue
+	format := strings.TrimPrefix(filepath.Ext(relPath), ".")
+	asset := &models.Asset{
+		DramaID:       &episode.DramaID,
+		EpisodeID:     &episode.ID,
+		StoryboardID:  &storyboard.ID,
+		StoryboardNum: &storyboard.StoryboardNumber,
+		Name:          fmt.Sprintf("对白 - 分镜 %d", storyboard.StoryboardNumber),
+		Type:          models.AssetTypeAudio,
+		Category:      &category,
+		URL:           url,
+		LocalPath:     &key,
+		Format:        &format,
+	}
+	if text = strings.TrimSpace(text); text != "" {
+		asset.Description = &text
+	}
+	if durationMs > 0 {
+		// 仅用于展示；混音时优先探测音频文件的精确时长
+		duration := int(math.Ceil(float64(durationMs) / 1000))
+		asset.Duration = &duration
+	}
+
+	// 同一分镜同一句台词重新配音时替换原素材，避免混音时重复播放
+	if existing := s.findDialogue(storyboard.ID, text); existing != nil {
+		oldPath := existing.LocalPath
+		asset.ID = existing.ID
+		asset.CreatedAt = existing.CreatedAt
+		if err := s.db.Model(existing).Select("name", "url", "local_path", "format", "duration").Updates(asset).Error; err != nil {
+			return nil, fmt.Errorf("failed to update dialogue asset: %w", err)
+		}
+		if oldPath != nil && *oldPath != key {
+			releaseStorageKey(s.db, s.log, oldPath)
+		}
+		s.log.Infow("Dialogue audio replaced", "asset_id", asset.ID, "storyboard_id", storyboard.ID, "url", url)
+		return asset, nil
+	}
+
+	if err := s.db.Create(asset).Error; err != nil {
+		return nil, fmt.Errorf("failed to create dialogue asset: %w", err)
+	}
+
+	s.log.Infow("Dialogue audio registered", "asset_id", asset.ID, "storyboard_id", storyboard.ID, "url", url)
+	return asset, nil
+}
+
+// findDialogue 查找分镜中同一台词的对白素材，台词为空时不匹配
+func (s *DialogueAudioService) findDialogue(storyboardID uint, text string) *models.Asset {
+	if text == "" {
+		return nil
+	}
+	var asset models.Asset
+	if err := s.db.Where("storyboard_id = ? AND type = ? AND category = ? AND description = ?",
+		storyboardID, models.AssetTypeAudio, models.AssetCategoryDialogue, text).
+		Order("id DESC").First(&asset).Error; err != nil {
+		return nil
+	}
+	return &asset
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+func TestRegisterDialogueCreatesStoryboardAsset(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		drama, episode := createTestEpisode(t, db)
+		storyboard := &models.Storyboard{EpisodeID: episode.ID, StoryboardNumber: 3}
+		mustCreate(t, db, storyboard)
+
+		service := NewDialogueAudioService(db, testStorage(t, cfg), cfg.Storage.LocalPath, logger.NewLogger(false))
+		if _, err := service.StoryboardDramaID(storyboard.ID + 100); err == nil {
+			t.Fatal("expected missing storyboard to be rejected")
+		}
+		if dramaID, err := service.StoryboardDramaID(storyboard.ID); err != nil || dramaID != drama.ID {
+			t.Fatalf("expected storyboard to resolve to drama %d, got %d (%v)", drama.ID, dramaID, err)
+		}
+		relPath := service.DialogueOutputPath(storyboard.ID, "line.mp3")
+		writeTestFile(t, filepath.Join(cfg.Storage.LocalPath, relPath))
+
+		asset, err := service.RegisterDialogue(storyboard.ID, relPath, "我们被耍了", 2500)
+		if err != nil {
+			t.Fatalf("register dialogue: %v", err)
+		}
+		if asset.Type != models.AssetTypeAudio || asset.Category == nil || *asset.Category != models.AssetCategoryDialogue {
+			t.Fatalf("expected dialogue audio asset, got type %q category %v", asset.Type, asset.Category)
+		}
+		if asset.StoryboardID == nil || *asset.StoryboardID != storyboard.ID || *asset.EpisodeID != episode.ID || *asset.DramaID != drama.ID {
+			t.Fatalf("asset not linked to storyboard/episode/drama: %+v", asset)
+		}
+		if asset.Duration == nil || *asset.Duration != 3 {
+			t.Fatalf("expected duration rounded up to 3s, got %v", asset.Duration)
+		}
+		if want := cfg.Storage.BaseURL + "/" + filepath.ToSlash(relPath); asset.URL != want {
+			t.Fatalf("expected url %q, got %q", want, asset.URL)
+		}
+
+		// 同一句台词重新配音替换原素材，不同台词新增素材
+		retakePath := service.DialogueOutputPath(storyboard.ID, "retake.mp3")
+		writeTestFile(t, filepath.Join(cfg.Storage.LocalPath, retakePath))
+		retake, err := service.RegisterDialogue(storyboard.ID, retakePath, "我们被耍了", 1200)
+		if err != nil {
+			t.Fatalf("register retake: %v", err)
+		}
+		if retake.ID != asset.ID {
+			t.Fatalf("expected retake to replace asset %d, got %d", asset.ID, retake.ID)
+		}
+		if _, err := service.RegisterDialogue(storyboard.ID, retakePath, "快走", 0); err != nil {
+			t.Fatalf("register second line: %v", err)
+		}
+
+		var dialogues []models.Asset
+		if err := db.Where("storyboard_id = ?", storyboard.ID).Order("id").Find(&dialogues).Error; err != nil {
+			t.Fatalf("load dialogues: %v", err)
+		}
+		if len(dialogues) != 2 || *dialogues[0].LocalPath != filepath.ToSlash(retakePath) || *dialogues[0].Duration != 2 {
+			t.Fatalf("expected replaced line plus one new line, got %+v", dialogues)
+		}
+	})
+}
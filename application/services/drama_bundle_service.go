@@ -0,0 +1,795 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	dramaBundleVersion  = 1
+	dramaBundleManifest = "manifest.json"
+	dramaBundleMediaDir = "media/"
+)
+
+// DramaBundleService 将剧本及其全部数据与媒体文件导出为 zip 包，或从 zip 包导入为新剧本
+type DramaBundleService struct {
+	db          *gorm.DB
+	fileStorage storage.Storage
+	localPath   string
+	log         *logger.Logger
+}
+
+// DramaBundle zip 包中的 manifest.json
+// 媒体文件以存储 key 保存在 media/ 目录下，References 记录数据中的引用（URL或相对路径）对应的 key
+type DramaBundle struct {
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+
+	Drama            models.Drama             `json:"drama"`
+	Branding         *models.DramaBranding    `json:"branding,omitempty"`
+	Preset           *models.DramaPreset      `json:"preset,omitempty"`
+	Episodes         []models.Episode         `json:"episodes"`
+	Characters       []models.Character       `json:"characters"`
+	Scenes           []models.Scene           `json:"scenes"`
+	Props            []models.Prop            `json:"props"`
+	Storyboards      []models.Storyboard      `json:"storyboards"`
+	FramePrompts     []models.FramePrompt     `json:"frame_prompts"`
+	ImageGenerations []models.ImageGeneration `json:"image_generations"`
+	VideoGenerations []models.VideoGeneration `json:"video_generations"`
+	Assets           []models.Asset           `json:"assets"`
+	VideoMerges      []models.VideoMerge      `json:"video_merges,omitempty"`
+
+	EpisodeScreenplays []models.EpisodeScreenplay `json:"episode_screenplays,omitempty"`
+	EpisodeSummaries   []models.EpisodeSummary    `json:"episode_summaries,omitempty"`
+
+	EpisodeCharacters    []EpisodeCharacterLink    `json:"episode_characters"`
+	StoryboardCharacters []StoryboardCharacterLink `json:"storyboard_characters"`
+	StoryboardProps      []StoryboardPropLink      `json:"storyboard_props"`
+
+	Media      []string          `json:"media"`
+	References map[string]string `json:"references"`
+}
+
+// EpisodeCharacterLink 章节与角色关联（episode_characters）
+type EpisodeCharacterLink struct {
+	EpisodeID   uint `json:"episode_id"`
+	CharacterID uint `json:"character_id"`
+}
+
+// StoryboardCharacterLink 分镜与角色关联（storyboard_characters）
+type StoryboardCharacterLink struct {
+	StoryboardID uint `json:"storyboard_id"`
+	CharacterID  uint `json:"character_id"`
+}
+
+// StoryboardPropLink 分镜与道具关联（storyboard_props）
+type StoryboardPropLink struct {
+	StoryboardID uint `json:"storyboard_id"`
+	PropID       uint `json:"prop_id"`
+}
+
+func NewDramaBundleService(db *gorm.DB, cfg *config.Config, fileStorage storage.Storage, log *logger.Logger) *DramaBundleService {
+	return &DramaBundleService{
+		db:          db,
+		fileStorage: fileStorage,
+		localPath:   cfg.Storage.LocalPath,
+		log:         log,
+	}
+}
+
+// ExportDrama 将剧本导出为 zip 包写入 w
+func (s *DramaBundleService) ExportDrama(dramaID uint, w io.Writer) (*DramaBundle, error) {
+	bundle, err := s.loadBundle(dramaID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 收集本地存储中的媒体文件，远程URL原样保留
+	keys := make(map[string]bool)
+	visitBundleMedia(bundle, func(value string) string {
+		if _, ok := bundle.References[value]; ok {
+			return value
+		}
+		if key, ok := s.mediaKey(value); ok {
+			bundle.References[value] = key
+			keys[key] = true
+		}
+		return value
+	})
+	for key := range keys {
+		bundle.Media = append(bundle.Media, key)
+	}
+	sort.Strings(bundle.Media)
+
+	zw := zip.NewWriter(w)
+	manifest, err := zw.Create(dramaBundleManifest)
+	if err != nil {
+		return nil, err
+	}
+	encoder := json.NewEncoder(manifest)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	for _, key := range bundle.Media {
+		if err := s.writeMedia(zw, key); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	s.log.Infow("Drama exported",
+		"drama_id", dramaID,
+		"episodes", len(bundle.Episodes),
+		"storyboards", len(bundle.Storyboards),
+		"media", len(bundle.Media))
+	return bundle, nil
+}
+
+// ImportDrama 从 zip 包导入剧本，所有记录使用新ID，媒体文件写入当前存储
+func (s *DramaBundleService) ImportDrama(r io.ReaderAt, size int64) (*models.Drama, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	manifest, ok := files[dramaBundleManifest]
+	if !ok {
+		return nil, fmt.Errorf("invalid bundle: manifest.json not found")
+	}
+	rc, err := manifest.Open()
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	var bundle DramaBundle
+	err = json.NewDecoder(rc).Decode(&bundle)
+	rc.Close()
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if bundle.Version < 1 || bundle.Version > dramaBundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version: %d", bundle.Version)
+	}
+
+	// 先写入媒体文件，再改写数据中的引用
+	newKeys := make(map[string]string, len(bundle.Media))
+	for _, key := range bundle.Media {
+		f, ok := files[dramaBundleMediaDir+key]
+		if !ok {
+			s.log.Warnw("Bundle media missing", "key", key)
+			continue
+		}
+		newKey, err := s.importMedia(f, key)
+		if err != nil {
+			return nil, err
+		}
+		newKeys[key] = newKey
+	}
+
+	visitBundleMedia(&bundle, func(value string) string {
+		newKey, ok := newKeys[bundle.References[value]]
+		if !ok {
+			return value
+		}
+		if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "/") {
+			return s.fileStorage.GetURL(newKey)
+		}
+		return newKey
+	})
+
+	var drama *models.Drama
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		drama, err = s.createBundle(tx, &bundle)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to import drama: %w", err)
+	}
+
+	s.log.Infow("Drama imported",
+		"drama_id", drama.ID,
+		"title", drama.Title,
+		"episodes", len(bundle.Episodes),
+		"storyboards", len(bundle.Storyboards),
+		"media", len(newKeys))
+	return drama, nil
+}
+
+// loadBundle 读取剧本的全部数据（不含媒体文件）
+func (s *DramaBundleService) loadBundle(dramaID uint) (*DramaBundle, error) {
+	bundle := &DramaBundle{
+		Version:    dramaBundleVersion,
+		ExportedAt: time.Now(),
+		References: make(map[string]string),
+	}
+
+	if err := s.db.First(&bundle.Drama, dramaID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("drama not found")
+		}
+		return nil, err
+	}
+
+	var branding models.DramaBranding
+	if err := s.db.Where("drama_id = ?", dramaID).First(&branding).Error; err == nil {
+		bundle.Branding = &branding
+	}
+	var preset models.DramaPreset
+	if err := s.db.Where("drama_id = ?", dramaID).First(&preset).Error; err == nil {
+		bundle.Preset = &preset
+	}
+
+	queries := []struct {
+		dest  interface{}
+		order string
+	}{
+		{&bundle.Episodes, "episode_number ASC"},
+		{&bundle.Characters, "sort_order ASC, id ASC"},
+		{&bundle.Scenes, "id ASC"},
+		{&bundle.Props, "id ASC"},
+		{&bundle.ImageGenerations, "id ASC"},
+		{&bundle.VideoGenerations, "id ASC"},
+		{&bundle.VideoMerges, "id ASC"},
+	}
+	for _, q := range queries {
+		if err := s.db.Where("drama_id = ?", dramaID).Order(q.order).Find(q.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+	if err := s.db.Preload("Tags").Where("drama_id = ?", dramaID).Order("id ASC").Find(&bundle.Assets).Error; err != nil {
+		return nil, err
+	}
+
+	episodeIDs := make([]uint, 0, len(bundle.Episodes))
+	for i := range bundle.Episodes {
+		episodeIDs = append(episodeIDs, bundle.Episodes[i].ID)
+		// HLS 分片目录不导出，可在目标实例重新生成
+		bundle.Episodes[i].HLSURL = nil
+	}
+	if len(episodeIDs) == 0 {
+		return bundle, nil
+	}
+
+	if err := s.db.Where("episode_id IN ?", episodeIDs).Order("episode_id ASC, storyboard_number ASC").Find(&bundle.Storyboards).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Table("episode_characters").Where("episode_id IN ?", episodeIDs).Find(&bundle.EpisodeCharacters).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("episode_id IN ?", episodeIDs).Order("episode_id ASC").Find(&bundle.EpisodeScreenplays).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Where("episode_id IN ?", episodeIDs).Order("episode_id ASC").Find(&bundle.EpisodeSummaries).Error; err != nil {
+		return nil, err
+	}
+
+	storyboardIDs := make([]uint, 0, len(bundle.Storyboards))
+	for _, sb := range bundle.Storyboards {
+		storyboardIDs = append(storyboardIDs, sb.ID)
+	}
+	if len(storyboardIDs) == 0 {
+		return bundle, nil
+	}
+
+	if err := s.db.Where("storyboard_id IN ?", storyboardIDs).Order("id ASC").Find(&bundle.FramePrompts).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Table("storyboard_characters").Where("storyboard_id IN ?", storyboardIDs).Find(&bundle.StoryboardCharacters).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.Table("storyboard_props").Where("storyboard_id IN ?", storyboardIDs).Find(&bundle.StoryboardProps).Error; err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// createBundle 按依赖顺序创建记录并映射新旧ID
+func (s *DramaBundleService) createBundle(tx *gorm.DB, b *DramaBundle) (*models.Drama, error) {
+	create := func(value interface{}) error {
+		return tx.Omit(clause.Associations).Create(value).Error
+	}
+
+	drama := b.Drama
+	drama.ID = 0
+	if err := create(&drama); err != nil {
+		return nil, err
+	}
+
+	if b.Branding != nil {
+		branding := *b.Branding
+		branding.ID = 0
+		branding.DramaID = drama.ID
+		// 显式写入全部字段，关闭的品牌设置和零值边距不能被列默认值覆盖
+		if err := tx.Select("*").Omit("ID", clause.Associations).Create(&branding).Error; err != nil {
+			return nil, err
+		}
+	}
+	if b.Preset != nil {
+		preset := *b.Preset
+		preset.ID = 0
+		preset.DramaID = drama.ID
+		if err := create(&preset); err != nil {
+			return nil, err
+		}
+	}
+
+	characterIDs := make(map[uint]uint)
+	for _, character := range b.Characters {
+		oldID := character.ID
+		character.ID = 0
+		character.DramaID = drama.ID
+		if err := create(&character); err != nil {
+			return nil, err
+		}
+		characterIDs[oldID] = character.ID
+	}
+
+	episodeIDs := make(map[uint]uint)
+	for _, episode := range b.Episodes {
+		oldID := episode.ID
+		episode.ID = 0
+		episode.DramaID = drama.ID
+		if err := create(&episode); err != nil {
+			return nil, err
+		}
+		episodeIDs[oldID] = episode.ID
+	}
+
+	sceneIDs := make(map[uint]uint)
+	for _, scene := range b.Scenes {
+		oldID := scene.ID
+		scene.ID = 0
+		scene.DramaID = drama.ID
+		scene.EpisodeID = remapID(episodeIDs, scene.EpisodeID)
+		if err := create(&scene); err != nil {
+			return nil, err
+		}
+		sceneIDs[oldID] = scene.ID
+	}
+
+	propIDs := make(map[uint]uint)
+	for _, prop := range b.Props {
+		oldID := prop.ID
+		prop.ID = 0
+		prop.DramaID = drama.ID
+		if err := create(&prop); err != nil {
+			return nil, err
+		}
+		propIDs[oldID] = prop.ID
+	}
+
+	storyboardIDs := make(map[uint]uint)
+	for _, sb := range b.Storyboards {
+		episodeID, ok := episodeIDs[sb.EpisodeID]
+		if !ok {
+			continue
+		}
+		oldID := sb.ID
+		sb.ID = 0
+		sb.EpisodeID = episodeID
+		sb.SceneID = remapID(sceneIDs, sb.SceneID)
+		if err := create(&sb); err != nil {
+			return nil, err
+		}
+		storyboardIDs[oldID] = sb.ID
+	}
+
+	for _, sp := range b.EpisodeScreenplays {
+		episodeID, ok := episodeIDs[sp.EpisodeID]
+		if !ok {
+			continue
+		}
+		sp.ID = 0
+		sp.EpisodeID = episodeID
+		if err := create(&sp); err != nil {
+			return nil, err
+		}
+	}
+	for _, summary := range b.EpisodeSummaries {
+		episodeID, ok := episodeIDs[summary.EpisodeID]
+		if !ok {
+			continue
+		}
+		summary.ID = 0
+		summary.EpisodeID = episodeID
+		if err := create(&summary); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, link := range b.EpisodeCharacters {
+		episodeID, ok1 := episodeIDs[link.EpisodeID]
+		characterID, ok2 := characterIDs[link.CharacterID]
+		if ok1 && ok2 {
+			if err := tx.Table("episode_characters").Create(&EpisodeCharacterLink{EpisodeID: episodeID, CharacterID: characterID}).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, link := range b.StoryboardCharacters {
+		storyboardID, ok1 := storyboardIDs[link.StoryboardID]
+		characterID, ok2 := characterIDs[link.CharacterID]
+		if ok1 && ok2 {
+			if err := tx.Table("storyboard_characters").Create(&StoryboardCharacterLink{StoryboardID: storyboardID, CharacterID: characterID}).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+	for _, link := range b.StoryboardProps {
+		storyboardID, ok1 := storyboardIDs[link.StoryboardID]
+		propID, ok2 := propIDs[link.PropID]
+		if ok1 && ok2 {
+			if err := tx.Table("storyboard_props").Create(&StoryboardPropLink{StoryboardID: storyboardID, PropID: propID}).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, fp := range b.FramePrompts {
+		storyboardID, ok := storyboardIDs[fp.StoryboardID]
+		if !ok {
+			continue
+		}
+		fp.ID = 0
+		fp.StoryboardID = storyboardID
+		if err := create(&fp); err != nil {
+			return nil, err
+		}
+	}
+
+	imageGenIDs := make(map[uint]uint)
+	for _, gen := range b.ImageGenerations {
+		oldID := gen.ID
+		gen.ID = 0
+		gen.DramaID = drama.ID
+		gen.StoryboardID = remapID(storyboardIDs, gen.StoryboardID)
+		gen.SceneID = remapID(sceneIDs, gen.SceneID)
+		gen.CharacterID = remapID(characterIDs, gen.CharacterID)
+		gen.PropID = remapID(propIDs, gen.PropID)
+		if err := create(&gen); err != nil {
+			return nil, err
+		}
+		imageGenIDs[oldID] = gen.ID
+	}
+
+	videoGenIDs := make(map[uint]uint)
+	for _, gen := range b.VideoGenerations {
+		oldID := gen.ID
+		gen.ID = 0
+		gen.DramaID = drama.ID
+		gen.StoryboardID = remapID(storyboardIDs, gen.StoryboardID)
+		gen.ImageGenID = remapID(imageGenIDs, gen.ImageGenID)
+		if err := create(&gen); err != nil {
+			return nil, err
+		}
+		videoGenIDs[oldID] = gen.ID
+	}
+
+	tagIDs := make(map[string]uint)
+	assetIDs := make(map[uint]uint)
+	for _, asset := range b.Assets {
+		tags := asset.Tags
+		oldID := asset.ID
+		asset.ID = 0
+		asset.DramaID = &drama.ID
+		asset.EpisodeID = remapID(episodeIDs, asset.EpisodeID)
+		asset.StoryboardID = remapID(storyboardIDs, asset.StoryboardID)
+		asset.ImageGenID = remapID(imageGenIDs, asset.ImageGenID)
+		asset.VideoGenID = remapID(videoGenIDs, asset.VideoGenID)
+		if err := create(&asset); err != nil {
+			return nil, err
+		}
+		assetIDs[oldID] = asset.ID
+
+		for _, tag := range tags {
+			tagID, err := s.importTag(tx, tag, drama.ID, tagIDs)
+			if err != nil {
+				return nil, err
+			}
+			if err := tx.Create(&models.AssetTag{AssetID: asset.ID, TagID: tagID}).Error; err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, merge := range b.VideoMerges {
+		episodeID, ok := episodeIDs[merge.EpisodeID]
+		if !ok {
+			continue
+		}
+		merge.ID = 0
+		merge.EpisodeID = episodeID
+		merge.DramaID = drama.ID
+		merge.TaskID = nil
+		merge.Scenes = remapMergeScenes(merge.Scenes, storyboardIDs)
+		merge.Options = remapMergeOptions(merge.Options, assetIDs)
+		if err := create(&merge); err != nil {
+			return nil, err
+		}
+	}
+
+	return &drama, nil
+}
+
+// remapMergeScenes 将合成片段中的分镜ID映射为新ID，不属于本剧本的分镜置零
+func remapMergeScenes(raw datatypes.JSON, storyboardIDs map[uint]uint) datatypes.JSON {
+	var scenes []models.SceneClip
+	if len(raw) == 0 || json.Unmarshal(raw, &scenes) != nil {
+		return raw
+	}
+	for i := range scenes {
+		scenes[i].SceneID = storyboardIDs[scenes[i].SceneID]
+	}
+	data, err := json.Marshal(scenes)
+	if err != nil {
+		return raw
+	}
+	return datatypes.JSON(data)
+}
+
+// remapMergeOptions 将合成选项中引用的背景音乐素材映射为新ID
+func remapMergeOptions(raw datatypes.JSON, assetIDs map[uint]uint) datatypes.JSON {
+	var options MergeOutputOptions
+	if len(raw) == 0 || json.Unmarshal(raw, &options) != nil {
+		return raw
+	}
+	if options.AudioMix == nil || options.AudioMix.BGMAssetID == nil {
+		return raw
+	}
+	options.AudioMix.BGMAssetID = remapID(assetIDs, options.AudioMix.BGMAssetID)
+	data, err := json.Marshal(options)
+	if err != nil {
+		return raw
+	}
+	return datatypes.JSON(data)
+}
+
+// importTag 剧本标签在新剧本下重建，全局标签按名称复用
+func (s *DramaBundleService) importTag(tx *gorm.DB, tag models.Tag, dramaID uint, cache map[string]uint) (uint, error) {
+	cacheKey := "global:" + tag.Name
+	query := tx.Where("name = ? AND drama_id IS NULL", tag.Name)
+	newTag := models.Tag{Name: tag.Name, Color: tag.Color}
+	if tag.DramaID != nil {
+		cacheKey = "drama:" + tag.Name
+		query = tx.Where("name = ? AND drama_id = ?", tag.Name, dramaID)
+		newTag.DramaID = &dramaID
+	}
+	if id, ok := cache[cacheKey]; ok {
+		return id, nil
+	}
+
+	var existing models.Tag
+	if err := query.First(&existing).Error; err == nil {
+		cache[cacheKey] = existing.ID
+		return existing.ID, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	if err := tx.Create(&newTag).Error; err != nil {
+		return 0, err
+	}
+	cache[cacheKey] = newTag.ID
+	return newTag.ID, nil
+}
+
+// mediaKey 将引用解析为当前存储中存在的文件 key，远程URL返回 false
+func (s *DramaBundleService) mediaKey(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, "data:") {
+		return "", false
+	}
+
+	key, ok := s.fileStorage.KeyFromURL(value)
+	if !ok {
+		switch {
+		case strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+			return "", false
+		case strings.Contains(value, "/static/"):
+			key = value[strings.Index(value, "/static/")+len("/static/"):]
+		default:
+			key = strings.TrimPrefix(filepath.ToSlash(value), filepath.ToSlash(filepath.Clean(s.localPath))+"/")
+		}
+		if idx := strings.IndexAny(key, "?#"); idx != -1 {
+			key = key[:idx]
+		}
+		key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	}
+	if key == "" {
+		return "", false
+	}
+
+	if _, err := s.fileStorage.Stat(key); err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+func (s *DramaBundleService) writeMedia(zw *zip.Writer, key string) error {
+	src, err := s.fileStorage.Download(key)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	defer src.Close()
+
+	// 图片与视频本身已压缩，直接存储
+	dst, err := zw.CreateHeader(&zip.FileHeader{Name: dramaBundleMediaDir + key, Method: zip.Store, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to write %s: %w", key, err)
+	}
+	return nil
+}
+
+// importMedia 将包内媒体写入存储，返回新的 key
+func (s *DramaBundleService) importMedia(f *zip.File, key string) (string, error) {
+	category := "imports"
+	if idx := strings.Index(key, "/"); idx > 0 {
+		category = key[:idx]
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	url, err := s.fileStorage.Upload(rc, path.Base(key), category)
+	if err != nil {
+		return "", fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	newKey, ok := s.fileStorage.KeyFromURL(url)
+	if !ok {
+		return "", fmt.Errorf("failed to resolve stored key for %s", key)
+	}
+	return newKey, nil
+}
+
+// visitBundleMedia 遍历所有引用存储文件的字段，visit 返回替换后的值；JSON 列逐个字符串处理
+func visitBundleMedia(b *DramaBundle, visit func(value string) string) {
+	ref := func(p *string) {
+		if p != nil && *p != "" {
+			*p = visit(*p)
+		}
+	}
+	embedded := func(p *datatypes.JSON) {
+		if p != nil && len(*p) > 0 {
+			*p = datatypes.JSON(rewriteJSONStrings(*p, visit))
+		}
+	}
+
+	ref(b.Drama.Thumbnail)
+	if b.Branding != nil {
+		ref(b.Branding.IntroVideo)
+		ref(b.Branding.OutroVideo)
+		ref(b.Branding.LogoImage)
+	}
+	for i := range b.Episodes {
+		ref(b.Episodes[i].VideoURL)
+		ref(b.Episodes[i].Thumbnail)
+	}
+	for i := range b.Characters {
+		ref(b.Characters[i].ImageURL)
+		ref(b.Characters[i].LocalPath)
+		embedded(&b.Characters[i].ReferenceImages)
+	}
+	for i := range b.Scenes {
+		ref(b.Scenes[i].ImageURL)
+		ref(b.Scenes[i].LocalPath)
+	}
+	for i := range b.Props {
+		ref(b.Props[i].ImageURL)
+		ref(b.Props[i].LocalPath)
+		embedded(&b.Props[i].ReferenceImages)
+	}
+	for i := range b.Storyboards {
+		ref(b.Storyboards[i].ComposedImage)
+		ref(b.Storyboards[i].VideoURL)
+	}
+	for i := range b.ImageGenerations {
+		gen := &b.ImageGenerations[i]
+		ref(gen.ImageURL)
+		ref(gen.MinioURL)
+		ref(gen.LocalPath)
+		embedded(&gen.ReferenceImages)
+	}
+	for i := range b.VideoGenerations {
+		gen := &b.VideoGenerations[i]
+		ref(gen.ImageURL)
+		ref(gen.FirstFrameURL)
+		ref(gen.LastFrameURL)
+		ref(gen.VideoURL)
+		ref(gen.MinioURL)
+		ref(gen.LocalPath)
+		if gen.ReferenceImageURLs != nil && *gen.ReferenceImageURLs != "" {
+			urls := string(rewriteJSONStrings([]byte(*gen.ReferenceImageURLs), visit))
+			gen.ReferenceImageURLs = &urls
+		}
+	}
+	for i := range b.VideoMerges {
+		merge := &b.VideoMerges[i]
+		ref(merge.MergedURL)
+		embedded(&merge.Scenes)
+	}
+	for i := range b.Assets {
+		asset := &b.Assets[i]
+		ref(&asset.URL)
+		ref(asset.LocalPath)
+		ref(asset.ThumbnailURL)
+		ref(asset.PosterURL)
+		ref(asset.SpriteURL)
+		ref(asset.WaveformURL)
+		ref(asset.PeaksURL)
+	}
+}
+
+// rewriteJSONStrings 对 JSON 中的每个字符串值调用 fn；无法解析时按单个字符串处理
+func rewriteJSONStrings(raw []byte, fn func(string) string) []byte {
+	var data interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return []byte(fn(string(raw)))
+	}
+
+	var walk func(v interface{}) interface{}
+	walk = func(v interface{}) interface{} {
+		switch val := v.(type) {
+		case string:
+			return fn(val)
+		case []interface{}:
+			for i := range val {
+				val[i] = walk(val[i])
+			}
+		case map[string]interface{}:
+			for k := range val {
+				val[k] = walk(val[k])
+			}
+		}
+		return v
+	}
+
+	result, err := json.Marshal(walk(data))
+	if err != nil {
+		return raw
+	}
+	return result
+}
+
+func remapID(ids map[uint]uint, id *uint) *uint {
+	if id == nil {
+		return nil
+	}
+	newID, ok := ids[*id]
+	if !ok {
+		return nil
+	}
+	return &newID
+}
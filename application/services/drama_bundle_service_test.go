@@ -0,0 +1,67 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestDramaBundleRoundTrip(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		drama, episode := createTestEpisode(t, db)
+		mustCreate(t, db, &models.DramaBranding{DramaID: drama.ID, Enabled: false, WatermarkPosition: "top-right"})
+		storyboard := &models.Storyboard{EpisodeID: episode.ID, StoryboardNumber: 1}
+		mustCreate(t, db, storyboard)
+		writeTestFile(t, filepath.Join(cfg.Storage.LocalPath, "videos", "merged", "episode.mp4"))
+		mergedURL := cfg.Storage.BaseURL + "/videos/merged/episode.mp4"
+		merge := &models.VideoMerge{EpisodeID: episode.ID, DramaID: drama.ID, Provider: "ffmpeg", Status: models.VideoMergeStatusCompleted,
+			Scenes: datatypes.JSON(fmt.Sprintf(`[{"scene_id":%d,"duration":5,"order":0}]`, storyboard.ID)), MergedURL: &mergedURL}
+		mustCreate(t, db, merge)
+
+		fileStorage := testStorage(t, cfg)
+		bundleService := NewDramaBundleService(db, cfg, fileStorage, logger.NewLogger(false))
+		var buf bytes.Buffer
+		if _, err := bundleService.ExportDrama(drama.ID, &buf); err != nil {
+			t.Fatalf("export drama: %v", err)
+		}
+		imported, err := bundleService.ImportDrama(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		if err != nil {
+			t.Fatalf("import drama: %v", err)
+		}
+
+		// 关闭的品牌设置导入后仍为关闭
+		var branding models.DramaBranding
+		if err := db.Where("drama_id = ?", imported.ID).First(&branding).Error; err != nil || branding.Enabled {
+			t.Fatalf("imported branding: %+v err=%v", branding, err)
+		}
+
+		// 合成记录随剧本导入，分镜ID与成片地址指向新记录
+		var importedMerge models.VideoMerge
+		if err := db.Where("drama_id = ?", imported.ID).First(&importedMerge).Error; err != nil {
+			t.Fatalf("imported merge: %v", err)
+		}
+		var importedStoryboard models.Storyboard
+		db.Joins("JOIN episodes ON episodes.id = storyboards.episode_id").
+			Where("episodes.drama_id = ?", imported.ID).First(&importedStoryboard)
+		var scenes []models.SceneClip
+		if err := json.Unmarshal(importedMerge.Scenes, &scenes); err != nil || len(scenes) != 1 || scenes[0].SceneID != importedStoryboard.ID {
+			t.Fatalf("merge scenes not remapped: %s (storyboard %d)", importedMerge.Scenes, importedStoryboard.ID)
+		}
+		if importedMerge.MergedURL == nil || *importedMerge.MergedURL == mergedURL || !strings.HasPrefix(*importedMerge.MergedURL, cfg.Storage.BaseURL) {
+			t.Fatalf("merged url not rewritten: %v", importedMerge.MergedURL)
+		}
+
+		if _, err := bundleService.ImportDrama(bytes.NewReader([]byte("not a zip")), 9); err == nil || !strings.HasPrefix(err.Error(), "invalid bundle") {
+			t.Fatalf("expected invalid bundle error, got %v", err)
+		}
+	})
+}
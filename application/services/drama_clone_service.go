@@ -0,0 +1,570 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DramaCloneService 复制剧本或章节作为模板
+// 复制只新建数据库记录，图片/视频文件与原记录共用，无需重新生成；共用的去重文件增加引用计数
+type DramaCloneService struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+// CloneDramaRequest 复制剧本参数
+type CloneDramaRequest struct {
+	Title      string `json:"title"`       // 新剧本标题，默认 "<原标题> (副本)"
+	SkipVideos bool   `json:"skip_videos"` // 不复制已生成的视频
+}
+
+// CloneEpisodeRequest 复制章节参数
+type CloneEpisodeRequest struct {
+	TargetDramaID *uint  `json:"target_drama_id"` // 目标剧本，默认为原剧本
+	Title         string `json:"title"`           // 新章节标题，默认 "<原标题> (副本)"
+	EpisodeNumber *int   `json:"episode_number"`  // 新章节序号，默认排在目标剧本最后
+	SkipVideos    bool   `json:"skip_videos"`     // 不复制已生成的视频
+}
+
+func NewDramaCloneService(db *gorm.DB, log *logger.Logger) *DramaCloneService {
+	return &DramaCloneService{
+		db:  db,
+		log: log,
+	}
+}
+
+// CloneDrama 深拷贝剧本：角色、场景、道具、章节、分镜及其关联、帧提示词与生成记录
+func (s *DramaCloneService) CloneDrama(dramaID uint, req *CloneDramaRequest) (*models.Drama, error) {
+	var src models.Drama
+	if err := s.db.First(&src, dramaID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("drama not found")
+		}
+		return nil, err
+	}
+
+	drama := src
+	drama.ID = 0
+	drama.CreatedAt, drama.UpdatedAt = time.Time{}, time.Time{}
+	drama.Title = req.Title
+	if drama.Title == "" {
+		drama.Title = src.Title + " (副本)"
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(&drama).Error; err != nil {
+			return err
+		}
+		c := newDramaCloner(tx, s.log, drama.ID, req.SkipVideos)
+
+		var branding models.DramaBranding
+		if err := tx.Where("drama_id = ?", src.ID).First(&branding).Error; err == nil {
+			branding.ID = 0
+			branding.DramaID = drama.ID
+			branding.CreatedAt, branding.UpdatedAt = time.Time{}, time.Time{}
+			// 显式写入全部字段，关闭的品牌设置和零值边距不能被列默认值覆盖
+			if err := tx.Select("*").Omit("ID").Create(&branding).Error; err != nil {
+				return err
+			}
+		}
+
+		var preset models.DramaPreset
+		if err := tx.Where("drama_id = ?", src.ID).First(&preset).Error; err == nil {
+			preset.ID = 0
+			preset.DramaID = drama.ID
+			preset.CreatedAt, preset.UpdatedAt = time.Time{}, time.Time{}
+			if err := tx.Create(&preset).Error; err != nil {
+				return err
+			}
+		}
+
+		var characters []models.Character
+		if err := tx.Where("drama_id = ?", src.ID).Order("sort_order ASC, id ASC").Find(&characters).Error; err != nil {
+			return err
+		}
+		for _, character := range characters {
+			if _, err := c.cloneCharacter(character); err != nil {
+				return err
+			}
+		}
+
+		var props []models.Prop
+		if err := tx.Where("drama_id = ?", src.ID).Order("id ASC").Find(&props).Error; err != nil {
+			return err
+		}
+		for _, prop := range props {
+			if _, err := c.cloneProp(prop); err != nil {
+				return err
+			}
+		}
+
+		var episodes []models.Episode
+		if err := tx.Where("drama_id = ?", src.ID).Order("episode_number ASC").Find(&episodes).Error; err != nil {
+			return err
+		}
+		newEpisodes := make([]*models.Episode, len(episodes))
+		for i, episode := range episodes {
+			newEpisode, err := c.cloneEpisode(episode, episode.Title, episode.EpisodeNum)
+			if err != nil {
+				return err
+			}
+			newEpisodes[i] = newEpisode
+		}
+
+		var scenes []models.Scene
+		if err := tx.Where("drama_id = ?", src.ID).Order("id ASC").Find(&scenes).Error; err != nil {
+			return err
+		}
+		for _, scene := range scenes {
+			if _, err := c.cloneScene(scene, remapID(c.episodeIDs, scene.EpisodeID)); err != nil {
+				return err
+			}
+		}
+
+		for i, episode := range episodes {
+			if err := c.cloneEpisodeContent(episode, newEpisodes[i]); err != nil {
+				return err
+			}
+		}
+
+		// 角色、场景、道具的图片生成记录
+		var imageGens []models.ImageGeneration
+		if err := tx.Where("drama_id = ? AND storyboard_id IS NULL", src.ID).Order("id ASC").Find(&imageGens).Error; err != nil {
+			return err
+		}
+		return c.cloneImageGenerations(imageGens)
+	})
+	if err != nil {
+		s.log.Errorw("Failed to clone drama", "error", err, "drama_id", dramaID)
+		return nil, err
+	}
+
+	s.log.Infow("Drama cloned", "source_id", dramaID, "drama_id", drama.ID, "skip_videos", req.SkipVideos)
+	return &drama, nil
+}
+
+// CloneEpisode 复制章节到同一剧本或其他剧本
+// 目标剧本中同名角色、道具与同地点同时间的场景直接复用，不存在时复制；章节专属场景随章节复制
+func (s *DramaCloneService) CloneEpisode(episodeID uint, req *CloneEpisodeRequest) (*models.Episode, error) {
+	var src models.Episode
+	if err := s.db.First(&src, episodeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("episode not found")
+		}
+		return nil, err
+	}
+
+	targetDramaID := src.DramaID
+	if req.TargetDramaID != nil && *req.TargetDramaID != 0 {
+		targetDramaID = *req.TargetDramaID
+		var count int64
+		if err := s.db.Model(&models.Drama{}).Where("id = ?", targetDramaID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count == 0 {
+			return nil, fmt.Errorf("target drama not found")
+		}
+	}
+
+	title := req.Title
+	if title == "" {
+		title = src.Title + " (副本)"
+	}
+
+	var episode *models.Episode
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		episodeNum := 0
+		if req.EpisodeNumber != nil {
+			episodeNum = *req.EpisodeNumber
+		} else {
+			var maxNum *int
+			if err := tx.Model(&models.Episode{}).Where("drama_id = ?", targetDramaID).Select("MAX(episode_number)").Scan(&maxNum).Error; err != nil {
+				return err
+			}
+			if maxNum != nil {
+				episodeNum = *maxNum
+			}
+			episodeNum++
+		}
+
+		c := newDramaCloner(tx, s.log, targetDramaID, req.SkipVideos)
+		var err error
+		episode, err = c.cloneEpisode(src, title, episodeNum)
+		if err != nil {
+			return err
+		}
+
+		var scenes []models.Scene
+		if err := tx.Where("episode_id = ?", src.ID).Order("id ASC").Find(&scenes).Error; err != nil {
+			return err
+		}
+		for _, scene := range scenes {
+			if _, err := c.cloneScene(scene, &episode.ID); err != nil {
+				return err
+			}
+		}
+
+		return c.cloneEpisodeContent(src, episode)
+	})
+	if err != nil {
+		s.log.Errorw("Failed to clone episode", "error", err, "episode_id", episodeID)
+		return nil, err
+	}
+
+	s.log.Infow("Episode cloned",
+		"source_id", episodeID,
+		"episode_id", episode.ID,
+		"drama_id", targetDramaID,
+		"skip_videos", req.SkipVideos)
+	return episode, nil
+}
+
+// dramaCloner 在事务中复制记录，并记录原ID到新ID的映射
+type dramaCloner struct {
+	tx         *gorm.DB
+	log        *logger.Logger
+	dramaID    uint // 目标剧本
+	skipVideos bool
+
+	characterIDs  map[uint]uint
+	sceneIDs      map[uint]uint
+	propIDs       map[uint]uint
+	episodeIDs    map[uint]uint
+	storyboardIDs map[uint]uint
+	imageGenIDs   map[uint]uint
+}
+
+func newDramaCloner(tx *gorm.DB, log *logger.Logger, dramaID uint, skipVideos bool) *dramaCloner {
+	return &dramaCloner{
+		tx:            tx,
+		log:           log,
+		dramaID:       dramaID,
+		skipVideos:    skipVideos,
+		characterIDs:  make(map[uint]uint),
+		sceneIDs:      make(map[uint]uint),
+		propIDs:       make(map[uint]uint),
+		episodeIDs:    make(map[uint]uint),
+		storyboardIDs: make(map[uint]uint),
+		imageGenIDs:   make(map[uint]uint),
+	}
+}
+
+func (c *dramaCloner) create(value interface{}) error {
+	return c.tx.Omit(clause.Associations).Create(value).Error
+}
+
+func (c *dramaCloner) cloneCharacter(src models.Character) (uint, error) {
+	oldID := src.ID
+	src.ID = 0
+	src.DramaID = c.dramaID
+	src.CreatedAt, src.UpdatedAt = time.Time{}, time.Time{}
+	if err := c.create(&src); err != nil {
+		return 0, err
+	}
+	retainStorageKey(c.tx, c.log, src.LocalPath)
+	c.characterIDs[oldID] = src.ID
+	return src.ID, nil
+}
+
+func (c *dramaCloner) cloneProp(src models.Prop) (uint, error) {
+	oldID := src.ID
+	src.ID = 0
+	src.DramaID = c.dramaID
+	src.CreatedAt, src.UpdatedAt = time.Time{}, time.Time{}
+	if err := c.create(&src); err != nil {
+		return 0, err
+	}
+	retainStorageKey(c.tx, c.log, src.LocalPath)
+	c.propIDs[oldID] = src.ID
+	return src.ID, nil
+}
+
+func (c *dramaCloner) cloneScene(src models.Scene, episodeID *uint) (uint, error) {
+	oldID := src.ID
+	src.ID = 0
+	src.DramaID = c.dramaID
+	src.EpisodeID = episodeID
+	src.CreatedAt, src.UpdatedAt = time.Time{}, time.Time{}
+	if err := c.create(&src); err != nil {
+		return 0, err
+	}
+	retainStorageKey(c.tx, c.log, src.LocalPath)
+	c.sceneIDs[oldID] = src.ID
+	return src.ID, nil
+}
+
+func (c *dramaCloner) cloneEpisode(src models.Episode, title string, episodeNum int) (*models.Episode, error) {
+	oldID := src.ID
+	src.ID = 0
+	src.DramaID = c.dramaID
+	src.Title = title
+	src.EpisodeNum = episodeNum
+	src.CreatedAt, src.UpdatedAt = time.Time{}, time.Time{}
+	if c.skipVideos {
+		src.VideoURL = nil
+		src.HLSURL = nil
+	}
+	if err := c.create(&src); err != nil {
+		return nil, err
+	}
+	c.episodeIDs[oldID] = src.ID
+	return &src, nil
+}
+
+// characterID 返回目标剧本中对应的角色：已映射的直接返回，否则按名称复用或复制；原角色已删除时返回 0
+func (c *dramaCloner) characterID(srcID uint) (uint, error) {
+	if id, ok := c.characterIDs[srcID]; ok {
+		return id, nil
+	}
+
+	var src models.Character
+	if err := c.tx.First(&src, srcID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	var existing models.Character
+	if err := c.tx.Where("drama_id = ? AND name = ?", c.dramaID, src.Name).First(&existing).Error; err == nil {
+		c.characterIDs[srcID] = existing.ID
+		return existing.ID, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	return c.cloneCharacter(src)
+}
+
+// propID 同 characterID，按道具名称匹配
+func (c *dramaCloner) propID(srcID uint) (uint, error) {
+	if id, ok := c.propIDs[srcID]; ok {
+		return id, nil
+	}
+
+	var src models.Prop
+	if err := c.tx.First(&src, srcID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	var existing models.Prop
+	if err := c.tx.Where("drama_id = ? AND name = ?", c.dramaID, src.Name).First(&existing).Error; err == nil {
+		c.propIDs[srcID] = existing.ID
+		return existing.ID, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+	return c.cloneProp(src)
+}
+
+// sceneID 同 characterID，按地点与时间匹配剧本级场景，未匹配时复制为剧本级场景
+func (c *dramaCloner) sceneID(srcID *uint) (*uint, error) {
+	if srcID == nil {
+		return nil, nil
+	}
+	if id, ok := c.sceneIDs[*srcID]; ok {
+		return &id, nil
+	}
+
+	var src models.Scene
+	if err := c.tx.First(&src, *srcID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var existing models.Scene
+	if err := c.tx.Where("drama_id = ? AND location = ? AND time = ?", c.dramaID, src.Location, src.Time).First(&existing).Error; err == nil {
+		c.sceneIDs[*srcID] = existing.ID
+		return &existing.ID, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	id, err := c.cloneScene(src, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// cloneEpisodeContent 复制章节下的角色关联、分镜及分镜关联、帧提示词与生成记录
+func (c *dramaCloner) cloneEpisodeContent(src models.Episode, dst *models.Episode) error {
+	var characterIDs []uint
+	if err := c.tx.Table("episode_characters").Where("episode_id = ?", src.ID).Pluck("character_id", &characterIDs).Error; err != nil {
+		return err
+	}
+	for _, srcID := range characterIDs {
+		id, err := c.characterID(srcID)
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			continue
+		}
+		if err := c.tx.Table("episode_characters").Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&EpisodeCharacterLink{EpisodeID: dst.ID, CharacterID: id}).Error; err != nil {
+			return err
+		}
+	}
+
+	var screenplays []models.EpisodeScreenplay
+	if err := c.tx.Where("episode_id = ?", src.ID).Find(&screenplays).Error; err != nil {
+		return err
+	}
+	for _, sp := range screenplays {
+		sp.ID = 0
+		sp.EpisodeID = dst.ID
+		sp.CreatedAt, sp.UpdatedAt = time.Time{}, time.Time{}
+		if err := c.tx.Create(&sp).Error; err != nil {
+			return err
+		}
+	}
+
+	var summaries []models.EpisodeSummary
+	if err := c.tx.Where("episode_id = ?", src.ID).Find(&summaries).Error; err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		summary.ID = 0
+		summary.EpisodeID = dst.ID
+		summary.CreatedAt, summary.UpdatedAt = time.Time{}, time.Time{}
+		if err := c.tx.Create(&summary).Error; err != nil {
+			return err
+		}
+	}
+
+	var storyboards []models.Storyboard
+	if err := c.tx.Where("episode_id = ?", src.ID).Order("storyboard_number ASC").Find(&storyboards).Error; err != nil {
+		return err
+	}
+	if len(storyboards) == 0 {
+		return nil
+	}
+
+	srcStoryboardIDs := make([]uint, 0, len(storyboards))
+	for _, sb := range storyboards {
+		oldID := sb.ID
+		sceneID, err := c.sceneID(sb.SceneID)
+		if err != nil {
+			return err
+		}
+		sb.ID = 0
+		sb.EpisodeID = dst.ID
+		sb.SceneID = sceneID
+		sb.CreatedAt, sb.UpdatedAt = time.Time{}, time.Time{}
+		if c.skipVideos {
+			sb.VideoURL = nil
+		}
+		if err := c.create(&sb); err != nil {
+			return err
+		}
+		c.storyboardIDs[oldID] = sb.ID
+		srcStoryboardIDs = append(srcStoryboardIDs, oldID)
+	}
+
+	var characterLinks []StoryboardCharacterLink
+	if err := c.tx.Table("storyboard_characters").Where("storyboard_id IN ?", srcStoryboardIDs).Find(&characterLinks).Error; err != nil {
+		return err
+	}
+	for _, link := range characterLinks {
+		id, err := c.characterID(link.CharacterID)
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			continue
+		}
+		if err := c.tx.Table("storyboard_characters").Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&StoryboardCharacterLink{StoryboardID: c.storyboardIDs[link.StoryboardID], CharacterID: id}).Error; err != nil {
+			return err
+		}
+	}
+
+	var propLinks []StoryboardPropLink
+	if err := c.tx.Table("storyboard_props").Where("storyboard_id IN ?", srcStoryboardIDs).Find(&propLinks).Error; err != nil {
+		return err
+	}
+	for _, link := range propLinks {
+		id, err := c.propID(link.PropID)
+		if err != nil {
+			return err
+		}
+		if id == 0 {
+			continue
+		}
+		if err := c.tx.Table("storyboard_props").Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&StoryboardPropLink{StoryboardID: c.storyboardIDs[link.StoryboardID], PropID: id}).Error; err != nil {
+			return err
+		}
+	}
+
+	var framePrompts []models.FramePrompt
+	if err := c.tx.Where("storyboard_id IN ?", srcStoryboardIDs).Order("id ASC").Find(&framePrompts).Error; err != nil {
+		return err
+	}
+	for _, fp := range framePrompts {
+		fp.ID = 0
+		fp.StoryboardID = c.storyboardIDs[fp.StoryboardID]
+		fp.CreatedAt, fp.UpdatedAt = time.Time{}, time.Time{}
+		if err := c.create(&fp); err != nil {
+			return err
+		}
+	}
+
+	var imageGens []models.ImageGeneration
+	if err := c.tx.Where("storyboard_id IN ?", srcStoryboardIDs).Order("id ASC").Find(&imageGens).Error; err != nil {
+		return err
+	}
+	if err := c.cloneImageGenerations(imageGens); err != nil {
+		return err
+	}
+
+	if c.skipVideos {
+		return nil
+	}
+	var videoGens []models.VideoGeneration
+	if err := c.tx.Where("storyboard_id IN ?", srcStoryboardIDs).Order("id ASC").Find(&videoGens).Error; err != nil {
+		return err
+	}
+	for _, gen := range videoGens {
+		gen.ID = 0
+		gen.DramaID = c.dramaID
+		gen.StoryboardID = remapID(c.storyboardIDs, gen.StoryboardID)
+		gen.ImageGenID = remapID(c.imageGenIDs, gen.ImageGenID)
+		gen.CreatedAt, gen.UpdatedAt = time.Time{}, time.Time{}
+		if err := c.create(&gen); err != nil {
+			return err
+		}
+		retainStorageKey(c.tx, c.log, gen.LocalPath)
+	}
+	return nil
+}
+
+func (c *dramaCloner) cloneImageGenerations(gens []models.ImageGeneration) error {
+	for _, gen := range gens {
+		oldID := gen.ID
+		gen.ID = 0
+		gen.DramaID = c.dramaID
+		gen.StoryboardID = remapID(c.storyboardIDs, gen.StoryboardID)
+		gen.SceneID = remapID(c.sceneIDs, gen.SceneID)
+		gen.CharacterID = remapID(c.characterIDs, gen.CharacterID)
+		gen.PropID = remapID(c.propIDs, gen.PropID)
+		gen.CreatedAt, gen.UpdatedAt = time.Time{}, time.Time{}
+		if err := c.create(&gen); err != nil {
+			return err
+		}
+		retainStorageKey(c.tx, c.log, gen.LocalPath)
+		c.imageGenIDs[oldID] = gen.ID
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+func TestCloneDramaCopiesCharacters(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		drama, _ := createTestEpisode(t, db)
+		mustCreate(t, db, &models.Character{DramaID: drama.ID, Name: "Hero"})
+
+		clone, err := NewDramaCloneService(db, logger.NewLogger(false)).CloneDrama(drama.ID, &CloneDramaRequest{})
+		if err != nil {
+			t.Fatalf("clone drama: %v", err)
+		}
+		var clonedCharacters int64
+		db.Model(&models.Character{}).Where("drama_id = ?", clone.ID).Count(&clonedCharacters)
+		if clonedCharacters != 1 {
+			t.Fatalf("expected 1 cloned character, got %d", clonedCharacters)
+		}
+	})
+}
+
+func TestCloneDramaKeepsDisabledBranding(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		drama, _ := createTestEpisode(t, db)
+		branding := &models.DramaBranding{DramaID: drama.ID, Enabled: false, WatermarkPosition: "top-right", WatermarkMargin: 0, CoverTime: 0}
+		mustCreate(t, db, branding)
+
+		clone, err := NewDramaCloneService(db, logger.NewLogger(false)).CloneDrama(drama.ID, &CloneDramaRequest{})
+		if err != nil {
+			t.Fatalf("clone drama: %v", err)
+		}
+		var cloned models.DramaBranding
+		if err := db.Where("drama_id = ?", clone.ID).First(&cloned).Error; err != nil {
+			t.Fatalf("load cloned branding: %v", err)
+		}
+		if cloned.Enabled || cloned.WatermarkMargin != 0 || cloned.CoverTime != 0 || cloned.ID == branding.ID {
+			t.Fatalf("cloned branding changed: %+v", cloned)
+		}
+	})
+}
+
+func TestCloneDramaRetainsSharedFilesAndResetsTimestamps(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		drama, _ := createTestEpisode(t, db)
+		key := "images/ab/abcdef.png"
+		blob := &models.StorageBlob{Hash: "abcdef", Key: key, Size: 1, RefCount: 2}
+		mustCreate(t, db, blob)
+		created := time.Now().Add(-72 * time.Hour).Truncate(time.Second)
+		character := &models.Character{DramaID: drama.ID, Name: "Hero", LocalPath: &key, CreatedAt: created, UpdatedAt: created}
+		mustCreate(t, db, character)
+		imageGen := &models.ImageGeneration{DramaID: drama.ID, CharacterID: &character.ID, Provider: "test", Prompt: "hero",
+			Status: models.ImageStatusCompleted, LocalPath: &key, CreatedAt: created, UpdatedAt: created}
+		mustCreate(t, db, imageGen)
+
+		clone, err := NewDramaCloneService(db, logger.NewLogger(false)).CloneDrama(drama.ID, &CloneDramaRequest{})
+		if err != nil {
+			t.Fatalf("clone drama: %v", err)
+		}
+
+		// 复制的角色与生成记录共用同一去重文件，各计一次引用
+		var saved models.StorageBlob
+		db.First(&saved, blob.ID)
+		if saved.RefCount != 4 {
+			t.Fatalf("expected 4 refs after clone, got %d", saved.RefCount)
+		}
+
+		var cloned models.Character
+		if err := db.Where("drama_id = ?", clone.ID).First(&cloned).Error; err != nil {
+			t.Fatalf("load cloned character: %v", err)
+		}
+		var clonedGen models.ImageGeneration
+		if err := db.Where("drama_id = ?", clone.ID).First(&clonedGen).Error; err != nil {
+			t.Fatalf("load cloned image generation: %v", err)
+		}
+		for name, ts := range map[string]time.Time{
+			"character created": cloned.CreatedAt, "character updated": cloned.UpdatedAt,
+			"generation created": clonedGen.CreatedAt, "generation updated": clonedGen.UpdatedAt,
+		} {
+			if !ts.After(created) {
+				t.Fatalf("%s time copied from source: %v", name, ts)
+			}
+		}
+	})
+}
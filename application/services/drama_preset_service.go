@@ -0,0 +1,280 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	models "github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type DramaPresetService struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewDramaPresetService(db *gorm.DB, log *logger.Logger) *DramaPresetService {
+	return &DramaPresetService{
+		db:  db,
+		log: log,
+	}
+}
+
+type UpdateDramaPresetRequest struct {
+	TextModel      *string `json:"text_model"`
+	ImageModel     *string `json:"image_model"`
+	VideoModel     *string `json:"video_model"`
+	TTSProvider    *string `json:"tts_provider"`
+	TTSVoice       *string `json:"tts_voice"`
+	AspectRatio    *string `json:"aspect_ratio"`
+	Resolution     *string `json:"resolution"`
+	ShotDuration   *int    `json:"shot_duration"`
+	NegativePrompt *string `json:"negative_prompt"`
+	SeedPolicy     *string `json:"seed_policy"`
+	Seed           *int64  `json:"seed"`
+	ReferenceMode  *string `json:"reference_mode"`
+}
+
+// presetAspectRatios 支持的画面比例（宽:高）
+var presetAspectRatios = map[string][2]int{
+	"16:9": {16, 9}, "9:16": {9, 16}, "1:1": {1, 1}, "4:3": {4, 3}, "3:4": {3, 4}, "21:9": {21, 9},
+}
+
+// presetResolutions 分辨率对应的短边像素
+var presetResolutions = map[string]int{
+	"720p": 720, "1080p": 1080, "1440p": 1440, "2160p": 2160,
+}
+
+var (
+	seedPolicies   = map[string]bool{"": true, models.SeedPolicyRandom: true, models.SeedPolicyFixed: true, models.SeedPolicyPerShot: true}
+	referenceModes = map[string]bool{"": true, "single": true, "first_last": true, "multiple": true, "none": true}
+)
+
+// GetPreset 获取剧本生成预设，未设置时返回 nil
+func (s *DramaPresetService) GetPreset(dramaID uint) (*models.DramaPreset, error) {
+	var preset models.DramaPreset
+	if err := s.db.Where("drama_id = ?", dramaID).First(&preset).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &preset, nil
+}
+
+// UpdatePreset 创建或更新剧本生成预设，只修改请求中出现的字段，空字符串表示清除
+func (s *DramaPresetService) UpdatePreset(dramaID uint, req *UpdateDramaPresetRequest) (*models.DramaPreset, error) {
+	var drama models.Drama
+	if err := s.db.Where("id = ?", dramaID).First(&drama).Error; err != nil {
+		return nil, fmt.Errorf("drama not found")
+	}
+
+	if req.AspectRatio != nil && *req.AspectRatio != "" {
+		if _, ok := presetAspectRatios[*req.AspectRatio]; !ok {
+			return nil, fmt.Errorf("invalid aspect ratio: %s", *req.AspectRatio)
+		}
+	}
+	if req.Resolution != nil && *req.Resolution != "" {
+		if _, ok := presetResolutions[strings.ToLower(*req.Resolution)]; !ok {
+			return nil, fmt.Errorf("invalid resolution: %s", *req.Resolution)
+		}
+	}
+	if req.ShotDuration != nil && (*req.ShotDuration < 0 || *req.ShotDuration > 60) {
+		return nil, fmt.Errorf("shot duration must be between 0 and 60 seconds")
+	}
+	if req.SeedPolicy != nil && !seedPolicies[*req.SeedPolicy] {
+		return nil, fmt.Errorf("invalid seed policy: %s", *req.SeedPolicy)
+	}
+	if req.ReferenceMode != nil && !referenceModes[*req.ReferenceMode] {
+		return nil, fmt.Errorf("invalid reference mode: %s", *req.ReferenceMode)
+	}
+
+	preset, err := s.GetPreset(dramaID)
+	if err != nil {
+		return nil, err
+	}
+	if preset == nil {
+		preset = &models.DramaPreset{DramaID: dramaID}
+	}
+
+	setString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = strings.TrimSpace(*src)
+		}
+	}
+	setString(&preset.TextModel, req.TextModel)
+	setString(&preset.ImageModel, req.ImageModel)
+	setString(&preset.VideoModel, req.VideoModel)
+	setString(&preset.TTSProvider, req.TTSProvider)
+	setString(&preset.TTSVoice, req.TTSVoice)
+	setString(&preset.AspectRatio, req.AspectRatio)
+	setString(&preset.Resolution, req.Resolution)
+	preset.Resolution = strings.ToLower(preset.Resolution)
+	setString(&preset.NegativePrompt, req.NegativePrompt)
+	setString(&preset.SeedPolicy, req.SeedPolicy)
+	setString(&preset.ReferenceMode, req.ReferenceMode)
+	if req.ShotDuration != nil {
+		preset.ShotDuration = *req.ShotDuration
+	}
+	if req.Seed != nil {
+		preset.Seed = req.Seed
+	}
+	// 固定种子策略未指定种子时随机选一个并保存，之后的生成保持一致
+	if (preset.SeedPolicy == models.SeedPolicyFixed || preset.SeedPolicy == models.SeedPolicyPerShot) && preset.Seed == nil {
+		seed := rand.Int63n(1 << 31)
+		preset.Seed = &seed
+	}
+
+	if err := s.db.Save(preset).Error; err != nil {
+		return nil, err
+	}
+
+	s.log.Infow("Drama preset updated", "drama_id", dramaID)
+	return preset, nil
+}
+
+// loadDramaPreset 读取剧本生成预设，未设置或读取失败时返回空预设（全部沿用全局默认）
+func loadDramaPreset(db *gorm.DB, dramaID uint) *models.DramaPreset {
+	var preset models.DramaPreset
+	if err := db.Where("drama_id = ?", dramaID).First(&preset).Error; err != nil {
+		return &models.DramaPreset{DramaID: dramaID}
+	}
+	return &preset
+}
+
+// loadDramaPresetByID 同 loadDramaPreset，剧本 ID 为字符串
+func loadDramaPresetByID(db *gorm.DB, dramaID string) *models.DramaPreset {
+	id, err := strconv.ParseUint(dramaID, 10, 32)
+	if err != nil {
+		return &models.DramaPreset{}
+	}
+	return loadDramaPreset(db, uint(id))
+}
+
+// loadEpisodePreset 按章节读取所属剧本的生成预设
+func loadEpisodePreset(db *gorm.DB, episodeID interface{}) *models.DramaPreset {
+	var episode models.Episode
+	if err := db.Select("id", "drama_id").Where("id = ?", episodeID).First(&episode).Error; err != nil {
+		return &models.DramaPreset{}
+	}
+	return loadDramaPreset(db, episode.DramaID)
+}
+
+// presetImageSize 按预设的画面比例和分辨率计算图片尺寸（宽x高），未设置画面比例时返回 fallback
+// 只设置画面比例时短边取 1440，满足部分模型的最低像素要求
+func presetImageSize(preset *models.DramaPreset, fallback string) string {
+	ratio, ok := presetAspectRatios[preset.AspectRatio]
+	if !ok {
+		return fallback
+	}
+	short, ok := presetResolutions[preset.Resolution]
+	if !ok {
+		short = 1440
+	}
+	if ratio[0] >= ratio[1] {
+		return fmt.Sprintf("%dx%d", short*ratio[0]/ratio[1], short)
+	}
+	return fmt.Sprintf("%dx%d", short, short*ratio[1]/ratio[0])
+}
+
+// presetSeed 按种子策略返回种子：fixed 所有生成相同，per_shot 与资源 ID（镜头、角色等）相加，random 返回 nil
+func presetSeed(preset *models.DramaPreset, resourceID *uint) *int64 {
+	if preset.Seed == nil {
+		return nil
+	}
+	switch preset.SeedPolicy {
+	case models.SeedPolicyFixed:
+		seed := *preset.Seed
+		return &seed
+	case models.SeedPolicyPerShot:
+		seed := *preset.Seed
+		if resourceID != nil {
+			seed += int64(*resourceID)
+		}
+		return &seed
+	}
+	return nil
+}
+
+// applyImagePreset 用剧本预设补全图片生成请求中未指定的模型、尺寸、反向提示词和种子
+func applyImagePreset(req *GenerateImageRequest, preset *models.DramaPreset) {
+	if req.Model == "" {
+		req.Model = preset.ImageModel
+	}
+	if req.Size == "" && req.Width == nil && req.Height == nil {
+		req.Size = presetImageSize(preset, "")
+	}
+	if req.NegativePrompt == nil && preset.NegativePrompt != "" {
+		negativePrompt := preset.NegativePrompt
+		req.NegativePrompt = &negativePrompt
+	}
+	if req.Seed == nil {
+		resourceID := req.StoryboardID
+		for _, id := range []*uint{req.CharacterID, req.SceneID, req.PropID} {
+			if resourceID == nil {
+				resourceID = id
+			}
+		}
+		req.Seed = presetSeed(preset, resourceID)
+	}
+}
+
+// applyVideoPreset 用剧本预设补全视频生成请求中未指定的模型、比例、分辨率、时长、种子和参考图模式
+func applyVideoPreset(req *GenerateVideoRequest, preset *models.DramaPreset) {
+	if req.Model == "" {
+		req.Model = preset.VideoModel
+	}
+	if req.AspectRatio == nil && preset.AspectRatio != "" {
+		aspectRatio := preset.AspectRatio
+		req.AspectRatio = &aspectRatio
+	}
+	if req.Resolution == nil && preset.Resolution != "" {
+		resolution := preset.Resolution
+		req.Resolution = &resolution
+	}
+	if (req.Duration == nil || *req.Duration <= 0) && preset.ShotDuration > 0 {
+		duration := preset.ShotDuration
+		req.Duration = &duration
+	}
+	if req.Seed == nil {
+		req.Seed = presetSeed(preset, req.StoryboardID)
+	}
+	if req.ReferenceMode == "" {
+		applyPresetReferenceMode(req, preset.ReferenceMode)
+	}
+}
+
+// applyPresetReferenceMode 按预设的参考图模式整理请求中的图片；缺少该模式需要的图片时保持自动判断
+func applyPresetReferenceMode(req *GenerateVideoRequest, mode string) {
+	image := req.ImageURL
+	if req.ImageLocalPath != nil && *req.ImageLocalPath != "" {
+		image = *req.ImageLocalPath
+	}
+
+	switch mode {
+	case "none":
+		req.ReferenceMode = mode
+	case "single":
+		if image != "" {
+			req.ReferenceMode = mode
+		}
+	case "first_last":
+		if req.FirstFrameURL == nil && req.FirstFrameLocalPath == nil && image != "" {
+			req.FirstFrameURL = &image
+		}
+		if req.FirstFrameURL != nil || req.FirstFrameLocalPath != nil {
+			req.ReferenceMode = mode
+		}
+	case "multiple":
+		if len(req.ReferenceImageURLs) == 0 && image != "" {
+			req.ReferenceImageURLs = []string{image}
+		}
+		if len(req.ReferenceImageURLs) > 0 {
+			req.ReferenceMode = mode
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/drama-generator/backend/domain/models"
@@ -218,7 +219,9 @@ func (s *DramaService) ListDramas(query *DramaListQuery) ([]models.Drama, int64,
 	}
 
 	if query.Keyword != "" {
-		db = db.Where("title LIKE ? OR description LIKE ?", "%"+query.Keyword+"%", "%"+query.Keyword+"%")
+		// PostgreSQL 的 LIKE 区分大小写，统一转小写比较
+		keyword := "%" + strings.ToLower(query.Keyword) + "%"
+		db = db.Where("LOWER(title) LIKE ? OR LOWER(description) LIKE ?", keyword, keyword)
 	}
 
 	if err := db.Count(&total).Error; err != nil {
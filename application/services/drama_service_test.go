@@ -0,0 +1,26 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+func TestDramaServiceSearchAndStats(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		dramaService := NewDramaService(db, testConfig(t), logger.NewLogger(false))
+		if _, err := dramaService.CreateDrama(&CreateDramaRequest{Title: "Midnight Train", Genre: "mystery"}); err != nil {
+			t.Fatalf("create drama: %v", err)
+		}
+
+		// 关键字搜索不区分大小写
+		dramas, total, err := dramaService.ListDramas(&DramaListQuery{Page: 1, PageSize: 10, Keyword: "midnight"})
+		if err != nil || total != 1 || len(dramas) != 1 {
+			t.Fatalf("list dramas: total=%d err=%v", total, err)
+		}
+		if _, err := dramaService.GetDramaStats(); err != nil {
+			t.Fatalf("drama stats: %v", err)
+		}
+	})
+}
@@ -77,6 +77,9 @@ func (s *FramePromptService) GenerateFramePrompt(req GenerateFramePromptRequest,
 	if err := s.db.Preload("Characters").First(&storyboard, req.StoryboardID).Error; err != nil {
 		return "", fmt.Errorf("storyboard not found: %w", err)
 	}
+	if model == "" {
+		model = loadEpisodePreset(s.db, storyboard.EpisodeID).TextModel
+	}
 
 	// 创建任务
 	task, err := s.taskService.CreateTask("frame_prompt_generation", req.StoryboardID)
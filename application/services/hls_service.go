@@ -0,0 +1,248 @@
+package services
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	models "github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/external/ffmpeg"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type HLSService struct {
+	db          *gorm.DB
+	ffmpeg      *ffmpeg.FFmpeg
+	taskService *TaskService
+	fileStorage storage.Storage
+	storagePath string
+	baseURL     string
+	log         *logger.Logger
+}
+
+func NewHLSService(db *gorm.DB, fileStorage storage.Storage, storagePath, baseURL string, log *logger.Logger) *HLSService {
+	return &HLSService{
+		db:          db,
+		ffmpeg:      ffmpeg.NewFFmpeg(log, mediaCache(fileStorage)),
+		taskService: NewTaskService(db, log),
+		fileStorage: fileStorage,
+		storagePath: storagePath,
+		baseURL:     baseURL,
+		log:         log,
+	}
+}
+
+// HLSSettings HLS 打包选项
+type HLSSettings struct {
+	Enabled         bool     `json:"enabled"`
+	Renditions      []string `json:"renditions"`       // 档位名称，如 ["720p","480p"]，为空时使用默认码率阶梯
+	SegmentDuration int      `json:"segment_duration"` // 分片时长（秒），默认 6
+}
+
+// PlaylistEpisode 播放列表中的剧集
+type PlaylistEpisode struct {
+	EpisodeID     uint    `json:"episode_id"`
+	EpisodeNumber int     `json:"episode_number"`
+	Title         string  `json:"title"`
+	Duration      int     `json:"duration"`
+	Thumbnail     *string `json:"thumbnail"`
+	VideoURL      string  `json:"video_url"`
+	HLSURL        *string `json:"hls_url"`
+}
+
+// DramaPlaylist 剧本播放列表
+type DramaPlaylist struct {
+	DramaID   uint              `json:"drama_id"`
+	Title     string            `json:"title"`
+	Thumbnail *string           `json:"thumbnail"`
+	Episodes  []PlaylistEpisode `json:"episodes"`
+}
+
+// renditions 按名称筛选码率档位
+func (h *HLSSettings) renditions() ([]ffmpeg.HLSRendition, error) {
+	if h == nil || len(h.Renditions) == 0 {
+		return ffmpeg.DefaultHLSRenditions, nil
+	}
+	var result []ffmpeg.HLSRendition
+	for _, name := range h.Renditions {
+		found := false
+		for _, r := range ffmpeg.DefaultHLSRenditions {
+			if strings.EqualFold(r.Name, strings.TrimSpace(name)) {
+				result = append(result, r)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unsupported rendition: %s", name)
+		}
+	}
+	return result, nil
+}
+
+// PackageEpisode 创建 HLS 打包任务，返回任务ID
+func (s *HLSService) PackageEpisode(episodeID uint, settings *HLSSettings) (string, error) {
+	var episode models.Episode
+	if err := s.db.Where("id = ?", episodeID).First(&episode).Error; err != nil {
+		return "", fmt.Errorf("episode not found")
+	}
+	if episode.VideoURL == nil || *episode.VideoURL == "" {
+		return "", fmt.Errorf("episode has no finalized video")
+	}
+
+	renditions, err := settings.renditions()
+	if err != nil {
+		return "", err
+	}
+	segmentDuration := 0
+	if settings != nil {
+		segmentDuration = settings.SegmentDuration
+	}
+
+	task, err := s.taskService.CreateTask("hls_packaging", fmt.Sprintf("%d", episode.ID))
+	if err != nil {
+		return "", fmt.Errorf("创建任务失败: %w", err)
+	}
+
+	go s.processPackaging(task.ID, episode, renditions, segmentDuration)
+
+	return task.ID, nil
+}
+
+func (s *HLSService) processPackaging(taskID string, episode models.Episode, renditions []ffmpeg.HLSRendition, segmentDuration int) {
+	s.taskService.UpdateTaskStatus(taskID, "processing", 10, "正在转码 HLS...")
+
+	relDir := filepath.Join("hls", fmt.Sprintf("episode_%d", episode.ID), fmt.Sprintf("%d", time.Now().UnixNano()))
+	_, packaged, err := s.ffmpeg.PackageHLS(&ffmpeg.HLSOptions{
+		InputPath:       storageMediaPath(s.fileStorage, s.storagePath, s.baseURL, *episode.VideoURL),
+		OutputDir:       filepath.Join(s.storagePath, relDir),
+		Renditions:      renditions,
+		SegmentDuration: segmentDuration,
+	})
+	if err != nil {
+		os.RemoveAll(filepath.Join(s.storagePath, relDir))
+		s.log.Errorw("HLS packaging failed", "error", err, "episode_id", episode.ID)
+		s.taskService.UpdateTaskError(taskID, err)
+		return
+	}
+
+	hlsURL := s.fileStorage.GetURL(filepath.ToSlash(filepath.Join(relDir, "master.m3u8")))
+	if err := s.publishPackage(relDir); err != nil {
+		s.removePackage(hlsURL)
+		s.log.Errorw("Failed to store HLS package", "error", err, "episode_id", episode.ID)
+		s.taskService.UpdateTaskError(taskID, err)
+		return
+	}
+	if err := s.db.Model(&models.Episode{}).Where("id = ?", episode.ID).Update("hls_url", hlsURL).Error; err != nil {
+		s.taskService.UpdateTaskError(taskID, fmt.Errorf("failed to update episode: %w", err))
+		return
+	}
+
+	// 清理旧的打包结果
+	if episode.HLSURL != nil {
+		s.removePackage(*episode.HLSURL)
+	}
+
+	names := make([]string, len(packaged))
+	for i, r := range packaged {
+		names[i] = r.Name
+	}
+	s.taskService.UpdateTaskResult(taskID, map[string]interface{}{
+		"episode_id": episode.ID,
+		"hls_url":    hlsURL,
+		"renditions": names,
+	})
+	s.log.Infow("Episode packaged as HLS", "episode_id", episode.ID, "hls_url", hlsURL)
+}
+
+// publishPackage 将本地生成的播放列表与分片逐个写入存储后端
+func (s *HLSService) publishPackage(relDir string) error {
+	return filepath.WalkDir(filepath.Join(s.storagePath, relDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(s.storagePath, path)
+		if err != nil {
+			return err
+		}
+		_, err = publishMedia(s.fileStorage, s.storagePath, rel)
+		return err
+	})
+}
+
+// removePackage 删除 HLS 打包目录（存储后端中的文件与本地副本）
+func (s *HLSService) removePackage(hlsURL string) {
+	masterPath := resolveMediaPath(s.storagePath, s.baseURL, hlsURL)
+	if key, ok := s.fileStorage.KeyFromURL(hlsURL); ok {
+		masterPath = filepath.Join(s.storagePath, filepath.FromSlash(key))
+	}
+	dir := filepath.Dir(masterPath)
+	hlsRoot := filepath.Join(s.storagePath, "hls") + string(filepath.Separator)
+	if !strings.HasPrefix(dir, hlsRoot) {
+		return
+	}
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if rel, err := filepath.Rel(s.storagePath, path); err == nil {
+			if err := s.fileStorage.Delete(filepath.ToSlash(rel)); err != nil {
+				s.log.Warnw("Failed to delete HLS file", "error", err, "key", rel)
+			}
+		}
+		return nil
+	})
+	if err := os.RemoveAll(dir); err != nil {
+		s.log.Warnw("Failed to remove old HLS package", "error", err, "dir", dir)
+	}
+}
+
+// GetDramaPlaylist 列出剧本所有已定稿剧集
+func (s *HLSService) GetDramaPlaylist(dramaID uint) (*DramaPlaylist, error) {
+	var drama models.Drama
+	if err := s.db.Where("id = ?", dramaID).First(&drama).Error; err != nil {
+		return nil, fmt.Errorf("drama not found")
+	}
+
+	var episodes []models.Episode
+	if err := s.db.Where("drama_id = ? AND status = ? AND video_url IS NOT NULL AND video_url <> ''", dramaID, "completed").
+		Order("episode_number ASC").
+		Find(&episodes).Error; err != nil {
+		return nil, err
+	}
+
+	playlist := &DramaPlaylist{
+		DramaID:   drama.ID,
+		Title:     drama.Title,
+		Thumbnail: drama.Thumbnail,
+		Episodes:  make([]PlaylistEpisode, 0, len(episodes)),
+	}
+	for _, ep := range episodes {
+		playlist.Episodes = append(playlist.Episodes, PlaylistEpisode{
+			EpisodeID:     ep.ID,
+			EpisodeNumber: ep.EpisodeNum,
+			Title:         ep.Title,
+			Duration:      ep.Duration,
+			Thumbnail:     ep.Thumbnail,
+			VideoURL:      s.publicURL(*ep.VideoURL),
+			HLSURL:        ep.HLSURL,
+		})
+	}
+	return playlist, nil
+}
+
+// publicURL 将存储相对路径转换为访问URL
+func (s *HLSService) publicURL(ref string) string {
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "/static/") {
+		return ref
+	}
+	if rel, err := filepath.Rel(s.storagePath, ref); err == nil && filepath.IsAbs(ref) && !strings.HasPrefix(rel, "..") {
+		ref = rel
+	}
+	return s.fileStorage.GetURL(strings.TrimPrefix(filepath.ToSlash(ref), "/"))
+}
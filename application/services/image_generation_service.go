@@ -24,7 +24,7 @@ type ImageGenerationService struct {
 	db              *gorm.DB
 	aiService       *AIService
 	transferService *ResourceTransferService
-	localStorage    *storage.LocalStorage
+	fileStorage     storage.Storage
 	log             *logger.Logger
 	config          *config.Config
 	promptI18n      *PromptI18n
@@ -49,12 +49,12 @@ func truncateImageURL(url string) string {
 	return url
 }
 
-func NewImageGenerationService(db *gorm.DB, cfg *config.Config, transferService *ResourceTransferService, localStorage *storage.LocalStorage, log *logger.Logger) *ImageGenerationService {
+func NewImageGenerationService(db *gorm.DB, cfg *config.Config, transferService *ResourceTransferService, fileStorage storage.Storage, log *logger.Logger) *ImageGenerationService {
 	return &ImageGenerationService{
 		db:              db,
 		aiService:       NewAIService(db, log),
 		transferService: transferService,
-		localStorage:    localStorage,
+		fileStorage:     fileStorage,
 		config:          cfg,
 		promptI18n:      NewPromptI18n(cfg),
 		log:             log,
@@ -96,6 +96,8 @@ func (s *ImageGenerationService) GenerateImage(request *GenerateImageRequest) (*
 	if err := s.db.Where("id = ? ", request.DramaID).First(&drama).Error; err != nil {
 		return nil, fmt.Errorf("drama not found")
 	}
+	// 请求未指定的参数沿用剧本生成预设
+	applyImagePreset(request, loadDramaPreset(s.db, drama.ID))
 	// 注意：SceneID可能指向Scene或Storyboard表，调用方已经做过权限验证，这里不再重复验证
 
 	provider := request.Provider
@@ -103,6 +105,18 @@ func (s *ImageGenerationService) GenerateImage(request *GenerateImageRequest) (*
 		provider = "openai"
 	}
 
+	// 设置默认图片类型
+	imageType := request.ImageType
+	if imageType == "" {
+		imageType = string(models.ImageTypeStoryboard)
+	}
+
+	// 分镜图片自动附加关联角色的参考图（设定图或正面、特写），减少角色在镜头间走样
+	if request.StoryboardID != nil && imageType == string(models.ImageTypeStoryboard) {
+		request.ReferenceImages = mergeReferenceImages(request.ReferenceImages,
+			storyboardCharacterReferenceImages(s.db, *request.StoryboardID), maxReferenceImages)
+	}
+
 	// 序列化参考图片
 	var referenceImagesJSON []byte
 	if len(request.ReferenceImages) > 0 {
@@ -115,12 +129,6 @@ func (s *ImageGenerationService) GenerateImage(request *GenerateImageRequest) (*
 		return nil, fmt.Errorf("invalid drama ID")
 	}
 
-	// 设置默认图片类型
-	imageType := request.ImageType
-	if imageType == "" {
-		imageType = string(models.ImageTypeStoryboard)
-	}
-
 	imageGen := &models.ImageGeneration{
 		StoryboardID:    request.StoryboardID,
 		DramaID:         uint(dramaIDParsed),
@@ -168,6 +176,9 @@ func (s *ImageGenerationService) ProcessImageGeneration(imageGenID uint) {
 	if err := s.db.First(&drama, imageGen.DramaID).Error; err != nil {
 		s.log.Warnw("Failed to load drama for style", "error", err, "drama_id", imageGen.DramaID)
 	}
+	if preset := loadDramaPreset(s.db, imageGen.DramaID); preset.AspectRatio != "" {
+		imageRatio = preset.AspectRatio
+	}
 
 	s.db.Model(&imageGen).Update("status", models.ImageStatusProcessing)
 
@@ -340,9 +351,9 @@ func (s *ImageGenerationService) completeImageGeneration(imageGenID uint, result
 
 	// 下载图片到本地存储并保存相对路径到数据库
 	var localPath *string
-	if s.localStorage != nil && result.ImageURL != "" &&
+	if s.fileStorage != nil && result.ImageURL != "" &&
 		(strings.HasPrefix(result.ImageURL, "http://") || strings.HasPrefix(result.ImageURL, "https://")) {
-		downloadResult, err := s.localStorage.DownloadFromURLWithPath(result.ImageURL, "images")
+		downloadResult, err := s.fileStorage.DownloadFromURLWithPath(result.ImageURL, "images")
 		if err != nil {
 			errStr := err.Error()
 			if len(errStr) > 200 {
@@ -426,8 +437,22 @@ func (s *ImageGenerationService) completeImageGeneration(imageGenID uint, result
 		}
 	}
 
-	// 如果关联了角色，同步更新角色的image_url和local_path
-	if imageGen.CharacterID != nil {
+	// 角色参考图写入角色的reference_images，不覆盖主形象
+	if imageGen.CharacterID != nil && imageGen.ImageType == string(models.ImageTypeCharacterReference) {
+		view := models.CharacterViewSheet
+		if imageGen.FrameType != nil && *imageGen.FrameType != "" {
+			view = *imageGen.FrameType
+		}
+		if err := saveCharacterReferenceImage(s.db, *imageGen.CharacterID, view, result.ImageURL, localPath); err != nil {
+			s.log.Errorw("Failed to save character reference image", "error", err, "character_id", *imageGen.CharacterID, "view", view)
+		} else {
+			s.log.Infow("Character reference image saved",
+				"character_id", *imageGen.CharacterID,
+				"view", view,
+				"image_url", truncateImageURL(result.ImageURL))
+		}
+	} else if imageGen.CharacterID != nil {
+		// 如果关联了角色，同步更新角色的image_url和local_path
 		characterUpdates := map[string]interface{}{
 			"image_url": result.ImageURL,
 		}
@@ -631,6 +656,10 @@ func (s *ImageGenerationService) ListImageGenerations(dramaID *uint, sceneID *ui
 }
 
 func (s *ImageGenerationService) DeleteImageGeneration(imageGenID uint) error {
+	var imageGen models.ImageGeneration
+	if err := s.db.Select("id", "local_path").Where("id = ?", imageGenID).First(&imageGen).Error; err != nil {
+		return fmt.Errorf("image generation not found")
+	}
 	result := s.db.Where("id = ? ", imageGenID).Delete(&models.ImageGeneration{})
 	if result.Error != nil {
 		return result.Error
@@ -638,6 +667,7 @@ func (s *ImageGenerationService) DeleteImageGeneration(imageGenID uint) error {
 	if result.RowsAffected == 0 {
 		return fmt.Errorf("image generation not found")
 	}
+	releaseStorageKey(s.db, s.log, imageGen.LocalPath)
 	return nil
 }
 
@@ -824,6 +854,10 @@ func (s *ImageGenerationService) ExtractBackgroundsForEpisode(episodeID string,
 		return "", fmt.Errorf("episode has no script content")
 	}
 
+	if model == "" {
+		model = loadDramaPreset(s.db, episode.DramaID).TextModel
+	}
+
 	// 创建任务
 	task, err := s.taskService.CreateTask("background_extraction", episodeID)
 	if err != nil {
@@ -1315,8 +1349,8 @@ func (s *ImageGenerationService) loadImageAsBase64(localPath string) (string, er
 		fullPath = localPath
 	} else {
 		// 如果是相对路径，拼接存储根目录
-		if s.localStorage != nil {
-			fullPath = s.localStorage.GetAbsolutePath(localPath)
+		if s.fileStorage != nil {
+			fullPath = s.fileStorage.GetAbsolutePath(localPath)
 		} else {
 			fullPath = filepath.Join(s.config.Storage.LocalPath, localPath)
 		}
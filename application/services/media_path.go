@@ -0,0 +1,57 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/drama-generator/backend/infrastructure/external/ffmpeg"
+	"github.com/drama-generator/backend/infrastructure/storage"
+)
+
+// resolveMediaPath 将媒体引用转换为 FFmpeg 可读取的路径
+// 支持：绝对路径、存储相对路径、本地存储访问URL（baseURL 或 /static 前缀）、远程URL
+func resolveMediaPath(storagePath, baseURL, ref string) string {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return ""
+	}
+	if baseURL != "" && strings.HasPrefix(ref, baseURL+"/") {
+		return filepath.Join(storagePath, strings.TrimPrefix(ref, baseURL+"/"))
+	}
+	if strings.HasPrefix(ref, "/static/") {
+		return filepath.Join(storagePath, strings.TrimPrefix(ref, "/static/"))
+	}
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		return ref
+	}
+	if filepath.IsAbs(ref) || strings.HasPrefix(ref, storagePath) {
+		return ref
+	}
+	return filepath.Join(storagePath, strings.TrimPrefix(ref, "/"))
+}
+
+// storageMediaPath 与 resolveMediaPath 相同，但存储后端的访问URL（如 S3）通过 GetAbsolutePath 取得本地副本
+func storageMediaPath(fileStorage storage.Storage, storagePath, baseURL, ref string) string {
+	if key, ok := fileStorage.KeyFromURL(strings.TrimSpace(ref)); ok {
+		return fileStorage.GetAbsolutePath(key)
+	}
+	return resolveMediaPath(storagePath, baseURL, ref)
+}
+
+// publishMedia 将 ffmpeg 在本地存储目录 relPath 处生成的文件写入存储后端，返回访问URL
+func publishMedia(fileStorage storage.Storage, storagePath, relPath string) (string, error) {
+	key := filepath.ToSlash(relPath)
+	if err := fileStorage.PutFile(key, filepath.Join(storagePath, relPath), ""); err != nil {
+		return "", fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return fileStorage.GetURL(key), nil
+}
+
+// mediaCache 存储后端支持远程媒体缓存（如 DedupStorage）时返回该缓存，注入 ffmpeg 以复用已下载的文件
+func mediaCache(fileStorage storage.Storage) ffmpeg.MediaCache {
+	if cache, ok := fileStorage.(ffmpeg.MediaCache); ok {
+		return cache
+	}
+	return nil
+}
@@ -0,0 +1,857 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// PipelineService 章节一键生产流水线：从剧本提取到成片合成，每个阶段对应一个 AsyncTask
+//
+// 阶段按顺序执行，依赖的阶段完成（或跳过）后才会开始；失败后可从失败阶段继续，
+// 配置了审批的阶段完成后暂停，确认后继续下一阶段。各阶段只处理尚未生成的内容，重复执行不会覆盖已有结果
+type PipelineService struct {
+	db                 *gorm.DB
+	log                *logger.Logger
+	taskService        *TaskService
+	characterService   *CharacterLibraryService
+	propService        *PropService
+	imageService       *ImageGenerationService
+	storyboardService  *StoryboardService
+	framePromptService *FramePromptService
+	videoService       *VideoGenerationService
+	mergeService       *VideoMergeService
+	pollInterval       time.Duration
+	stageTimeout       time.Duration
+}
+
+func NewPipelineService(db *gorm.DB, cfg *config.Config, transferService *ResourceTransferService, fileStorage storage.Storage, log *logger.Logger) *PipelineService {
+	aiService := NewAIService(db, log)
+	taskService := NewTaskService(db, log)
+	imageService := NewImageGenerationService(db, cfg, transferService, fileStorage, log)
+	return &PipelineService{
+		db:                 db,
+		log:                log,
+		taskService:        taskService,
+		characterService:   NewCharacterLibraryService(db, log, cfg),
+		propService:        NewPropService(db, aiService, taskService, imageService, log, cfg),
+		imageService:       imageService,
+		storyboardService:  NewStoryboardService(db, cfg, log),
+		framePromptService: NewFramePromptService(db, cfg, log),
+		videoService:       NewVideoGenerationService(db, transferService, fileStorage, aiService, log, NewPromptI18n(cfg)),
+		mergeService:       NewVideoMergeService(db, transferService, fileStorage, cfg.Storage.LocalPath, cfg.Storage.BaseURL, log),
+		pollInterval:       5 * time.Second,
+		stageTimeout:       2 * time.Hour,
+	}
+}
+
+// 流水线阶段名称
+const (
+	PipelineStageCharacters       = "characters"
+	PipelineStageProps            = "props"
+	PipelineStageBackgrounds      = "backgrounds"
+	PipelineStageStoryboards      = "storyboards"
+	PipelineStageCharacterImages  = "character_images"
+	PipelineStageSceneImages      = "scene_images"
+	PipelineStagePropImages       = "prop_images"
+	PipelineStageFramePrompts     = "frame_prompts"
+	PipelineStageStoryboardImages = "storyboard_images"
+	PipelineStageVideos           = "videos"
+	PipelineStageFinalize         = "finalize"
+)
+
+// PipelineApproveAll 在 approval_stages 中表示每个阶段完成后都等待确认
+const PipelineApproveAll = "all"
+
+var errPipelineCancelled = errors.New("pipeline cancelled")
+
+// errPipelineStageClaimed 阶段已被另一个执行协程领取（取消后立即继续时旧协程尚未退出），当前协程直接退出
+var errPipelineStageClaimed = errors.New("pipeline stage claimed by another runner")
+
+// pipelineStageDef 阶段定义，run 返回阶段结果说明
+type pipelineStageDef struct {
+	name      string
+	dependsOn []string
+	run       func(s *PipelineService, r *pipelineRun) (string, error)
+}
+
+var pipelineStageDefs = []pipelineStageDef{
+	{PipelineStageCharacters, nil, (*PipelineService).runCharacterExtraction},
+	{PipelineStageProps, nil, (*PipelineService).runPropExtraction},
+	{PipelineStageBackgrounds, nil, (*PipelineService).runBackgroundExtraction},
+	{PipelineStageStoryboards, []string{PipelineStageCharacters, PipelineStageProps, PipelineStageBackgrounds}, (*PipelineService).runStoryboardGeneration},
+	{PipelineStageCharacterImages, []string{PipelineStageCharacters}, (*PipelineService).runCharacterImages},
+	{PipelineStageSceneImages, []string{PipelineStageBackgrounds}, (*PipelineService).runSceneImages},
+	{PipelineStagePropImages, []string{PipelineStageProps}, (*PipelineService).runPropImages},
+	{PipelineStageFramePrompts, []string{PipelineStageStoryboards}, (*PipelineService).runFramePrompts},
+	{PipelineStageStoryboardImages, []string{PipelineStageFramePrompts, PipelineStageCharacterImages, PipelineStageSceneImages, PipelineStagePropImages}, (*PipelineService).runStoryboardImages},
+	{PipelineStageVideos, []string{PipelineStageStoryboardImages}, (*PipelineService).runVideoGeneration},
+	{PipelineStageFinalize, []string{PipelineStageVideos}, (*PipelineService).runFinalize},
+}
+
+func findPipelineStageDef(name string) *pipelineStageDef {
+	for i := range pipelineStageDefs {
+		if pipelineStageDefs[i].name == name {
+			return &pipelineStageDefs[i]
+		}
+	}
+	return nil
+}
+
+// PipelineOptions 流水线配置，保存在 episode_pipelines.options
+type PipelineOptions struct {
+	Model          string   `json:"model,omitempty"`           // 文本模型（提取、分镜、帧提示词），为空使用默认配置
+	ImageModel     string   `json:"image_model,omitempty"`     // 图片模型，为空使用默认配置
+	Style          string   `json:"style,omitempty"`           // 画面风格，为空使用剧本风格
+	ApprovalStages []string `json:"approval_stages,omitempty"` // 完成后暂停等待确认的阶段，"all" 表示全部
+	SkipStages     []string `json:"skip_stages,omitempty"`     // 跳过的阶段（如角色已手动准备好）
+}
+
+// pipelineRun 单个阶段执行时的上下文
+type pipelineRun struct {
+	pipeline *models.EpisodePipeline
+	stage    *models.PipelineStage
+	taskID   string
+	options  PipelineOptions
+	style    string
+}
+
+func (r *pipelineRun) episodeID() string {
+	return strconv.FormatUint(uint64(r.pipeline.EpisodeID), 10)
+}
+
+// StartPipeline 为章节创建并启动流水线，同一章节同时只能有一个未结束的流水线
+func (s *PipelineService) StartPipeline(episodeID string, options *PipelineOptions) (*models.EpisodePipeline, error) {
+	var episode models.Episode
+	if err := s.db.Where("id = ?", episodeID).First(&episode).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("episode not found")
+		}
+		return nil, err
+	}
+	if options == nil {
+		options = &PipelineOptions{}
+	}
+	for _, name := range append(append([]string{}, options.ApprovalStages...), options.SkipStages...) {
+		if name != PipelineApproveAll && findPipelineStageDef(name) == nil {
+			return nil, fmt.Errorf("unknown stage: %s", name)
+		}
+	}
+
+	var active int64
+	s.db.Model(&models.EpisodePipeline{}).
+		Where("episode_id = ? AND status IN ?", episode.ID, []string{models.PipelineStatusRunning, models.PipelineStatusAwaitingApproval}).
+		Count(&active)
+	if active > 0 {
+		return nil, fmt.Errorf("pipeline already running")
+	}
+
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+	pipeline := &models.EpisodePipeline{
+		EpisodeID: episode.ID,
+		DramaID:   episode.DramaID,
+		Status:    models.PipelineStatusRunning,
+		Options:   datatypes.JSON(optionsJSON),
+	}
+	for i, def := range pipelineStageDefs {
+		stage := models.PipelineStage{
+			Name:      def.name,
+			Seq:       i + 1,
+			DependsOn: strings.Join(def.dependsOn, ","),
+			Status:    models.PipelineStagePending,
+		}
+		if containsString(options.SkipStages, def.name) {
+			stage.Status = models.PipelineStageSkipped
+		}
+		pipeline.Stages = append(pipeline.Stages, stage)
+	}
+	if err := s.db.Create(pipeline).Error; err != nil {
+		return nil, fmt.Errorf("failed to create pipeline: %w", err)
+	}
+
+	s.log.Infow("Pipeline started", "pipeline_id", pipeline.ID, "episode_id", episode.ID)
+	go s.run(pipeline.ID)
+	return s.GetPipeline(pipeline.ID)
+}
+
+// GetPipeline 获取流水线及各阶段状态
+func (s *PipelineService) GetPipeline(pipelineID uint) (*models.EpisodePipeline, error) {
+	var pipeline models.EpisodePipeline
+	err := s.db.Preload("Stages", func(db *gorm.DB) *gorm.DB { return db.Order("seq ASC") }).
+		First(&pipeline, pipelineID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("pipeline not found")
+		}
+		return nil, err
+	}
+	return &pipeline, nil
+}
+
+// GetLatestPipeline 获取章节最近一次的流水线
+func (s *PipelineService) GetLatestPipeline(episodeID string) (*models.EpisodePipeline, error) {
+	var pipeline models.EpisodePipeline
+	if err := s.db.Where("episode_id = ?", episodeID).Order("id DESC").First(&pipeline).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("pipeline not found")
+		}
+		return nil, err
+	}
+	return s.GetPipeline(pipeline.ID)
+}
+
+// ResumePipeline 从失败或取消的阶段继续执行，已完成的阶段不会重复执行
+func (s *PipelineService) ResumePipeline(pipelineID uint) (*models.EpisodePipeline, error) {
+	pipeline, err := s.GetPipeline(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline.Status != models.PipelineStatusFailed && pipeline.Status != models.PipelineStatusCancelled {
+		return nil, fmt.Errorf("pipeline is %s", pipeline.Status)
+	}
+	// 取消只修改状态，执行协程要到下一次轮询才退出；阶段仍在运行时继续会出现两个协程同时提交生成
+	for _, stage := range pipeline.Stages {
+		if stage.Status == models.PipelineStageRunning {
+			return nil, fmt.Errorf("pipeline stage %s is still stopping", stage.Name)
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.EpisodePipeline{}).
+			Where("id = ? AND status = ?", pipelineID, pipeline.Status).
+			Updates(map[string]interface{}{"status": models.PipelineStatusRunning, "error": nil, "completed_at": nil})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("pipeline is %s", pipeline.Status)
+		}
+		return tx.Model(&models.PipelineStage{}).
+			Where("pipeline_id = ? AND status IN ?", pipelineID, []string{models.PipelineStageFailed, models.PipelineStageCancelled}).
+			Updates(map[string]interface{}{"status": models.PipelineStagePending, "error": nil}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Infow("Pipeline resumed", "pipeline_id", pipelineID)
+	go s.run(pipelineID)
+	return s.GetPipeline(pipelineID)
+}
+
+// ApprovePipeline 确认当前暂停的阶段，继续执行后续阶段
+func (s *PipelineService) ApprovePipeline(pipelineID uint) (*models.EpisodePipeline, error) {
+	pipeline, err := s.GetPipeline(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline.Status != models.PipelineStatusAwaitingApproval || pipeline.CurrentStage == nil {
+		return nil, fmt.Errorf("pipeline is %s", pipeline.Status)
+	}
+
+	now := time.Now()
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.EpisodePipeline{}).
+			Where("id = ? AND status = ?", pipelineID, models.PipelineStatusAwaitingApproval).
+			Update("status", models.PipelineStatusRunning)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("pipeline is %s", pipeline.Status)
+		}
+		return tx.Model(&models.PipelineStage{}).
+			Where("pipeline_id = ? AND name = ?", pipelineID, *pipeline.CurrentStage).
+			Update("approved_at", &now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.log.Infow("Pipeline stage approved", "pipeline_id", pipelineID, "stage", *pipeline.CurrentStage)
+	go s.run(pipelineID)
+	return s.GetPipeline(pipelineID)
+}
+
+// CancelPipeline 取消运行中或等待确认的流水线；已提交给服务商的生成请求不会撤回
+func (s *PipelineService) CancelPipeline(pipelineID uint) (*models.EpisodePipeline, error) {
+	pipeline, err := s.GetPipeline(pipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline.Status != models.PipelineStatusRunning && pipeline.Status != models.PipelineStatusAwaitingApproval {
+		return nil, fmt.Errorf("pipeline is %s", pipeline.Status)
+	}
+
+	now := time.Now()
+	result := s.db.Model(&models.EpisodePipeline{}).
+		Where("id = ? AND status = ?", pipelineID, pipeline.Status).
+		Updates(map[string]interface{}{"status": models.PipelineStatusCancelled, "completed_at": &now})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, fmt.Errorf("pipeline is %s", pipeline.Status)
+	}
+
+	// 运行中的阶段由执行协程在下一次轮询时发现取消并退出
+	for _, stage := range pipeline.Stages {
+		if stage.Status == models.PipelineStageRunning && stage.TaskID != nil {
+			s.taskService.CancelTask(*stage.TaskID)
+		}
+	}
+
+	s.log.Infow("Pipeline cancelled", "pipeline_id", pipelineID)
+	return s.GetPipeline(pipelineID)
+}
+
+// MarkInterruptedPipelines 服务启动时将上次进程中未执行完的流水线标记为失败，之后可从中断的阶段继续
+func (s *PipelineService) MarkInterruptedPipelines() (int64, error) {
+	var ids []uint
+	if err := s.db.Model(&models.EpisodePipeline{}).Where("status = ?", models.PipelineStatusRunning).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	message := "服务重启，流水线中断"
+	now := time.Now()
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.PipelineStage{}).
+			Where("pipeline_id IN ? AND status = ?", ids, models.PipelineStageRunning).
+			Updates(map[string]interface{}{"status": models.PipelineStageFailed, "error": message, "completed_at": &now}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.EpisodePipeline{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"status": models.PipelineStatusFailed, "error": message, "completed_at": &now}).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
+// run 依次执行未完成的阶段，直到全部完成、失败、取消或需要确认
+func (s *PipelineService) run(pipelineID uint) {
+	pipeline, err := s.GetPipeline(pipelineID)
+	if err != nil {
+		s.log.Errorw("Failed to load pipeline", "error", err, "pipeline_id", pipelineID)
+		return
+	}
+
+	var options PipelineOptions
+	if len(pipeline.Options) > 0 {
+		json.Unmarshal(pipeline.Options, &options)
+	}
+	style := options.Style
+	if style == "" {
+		var drama models.Drama
+		if err := s.db.First(&drama, pipeline.DramaID).Error; err == nil {
+			style = drama.Style
+		}
+	}
+
+	finished := make(map[string]bool)
+	for i := range pipeline.Stages {
+		stage := &pipeline.Stages[i]
+		if stage.Status == models.PipelineStageCompleted || stage.Status == models.PipelineStageSkipped {
+			finished[stage.Name] = true
+			if s.needsApproval(pipeline, stage, options) {
+				s.pause(pipeline, stage)
+				return
+			}
+			continue
+		}
+		if s.pipelineCancelled(pipelineID) {
+			return
+		}
+
+		def := findPipelineStageDef(stage.Name)
+		if def == nil {
+			s.failPipeline(pipeline, stage, fmt.Errorf("unknown stage: %s", stage.Name))
+			return
+		}
+		for _, dep := range def.dependsOn {
+			if !finished[dep] {
+				s.failPipeline(pipeline, stage, fmt.Errorf("stage %s depends on unfinished stage %s", stage.Name, dep))
+				return
+			}
+		}
+
+		if err := s.runStage(pipeline, stage, def, options, style); err != nil {
+			if errors.Is(err, errPipelineStageClaimed) {
+				s.log.Infow("Pipeline stage taken over by another runner", "pipeline_id", pipelineID, "stage", stage.Name)
+				return
+			}
+			if errors.Is(err, errPipelineCancelled) {
+				s.db.Model(&models.EpisodePipeline{}).
+					Where("id = ? AND status = ?", pipelineID, models.PipelineStatusRunning).
+					Updates(map[string]interface{}{"status": models.PipelineStatusCancelled, "completed_at": time.Now()})
+				return
+			}
+			s.failPipeline(pipeline, stage, err)
+			return
+		}
+		finished[stage.Name] = true
+
+		if s.needsApproval(pipeline, stage, options) {
+			s.pause(pipeline, stage)
+			return
+		}
+	}
+
+	now := time.Now()
+	s.db.Model(&models.EpisodePipeline{}).
+		Where("id = ? AND status = ?", pipelineID, models.PipelineStatusRunning).
+		Updates(map[string]interface{}{"status": models.PipelineStatusCompleted, "current_stage": nil, "completed_at": &now})
+	s.log.Infow("Pipeline completed", "pipeline_id", pipelineID, "episode_id", pipeline.EpisodeID)
+}
+
+// runStage 为阶段创建 AsyncTask 并执行，结果同步到任务与阶段记录
+// 阶段从 pending 领取为 running，同一阶段只会被一个执行协程执行
+func (s *PipelineService) runStage(pipeline *models.EpisodePipeline, stage *models.PipelineStage, def *pipelineStageDef, options PipelineOptions, style string) error {
+	now := time.Now()
+	result := s.db.Model(&models.PipelineStage{}).
+		Where("id = ? AND status = ?", stage.ID, models.PipelineStagePending).
+		Updates(map[string]interface{}{
+			"status":       models.PipelineStageRunning,
+			"message":      nil,
+			"error":        nil,
+			"started_at":   &now,
+			"completed_at": nil,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errPipelineStageClaimed
+	}
+	stage.Status = models.PipelineStageRunning
+
+	task, err := s.taskService.CreateTask("pipeline_"+stage.Name, strconv.FormatUint(uint64(pipeline.EpisodeID), 10))
+	if err != nil {
+		completedAt := time.Now()
+		stage.Status = models.PipelineStageFailed
+		s.db.Model(stage).Updates(map[string]interface{}{"status": models.PipelineStageFailed, "error": err.Error(), "completed_at": &completedAt})
+		return err
+	}
+	stage.TaskID = &task.ID
+	s.db.Model(stage).Update("task_id", task.ID)
+	s.db.Model(pipeline).Update("current_stage", stage.Name)
+	s.taskService.UpdateTaskStatus(task.ID, "processing", 0, "阶段开始: "+stage.Name)
+	s.log.Infow("Pipeline stage started", "pipeline_id", pipeline.ID, "stage", stage.Name, "task_id", task.ID)
+
+	message, err := def.run(s, &pipelineRun{pipeline: pipeline, stage: stage, taskID: task.ID, options: options, style: style})
+	completedAt := time.Now()
+	if err != nil {
+		status := models.PipelineStageFailed
+		if errors.Is(err, errPipelineCancelled) {
+			status = models.PipelineStageCancelled
+		} else {
+			s.taskService.UpdateTaskError(task.ID, err)
+		}
+		stage.Status = status
+		s.db.Model(stage).Updates(map[string]interface{}{"status": status, "error": err.Error(), "completed_at": &completedAt})
+		s.log.Errorw("Pipeline stage failed", "error", err, "pipeline_id", pipeline.ID, "stage", stage.Name)
+		return err
+	}
+
+	s.taskService.UpdateTaskResult(task.ID, map[string]interface{}{
+		"pipeline_id": pipeline.ID,
+		"stage":       stage.Name,
+		"message":     message,
+	})
+	stage.Status = models.PipelineStageCompleted
+	s.db.Model(stage).Updates(map[string]interface{}{"status": models.PipelineStageCompleted, "message": message, "completed_at": &completedAt})
+	s.log.Infow("Pipeline stage completed", "pipeline_id", pipeline.ID, "stage", stage.Name, "message", message)
+	return nil
+}
+
+// needsApproval 已完成的阶段配置了审批且尚未确认；最后一个阶段无需确认
+func (s *PipelineService) needsApproval(pipeline *models.EpisodePipeline, stage *models.PipelineStage, options PipelineOptions) bool {
+	if stage.Status != models.PipelineStageCompleted || stage.ApprovedAt != nil {
+		return false
+	}
+	if stage.Seq == len(pipeline.Stages) {
+		return false
+	}
+	return containsString(options.ApprovalStages, PipelineApproveAll) || containsString(options.ApprovalStages, stage.Name)
+}
+
+func (s *PipelineService) pause(pipeline *models.EpisodePipeline, stage *models.PipelineStage) {
+	s.db.Model(&models.EpisodePipeline{}).
+		Where("id = ? AND status = ?", pipeline.ID, models.PipelineStatusRunning).
+		Updates(map[string]interface{}{"status": models.PipelineStatusAwaitingApproval, "current_stage": stage.Name})
+	s.log.Infow("Pipeline awaiting approval", "pipeline_id", pipeline.ID, "stage", stage.Name)
+}
+
+func (s *PipelineService) failPipeline(pipeline *models.EpisodePipeline, stage *models.PipelineStage, err error) {
+	now := time.Now()
+	s.db.Model(&models.EpisodePipeline{}).
+		Where("id = ? AND status = ?", pipeline.ID, models.PipelineStatusRunning).
+		Updates(map[string]interface{}{
+			"status":        models.PipelineStatusFailed,
+			"current_stage": stage.Name,
+			"error":         err.Error(),
+			"completed_at":  &now,
+		})
+}
+
+func (s *PipelineService) pipelineCancelled(pipelineID uint) bool {
+	var count int64
+	s.db.Model(&models.EpisodePipeline{}).Where("id = ? AND status = ?", pipelineID, models.PipelineStatusCancelled).Count(&count)
+	return count > 0
+}
+
+// checkCancelled 流水线被取消，或阶段任务被单独取消时返回 errPipelineCancelled
+func (s *PipelineService) checkCancelled(r *pipelineRun) error {
+	if s.pipelineCancelled(r.pipeline.ID) || s.taskService.IsTaskCancelled(r.taskID) {
+		return errPipelineCancelled
+	}
+	return nil
+}
+
+// waitTasks 等待子任务全部结束并汇报进度，任一子任务失败即返回错误
+func (s *PipelineService) waitTasks(r *pipelineRun, label string, taskIDs []string) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+
+	deadline := time.Now().Add(s.stageTimeout)
+	for {
+		if err := s.checkCancelled(r); err != nil {
+			return err
+		}
+
+		var tasks []models.AsyncTask
+		if err := s.db.Where("id IN ?", taskIDs).Find(&tasks).Error; err != nil {
+			return err
+		}
+		completed := 0
+		for _, task := range tasks {
+			switch task.Status {
+			case "completed":
+				completed++
+			case "failed":
+				return fmt.Errorf("%s失败: %s", label, task.Error)
+			case TaskStatusCancelled:
+				return fmt.Errorf("%s任务已被取消", label)
+			}
+		}
+
+		s.taskService.UpdateTaskStatus(r.taskID, "processing", completed*100/len(taskIDs),
+			fmt.Sprintf("%s: %d/%d 完成", label, completed, len(taskIDs)))
+		if completed == len(taskIDs) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s等待超时", label)
+		}
+		time.Sleep(s.pollInterval)
+	}
+}
+
+// waitRecords 等待生成记录（图片、视频、合成）全部进入 completed 或 failed 状态，有失败时返回错误
+func (s *PipelineService) waitRecords(r *pipelineRun, label string, model interface{}, ids []uint) error {
+	progress, err := s.taskService.WaitForRecords(label, model, ids, s.pollInterval, s.stageTimeout, func(p RecordProgress) error {
+		if err := s.checkCancelled(r); err != nil {
+			return err
+		}
+		s.taskService.UpdateTaskStatus(r.taskID, "processing", (p.Completed+p.Failed)*100/p.Total,
+			fmt.Sprintf("%s: %d/%d 完成, %d 失败", label, p.Completed, p.Total, p.Failed))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if progress.Failed > 0 {
+		return fmt.Errorf("%d 个%s失败", progress.Failed, label)
+	}
+	return nil
+}
+
+func (s *PipelineService) runCharacterExtraction(r *pipelineRun) (string, error) {
+	taskID, err := s.characterService.ExtractCharactersFromScript(r.pipeline.EpisodeID)
+	if err != nil {
+		return "", err
+	}
+	if err := s.waitTasks(r, "角色提取", []string{taskID}); err != nil {
+		return "", err
+	}
+
+	var count int64
+	s.db.Model(&models.Character{}).Where("drama_id = ?", r.pipeline.DramaID).Count(&count)
+	return fmt.Sprintf("共 %d 个角色", count), nil
+}
+
+func (s *PipelineService) runPropExtraction(r *pipelineRun) (string, error) {
+	taskID, err := s.propService.ExtractPropsFromScript(r.pipeline.EpisodeID)
+	if err != nil {
+		return "", err
+	}
+	if err := s.waitTasks(r, "道具提取", []string{taskID}); err != nil {
+		return "", err
+	}
+
+	var count int64
+	s.db.Model(&models.Prop{}).Where("drama_id = ?", r.pipeline.DramaID).Count(&count)
+	return fmt.Sprintf("共 %d 个道具", count), nil
+}
+
+func (s *PipelineService) runBackgroundExtraction(r *pipelineRun) (string, error) {
+	taskID, err := s.imageService.ExtractBackgroundsForEpisode(r.episodeID(), r.options.Model, r.style)
+	if err != nil {
+		return "", err
+	}
+	if err := s.waitTasks(r, "场景提取", []string{taskID}); err != nil {
+		return "", err
+	}
+
+	var count int64
+	s.db.Model(&models.Scene{}).Where("episode_id = ?", r.pipeline.EpisodeID).Count(&count)
+	return fmt.Sprintf("共 %d 个场景", count), nil
+}
+
+func (s *PipelineService) runStoryboardGeneration(r *pipelineRun) (string, error) {
+	taskID, err := s.storyboardService.GenerateStoryboard(r.episodeID(), r.options.Model)
+	if err != nil {
+		return "", err
+	}
+	if err := s.waitTasks(r, "分镜生成", []string{taskID}); err != nil {
+		return "", err
+	}
+
+	var count int64
+	s.db.Model(&models.Storyboard{}).Where("episode_id = ?", r.pipeline.EpisodeID).Count(&count)
+	if count == 0 {
+		return "", fmt.Errorf("分镜生成完成但没有镜头")
+	}
+	return fmt.Sprintf("共 %d 个镜头", count), nil
+}
+
+func (s *PipelineService) runCharacterImages(r *pipelineRun) (string, error) {
+	var characters []models.Character
+	if err := s.db.Where("drama_id = ? AND (image_url IS NULL OR image_url = '')", r.pipeline.DramaID).
+		Find(&characters).Error; err != nil {
+		return "", err
+	}
+
+	ids := make([]uint, 0, len(characters))
+	for _, character := range characters {
+		imageGen, err := s.characterService.GenerateCharacterImage(strconv.FormatUint(uint64(character.ID), 10), s.imageService, r.options.ImageModel, r.style)
+		if err != nil {
+			return "", fmt.Errorf("角色 %s 图片生成失败: %w", character.Name, err)
+		}
+		ids = append(ids, imageGen.ID)
+	}
+	if err := s.waitRecords(r, "角色图片", &models.ImageGeneration{}, ids); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("生成 %d 张角色图片", len(ids)), nil
+}
+
+func (s *PipelineService) runSceneImages(r *pipelineRun) (string, error) {
+	var scenes []models.Scene
+	if err := s.db.Where("episode_id = ? AND (image_url IS NULL OR image_url = '')", r.pipeline.EpisodeID).
+		Find(&scenes).Error; err != nil {
+		return "", err
+	}
+
+	ids := make([]uint, 0, len(scenes))
+	for _, scene := range scenes {
+		generations, err := s.imageService.GenerateImagesForScene(strconv.FormatUint(uint64(scene.ID), 10))
+		if err != nil {
+			return "", fmt.Errorf("场景 %s 图片生成失败: %w", scene.Location, err)
+		}
+		for _, g := range generations {
+			ids = append(ids, g.ID)
+		}
+	}
+	if err := s.waitRecords(r, "场景图片", &models.ImageGeneration{}, ids); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("生成 %d 张场景图片", len(ids)), nil
+}
+
+func (s *PipelineService) runPropImages(r *pipelineRun) (string, error) {
+	var props []models.Prop
+	if err := s.db.Where("drama_id = ? AND (image_url IS NULL OR image_url = '') AND prompt IS NOT NULL AND prompt <> ''", r.pipeline.DramaID).
+		Find(&props).Error; err != nil {
+		return "", err
+	}
+
+	taskIDs := make([]string, 0, len(props))
+	for _, prop := range props {
+		taskID, err := s.propService.GeneratePropImage(prop.ID)
+		if err != nil {
+			return "", fmt.Errorf("道具 %s 图片生成失败: %w", prop.Name, err)
+		}
+		taskIDs = append(taskIDs, taskID)
+	}
+	if err := s.waitTasks(r, "道具图片", taskIDs); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("生成 %d 张道具图片", len(taskIDs)), nil
+}
+
+func (s *PipelineService) runFramePrompts(r *pipelineRun) (string, error) {
+	var storyboards []models.Storyboard
+	err := s.db.Where("episode_id = ?", r.pipeline.EpisodeID).
+		Where("id NOT IN (?)", s.db.Model(&models.FramePrompt{}).Select("storyboard_id").Where("frame_type = ?", string(FrameTypeFirst))).
+		Order("storyboard_number ASC").
+		Find(&storyboards).Error
+	if err != nil {
+		return "", err
+	}
+
+	taskIDs := make([]string, 0, len(storyboards))
+	for _, sb := range storyboards {
+		taskID, err := s.framePromptService.GenerateFramePrompt(GenerateFramePromptRequest{
+			StoryboardID: strconv.FormatUint(uint64(sb.ID), 10),
+			FrameType:    FrameTypeFirst,
+		}, r.options.Model)
+		if err != nil {
+			return "", fmt.Errorf("镜头 %d 帧提示词生成失败: %w", sb.StoryboardNumber, err)
+		}
+		taskIDs = append(taskIDs, taskID)
+	}
+	if err := s.waitTasks(r, "帧提示词", taskIDs); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("生成 %d 个首帧提示词", len(taskIDs)), nil
+}
+
+// pipelineGenerationStatuses 镜头已有这些状态的生成记录时不再重复提交（排队中和生成中的记录等待完成）
+var pipelineGenerationStatuses = []string{"pending", "processing", "completed"}
+
+// storyboardsWithoutGeneration 本集中尚无已完成或进行中生成记录的镜头
+func (s *PipelineService) storyboardsWithoutGeneration(r *pipelineRun, model interface{}) ([]models.Storyboard, error) {
+	var storyboards []models.Storyboard
+	err := s.db.Where("episode_id = ?", r.pipeline.EpisodeID).
+		Where("id NOT IN (?)", s.db.Model(model).Select("storyboard_id").
+			Where("storyboard_id IS NOT NULL AND status IN ?", pipelineGenerationStatuses)).
+		Order("storyboard_number ASC").
+		Find(&storyboards).Error
+	return storyboards, err
+}
+
+// inFlightGenerations 本集镜头仍在排队或生成中的记录ID，恢复流水线时等待这些记录而不是重新提交
+func (s *PipelineService) inFlightGenerations(r *pipelineRun, model interface{}) ([]uint, error) {
+	var ids []uint
+	err := s.db.Model(model).
+		Where("storyboard_id IN (?)", s.db.Model(&models.Storyboard{}).Select("id").Where("episode_id = ?", r.pipeline.EpisodeID)).
+		Where("status IN ?", []string{"pending", "processing"}).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// runStoryboardImages 为尚无图片的镜头生成首帧图片，优先使用首帧提示词，没有时使用分镜的图片提示词
+func (s *PipelineService) runStoryboardImages(r *pipelineRun) (string, error) {
+	storyboards, err := s.storyboardsWithoutGeneration(r, &models.ImageGeneration{})
+	if err != nil {
+		return "", err
+	}
+	ids, err := s.inFlightGenerations(r, &models.ImageGeneration{})
+	if err != nil {
+		return "", err
+	}
+
+	dramaID := strconv.FormatUint(uint64(r.pipeline.DramaID), 10)
+	firstFrame := string(FrameTypeFirst)
+	for _, sb := range storyboards {
+		req := &GenerateImageRequest{
+			StoryboardID: &sb.ID,
+			DramaID:      dramaID,
+			ImageType:    string(models.ImageTypeStoryboard),
+			Model:        r.options.ImageModel,
+		}
+		var framePrompt models.FramePrompt
+		if err := s.db.Where("storyboard_id = ? AND frame_type = ?", sb.ID, firstFrame).First(&framePrompt).Error; err == nil && framePrompt.Prompt != "" {
+			req.Prompt = framePrompt.Prompt
+			req.FrameType = &firstFrame
+		} else if sb.ImagePrompt != nil && *sb.ImagePrompt != "" {
+			req.Prompt = *sb.ImagePrompt
+		} else {
+			continue
+		}
+
+		imageGen, err := s.imageService.GenerateImage(req)
+		if err != nil {
+			return "", fmt.Errorf("镜头 %d 图片生成失败: %w", sb.StoryboardNumber, err)
+		}
+		ids = append(ids, imageGen.ID)
+	}
+	if err := s.waitRecords(r, "分镜图片", &models.ImageGeneration{}, ids); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("生成 %d 张分镜图片", len(ids)), nil
+}
+
+// runVideoGeneration 用每个镜头最新完成的图片生成视频，已有完成或进行中视频的镜头跳过
+func (s *PipelineService) runVideoGeneration(r *pipelineRun) (string, error) {
+	storyboards, err := s.storyboardsWithoutGeneration(r, &models.VideoGeneration{})
+	if err != nil {
+		return "", err
+	}
+	ids, err := s.inFlightGenerations(r, &models.VideoGeneration{})
+	if err != nil {
+		return "", err
+	}
+
+	for _, sb := range storyboards {
+		var imageGen models.ImageGeneration
+		if err := s.db.Where("storyboard_id = ? AND status = ?", sb.ID, models.ImageStatusCompleted).
+			Order("created_at DESC").First(&imageGen).Error; err != nil {
+			s.log.Warnw("No completed image for storyboard, skipping video", "storyboard_id", sb.ID)
+			continue
+		}
+		videoGen, err := s.videoService.GenerateVideoFromImage(imageGen.ID)
+		if err != nil {
+			return "", fmt.Errorf("镜头 %d 视频生成失败: %w", sb.StoryboardNumber, err)
+		}
+		ids = append(ids, videoGen.ID)
+	}
+	if err := s.waitRecords(r, "视频生成", &models.VideoGeneration{}, ids); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("生成 %d 段视频", len(ids)), nil
+}
+
+func (s *PipelineService) runFinalize(r *pipelineRun) (string, error) {
+	result, err := s.mergeService.FinalizeEpisode(r.episodeID(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	ids := []uint{}
+	if mergeID, ok := result["merge_id"].(uint); ok {
+		ids = append(ids, mergeID)
+	}
+	if variantIDs, ok := result["variant_merge_ids"].([]uint); ok {
+		ids = append(ids, variantIDs...)
+	}
+	if err := s.waitRecords(r, "视频合成", &models.VideoMerge{}, ids); err != nil {
+		return "", err
+	}
+
+	var episode models.Episode
+	if err := s.db.First(&episode, r.pipeline.EpisodeID).Error; err == nil && episode.VideoURL != nil {
+		return "成片: " + *episode.VideoURL, nil
+	}
+	return "成片合成完成", nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/drama-generator/backend/domain/models"
+	"gorm.io/gorm"
+)
+
+func TestPipelineResumeWaitsForInFlightImages(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		drama, episode := createTestEpisode(t, db)
+		pipeline := &models.EpisodePipeline{EpisodeID: episode.ID, DramaID: drama.ID, Status: models.PipelineStatusRunning}
+		mustCreate(t, db, pipeline)
+
+		// 每个镜头都已有已完成、排队中或生成中的图片，恢复时不应再次提交
+		prompt := "platform at night"
+		statuses := []models.ImageGenerationStatus{models.ImageStatusCompleted, models.ImageStatusPending, models.ImageStatusProcessing}
+		var inFlight []uint
+		for i, status := range statuses {
+			storyboard := &models.Storyboard{EpisodeID: episode.ID, StoryboardNumber: i + 1, ImagePrompt: &prompt}
+			mustCreate(t, db, storyboard)
+			gen := &models.ImageGeneration{StoryboardID: &storyboard.ID, DramaID: drama.ID, Provider: "test", Prompt: prompt, Status: status}
+			mustCreate(t, db, gen)
+			if status != models.ImageStatusCompleted {
+				inFlight = append(inFlight, gen.ID)
+			}
+		}
+
+		pipelineService := newTestPipelineService(t, db, cfg)
+		pipelineService.pollInterval = 10 * time.Millisecond
+		pipelineService.stageTimeout = 5 * time.Second
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			db.Model(&models.ImageGeneration{}).Where("id IN ?", inFlight).Update("status", models.ImageStatusCompleted)
+		}()
+		message, err := pipelineService.runStoryboardImages(&pipelineRun{pipeline: pipeline})
+		if err != nil {
+			t.Fatalf("run storyboard images: %v", err)
+		}
+		if !strings.Contains(message, "2") {
+			t.Fatalf("expected to wait for 2 in-flight images, got %q", message)
+		}
+
+		var count int64
+		db.Model(&models.ImageGeneration{}).Count(&count)
+		if count != int64(len(statuses)) {
+			t.Fatalf("expected no new image generations, got %d rows", count)
+		}
+	})
+}
+
+func TestPipelineResumeWaitsForCancelledStageToExit(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		drama, episode := createTestEpisode(t, db)
+		// 已取消但旧执行协程仍在执行 characters 阶段
+		pipeline := &models.EpisodePipeline{EpisodeID: episode.ID, DramaID: drama.ID, Status: models.PipelineStatusCancelled,
+			Stages: []models.PipelineStage{
+				{Name: PipelineStageCharacters, Seq: 1, Status: models.PipelineStageRunning},
+				{Name: PipelineStageProps, Seq: 2, Status: models.PipelineStagePending},
+			}}
+		mustCreate(t, db, pipeline)
+
+		pipelineService := newTestPipelineService(t, db, cfg)
+		if _, err := pipelineService.ResumePipeline(pipeline.ID); err == nil || !strings.Contains(err.Error(), "still stopping") {
+			t.Fatalf("expected resume to be refused while a stage is running, got %v", err)
+		}
+
+		// 阶段已被其他协程领取时不再执行，也不创建任务
+		def := findPipelineStageDef(PipelineStageCharacters)
+		stage := pipeline.Stages[0]
+		stage.Status = models.PipelineStagePending
+		if err := pipelineService.runStage(pipeline, &stage, def, PipelineOptions{}, ""); err != errPipelineStageClaimed {
+			t.Fatalf("expected claimed stage to be skipped, got %v", err)
+		}
+		var tasks int64
+		db.Model(&models.AsyncTask{}).Count(&tasks)
+		if tasks != 0 {
+			t.Fatalf("expected no stage task, got %d", tasks)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"fmt"
 
+	"github.com/drama-generator/backend/domain/models"
 	"github.com/drama-generator/backend/pkg/config"
 )
 
@@ -321,7 +322,7 @@ You must return a **JSON object** with the following structure:
   "description": "Complete action sequence of a swordsman in black from drawing a blade to striking."
 }
 
-`, style, imageRatio)
+`, imageRatio)
 	}
 
 	return fmt.Sprintf(`**Role:** 你是一位精通视觉叙事与图像生成提示词的专家。你需要生成一个描述 3x3 九宫格动作序列的提示词。
@@ -588,6 +589,66 @@ Output Format:
   - script_content: 详细剧本内容（800-1200字）`
 }
 
+// GetContinuityCheckPrompt 获取连贯性检查提示词（角色退场与服装变化）
+func (p *PromptI18n) GetContinuityCheckPrompt() string {
+	if p.IsEnglish() {
+		return `You are a continuity supervisor for short dramas. The user sends one episode as JSON:
+- characters: name and appearance (the canonical look, including costume)
+- exited: characters already written out in earlier episodes (dead, left for good, etc.)
+- storyboards: shots in order, with number, location, time, linked characters, action, dialogue, description and image prompt
+
+Find:
+1. exits: characters who are written out in this episode (die, leave permanently, are imprisoned for the rest of the story). Do not report temporary departures.
+2. costume_changes: shots where a character's clothing, hairstyle or look contradicts their appearance text or their look in the earlier shots of the same scene, and the story gives no reason for the change.
+
+Output Format:
+**CRITICAL: Return ONLY a valid JSON object. Start directly with { and end with }.**
+{"exits": [{"character": "name", "storyboard_number": 1, "reason": "why"}], "costume_changes": [{"character": "name", "storyboard_number": 1, "description": "what differs"}]}
+Use empty arrays when nothing is found. Character names must match the characters list exactly.`
+	}
+
+	return `你是短剧的场记（连贯性监督）。用户会以JSON发送一集内容：
+- characters：角色名与外貌设定（标准形象，包括服装）
+- exited：前面各集已经退场的角色（死亡、永久离开等）
+- storyboards：按顺序排列的分镜，包含镜号、地点、时间、关联角色、动作、对白、描述和画面提示词
+
+请找出：
+1. exits：在本集中退场的角色（死亡、永久离开、被关押直到剧终等），暂时离开不算退场。
+2. costume_changes：角色的服装、发型或造型与外貌设定不符，或与同一场景前面镜头不一致，且剧情没有给出变化原因的镜头。
+
+输出格式：
+**重要：必须只返回纯JSON对象，直接以 { 开头，以 } 结尾。**
+{"exits": [{"character": "角色名", "storyboard_number": 1, "reason": "原因"}], "costume_changes": [{"character": "角色名", "storyboard_number": 1, "description": "不一致之处"}]}
+没有发现时返回空数组。角色名必须与characters中的完全一致。`
+}
+
+// GetCharacterReferencePrompt 获取角色参考图的构图要求，附加在角色外貌描述之后
+// view: front, side, back, closeup, sheet（多视角合成设定图）
+func (p *PromptI18n) GetCharacterReferencePrompt(view string) string {
+	prompts := map[string]map[string]string{
+		"zh": {
+			models.CharacterViewFront:   "角色设定参考图，正面全身站立，双臂自然下垂，中性表情，纯白背景，均匀柔光，无其他人物和道具，完整展示服装与发型",
+			models.CharacterViewSide:    "角色设定参考图，侧面（左侧90度）全身站立，中性表情，纯白背景，均匀柔光，无其他人物和道具，完整展示服装与发型的侧面轮廓",
+			models.CharacterViewBack:    "角色设定参考图，背面全身站立，纯白背景，均匀柔光，无其他人物和道具，完整展示服装与发型的背面",
+			models.CharacterViewCloseup: "角色面部特写表情参考图，同一角色的四个头肩特写排成一排：平静、微笑、愤怒、悲伤，正面角度，纯白背景，均匀柔光，五官与发型在各表情中完全一致",
+			models.CharacterViewSheet:   "角色三视图设定稿（character turnaround sheet），同一角色在一张图中从左到右依次为正面全身、侧面全身、背面全身，右侧为平静、微笑、愤怒、悲伤四个面部特写，纯白背景，均匀柔光，各视角的服装、发型、体型和配色完全一致，无文字标注",
+		},
+		"en": {
+			models.CharacterViewFront:   "character reference sheet, front view, full body standing, arms relaxed at sides, neutral expression, plain white background, even soft lighting, no other people or props, clearly showing outfit and hairstyle",
+			models.CharacterViewSide:    "character reference sheet, side view (left profile, 90 degrees), full body standing, neutral expression, plain white background, even soft lighting, no other people or props, clearly showing the outfit and hairstyle silhouette",
+			models.CharacterViewBack:    "character reference sheet, back view, full body standing, plain white background, even soft lighting, no other people or props, clearly showing the back of the outfit and hairstyle",
+			models.CharacterViewCloseup: "character expression sheet, four head-and-shoulders close-ups of the same character in a row: calm, smiling, angry, sad, front angle, plain white background, even soft lighting, identical facial features and hairstyle in every expression",
+			models.CharacterViewSheet:   "character turnaround sheet, the same character shown left to right as front view, side view and back view full body, with four facial expression close-ups on the right (calm, smiling, angry, sad), plain white background, even soft lighting, identical outfit, hairstyle, body shape and colors in every view, no text labels",
+		},
+	}
+
+	lang := "zh"
+	if p.IsEnglish() {
+		lang = "en"
+	}
+	return prompts[lang][view]
+}
+
 // FormatUserPrompt 格式化用户提示词的通用文本
 func (p *PromptI18n) FormatUserPrompt(key string, args ...interface{}) string {
 	templates := map[string]map[string]string{
@@ -622,6 +683,21 @@ func (p *PromptI18n) FormatUserPrompt(key string, args ...interface{}) string {
 			"angle_label":            "Angle: %s",
 			"movement_label":         "Movement: %s",
 			"drama_info_template":    "Title: %s\nSummary: %s\nGenre: %s",
+			"outline_summary_field":  "\n\nAlso add a summary field to the JSON object: the synopsis of the whole drama (150-300 words).",
+			"outline_batch":          "\n\nIn this request plan episodes %d to %d only. The episodes array must contain exactly these episodes.",
+			"outline_continuation":   "\n\nEpisodes already planned:\n%s\n\nContinue the plan with episodes %d to %d only. The episodes array must contain exactly these episodes.",
+			"outline_episode_line":   "Episode %d %s: %s",
+			"episode_conflict_label": "Conflict: %s",
+			"cliffhanger_label":      "Cliffhanger: %s",
+			"series_outline_label":   "【Drama Outline】",
+			"character_bios_label":   "【Characters】(use exactly these names, do not rename or invent main characters)",
+			"character_bio_line":     "- %s (%s): %s",
+			"previously_label":       "【Previously】",
+			"previous_ending_label":  "【End of Previous Episode】",
+			"episode_plan_label":     "【Plan for Episode %d】",
+			"next_plan_label":        "【Plan for Next Episode】",
+			"single_episode_request": "\n\nPlease write the detailed script for episode %d only, continuing the story above without contradicting earlier episodes. The episodes array must contain only this episode, and add a summary field to it (plot summary within 80 words, used to keep later episodes consistent).",
+			"episode_summary_prompt": "Summarize the plot of the following episode script in no more than 80 words, keeping character names and key events. Return only the summary text.\n\n%s",
 		},
 		"zh": {
 			"outline_request":        "请为以下主题创作短剧大纲：\n\n主题：%s",
@@ -653,6 +729,21 @@ func (p *PromptI18n) FormatUserPrompt(key string, args ...interface{}) string {
 			"angle_label":            "角度: %s",
 			"movement_label":         "运镜: %s",
 			"drama_info_template":    "剧名：%s\n简介：%s\n类型：%s",
+			"outline_summary_field":  "\n\n另外在JSON对象中增加 summary 字段：全剧故事梗概（200-400字）。",
+			"outline_batch":          "\n\n本次先规划第%d集到第%d集，episodes数组只包含这些集。",
+			"outline_continuation":   "\n\n已规划的剧集：\n%s\n\n请继续规划第%d集到第%d集，episodes数组只包含这些集。",
+			"outline_episode_line":   "第%d集 %s：%s",
+			"episode_conflict_label": "主要矛盾：%s",
+			"cliffhanger_label":      "悬念：%s",
+			"series_outline_label":   "【剧本大纲】",
+			"character_bios_label":   "【角色设定】（角色名必须与此完全一致，不得改名或新增主要角色）",
+			"character_bio_line":     "- %s（%s）：%s",
+			"previously_label":       "【前情提要】",
+			"previous_ending_label":  "【上一集结尾】",
+			"episode_plan_label":     "【第%d集规划】",
+			"next_plan_label":        "【下一集规划】",
+			"single_episode_request": "\n\n请只创作第%d集的详细剧本，承接以上剧情，不得与前面各集矛盾。episodes数组只包含这一集，并为其增加 summary 字段（80字以内的剧情摘要，用于后续剧集保持连贯）。",
+			"episode_summary_prompt": "请用不超过120字概括以下剧本的剧情，保留角色名和关键事件，只返回摘要文本。\n\n%s",
 		},
 	}
 
@@ -93,7 +93,8 @@ func (s *PropService) processPropExtraction(taskID string, episode models.Episod
 	promptTemplate := s.promptI18n.GetPropExtractionPrompt(drama.Style)
 	prompt := fmt.Sprintf(promptTemplate, script)
 
-	response, err := s.aiService.GenerateText(prompt, "", ai.WithMaxTokens(2000))
+	textModel := loadDramaPreset(s.db, episode.DramaID).TextModel
+	response, err := s.aiService.GenerateTextWithModel(textModel, prompt, "", ai.WithMaxTokens(2000))
 	if err != nil {
 		s.taskService.UpdateTaskError(taskID, err)
 		return
@@ -1,21 +1,290 @@
 package services
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/storage"
 	"github.com/drama-generator/backend/pkg/logger"
 	"gorm.io/gorm"
 )
 
+const (
+	resourceTransferMaxRetries = 3               // 单次扫描中每个资源的下载次数
+	resourceTransferMaxBackoff = 24 * time.Hour  // 扫描之间重试间隔的上限
+	resourceTransferRetryDelay = 2 * time.Second // 单次扫描中重试的初始间隔
+)
+
+// resourceTransferMu 同一时间只允许一次转存扫描
+var resourceTransferMu sync.Mutex
+
+// ResourceTransferService 将 AI 服务商返回的临时URL（通常数小时后过期）转存到本地存储
 type ResourceTransferService struct {
-	db  *gorm.DB
-	log *logger.Logger
+	db          *gorm.DB
+	fileStorage storage.Storage
+	log         *logger.Logger
+	retryDelay  time.Duration
+}
+
+// ResourceTransferStats 转存统计
+type ResourceTransferStats struct {
+	Scanned     int `json:"scanned"`
+	Transferred int `json:"transferred"`
+	Failed      int `json:"failed"`
+	Expired     int `json:"expired"`
+}
+
+// transferTarget 需要转存的资源类型
+type transferTarget struct {
+	resourceType string
+	model        interface{}
+	table        string
+	urlColumn    string
+	category     string
+	completed    string // 完成状态的过滤条件
 }
 
-func NewResourceTransferService(db *gorm.DB, log *logger.Logger) *ResourceTransferService {
+var transferTargets = []transferTarget{
+	{resourceType: "image_generation", model: &models.ImageGeneration{}, table: "image_generations", urlColumn: "image_url", category: "images", completed: "status = 'completed'"},
+	{resourceType: "video_generation", model: &models.VideoGeneration{}, table: "video_generations", urlColumn: "video_url", category: "videos", completed: "status = 'completed'"},
+	{resourceType: "character", model: &models.Character{}, table: "characters", urlColumn: "image_url", category: "characters"},
+	{resourceType: "scene", model: &models.Scene{}, table: "scenes", urlColumn: "image_url", category: "images"},
+	{resourceType: "prop", model: &models.Prop{}, table: "props", urlColumn: "image_url", category: "images"},
+}
+
+func NewResourceTransferService(db *gorm.DB, fileStorage storage.Storage, log *logger.Logger) *ResourceTransferService {
 	return &ResourceTransferService{
-		db:  db,
-		log: log,
+		db:          db,
+		fileStorage: fileStorage,
+		log:         log,
+		retryDelay:  resourceTransferRetryDelay,
+	}
+}
+
+// TransferPending 转存所有只有远程URL、没有本地副本的资源
+// since 不为空时只扫描该时间之后更新的记录；limit 为每种资源的最大处理数，0 表示不限
+func (s *ResourceTransferService) TransferPending(since *time.Time, limit int) (*ResourceTransferStats, error) {
+	if s.fileStorage == nil {
+		return nil, fmt.Errorf("file storage not configured")
+	}
+	if !resourceTransferMu.TryLock() {
+		return nil, fmt.Errorf("resource transfer already running")
+	}
+	defer resourceTransferMu.Unlock()
+
+	stats := &ResourceTransferStats{}
+	for _, target := range transferTargets {
+		if err := s.transferTarget(target, since, limit, stats); err != nil {
+			return stats, err
+		}
+	}
+
+	s.log.Infow("Resource transfer finished",
+		"scanned", stats.Scanned,
+		"transferred", stats.Transferred,
+		"failed", stats.Failed,
+		"expired", stats.Expired)
+	return stats, nil
+}
+
+// ListTransfers 查询转存失败或源URL已过期的资源
+func (s *ResourceTransferService) ListTransfers(status string, dramaID uint) ([]models.ResourceTransfer, error) {
+	query := s.db.Model(&models.ResourceTransfer{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if dramaID > 0 {
+		query = query.Where("drama_id = ?", dramaID)
+	}
+
+	var transfers []models.ResourceTransfer
+	if err := query.Order("updated_at DESC").Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+	return transfers, nil
+}
+
+func (s *ResourceTransferService) transferTarget(target transferTarget, since *time.Time, limit int, stats *ResourceTransferStats) error {
+	// 已过期或未到重试时间的资源跳过
+	var skipIDs []uint
+	if err := s.db.Model(&models.ResourceTransfer{}).
+		Where("resource_type = ? AND (status = ? OR next_retry_at > ?)", target.resourceType, models.ResourceTransferExpired, time.Now()).
+		Pluck("resource_id", &skipIDs).Error; err != nil {
+		return fmt.Errorf("failed to load transfer records: %w", err)
+	}
+
+	query := s.db.Model(target.model).
+		Where(fmt.Sprintf("(%s LIKE 'http://%%' OR %s LIKE 'https://%%')", target.urlColumn, target.urlColumn)).
+		Where("local_path IS NULL OR local_path = ''")
+	if target.completed != "" {
+		query = query.Where(target.completed)
+	}
+	if since != nil {
+		query = query.Where("updated_at >= ?", *since)
+	}
+	if len(skipIDs) > 0 {
+		query = query.Where("id NOT IN ?", skipIDs)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var rows []struct {
+		ID      uint
+		DramaID uint
+		URL     string
+	}
+	if err := query.Order("id").Select(fmt.Sprintf("id, drama_id, %s AS url", target.urlColumn)).Scan(&rows).Error; err != nil {
+		return fmt.Errorf("failed to scan %s: %w", target.table, err)
+	}
+
+	for _, row := range rows {
+		stats.Scanned++
+		localPath, err := s.transfer(row.URL, target.category)
+		if err != nil {
+			expired := sourceURLExpired(row.URL, err)
+			s.recordFailure(target.resourceType, row.ID, row.DramaID, row.URL, err, expired)
+			if expired {
+				stats.Expired++
+			} else {
+				stats.Failed++
+			}
+			continue
+		}
+
+		if err := s.db.Table(target.table).Where("id = ?", row.ID).UpdateColumn("local_path", localPath).Error; err != nil {
+			s.log.Errorw("Failed to save local path", "table", target.table, "id", row.ID, "error", err)
+			stats.Failed++
+			continue
+		}
+		s.db.Where("resource_type = ? AND resource_id = ?", target.resourceType, row.ID).Delete(&models.ResourceTransfer{})
+		stats.Transferred++
+	}
+	return nil
+}
+
+// transfer 下载远程文件到本地存储并返回相对路径，失败时按间隔重试；源URL已过期时不再重试
+func (s *ResourceTransferService) transfer(sourceURL, category string) (string, error) {
+	// 本存储自身的URL无需下载
+	if key, ok := s.fileStorage.KeyFromURL(sourceURL); ok {
+		if _, err := s.fileStorage.Stat(key); err == nil {
+			return key, nil
+		}
+	}
+	if sourceURLExpired(sourceURL, nil) {
+		return "", fmt.Errorf("source url expired")
+	}
+
+	var lastErr error
+	delay := s.retryDelay
+	for attempt := 1; attempt <= resourceTransferMaxRetries; attempt++ {
+		result, err := s.fileStorage.DownloadFromURLWithPath(sourceURL, category)
+		if err == nil {
+			return result.RelativePath, nil
+		}
+		lastErr = err
+		if sourceURLExpired(sourceURL, err) || attempt == resourceTransferMaxRetries {
+			break
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+	return "", lastErr
+}
+
+// recordFailure 记录转存失败；过期的资源标记为 expired，其余按失败次数退避后重试
+func (s *ResourceTransferService) recordFailure(resourceType string, resourceID, dramaID uint, sourceURL string, cause error, expired bool) {
+	var record models.ResourceTransfer
+	err := s.db.Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).First(&record).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.log.Errorw("Failed to load transfer record", "type", resourceType, "id", resourceID, "error", err)
+		return
+	}
+
+	errMsg := cause.Error()
+	if len(errMsg) > 500 {
+		errMsg = errMsg[:500] + "..."
 	}
+	record.ResourceType = resourceType
+	record.ResourceID = resourceID
+	record.DramaID = dramaID
+	record.SourceURL = sourceURL
+	record.Attempts++
+	record.LastError = &errMsg
+
+	if expired {
+		record.Status = models.ResourceTransferExpired
+		record.NextRetryAt = nil
+		s.log.Warnw("Resource source url expired", "type", resourceType, "id", resourceID, "drama_id", dramaID)
+	} else {
+		backoff := time.Duration(1<<uint(min(record.Attempts-1, 5))) * time.Hour
+		if backoff > resourceTransferMaxBackoff {
+			backoff = resourceTransferMaxBackoff
+		}
+		nextRetry := time.Now().Add(backoff)
+		record.Status = models.ResourceTransferFailed
+		record.NextRetryAt = &nextRetry
+		s.log.Warnw("Failed to transfer resource", "type", resourceType, "id", resourceID, "attempts", record.Attempts, "error", errMsg)
+	}
+
+	if err := s.db.Save(&record).Error; err != nil {
+		s.log.Errorw("Failed to save transfer record", "type", resourceType, "id", resourceID, "error", err)
+	}
+}
+
+// sourceURLExpired 判断源URL是否已失效：签名参数中的过期时间已过，或下载返回 403/404/410
+func sourceURLExpired(sourceURL string, err error) bool {
+	var statusErr *storage.HTTPStatusError
+	if errors.As(err, &statusErr) {
+		switch statusErr.StatusCode {
+		case http.StatusForbidden, http.StatusNotFound, http.StatusGone:
+			return true
+		}
+	}
+
+	expiresAt, ok := signedURLExpiry(sourceURL)
+	return ok && time.Now().After(expiresAt)
 }
 
-// ResourceTransferService 现在只保留基本结构，MinIO相关功能已移除
-// 如需资源转存功能，请使用本地存储
+// signedURLExpiry 解析签名URL的过期时间，支持 AWS/TOS 风格（X-*-Date + X-*-Expires）与 OSS 风格（Expires 为 Unix 时间戳）
+func signedURLExpiry(sourceURL string) (time.Time, bool) {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	query := make(map[string]string)
+	for name, values := range u.Query() {
+		if len(values) > 0 {
+			query[strings.ToLower(name)] = values[0]
+		}
+	}
+
+	for _, prefix := range []string{"x-amz-", "x-tos-"} {
+		date, okDate := query[prefix+"date"]
+		expires, okExpires := query[prefix+"expires"]
+		if !okDate || !okExpires {
+			continue
+		}
+		signedAt, err := time.Parse("20060102T150405Z", date)
+		seconds, err2 := strconv.ParseInt(expires, 10, 64)
+		if err != nil || err2 != nil {
+			continue
+		}
+		return signedAt.Add(time.Duration(seconds) * time.Second), true
+	}
+
+	if expires, ok := query["expires"]; ok {
+		if unix, err := strconv.ParseInt(expires, 10, 64); err == nil && unix > 1e9 {
+			return time.Unix(unix, 0), true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+func TestResourceTransferBacksOffFailedDownloads(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		drama, _ := createTestEpisode(t, db)
+
+		// 转存扫描：下载失败的资源记录重试时间，下一次扫描跳过
+		remoteURL := "http://127.0.0.1:1/prop.png"
+		mustCreate(t, db, &models.Prop{DramaID: drama.ID, Name: "Lantern", ImageURL: &remoteURL})
+		fileStorage := testStorage(t, cfg)
+		transferService := NewResourceTransferService(db, fileStorage, logger.NewLogger(false))
+		transferService.retryDelay = 0
+		stats, err := transferService.TransferPending(nil, 0)
+		if err != nil || stats.Failed != 1 {
+			t.Fatalf("transfer pending: stats=%+v err=%v", stats, err)
+		}
+		if stats, err = transferService.TransferPending(nil, 0); err != nil || stats.Scanned != 0 {
+			t.Fatalf("second transfer should skip: stats=%+v err=%v", stats, err)
+		}
+	})
+}
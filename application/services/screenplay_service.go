@@ -0,0 +1,462 @@
+package services
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/screenplay"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ScreenplayService 剧本文件（Fountain / Final Draft）导入，剧本与分镜表导出
+type ScreenplayService struct {
+	db          *gorm.DB
+	storagePath string
+	baseURL     string
+	log         *logger.Logger
+}
+
+func NewScreenplayService(db *gorm.DB, storagePath, baseURL string, log *logger.Logger) *ScreenplayService {
+	return &ScreenplayService{
+		db:          db,
+		storagePath: storagePath,
+		baseURL:     baseURL,
+		log:         log,
+	}
+}
+
+// ScreenplayFile 上传的剧本文件，Format 为空时按扩展名判断
+type ScreenplayFile struct {
+	Name   string
+	Data   []byte
+	Format screenplay.Format
+}
+
+// ScreenplayImportResult 导入结果
+type ScreenplayImportResult struct {
+	Title             string           `json:"title,omitempty"`
+	Episodes          []models.Episode `json:"episodes"`
+	ScenesCreated     int              `json:"scenes_created"`
+	CharactersCreated []string         `json:"characters_created"`
+	CharactersMatched []string         `json:"characters_matched"`
+	DialogueLines     int              `json:"dialogue_lines"`
+}
+
+type parsedScreenplayEpisode struct {
+	episode *screenplay.Episode
+	file    ScreenplayFile
+}
+
+// ImportScreenplay 解析剧本文件并创建章节：每个分集标记（或每个没有分集标记的文件）为一集，
+// 场景标题创建章节场景，角色名匹配或创建角色并关联到章节，结构化剧本保存在 episode_screenplays。
+// replace 为 true 时替换剧本现有章节，否则追加到最后一集之后
+func (s *ScreenplayService) ImportScreenplay(dramaID uint, files []ScreenplayFile, replace bool) (*ScreenplayImportResult, error) {
+	var drama models.Drama
+	if err := s.db.First(&drama, dramaID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("drama not found")
+		}
+		return nil, err
+	}
+
+	// 先解析全部文件，避免导入到一半失败
+	result := &ScreenplayImportResult{CharactersCreated: []string{}, CharactersMatched: []string{}}
+	var parsed []parsedScreenplayEpisode
+	for _, file := range files {
+		if file.Format == "" {
+			format, err := screenplay.FormatFromFilename(file.Name)
+			if err != nil {
+				return nil, fmt.Errorf("invalid screenplay %s: %w", file.Name, err)
+			}
+			file.Format = format
+		}
+		doc, err := screenplay.Parse(file.Data, file.Format)
+		if err != nil {
+			return nil, fmt.Errorf("invalid screenplay %s: %w", file.Name, err)
+		}
+		if result.Title == "" {
+			result.Title = doc.Title
+		}
+		for _, ep := range doc.Episodes {
+			parsed = append(parsed, parsedScreenplayEpisode{episode: ep, file: file})
+		}
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("invalid screenplay: no scenes or dialogue found")
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		nextNum := 1
+		if replace {
+			var oldIDs []uint
+			if err := tx.Model(&models.Episode{}).Where("drama_id = ?", dramaID).Pluck("id", &oldIDs).Error; err != nil {
+				return err
+			}
+			if len(oldIDs) > 0 {
+				if err := tx.Where("episode_id IN ?", oldIDs).Delete(&models.EpisodeScreenplay{}).Error; err != nil {
+					return err
+				}
+				if err := tx.Where("episode_id IN ?", oldIDs).Delete(&models.EpisodeSummary{}).Error; err != nil {
+					return err
+				}
+				if err := tx.Where("id IN ?", oldIDs).Delete(&models.Episode{}).Error; err != nil {
+					return err
+				}
+			}
+		} else {
+			var maxNum *int
+			if err := tx.Model(&models.Episode{}).Where("drama_id = ?", dramaID).Select("MAX(episode_number)").Scan(&maxNum).Error; err != nil {
+				return err
+			}
+			if maxNum != nil {
+				nextNum = *maxNum + 1
+			}
+		}
+
+		characters, err := s.characterResolver(tx, &drama, result)
+		if err != nil {
+			return err
+		}
+
+		for _, p := range parsed {
+			episode, err := s.createEpisode(tx, &drama, p, nextNum, characters, result)
+			if err != nil {
+				return err
+			}
+			result.Episodes = append(result.Episodes, *episode)
+			nextNum++
+		}
+
+		return tx.Model(&drama).Update("updated_at", time.Now()).Error
+	})
+	if err != nil {
+		s.log.Errorw("Failed to import screenplay", "error", err, "drama_id", dramaID)
+		return nil, err
+	}
+
+	s.log.Infow("Screenplay imported",
+		"drama_id", dramaID,
+		"episodes", len(result.Episodes),
+		"scenes", result.ScenesCreated,
+		"characters_created", len(result.CharactersCreated))
+	return result, nil
+}
+
+// characterResolver 返回按名称（不区分大小写）查找或创建角色的函数
+func (s *ScreenplayService) characterResolver(tx *gorm.DB, drama *models.Drama, result *ScreenplayImportResult) (func(name string) (uint, error), error) {
+	var existing []models.Character
+	if err := tx.Where("drama_id = ?", drama.ID).Order("sort_order ASC, id ASC").Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	ids := make(map[string]uint, len(existing))
+	for _, c := range existing {
+		ids[strings.ToLower(c.Name)] = c.ID
+	}
+	nextSort := len(existing)
+	matched := make(map[string]bool)
+
+	return func(name string) (uint, error) {
+		key := strings.ToLower(name)
+		if id, ok := ids[key]; ok {
+			if !matched[key] {
+				matched[key] = true
+				result.CharactersMatched = append(result.CharactersMatched, name)
+			}
+			return id, nil
+		}
+		character := &models.Character{DramaID: drama.ID, Name: name, SortOrder: nextSort}
+		if err := tx.Create(character).Error; err != nil {
+			return 0, err
+		}
+		nextSort++
+		ids[key] = character.ID
+		matched[key] = true
+		result.CharactersCreated = append(result.CharactersCreated, name)
+		return character.ID, nil
+	}, nil
+}
+
+func (s *ScreenplayService) createEpisode(tx *gorm.DB, drama *models.Drama, p parsedScreenplayEpisode, episodeNum int, characterID func(string) (uint, error), result *ScreenplayImportResult) (*models.Episode, error) {
+	title := p.episode.Title
+	if title == "" {
+		title = fmt.Sprintf("第%d集", episodeNum)
+	}
+	script := p.episode.PlainText()
+	episode := &models.Episode{
+		DramaID:       drama.ID,
+		EpisodeNum:    episodeNum,
+		Title:         title,
+		ScriptContent: &script,
+		Status:        "draft",
+	}
+	if err := tx.Create(episode).Error; err != nil {
+		return nil, err
+	}
+
+	content, err := json.Marshal(p.episode)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Create(&models.EpisodeScreenplay{
+		EpisodeID:  episode.ID,
+		Format:     string(p.file.Format),
+		SourceName: p.file.Name,
+		Content:    datatypes.JSON(content),
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	// 同一地点与时间的多场戏合并为一个场景
+	var scenes []*models.Scene
+	sceneIndex := make(map[string]*models.Scene)
+	for _, sc := range p.episode.Scenes {
+		if sc.Location == "" {
+			continue
+		}
+		key := sc.Location + "\x00" + sc.Time
+		if scene, ok := sceneIndex[key]; ok {
+			scene.StoryboardCount++
+			continue
+		}
+		scene := &models.Scene{
+			DramaID:         drama.ID,
+			EpisodeID:       &episode.ID,
+			Location:        sc.Location,
+			Time:            sc.Time,
+			StoryboardCount: 1,
+			Status:          "pending",
+		}
+		sceneIndex[key] = scene
+		scenes = append(scenes, scene)
+	}
+	for _, scene := range scenes {
+		if err := tx.Create(scene).Error; err != nil {
+			return nil, err
+		}
+	}
+	result.ScenesCreated += len(scenes)
+
+	for _, name := range p.episode.Characters() {
+		id, err := characterID(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := tx.Table("episode_characters").Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&EpisodeCharacterLink{EpisodeID: episode.ID, CharacterID: id}).Error; err != nil {
+			return nil, err
+		}
+	}
+	for _, sc := range p.episode.Scenes {
+		for _, el := range sc.Elements {
+			if el.Type == screenplay.ElementDialogue {
+				result.DialogueLines++
+			}
+		}
+	}
+
+	return episode, nil
+}
+
+// GetEpisodeScreenplay 获取章节导入时保存的结构化剧本
+func (s *ScreenplayService) GetEpisodeScreenplay(episodeID string) (*models.EpisodeScreenplay, error) {
+	var sp models.EpisodeScreenplay
+	if err := s.db.Where("episode_id = ?", episodeID).First(&sp).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("screenplay not found")
+		}
+		return nil, err
+	}
+	return &sp, nil
+}
+
+// ExportEpisodeScreenplay 导出章节剧本（Fountain / FDX / PDF）
+// 导入的结构化剧本与当前剧本内容一致时直接使用，否则按纯文本剧本解析
+func (s *ScreenplayService) ExportEpisodeScreenplay(episodeID uint, format screenplay.Format) ([]byte, error) {
+	episode, drama, err := s.loadEpisode(episodeID)
+	if err != nil {
+		return nil, err
+	}
+	if episode.ScriptContent == nil || strings.TrimSpace(*episode.ScriptContent) == "" {
+		return nil, fmt.Errorf("episode has no script")
+	}
+	script := strings.TrimSpace(*episode.ScriptContent)
+
+	ep := &screenplay.Episode{}
+	var sp models.EpisodeScreenplay
+	if err := s.db.Where("episode_id = ?", episode.ID).First(&sp).Error; err == nil {
+		if err := json.Unmarshal(sp.Content, ep); err != nil || ep.PlainText() != script {
+			ep = &screenplay.Episode{}
+		}
+	}
+	if len(ep.Scenes) == 0 {
+		// 纯文本剧本中即使有分集标记也属于同一章节
+		for _, parsed := range screenplay.ParseScript(script).Episodes {
+			ep.Scenes = append(ep.Scenes, parsed.Scenes...)
+		}
+	}
+	ep.Title = episode.Title
+
+	return renderScreenplay(&screenplay.Document{Title: drama.Title, Episodes: []*screenplay.Episode{ep}}, format)
+}
+
+// ExportShootingScript 导出章节分镜表：每个镜头的镜号、景别、角度、运镜、对白、时长，PDF 包含首帧图片
+func (s *ScreenplayService) ExportShootingScript(episodeID uint, format screenplay.Format) ([]byte, error) {
+	episode, drama, err := s.loadEpisode(episodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var storyboards []models.Storyboard
+	if err := s.db.Where("episode_id = ?", episode.ID).Order("storyboard_number ASC").Find(&storyboards).Error; err != nil {
+		return nil, err
+	}
+	if len(storyboards) == 0 {
+		return nil, fmt.Errorf("episode has no storyboards")
+	}
+
+	shooting := &screenplay.ShootingScript{Title: drama.Title, EpisodeTitle: episode.Title}
+	for _, sb := range storyboards {
+		shot := screenplay.Shot{
+			Number:   sb.StoryboardNumber,
+			Title:    getString(sb.Title),
+			Location: getString(sb.Location),
+			Time:     getString(sb.Time),
+			ShotType: getString(sb.ShotType),
+			Angle:    getString(sb.Angle),
+			Movement: getString(sb.Movement),
+			Action:   getString(sb.Action),
+			Dialogue: getString(sb.Dialogue),
+			Duration: sb.Duration,
+		}
+		if format == screenplay.FormatPDF {
+			if ref := s.firstFrameImage(&sb); ref != "" {
+				img, err := s.loadImage(ref)
+				if err != nil {
+					s.log.Warnw("Failed to load first frame for shooting script", "error", err, "storyboard_id", sb.ID, "image", ref)
+				}
+				shot.Image = img
+			}
+		}
+		shooting.Shots = append(shooting.Shots, shot)
+	}
+
+	if format == screenplay.FormatPDF {
+		return screenplay.WriteShootingScriptPDF(shooting)
+	}
+	return renderScreenplay(shooting.Document(), format)
+}
+
+func (s *ScreenplayService) loadEpisode(episodeID uint) (*models.Episode, *models.Drama, error) {
+	var episode models.Episode
+	if err := s.db.First(&episode, episodeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("episode not found")
+		}
+		return nil, nil, err
+	}
+	var drama models.Drama
+	if err := s.db.First(&drama, episode.DramaID).Error; err != nil {
+		return nil, nil, err
+	}
+	return &episode, &drama, nil
+}
+
+// firstFrameImage 镜头首帧图片：优先最新完成的首帧图片，其次最新完成的分镜图片，最后是合成图
+func (s *ScreenplayService) firstFrameImage(sb *models.Storyboard) string {
+	var gens []models.ImageGeneration
+	s.db.Where("storyboard_id = ? AND status = ?", sb.ID, models.ImageStatusCompleted).
+		Order("created_at DESC").Find(&gens)
+
+	ref := func(gen *models.ImageGeneration) string {
+		if gen.LocalPath != nil && *gen.LocalPath != "" {
+			return *gen.LocalPath
+		}
+		return getString(gen.ImageURL)
+	}
+	for i := range gens {
+		if gens[i].FrameType != nil && *gens[i].FrameType == string(FrameTypeFirst) {
+			if r := ref(&gens[i]); r != "" {
+				return r
+			}
+		}
+	}
+	for i := range gens {
+		if r := ref(&gens[i]); r != "" {
+			return r
+		}
+	}
+	return getString(sb.ComposedImage)
+}
+
+// maxExportImageSize 导出时下载单张图片的大小上限
+const maxExportImageSize = 20 << 20
+
+// loadImage 读取并解码图片：data URI、远程URL或本地存储
+func (s *ScreenplayService) loadImage(ref string) (image.Image, error) {
+	var data []byte
+	switch {
+	case strings.HasPrefix(ref, "data:"):
+		idx := strings.Index(ref, ",")
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid data uri")
+		}
+		decoded, err := base64.StdEncoding.DecodeString(ref[idx+1:])
+		if err != nil {
+			return nil, err
+		}
+		data = decoded
+	case strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://"):
+		if path := resolveMediaPath(s.storagePath, s.baseURL, ref); path != ref {
+			return s.loadImage(path)
+		}
+		client := &http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Get(ref)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+		}
+		if data, err = io.ReadAll(io.LimitReader(resp.Body, maxExportImageSize)); err != nil {
+			return nil, err
+		}
+	default:
+		var err error
+		if data, err = os.ReadFile(resolveMediaPath(s.storagePath, s.baseURL, ref)); err != nil {
+			return nil, err
+		}
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
+
+func renderScreenplay(doc *screenplay.Document, format screenplay.Format) ([]byte, error) {
+	switch format {
+	case screenplay.FormatFountain:
+		return screenplay.WriteFountain(doc), nil
+	case screenplay.FormatFDX:
+		return screenplay.WriteFDX(doc)
+	case screenplay.FormatPDF:
+		return screenplay.WritePDF(doc)
+	default:
+		return nil, fmt.Errorf("unsupported screenplay format: %s", format)
+	}
+}
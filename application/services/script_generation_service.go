@@ -46,6 +46,9 @@ func (s *ScriptGenerationService) GenerateCharacters(req *GenerateCharactersRequ
 	if err := s.db.Where("id = ? ", req.DramaID).First(&drama).Error; err != nil {
 		return "", fmt.Errorf("drama not found")
 	}
+	if req.Model == "" {
+		req.Model = loadDramaPreset(s.db, drama.ID).TextModel
+	}
 
 	// 创建任务
 	task, err := s.taskService.CreateTask("character_generation", req.DramaID)
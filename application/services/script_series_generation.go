@@ -0,0 +1,563 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/ai"
+	"github.com/drama-generator/backend/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// outlineBatchSize 每次请求规划的集数，长剧分批生成避免超出模型输出长度
+	outlineBatchSize = 20
+	// previousEndingRunes 上一集剧本结尾的截取长度，用于衔接
+	previousEndingRunes = 600
+)
+
+// GenerateOutlineRequest 生成分集大纲
+type GenerateOutlineRequest struct {
+	DramaID      string  `json:"drama_id" binding:"required"`
+	Theme        string  `json:"theme"`                                           // 为空时使用剧本的标题、简介与类型
+	EpisodeCount int     `json:"episode_count" binding:"omitempty,min=1,max=200"` // 为空时使用剧本的总集数
+	Genre        string  `json:"genre"`
+	Style        string  `json:"style"`
+	Overwrite    bool    `json:"overwrite"` // 覆盖剧本简介与已有章节的标题、概要
+	Temperature  float64 `json:"temperature"`
+	Model        string  `json:"model"`
+}
+
+// GenerateEpisodeScriptsRequest 按集数范围依次生成章节剧本
+type GenerateEpisodeScriptsRequest struct {
+	DramaID      string  `json:"drama_id" binding:"required"`
+	StartEpisode int     `json:"start_episode"` // 默认第一集
+	EndEpisode   int     `json:"end_episode"`   // 默认最后一集
+	Overwrite    bool    `json:"overwrite"`     // 重新生成已有剧本的章节
+	Temperature  float64 `json:"temperature"`
+	Model        string  `json:"model"`
+}
+
+type outlineResult struct {
+	Title    string           `json:"title"`
+	Summary  string           `json:"summary"`
+	Episodes []outlineEpisode `json:"episodes"`
+}
+
+type outlineEpisode struct {
+	EpisodeNumber int    `json:"episode_number"`
+	Title         string `json:"title"`
+	Summary       string `json:"summary"`
+	Conflict      string `json:"conflict"`
+	Cliffhanger   string `json:"cliffhanger"`
+}
+
+type episodeScriptResult struct {
+	Episodes []struct {
+		EpisodeNumber int    `json:"episode_number"`
+		Title         string `json:"title"`
+		ScriptContent string `json:"script_content"`
+		Summary       string `json:"summary"`
+	} `json:"episodes"`
+}
+
+// GenerateOutline 创建大纲生成任务：生成全剧梗概与每集的标题、概要、矛盾与悬念，
+// 梗概保存为剧本简介，分集规划保存为章节标题与描述（不存在的章节自动创建）
+func (s *ScriptGenerationService) GenerateOutline(req *GenerateOutlineRequest) (string, error) {
+	var drama models.Drama
+	if err := s.db.Where("id = ?", req.DramaID).First(&drama).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("drama not found")
+		}
+		return "", err
+	}
+	if req.Model == "" {
+		req.Model = loadDramaPreset(s.db, drama.ID).TextModel
+	}
+
+	task, err := s.taskService.CreateTask("outline_generation", req.DramaID)
+	if err != nil {
+		s.log.Errorw("Failed to create outline generation task", "error", err)
+		return "", fmt.Errorf("创建任务失败: %w", err)
+	}
+
+	go s.processOutlineGeneration(task.ID, &drama, req)
+
+	s.log.Infow("Outline generation task created", "task_id", task.ID, "drama_id", req.DramaID)
+	return task.ID, nil
+}
+
+func (s *ScriptGenerationService) processOutlineGeneration(taskID string, drama *models.Drama, req *GenerateOutlineRequest) {
+	s.taskService.UpdateTaskStatus(taskID, "processing", 0, "正在生成大纲...")
+
+	count := req.EpisodeCount
+	if count == 0 {
+		count = drama.TotalEpisodes
+	}
+	if count <= 0 {
+		count = 1
+	}
+	temperature := req.Temperature
+	if temperature == 0 {
+		temperature = 0.7
+	}
+
+	theme := req.Theme
+	if theme == "" {
+		theme = s.dramaInfo(drama)
+	}
+	genre := req.Genre
+	if genre == "" && drama.Genre != nil {
+		genre = *drama.Genre
+	}
+	style := req.Style
+	if style == "" {
+		style = drama.Style
+	}
+
+	base := s.promptI18n.FormatUserPrompt("outline_request", theme)
+	if genre != "" {
+		base += s.promptI18n.FormatUserPrompt("genre_preference", genre)
+	}
+	if style != "" {
+		base += s.promptI18n.FormatUserPrompt("style_requirement", style)
+	}
+	base += s.promptI18n.FormatUserPrompt("episode_count", count)
+
+	var characters []models.Character
+	s.db.Where("drama_id = ?", drama.ID).Order("sort_order ASC, id ASC").Find(&characters)
+	if bios := s.characterBios(characters); bios != "" {
+		base += "\n\n" + bios
+	}
+
+	outline := &outlineResult{}
+	for start := 1; start <= count; start += outlineBatchSize {
+		if s.taskService.IsTaskCancelled(taskID) {
+			return
+		}
+		end := min(start+outlineBatchSize-1, count)
+
+		userPrompt := base
+		if start == 1 {
+			userPrompt += s.promptI18n.FormatUserPrompt("outline_summary_field")
+			if end == count {
+				userPrompt += s.promptI18n.FormatUserPrompt("episode_importance", count)
+			} else {
+				userPrompt += s.promptI18n.FormatUserPrompt("outline_batch", start, end)
+			}
+		} else {
+			userPrompt += s.promptI18n.FormatUserPrompt("outline_continuation", s.plannedEpisodes(outline), start, end)
+		}
+
+		text, err := s.aiService.GenerateTextWithModel(req.Model, userPrompt, s.promptI18n.GetOutlineGenerationPrompt(), ai.WithTemperature(temperature))
+		if err != nil {
+			s.log.Errorw("Failed to generate outline", "error", err, "task_id", taskID, "start", start)
+			s.taskService.UpdateTaskError(taskID, fmt.Errorf("AI生成失败: %w", err))
+			return
+		}
+
+		var batch outlineResult
+		if err := utils.SafeParseAIJSON(text, &batch); err != nil {
+			s.log.Errorw("Failed to parse outline JSON", "error", err, "raw_response", text[:minInt(500, len(text))], "task_id", taskID)
+			s.taskService.UpdateTaskError(taskID, fmt.Errorf("解析AI返回结果失败: %w", err))
+			return
+		}
+		if outline.Title == "" {
+			outline.Title = batch.Title
+		}
+		if outline.Summary == "" {
+			outline.Summary = batch.Summary
+		}
+		for i, ep := range batch.Episodes {
+			// 模型漏填集数时按顺序补齐
+			if ep.EpisodeNumber < start || ep.EpisodeNumber > end {
+				ep.EpisodeNumber = start + i
+			}
+			if ep.EpisodeNumber <= end {
+				outline.Episodes = append(outline.Episodes, ep)
+			}
+		}
+
+		progress := end * 90 / count
+		s.taskService.UpdateTaskStatus(taskID, "processing", progress, fmt.Sprintf("已规划 %d/%d 集", end, count))
+	}
+
+	if len(outline.Episodes) == 0 {
+		s.taskService.UpdateTaskError(taskID, fmt.Errorf("AI未返回任何分集规划"))
+		return
+	}
+
+	episodes, err := s.saveOutline(drama, outline, req.Overwrite)
+	if err != nil {
+		s.log.Errorw("Failed to save outline", "error", err, "task_id", taskID)
+		s.taskService.UpdateTaskError(taskID, fmt.Errorf("保存大纲失败: %w", err))
+		return
+	}
+
+	s.taskService.UpdateTaskResult(taskID, map[string]interface{}{
+		"title":    outline.Title,
+		"summary":  outline.Summary,
+		"episodes": episodes,
+		"count":    len(episodes),
+	})
+	s.log.Infow("Outline generation completed", "task_id", taskID, "drama_id", drama.ID, "episodes", len(episodes))
+}
+
+// saveOutline 保存梗概与分集规划：已有章节仅在 overwrite 或字段为空时更新，缺少的章节按集数创建
+func (s *ScriptGenerationService) saveOutline(drama *models.Drama, outline *outlineResult, overwrite bool) ([]models.Episode, error) {
+	var episodes []models.Episode
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"updated_at": time.Now()}
+		if outline.Summary != "" && (overwrite || drama.Description == nil || *drama.Description == "") {
+			updates["description"] = outline.Summary
+		}
+		last := outline.Episodes[len(outline.Episodes)-1].EpisodeNumber
+		if last > drama.TotalEpisodes {
+			updates["total_episodes"] = last
+		}
+		if err := tx.Model(drama).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		for _, plan := range outline.Episodes {
+			description := s.planDescription(plan)
+
+			var episode models.Episode
+			err := tx.Where("drama_id = ? AND episode_number = ?", drama.ID, plan.EpisodeNumber).First(&episode).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				title := plan.Title
+				if title == "" {
+					title = fmt.Sprintf("第%d集", plan.EpisodeNumber)
+				}
+				episode = models.Episode{
+					DramaID:     drama.ID,
+					EpisodeNum:  plan.EpisodeNumber,
+					Title:       title,
+					Description: &description,
+					Status:      "draft",
+				}
+				if err := tx.Create(&episode).Error; err != nil {
+					return err
+				}
+				episodes = append(episodes, episode)
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			epUpdates := map[string]interface{}{}
+			if plan.Title != "" && overwrite {
+				epUpdates["title"] = plan.Title
+			}
+			if overwrite || episode.Description == nil || *episode.Description == "" {
+				epUpdates["description"] = description
+			}
+			if len(epUpdates) > 0 {
+				if err := tx.Model(&episode).Updates(epUpdates).Error; err != nil {
+					return err
+				}
+			}
+			episodes = append(episodes, episode)
+		}
+		return nil
+	})
+	return episodes, err
+}
+
+// planDescription 分集规划写入章节描述：概要、主要矛盾、悬念
+func (s *ScriptGenerationService) planDescription(plan outlineEpisode) string {
+	lines := []string{strings.TrimSpace(plan.Summary)}
+	if plan.Conflict != "" {
+		lines = append(lines, s.promptI18n.FormatUserPrompt("episode_conflict_label", plan.Conflict))
+	}
+	if plan.Cliffhanger != "" {
+		lines = append(lines, s.promptI18n.FormatUserPrompt("cliffhanger_label", plan.Cliffhanger))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// plannedEpisodes 已规划的剧集，分批生成时作为后续批次的上下文
+func (s *ScriptGenerationService) plannedEpisodes(outline *outlineResult) string {
+	var sb strings.Builder
+	if outline.Summary != "" {
+		sb.WriteString(outline.Summary + "\n")
+	}
+	for _, ep := range outline.Episodes {
+		sb.WriteString(s.promptI18n.FormatUserPrompt("outline_episode_line", ep.EpisodeNumber, ep.Title, ep.Summary) + "\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// GenerateEpisodeScripts 创建章节剧本生成任务：按集数顺序逐集生成，每集都带上剧本大纲、
+// 角色设定、前面各集的剧情摘要与上一集结尾，保证长剧的情节与角色名前后一致
+func (s *ScriptGenerationService) GenerateEpisodeScripts(req *GenerateEpisodeScriptsRequest) (string, error) {
+	var drama models.Drama
+	if err := s.db.Where("id = ?", req.DramaID).First(&drama).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("drama not found")
+		}
+		return "", err
+	}
+	if req.Model == "" {
+		req.Model = loadDramaPreset(s.db, drama.ID).TextModel
+	}
+
+	query := s.db.Where("drama_id = ?", drama.ID)
+	if req.StartEpisode > 0 {
+		query = query.Where("episode_number >= ?", req.StartEpisode)
+	}
+	if req.EndEpisode > 0 {
+		query = query.Where("episode_number <= ?", req.EndEpisode)
+	}
+	var episodes []models.Episode
+	if err := query.Order("episode_number ASC").Find(&episodes).Error; err != nil {
+		return "", err
+	}
+	if !req.Overwrite {
+		pending := episodes[:0]
+		for _, ep := range episodes {
+			if ep.ScriptContent == nil || strings.TrimSpace(*ep.ScriptContent) == "" {
+				pending = append(pending, ep)
+			}
+		}
+		episodes = pending
+	}
+	if len(episodes) == 0 {
+		return "", fmt.Errorf("no episodes to generate")
+	}
+
+	task, err := s.taskService.CreateTask("episode_script_generation", req.DramaID)
+	if err != nil {
+		s.log.Errorw("Failed to create episode script generation task", "error", err)
+		return "", fmt.Errorf("创建任务失败: %w", err)
+	}
+
+	go s.processEpisodeScripts(task.ID, &drama, episodes, req)
+
+	s.log.Infow("Episode script generation task created", "task_id", task.ID, "drama_id", req.DramaID, "episodes", len(episodes))
+	return task.ID, nil
+}
+
+// GenerateEpisodeScript 重新生成单个章节的剧本
+func (s *ScriptGenerationService) GenerateEpisodeScript(episodeID uint, model string, temperature float64) (string, error) {
+	var episode models.Episode
+	if err := s.db.First(&episode, episodeID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", fmt.Errorf("episode not found")
+		}
+		return "", err
+	}
+	return s.GenerateEpisodeScripts(&GenerateEpisodeScriptsRequest{
+		DramaID:      fmt.Sprintf("%d", episode.DramaID),
+		StartEpisode: episode.EpisodeNum,
+		EndEpisode:   episode.EpisodeNum,
+		Overwrite:    true,
+		Temperature:  temperature,
+		Model:        model,
+	})
+}
+
+func (s *ScriptGenerationService) processEpisodeScripts(taskID string, drama *models.Drama, targets []models.Episode, req *GenerateEpisodeScriptsRequest) {
+	s.taskService.UpdateTaskStatus(taskID, "processing", 0, "正在生成剧本...")
+
+	temperature := req.Temperature
+	if temperature == 0 {
+		temperature = 0.7
+	}
+
+	var characters []models.Character
+	s.db.Where("drama_id = ?", drama.ID).Order("sort_order ASC, id ASC").Find(&characters)
+
+	var generated []map[string]interface{}
+	for i, target := range targets {
+		if s.taskService.IsTaskCancelled(taskID) {
+			return
+		}
+		s.taskService.UpdateTaskStatus(taskID, "processing", i*100/len(targets),
+			fmt.Sprintf("正在生成第%d集剧本（%d/%d）", target.EpisodeNum, i+1, len(targets)))
+
+		// 每集重新读取全部章节，前面刚生成的剧本也作为前情
+		var episodes []models.Episode
+		if err := s.db.Where("drama_id = ?", drama.ID).Order("episode_number ASC").Find(&episodes).Error; err != nil {
+			s.taskService.UpdateTaskError(taskID, err)
+			return
+		}
+
+		userPrompt := s.buildContinuityContext(drama, characters, episodes, target.EpisodeNum, req.Model) +
+			s.promptI18n.FormatUserPrompt("single_episode_request", target.EpisodeNum)
+
+		text, err := s.aiService.GenerateTextWithModel(req.Model, userPrompt, s.promptI18n.GetEpisodeScriptPrompt(), ai.WithTemperature(temperature))
+		if err != nil {
+			s.log.Errorw("Failed to generate episode script", "error", err, "task_id", taskID, "episode", target.EpisodeNum)
+			s.taskService.UpdateTaskError(taskID, fmt.Errorf("第%d集AI生成失败: %w", target.EpisodeNum, err))
+			return
+		}
+
+		var result episodeScriptResult
+		if err := utils.SafeParseAIJSON(text, &result); err != nil || len(result.Episodes) == 0 || strings.TrimSpace(result.Episodes[0].ScriptContent) == "" {
+			s.log.Errorw("Failed to parse episode script JSON", "error", err, "raw_response", text[:minInt(500, len(text))], "task_id", taskID)
+			s.taskService.UpdateTaskError(taskID, fmt.Errorf("第%d集解析AI返回结果失败", target.EpisodeNum))
+			return
+		}
+		ep := result.Episodes[0]
+		script := strings.TrimSpace(ep.ScriptContent)
+
+		updates := map[string]interface{}{"script_content": script}
+		if ep.Title != "" && (target.Title == "" || target.Title == fmt.Sprintf("第%d集", target.EpisodeNum)) {
+			updates["title"] = ep.Title
+		}
+		if err := s.db.Model(&target).Updates(updates).Error; err != nil {
+			s.taskService.UpdateTaskError(taskID, fmt.Errorf("保存第%d集剧本失败: %w", target.EpisodeNum, err))
+			return
+		}
+		if summary := strings.TrimSpace(ep.Summary); summary != "" {
+			s.saveEpisodeSummary(target.ID, script, summary)
+		}
+
+		generated = append(generated, map[string]interface{}{
+			"id":             target.ID,
+			"episode_number": target.EpisodeNum,
+			"title":          target.Title,
+		})
+	}
+
+	s.taskService.UpdateTaskResult(taskID, map[string]interface{}{
+		"episodes": generated,
+		"count":    len(generated),
+	})
+	s.log.Infow("Episode script generation completed", "task_id", taskID, "drama_id", drama.ID, "count", len(generated))
+}
+
+// buildContinuityContext 生成第 episodeNum 集时的上下文：大纲、角色设定、前情提要、上一集结尾、本集与下一集规划
+func (s *ScriptGenerationService) buildContinuityContext(drama *models.Drama, characters []models.Character, episodes []models.Episode, episodeNum int, model string) string {
+	var sections []string
+
+	sections = append(sections, s.promptI18n.FormatUserPrompt("series_outline_label")+"\n"+s.dramaInfo(drama))
+	if bios := s.characterBios(characters); bios != "" {
+		sections = append(sections, bios)
+	}
+
+	var previously []string
+	var previous, current, next *models.Episode
+	for i := range episodes {
+		ep := &episodes[i]
+		switch {
+		case ep.EpisodeNum < episodeNum:
+			summary := ""
+			if ep.ScriptContent != nil && strings.TrimSpace(*ep.ScriptContent) != "" {
+				summary = s.episodeSummary(ep, model)
+				previous = ep
+			}
+			if summary == "" {
+				// 尚未写剧本的章节使用分集规划
+				summary = getString(ep.Description)
+			}
+			if summary != "" {
+				previously = append(previously, s.promptI18n.FormatUserPrompt("outline_episode_line", ep.EpisodeNum, ep.Title, summary))
+			}
+		case ep.EpisodeNum == episodeNum:
+			current = ep
+		case next == nil:
+			next = ep
+		}
+	}
+	if len(previously) > 0 {
+		sections = append(sections, s.promptI18n.FormatUserPrompt("previously_label")+"\n"+strings.Join(previously, "\n"))
+	}
+	if previous != nil && previous.EpisodeNum == episodeNum-1 {
+		sections = append(sections, s.promptI18n.FormatUserPrompt("previous_ending_label")+"\n"+tailRunes(strings.TrimSpace(*previous.ScriptContent), previousEndingRunes))
+	}
+	if current != nil {
+		sections = append(sections, s.promptI18n.FormatUserPrompt("episode_plan_label", episodeNum)+"\n"+
+			strings.TrimSpace(current.Title+"\n"+getString(current.Description)))
+	}
+	if next != nil && getString(next.Description) != "" {
+		sections = append(sections, s.promptI18n.FormatUserPrompt("next_plan_label")+"\n"+
+			s.promptI18n.FormatUserPrompt("outline_episode_line", next.EpisodeNum, next.Title, getString(next.Description)))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+func (s *ScriptGenerationService) dramaInfo(drama *models.Drama) string {
+	return s.promptI18n.FormatUserPrompt("drama_info_template", drama.Title, getString(drama.Description), getString(drama.Genre))
+}
+
+// characterBios 角色设定列表
+func (s *ScriptGenerationService) characterBios(characters []models.Character) string {
+	if len(characters) == 0 {
+		return ""
+	}
+	lines := []string{s.promptI18n.FormatUserPrompt("character_bios_label")}
+	for _, c := range characters {
+		bio := strings.Join(nonEmptyStrings(getString(c.Personality), getString(c.Description)), " ")
+		role := getString(c.Role)
+		if role == "" {
+			role = "-"
+		}
+		lines = append(lines, s.promptI18n.FormatUserPrompt("character_bio_line", c.Name, role, bio))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// episodeSummary 返回章节剧情摘要，剧本变化后重新生成；生成失败时返回空字符串
+func (s *ScriptGenerationService) episodeSummary(ep *models.Episode, model string) string {
+	script := strings.TrimSpace(getString(ep.ScriptContent))
+	hash := scriptHash(script)
+
+	var cached models.EpisodeSummary
+	if err := s.db.Where("episode_id = ?", ep.ID).First(&cached).Error; err == nil && cached.ScriptHash == hash {
+		return cached.Summary
+	}
+
+	text, err := s.aiService.GenerateTextWithModel(model, s.promptI18n.FormatUserPrompt("episode_summary_prompt", script), "", ai.WithTemperature(0.3))
+	if err != nil {
+		s.log.Warnw("Failed to summarize episode", "error", err, "episode_id", ep.ID)
+		return ""
+	}
+	summary := strings.TrimSpace(text)
+	s.saveEpisodeSummary(ep.ID, script, summary)
+	return summary
+}
+
+func (s *ScriptGenerationService) saveEpisodeSummary(episodeID uint, script, summary string) {
+	record := models.EpisodeSummary{EpisodeID: episodeID, Summary: summary, ScriptHash: scriptHash(script)}
+	err := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "episode_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"summary", "script_hash", "updated_at"}),
+	}).Create(&record).Error
+	if err != nil {
+		s.log.Warnw("Failed to save episode summary", "error", err, "episode_id", episodeID)
+	}
+}
+
+func scriptHash(script string) string {
+	sum := sha256.Sum256([]byte(script))
+	return hex.EncodeToString(sum[:])
+}
+
+// tailRunes 截取末尾 n 个字符
+func tailRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	runes := []rune(s)
+	return "……" + string(runes[len(runes)-n:])
+}
+
+func nonEmptyStrings(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
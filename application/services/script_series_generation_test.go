@@ -0,0 +1,289 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/ai"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// stubTextAI 启动 OpenAI 兼容的文本模型桩并登记为默认文本配置
+// respond 返回模型输出，返回错误时桩服务响应 500；返回的函数给出迄今收到的全部请求
+func stubTextAI(t *testing.T, db *gorm.DB, respond func(req ai.ChatCompletionRequest) (string, error)) func() []ai.ChatCompletionRequest {
+	t.Helper()
+	var mu sync.Mutex
+	var requests []ai.ChatCompletionRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ai.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		requests = append(requests, req)
+		mu.Unlock()
+
+		content, err := respond(req)
+		if err != nil {
+			http.Error(w, `{"error":{"message":"`+err.Error()+`"}}`, http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{
+				"message":       map[string]string{"role": "assistant", "content": content},
+				"finish_reason": "stop",
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	config := &models.AIServiceConfig{ServiceType: "text", Provider: "openai", Name: "stub", BaseURL: server.URL,
+		APIKey: "test", Model: models.ModelField{"stub-model"}, IsActive: true}
+	mustCreate(t, db, config)
+
+	return func() []ai.ChatCompletionRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]ai.ChatCompletionRequest(nil), requests...)
+	}
+}
+
+// userPrompt 请求中的用户消息
+func userPrompt(req ai.ChatCompletionRequest) string {
+	for _, msg := range req.Messages {
+		if msg.Role == "user" {
+			return msg.Content
+		}
+	}
+	return ""
+}
+
+func TestOutlineGenerationInBatches(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		drama, episode := createTestEpisode(t, db)
+		planned := "already planned"
+		if err := db.Model(episode).Update("description", planned).Error; err != nil {
+			t.Fatalf("set episode description: %v", err)
+		}
+		personality := "reckless"
+		mustCreate(t, db, &models.Character{DramaID: drama.ID, Name: "Ling", Personality: &personality})
+
+		// 第一批 1-20 集，第二批漏填集数，按顺序补齐为 21-25 集
+		batch := 0
+		requests := stubTextAI(t, db, func(req ai.ChatCompletionRequest) (string, error) {
+			var episodes []map[string]interface{}
+			batch++
+			if batch == 1 {
+				for i := 1; i <= outlineBatchSize; i++ {
+					episodes = append(episodes, map[string]interface{}{"episode_number": i, "title": fmt.Sprintf("T%d", i), "summary": fmt.Sprintf("plan %d", i)})
+				}
+				out, _ := json.Marshal(map[string]interface{}{"title": "Series", "summary": "A heist on rails", "episodes": episodes})
+				return string(out), nil
+			}
+			for i := 0; i < 5; i++ {
+				episodes = append(episodes, map[string]interface{}{"title": fmt.Sprintf("Late %d", i), "summary": "late plan"})
+			}
+			out, _ := json.Marshal(map[string]interface{}{"episodes": episodes})
+			return string(out), nil
+		})
+
+		service := NewScriptGenerationService(db, testConfig(t), logger.NewLogger(false))
+		task, err := service.taskService.CreateTask("outline_generation", fmt.Sprint(drama.ID))
+		if err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+		service.processOutlineGeneration(task.ID, drama, &GenerateOutlineRequest{EpisodeCount: 25})
+
+		reqs := requests()
+		if len(reqs) != 2 {
+			t.Fatalf("expected 2 outline batches, got %d", len(reqs))
+		}
+		if bio := service.promptI18n.FormatUserPrompt("character_bio_line", "Ling", "-", "reckless"); !strings.Contains(userPrompt(reqs[0]), bio) {
+			t.Fatalf("character bios missing from outline prompt:\n%s", userPrompt(reqs[0]))
+		}
+		if line := service.promptI18n.FormatUserPrompt("outline_episode_line", 20, "T20", "plan 20"); !strings.Contains(userPrompt(reqs[1]), line) {
+			t.Fatalf("second batch missing planned episodes:\n%s", userPrompt(reqs[1]))
+		}
+		if reqs[0].Temperature != 0.7 {
+			t.Fatalf("expected default temperature 0.7, got %v", reqs[0].Temperature)
+		}
+
+		saved, _ := service.taskService.GetTask(task.ID)
+		if saved.Status != "completed" || !strings.Contains(saved.Result, `"count":25`) {
+			t.Fatalf("unexpected task state %q result %s error %s", saved.Status, saved.Result, saved.Error)
+		}
+		var reloaded models.Drama
+		db.First(&reloaded, drama.ID)
+		if getString(reloaded.Description) != "A heist on rails" || reloaded.TotalEpisodes != 25 {
+			t.Fatalf("drama outline not saved: %q, %d episodes", getString(reloaded.Description), reloaded.TotalEpisodes)
+		}
+		// 未开启 overwrite 时保留已有章节的规划
+		var first, last models.Episode
+		db.Where("drama_id = ? AND episode_number = ?", drama.ID, 1).First(&first)
+		db.Where("drama_id = ? AND episode_number = ?", drama.ID, 25).First(&last)
+		if getString(first.Description) != planned || first.Title != "Departure" {
+			t.Fatalf("existing episode overwritten: %q %q", first.Title, getString(first.Description))
+		}
+		if last.Title != "Late 4" {
+			t.Fatalf("expected episode 25 from second batch, got %q", last.Title)
+		}
+	})
+}
+
+func TestOutlineGenerationFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		respond func(ai.ChatCompletionRequest) (string, error)
+		want    string
+	}{
+		{"ai error", func(ai.ChatCompletionRequest) (string, error) { return "", fmt.Errorf("overloaded") }, "AI生成失败"},
+		{"invalid json", func(ai.ChatCompletionRequest) (string, error) { return "no outline today", nil }, "解析AI返回结果失败"},
+		{"no episodes", func(ai.ChatCompletionRequest) (string, error) { return `{"title":"x","episodes":[]}`, nil }, "AI未返回任何分集规划"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+				drama, _ := createTestEpisode(t, db)
+				stubTextAI(t, db, tt.respond)
+
+				service := NewScriptGenerationService(db, testConfig(t), logger.NewLogger(false))
+				task, err := service.taskService.CreateTask("outline_generation", fmt.Sprint(drama.ID))
+				if err != nil {
+					t.Fatalf("create task: %v", err)
+				}
+				service.processOutlineGeneration(task.ID, drama, &GenerateOutlineRequest{EpisodeCount: 3})
+
+				saved, _ := service.taskService.GetTask(task.ID)
+				if saved.Status != "failed" || !strings.Contains(saved.Error, tt.want) {
+					t.Fatalf("expected failed task with %q, got %q: %s", tt.want, saved.Status, saved.Error)
+				}
+				var count int64
+				db.Model(&models.Episode{}).Where("drama_id = ?", drama.ID).Count(&count)
+				if count != 1 {
+					t.Fatalf("failed outline should not create episodes, got %d", count)
+				}
+			})
+		})
+	}
+}
+
+func TestEpisodeScriptsCarryContinuity(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		drama, first := createTestEpisode(t, db)
+		firstScript := "Ling boards the train. THE END OF ONE"
+		if err := db.Model(first).Update("script_content", firstScript).Error; err != nil {
+			t.Fatalf("set script: %v", err)
+		}
+		// 摘要对应的是旧剧本，需要重新生成
+		mustCreate(t, db, &models.EpisodeSummary{EpisodeID: first.ID, Summary: "stale", ScriptHash: scriptHash("old script")})
+		plan := "The heist begins"
+		second := &models.Episode{DramaID: drama.ID, EpisodeNum: 2, Title: "第2集", Description: &plan}
+		third := &models.Episode{DramaID: drama.ID, EpisodeNum: 3, Title: "Arrival"}
+		mustCreate(t, db, second, third)
+
+		service := NewScriptGenerationService(db, testConfig(t), logger.NewLogger(false))
+		requests := stubTextAI(t, db, func(req ai.ChatCompletionRequest) (string, error) {
+			if req.Messages[0].Role != "system" {
+				return "Ling boarded the train.", nil
+			}
+			num := 2
+			if strings.Contains(userPrompt(req), service.promptI18n.FormatUserPrompt("single_episode_request", 3)) {
+				num = 3
+			}
+			out, _ := json.Marshal(map[string]interface{}{"episodes": []map[string]interface{}{{
+				"episode_number": num, "title": fmt.Sprintf("Generated %d", num),
+				"script_content": fmt.Sprintf("Script %d. THE END OF %d", num, num), "summary": fmt.Sprintf("summary %d", num),
+			}}})
+			return string(out), nil
+		})
+
+		task, err := service.taskService.CreateTask("episode_script_generation", fmt.Sprint(drama.ID))
+		if err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+		service.processEpisodeScripts(task.ID, drama, []models.Episode{*second, *third}, &GenerateEpisodeScriptsRequest{})
+
+		reqs := requests()
+		if len(reqs) != 3 {
+			t.Fatalf("expected summary + 2 script requests, got %d", len(reqs))
+		}
+		if reqs[0].Temperature != 0.3 || !strings.Contains(userPrompt(reqs[0]), firstScript) {
+			t.Fatalf("expected stale summary to be regenerated from the script, got %+v", reqs[0])
+		}
+
+		// 第 2 集：新摘要作为前情，附上一集结尾与本集规划
+		secondPrompt := userPrompt(reqs[1])
+		for _, want := range []string{
+			service.promptI18n.FormatUserPrompt("outline_episode_line", 1, "Departure", "Ling boarded the train."),
+			"THE END OF ONE",
+			plan,
+		} {
+			if !strings.Contains(secondPrompt, want) {
+				t.Fatalf("episode 2 prompt missing %q:\n%s", want, secondPrompt)
+			}
+		}
+		// 第 3 集：刚生成的第 2 集使用保存的摘要，不再请求摘要
+		thirdPrompt := userPrompt(reqs[2])
+		if !strings.Contains(thirdPrompt, "summary 2") || !strings.Contains(thirdPrompt, "THE END OF 2") {
+			t.Fatalf("episode 3 prompt missing episode 2 context:\n%s", thirdPrompt)
+		}
+
+		var summaries []models.EpisodeSummary
+		db.Order("episode_id").Find(&summaries)
+		if len(summaries) != 3 || summaries[0].Summary != "Ling boarded the train." || summaries[0].ScriptHash != scriptHash(firstScript) {
+			t.Fatalf("unexpected summaries: %+v", summaries)
+		}
+		var reloaded models.Episode
+		db.First(&reloaded, second.ID)
+		if reloaded.Title != "Generated 2" || getString(reloaded.ScriptContent) != "Script 2. THE END OF 2" {
+			t.Fatalf("episode 2 not saved: %q %q", reloaded.Title, getString(reloaded.ScriptContent))
+		}
+		var kept models.Episode
+		db.First(&kept, third.ID)
+		if kept.Title != "Arrival" {
+			t.Fatalf("custom title should be kept, got %q", kept.Title)
+		}
+		saved, _ := service.taskService.GetTask(task.ID)
+		if saved.Status != "completed" || !strings.Contains(saved.Result, `"count":2`) {
+			t.Fatalf("unexpected task state %q result %s", saved.Status, saved.Result)
+		}
+	})
+}
+
+func TestEpisodeScriptsStopOnUnparsableEpisode(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		drama, episode := createTestEpisode(t, db)
+		requests := stubTextAI(t, db, func(ai.ChatCompletionRequest) (string, error) {
+			return `{"episodes":[{"episode_number":1,"script_content":"  "}]}`, nil
+		})
+
+		service := NewScriptGenerationService(db, testConfig(t), logger.NewLogger(false))
+		task, err := service.taskService.CreateTask("episode_script_generation", fmt.Sprint(drama.ID))
+		if err != nil {
+			t.Fatalf("create task: %v", err)
+		}
+		second := models.Episode{DramaID: drama.ID, EpisodeNum: 2, Title: "Next"}
+		service.processEpisodeScripts(task.ID, drama, []models.Episode{*episode, second}, &GenerateEpisodeScriptsRequest{})
+
+		if n := len(requests()); n != 1 {
+			t.Fatalf("expected generation to stop after the first episode, got %d requests", n)
+		}
+		saved, _ := service.taskService.GetTask(task.ID)
+		if saved.Status != "failed" || !strings.Contains(saved.Error, "第1集解析AI返回结果失败") {
+			t.Fatalf("expected parse failure for episode 1, got %q: %s", saved.Status, saved.Error)
+		}
+		var reloaded models.Episode
+		db.First(&reloaded, episode.ID)
+		if reloaded.ScriptContent != nil {
+			t.Fatalf("blank script should not be saved, got %q", *reloaded.ScriptContent)
+		}
+	})
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// 隔离目录（相对存储根目录），孤儿文件先移入此处，超过保留期后删除
+const storageQuarantineDir = ".quarantine"
+
+// storageGCMu 同一时间只允许一个清理任务
+var storageGCMu sync.Mutex
+
+// errStorageGCLocalOnly 非本地存储时拒绝清理
+var errStorageGCLocalOnly = errors.New("storage gc only supports local storage")
+
+// StorageGCService 清理存储目录中不再被数据库引用的文件
+// 仅支持本地存储：通过遍历本地文件系统查找孤儿文件，S3/MinIO 等对象存储请使用存储桶的生命周期规则清理
+type StorageGCService struct {
+	db          *gorm.DB
+	storageType string
+	storagePath string
+	baseURL     string
+	taskService *TaskService
+	log         *logger.Logger
+}
+
+// StorageGCOptions 清理参数
+type StorageGCOptions struct {
+	DryRun         bool `json:"dry_run"`
+	MinAgeDays     int  `json:"min_age_days"`     // 只隔离修改时间早于 N 天的孤儿文件，默认 7
+	PurgeAfterDays int  `json:"purge_after_days"` // 隔离超过 N 天后删除，默认 30
+	MaxListed      int  `json:"max_listed"`       // 报告中列出的孤儿文件上限，默认 500
+}
+
+// StorageGCFile 孤儿文件
+type StorageGCFile struct {
+	Key     string    `json:"key"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mod_time"`
+}
+
+// StorageGCReport 清理报告
+type StorageGCReport struct {
+	DryRun           bool            `json:"dry_run"`
+	ScannedFiles     int             `json:"scanned_files"`
+	ScannedBytes     int64           `json:"scanned_bytes"`
+	ReferencedFiles  int             `json:"referenced_files"`
+	OrphanFiles      int             `json:"orphan_files"`
+	OrphanBytes      int64           `json:"orphan_bytes"`
+	QuarantinedFiles int             `json:"quarantined_files"` // 本次移入隔离目录（dry-run 时为将要移入）的文件数
+	QuarantinedBytes int64           `json:"quarantined_bytes"`
+	PurgedFiles      int             `json:"purged_files"` // 本次从隔离目录删除（dry-run 时为将要删除）的文件数
+	PurgedBytes      int64           `json:"purged_bytes"`
+	Orphans          []StorageGCFile `json:"orphans"`
+	Truncated        bool            `json:"truncated"`
+}
+
+func NewStorageGCService(db *gorm.DB, cfg *config.Config, log *logger.Logger) *StorageGCService {
+	return &StorageGCService{
+		db:          db,
+		storageType: cfg.Storage.Type,
+		storagePath: cfg.Storage.LocalPath,
+		baseURL:     strings.TrimRight(cfg.Storage.BaseURL, "/"),
+		taskService: NewTaskService(db, log),
+		log:         log,
+	}
+}
+
+// checkLocalStorage 清理只扫描本地存储目录，配置为对象存储时本地目录只是临时工作区，不能据此判断孤儿文件
+func (s *StorageGCService) checkLocalStorage() error {
+	switch s.storageType {
+	case "", "local":
+		return nil
+	}
+	return errStorageGCLocalOnly
+}
+
+// StartGC 以异步任务方式执行清理，返回任务ID
+func (s *StorageGCService) StartGC(opts StorageGCOptions) (string, error) {
+	if err := s.checkLocalStorage(); err != nil {
+		return "", err
+	}
+	task, err := s.taskService.CreateTask("storage_gc", "storage")
+	if err != nil {
+		return "", fmt.Errorf("failed to create task: %w", err)
+	}
+
+	go func() {
+		s.taskService.UpdateTaskStatus(task.ID, "processing", 10, "正在扫描存储目录")
+		report, err := s.Run(opts)
+		if err != nil {
+			s.log.Errorw("Storage GC failed", "task_id", task.ID, "error", err)
+			s.taskService.UpdateTaskError(task.ID, err)
+			return
+		}
+		s.taskService.UpdateTaskResult(task.ID, report)
+	}()
+
+	return task.ID, nil
+}
+
+// Run 扫描存储目录，将孤儿文件移入隔离目录，并删除隔离期满的文件
+func (s *StorageGCService) Run(opts StorageGCOptions) (*StorageGCReport, error) {
+	if err := s.checkLocalStorage(); err != nil {
+		return nil, err
+	}
+	if !storageGCMu.TryLock() {
+		return nil, fmt.Errorf("storage gc already running")
+	}
+	defer storageGCMu.Unlock()
+
+	if opts.MinAgeDays <= 0 {
+		opts.MinAgeDays = 7
+	}
+	if opts.PurgeAfterDays <= 0 {
+		opts.PurgeAfterDays = 30
+	}
+	if opts.MaxListed <= 0 {
+		opts.MaxListed = 500
+	}
+
+	refs, refDirs, err := s.collectReferences()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &StorageGCReport{DryRun: opts.DryRun, Orphans: []StorageGCFile{}}
+	now := time.Now()
+	quarantineCutoff := now.AddDate(0, 0, -opts.MinAgeDays)
+	quarantineRoot := filepath.Join(s.storagePath, storageQuarantineDir, now.Format("20060102"))
+
+	err = filepath.Walk(s.storagePath, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		// 跳过隔离目录、临时文件等隐藏项
+		if strings.HasPrefix(info.Name(), ".") && p != s.storagePath {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.storagePath, p)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		report.ScannedFiles++
+		report.ScannedBytes += info.Size()
+
+		if refs[key] || underDir(key, refDirs) {
+			report.ReferencedFiles++
+			return nil
+		}
+
+		report.OrphanFiles++
+		report.OrphanBytes += info.Size()
+		if len(report.Orphans) < opts.MaxListed {
+			report.Orphans = append(report.Orphans, StorageGCFile{Key: key, Size: info.Size(), ModTime: info.ModTime()})
+		} else {
+			report.Truncated = true
+		}
+
+		if info.ModTime().After(quarantineCutoff) {
+			return nil
+		}
+		if !opts.DryRun {
+			if err := s.quarantine(p, filepath.Join(quarantineRoot, rel), now); err != nil {
+				s.log.Warnw("Failed to quarantine file", "key", key, "error", err)
+				return nil
+			}
+			s.forgetBlob(key)
+		}
+		report.QuarantinedFiles++
+		report.QuarantinedBytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan storage: %w", err)
+	}
+
+	if err := s.purgeQuarantine(now.AddDate(0, 0, -opts.PurgeAfterDays), opts.DryRun, report); err != nil {
+		return nil, err
+	}
+
+	s.log.Infow("Storage GC finished",
+		"dry_run", opts.DryRun,
+		"scanned", report.ScannedFiles,
+		"orphans", report.OrphanFiles,
+		"quarantined", report.QuarantinedFiles,
+		"purged", report.PurgedFiles)
+	return report, nil
+}
+
+// quarantine 移动文件到隔离目录，并以修改时间记录隔离时间
+func (s *StorageGCService) quarantine(src, dst string, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return err
+	}
+	return os.Chtimes(dst, now, now)
+}
+
+// forgetBlob 删除被隔离文件的去重记录，避免后续复用不存在的文件
+func (s *StorageGCService) forgetBlob(key string) {
+	var hashes []string
+	if err := s.db.Model(&models.StorageBlob{}).Where("storage_key = ?", key).Pluck("hash", &hashes).Error; err != nil || len(hashes) == 0 {
+		return
+	}
+	s.db.Where("blob_hash IN ?", hashes).Delete(&models.StorageURLCache{})
+	s.db.Where("hash IN ?", hashes).Delete(&models.StorageBlob{})
+}
+
+// purgeQuarantine 删除隔离时间早于 cutoff 的文件及空目录
+func (s *StorageGCService) purgeQuarantine(cutoff time.Time, dryRun bool, report *StorageGCReport) error {
+	root := filepath.Join(s.storagePath, storageQuarantineDir)
+	if _, err := os.Stat(root); os.IsNotExist(err) {
+		return nil
+	}
+
+	var dirs []string
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if p != root {
+				dirs = append(dirs, p)
+			}
+			return nil
+		}
+		if info.ModTime().After(cutoff) {
+			return nil
+		}
+		if !dryRun {
+			if err := os.Remove(p); err != nil {
+				s.log.Warnw("Failed to purge quarantined file", "path", p, "error", err)
+				return nil
+			}
+		}
+		report.PurgedFiles++
+		report.PurgedBytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to purge quarantine: %w", err)
+	}
+
+	if !dryRun {
+		// 由深到浅删除空目录，非空目录删除失败可忽略
+		for i := len(dirs) - 1; i >= 0; i-- {
+			os.Remove(dirs[i])
+		}
+	}
+	return nil
+}
+
+// collectReferences 汇总数据库中引用的文件 key；HLS 播放列表引用其所在目录
+func (s *StorageGCService) collectReferences() (map[string]bool, []string, error) {
+	refs := make(map[string]bool)
+	var dirs []string
+
+	add := func(value string) {
+		key, ok := s.referenceKey(value)
+		if !ok {
+			return
+		}
+		refs[key] = true
+		if strings.HasSuffix(key, ".m3u8") {
+			dirs = append(dirs, path.Dir(key)+"/")
+		}
+	}
+
+	for _, col := range StorageColumns {
+		if !s.db.Migrator().HasTable(col.Table) || !s.db.Migrator().HasColumn(col.Table, col.Column) {
+			continue
+		}
+
+		var values []string
+		err := s.db.Table(col.Table).
+			Where(fmt.Sprintf("%s IS NOT NULL AND %s <> ''", col.Column, col.textExpr(s.db))).
+			Pluck(col.Column, &values).Error
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load %s.%s: %w", col.Table, col.Column, err)
+		}
+
+		for _, value := range values {
+			if col.Embedded {
+				for _, str := range jsonStrings(value) {
+					add(str)
+				}
+				continue
+			}
+			add(value)
+		}
+	}
+
+	return refs, dirs, nil
+}
+
+// referenceKey 将URL或路径转换为存储 key，非本地存储的引用返回 false
+func (s *StorageGCService) referenceKey(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" || strings.HasPrefix(value, "data:") {
+		return "", false
+	}
+	if idx := strings.IndexAny(value, "?#"); idx != -1 {
+		value = value[:idx]
+	}
+
+	switch {
+	case s.baseURL != "" && strings.HasPrefix(value, s.baseURL+"/"):
+		value = strings.TrimPrefix(value, s.baseURL+"/")
+	case strings.Contains(value, "/static/"):
+		value = value[strings.Index(value, "/static/")+len("/static/"):]
+	case strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+		return "", false
+	case filepath.IsAbs(value):
+		absRoot, err := filepath.Abs(s.storagePath)
+		if err != nil {
+			return "", false
+		}
+		rel, err := filepath.Rel(absRoot, value)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			return "", false
+		}
+		value = rel
+	default:
+		value = strings.TrimPrefix(filepath.ToSlash(value), filepath.ToSlash(filepath.Clean(s.storagePath))+"/")
+	}
+
+	key := strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(value)), "/")
+	return key, key != ""
+}
+
+// jsonStrings 提取 JSON 中的所有字符串值；无法解析时按原文处理
+func jsonStrings(raw string) []string {
+	var data interface{}
+	if err := json.Unmarshal([]byte(raw), &data); err != nil {
+		return []string{raw}
+	}
+
+	var result []string
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch val := v.(type) {
+		case string:
+			result = append(result, val)
+		case []interface{}:
+			for _, item := range val {
+				walk(item)
+			}
+		case map[string]interface{}:
+			for _, item := range val {
+				walk(item)
+			}
+		}
+	}
+	walk(data)
+	return result
+}
+
+func underDir(key string, dirs []string) bool {
+	for _, dir := range dirs {
+		if strings.HasPrefix(key, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestStorageGCReadsJSONReferences(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		drama, _ := createTestEpisode(t, db)
+
+		imageURL := cfg.Storage.BaseURL + "/characters/hero.png"
+		character := &models.Character{DramaID: drama.ID, Name: "Hero", ImageURL: &imageURL,
+			ReferenceImages: datatypes.JSON(`["` + cfg.Storage.BaseURL + `/images/ref.png"]`)}
+		mustCreate(t, db, character)
+
+		// 存储清理读取 JSON 列中的引用
+		for _, key := range []string{"characters/hero.png", "images/ref.png", "images/orphan.png"} {
+			writeTestFile(t, filepath.Join(cfg.Storage.LocalPath, key))
+		}
+		gcReport, err := NewStorageGCService(db, cfg, logger.NewLogger(false)).Run(StorageGCOptions{DryRun: true, MinAgeDays: -1})
+		if err != nil {
+			t.Fatalf("storage gc: %v", err)
+		}
+		if gcReport.ReferencedFiles != 2 {
+			t.Fatalf("expected 2 referenced files, got %d (orphans %+v)", gcReport.ReferencedFiles, gcReport.Orphans)
+		}
+	})
+}
+
+func TestStorageGCRefusesObjectStorage(t *testing.T) {
+	db := testDatabases(t)["sqlite"]
+	cfg := testConfig(t)
+	cfg.Storage.Type = "s3"
+
+	// 对象存储时本地目录只是工作区，不能据此判断孤儿文件
+	writeTestFile(t, filepath.Join(cfg.Storage.LocalPath, "images/uploaded.png"))
+	service := NewStorageGCService(db, cfg, logger.NewLogger(false))
+	if _, err := service.Run(StorageGCOptions{MinAgeDays: -1}); err != errStorageGCLocalOnly {
+		t.Fatalf("expected local-only error, got %v", err)
+	}
+	if _, err := service.StartGC(StorageGCOptions{}); err != errStorageGCLocalOnly {
+		t.Fatalf("expected local-only error from StartGC, got %v", err)
+	}
+}
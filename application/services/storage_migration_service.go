@@ -0,0 +1,154 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/drama-generator/backend/infrastructure/database"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// StorageMigrationService 将本地存储目录迁移到 S3 兼容存储桶，并改写数据库中的文件引用
+type StorageMigrationService struct {
+	db        *gorm.DB
+	target    *storage.S3Storage
+	localPath string
+	baseURL   string
+	log       *logger.Logger
+}
+
+// StorageMigrationStats 迁移统计
+type StorageMigrationStats struct {
+	Files         int              `json:"files"`
+	Uploaded      int              `json:"uploaded"`
+	Skipped       int              `json:"skipped"` // 存储桶中已存在且大小一致
+	Failed        int              `json:"failed"`
+	Bytes         int64            `json:"bytes"`
+	RowsRewritten map[string]int64 `json:"rows_rewritten"` // table.column -> 行数
+}
+
+func NewStorageMigrationService(db *gorm.DB, cfg config.StorageConfig, target *storage.S3Storage, log *logger.Logger) *StorageMigrationService {
+	return &StorageMigrationService{
+		db:        db,
+		target:    target,
+		localPath: cfg.LocalPath,
+		baseURL:   strings.TrimRight(cfg.BaseURL, "/"),
+		log:       log,
+	}
+}
+
+// MigrateFiles 遍历本地存储目录，将文件按相同相对路径上传到存储桶
+// 与存储清理一致跳过隔离目录等隐藏项，远程媒体缓存只供本地工具使用，也不上传
+func (s *StorageMigrationService) MigrateFiles(dryRun bool, stats *StorageMigrationStats) error {
+	return filepath.Walk(s.localPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.localPath, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if key == "." {
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), ".") || key == storage.MediaCacheCategory {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+		stats.Files++
+
+		if existing, err := s.target.Stat(key); err == nil && existing.Size == info.Size() {
+			stats.Skipped++
+			return nil
+		} else if err != nil && !errors.Is(err, storage.ErrNotFound) {
+			s.log.Warnw("Failed to stat object", "key", key, "error", err)
+		}
+
+		if dryRun {
+			stats.Uploaded++
+			stats.Bytes += info.Size()
+			return nil
+		}
+
+		if err := s.target.PutFile(key, path, mime.TypeByExtension(filepath.Ext(key))); err != nil {
+			s.log.Errorw("Failed to upload file", "key", key, "error", err)
+			stats.Failed++
+			return nil
+		}
+		stats.Uploaded++
+		stats.Bytes += info.Size()
+		if stats.Uploaded%100 == 0 {
+			s.log.Infow("Storage migration progress", "uploaded", stats.Uploaded, "skipped", stats.Skipped, "failed", stats.Failed)
+		}
+		return nil
+	})
+}
+
+// RewriteReferences 将URL列中的本地访问前缀替换为存储桶访问前缀，
+// 并把 local_path 列中带存储根目录前缀的路径规范化为相对路径（即对象 key）
+func (s *StorageMigrationService) RewriteReferences(dryRun bool, stats *StorageMigrationStats) error {
+	if stats.RowsRewritten == nil {
+		stats.RowsRewritten = make(map[string]int64)
+	}
+
+	oldPrefix := s.baseURL + "/"
+	newPrefix := s.target.GetURL("")
+	rootPrefix := filepath.ToSlash(filepath.Clean(s.localPath)) + "/"
+
+	for _, col := range StorageColumns {
+		if !s.db.Migrator().HasTable(col.Table) || !s.db.Migrator().HasColumn(col.Table, col.Column) {
+			continue
+		}
+
+		from, to := oldPrefix, newPrefix
+		if col.LocalPath {
+			from, to = rootPrefix, ""
+		}
+		if from == "/" || from == to {
+			continue
+		}
+
+		pattern := from + "%"
+		if col.Embedded {
+			pattern = "%" + from + "%"
+		}
+		query := s.db.Table(col.Table).Where(fmt.Sprintf("%s LIKE ?", col.textExpr(s.db)), pattern)
+
+		var affected int64
+		if dryRun {
+			if err := query.Count(&affected).Error; err != nil {
+				return fmt.Errorf("failed to count %s.%s: %w", col.Table, col.Column, err)
+			}
+		} else {
+			replaced := fmt.Sprintf("REPLACE(%s, ?, ?)", col.textExpr(s.db))
+			if col.JSON {
+				replaced = database.AsJSON(s.db, replaced)
+			}
+			result := query.UpdateColumn(col.Column, gorm.Expr(replaced, from, to))
+			if result.Error != nil {
+				return fmt.Errorf("failed to rewrite %s.%s: %w", col.Table, col.Column, result.Error)
+			}
+			affected = result.RowsAffected
+		}
+
+		if affected > 0 {
+			stats.RowsRewritten[col.Table+"."+col.Column] = affected
+			s.log.Infow("Storage references rewritten", "table", col.Table, "column", col.Column, "rows", affected, "dry_run", dryRun)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+)
+
+func TestMigrateFilesSkipsHiddenAndCacheDirs(t *testing.T) {
+	cfg := testConfig(t)
+	for _, rel := range []string{
+		"images/ab/abcdef.png",
+		"videos/merged/final.mp4",
+		"cache/12/123456.mp4",
+		".quarantine/20260101/images/old.png",
+		"images/.upload.tmp",
+	} {
+		writeTestFile(t, filepath.Join(cfg.Storage.LocalPath, rel))
+	}
+
+	// 模拟空存储桶，记录被检查的对象
+	var mu sync.Mutex
+	var keys []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		keys = append(keys, strings.TrimPrefix(r.URL.Path, "/drama/"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+	target, err := storage.NewS3Storage(config.S3Config{Endpoint: server.URL, Bucket: "drama", AccessKey: "key", SecretKey: "secret", PathStyle: true}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := &StorageMigrationStats{}
+	service := NewStorageMigrationService(nil, cfg.Storage, target, logger.NewLogger(false))
+	if err := service.MigrateFiles(true, stats); err != nil {
+		t.Fatalf("migrate files: %v", err)
+	}
+
+	sort.Strings(keys)
+	if want := []string{"images/ab/abcdef.png", "videos/merged/final.mp4"}; strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Fatalf("migrated keys = %v, want %v", keys, want)
+	}
+	if stats.Files != 2 || stats.Uploaded != 2 {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"github.com/drama-generator/backend/infrastructure/database"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// StorageColumn 保存存储文件引用（访问URL或相对路径）的数据库列
+type StorageColumn struct {
+	Table     string
+	Column    string
+	LocalPath bool // 相对存储根目录的路径
+	Embedded  bool // JSON/文本列，文件引用嵌在内容中
+	JSON      bool // json 类型列，PostgreSQL 下需转换为文本再比较或替换
+}
+
+// StorageColumns 所有可能引用存储文件的列，存储迁移、清理与去重共用
+var StorageColumns = []StorageColumn{
+	{Table: "assets", Column: "url"},
+	{Table: "assets", Column: "local_path", LocalPath: true},
+	{Table: "assets", Column: "thumbnail_url"},
+	{Table: "assets", Column: "poster_url"},
+	{Table: "assets", Column: "sprite_url"},
+	{Table: "assets", Column: "waveform_url"},
+	{Table: "assets", Column: "peaks_url"},
+	{Table: "image_generations", Column: "image_url"},
+	{Table: "image_generations", Column: "minio_url"},
+	{Table: "image_generations", Column: "local_path", LocalPath: true},
+	{Table: "image_generations", Column: "reference_images", Embedded: true, JSON: true},
+	{Table: "video_generations", Column: "image_url"},
+	{Table: "video_generations", Column: "first_frame_url"},
+	{Table: "video_generations", Column: "last_frame_url"},
+	{Table: "video_generations", Column: "reference_image_urls", Embedded: true},
+	{Table: "video_generations", Column: "video_url"},
+	{Table: "video_generations", Column: "minio_url"},
+	{Table: "video_generations", Column: "local_path", LocalPath: true},
+	{Table: "characters", Column: "image_url"},
+	{Table: "characters", Column: "local_path", LocalPath: true},
+	{Table: "characters", Column: "reference_images", Embedded: true, JSON: true},
+	{Table: "scenes", Column: "image_url"},
+	{Table: "scenes", Column: "local_path", LocalPath: true},
+	{Table: "props", Column: "image_url"},
+	{Table: "props", Column: "local_path", LocalPath: true},
+	{Table: "props", Column: "reference_images", Embedded: true, JSON: true},
+	{Table: "character_libraries", Column: "image_url"},
+	{Table: "character_libraries", Column: "local_path", LocalPath: true},
+	{Table: "storyboards", Column: "composed_image"},
+	{Table: "storyboards", Column: "video_url"},
+	{Table: "episodes", Column: "video_url"},
+	{Table: "episodes", Column: "thumbnail"},
+	{Table: "episodes", Column: "hls_url"},
+	{Table: "dramas", Column: "thumbnail"},
+	{Table: "video_merges", Column: "merged_url"},
+	{Table: "video_merges", Column: "scenes", Embedded: true, JSON: true},
+	{Table: "drama_brandings", Column: "intro_video"},
+	{Table: "drama_brandings", Column: "outro_video"},
+	{Table: "drama_brandings", Column: "logo_image"},
+}
+
+// textExpr 按文本读取该列的 SQL 表达式
+func (c StorageColumn) textExpr(db *gorm.DB) string {
+	if c.JSON {
+		return database.AsText(db, c.Column)
+	}
+	return c.Column
+}
+
+// retainStorageKey 记录复制已有文件引用（local_path）时增加去重文件的引用计数
+func retainStorageKey(db *gorm.DB, log *logger.Logger, localPath *string) {
+	if localPath == nil || *localPath == "" {
+		return
+	}
+	if err := storage.RetainKey(db, *localPath); err != nil {
+		log.Warnw("Failed to retain storage key", "key", *localPath, "error", err)
+	}
+}
+
+// releaseStorageKey 删除引用文件的记录时减少去重文件的引用计数
+func releaseStorageKey(db *gorm.DB, log *logger.Logger, localPath *string) {
+	if localPath == nil || *localPath == "" {
+		return
+	}
+	if err := storage.ReleaseKey(db, *localPath); err != nil {
+		log.Warnw("Failed to release storage key", "key", *localPath, "error", err)
+	}
+}
@@ -381,13 +381,15 @@ func (s *StoryboardCompositionService) GenerateSceneImage(req *GenerateSceneImag
 
 	// 使用imageGen服务直接生成
 	if s.imageGen != nil {
+		// 默认 2560x1440（3,686,400像素，满足doubao模型最低要求），剧本预设了画面比例时按预设
+		imageSize := presetImageSize(loadDramaPreset(s.db, scene.DramaID), "2560x1440")
 		genReq := &GenerateImageRequest{
 			SceneID:   &req.SceneID,
 			DramaID:   fmt.Sprintf("%d", scene.DramaID),
 			ImageType: string(models.ImageTypeScene),
 			Prompt:    prompt,
-			Model:     req.Model,   // 使用用户指定的模型
-			Size:      "2560x1440", // 3,686,400像素，满足doubao模型最低要求（16:9比例）
+			Model:     req.Model, // 使用用户指定的模型
+			Size:      imageSize,
 			Quality:   "standard",
 		}
 		imageGen, err := s.imageGen.GenerateImage(genReq)
@@ -79,6 +79,9 @@ func (s *StoryboardService) GenerateStoryboard(episodeID string, model string) (
 	if err != nil {
 		return "", fmt.Errorf("剧集不存在或无权限访问")
 	}
+	if model == "" {
+		model = loadDramaPresetByID(s.db, episode.DramaID).TextModel
+	}
 
 	// 获取剧本内容
 	var scriptContent string
@@ -212,7 +215,7 @@ func (s *StoryboardService) GenerateStoryboard(episodeID string, model string) (
       "action": "陈峥缓缓转身，目光与身后的李芳对视，李芳手握手电筒，光束在两人之间晃动，眼神中透露疑惑和警惕",
       "dialogue": "陈峥：\"我们被耍了，这里根本没有我们要找的东西。\" 李芳：\"现在怎么办？我们的时间不多了。\"",
       "result": "两人站在昏暗中陷入沉思，手电筒光束照在地面形成圆形光斑，背景传来微弱的金属摩擦声，气氛紧张凝重",
-      "atmosphere": "低调光线·暗部占画面70%，侧面硬光勾勒人物轮廓，冷暖光对比强烈，海风吹过产生呼啸声，营造紧迫感",
+      "atmosphere": "低调光线·暗部占画面70%%，侧面硬光勾勒人物轮廓，冷暖光对比强烈，海风吹过产生呼啸声，营造紧迫感",
       "emotion": "紧张感↑↑·警惕↑↑（悬置）",
       "duration": 7,
       "bgm_prompt": "紧张感逐渐升级的音效，低频持续音",
@@ -318,7 +321,7 @@ func (s *StoryboardService) GenerateStoryboard(episodeID string, model string) (
 - 包含感官细节：视觉、听觉、触觉、嗅觉
 - 描述光线、色彩、质感、动态
 - 为视频生成AI提供足够的画面构建信息
-- 避免抽象词汇，使用具象的视觉化描述`, systemPrompt, scriptLabel, scriptContent, taskLabel, taskInstruction, charListLabel, characterList, charConstraint, sceneListLabel, sceneList, sceneConstraint)
+- 避免抽象词汇，使用具象的视觉化描述`, systemPrompt, scriptLabel, scriptContent, taskLabel, taskInstruction, charListLabel, characterList, charConstraint, sceneListLabel, sceneList, sceneConstraint, scriptContent)
 
 	// 创建异步任务
 	task, err := s.taskService.CreateTask("storyboard_generation", episodeID)
@@ -411,6 +414,15 @@ func (s *StoryboardService) processStoryboardGeneration(taskID, episodeID, model
 		s.log.Infow("Parsed storyboard as object format", "count", len(result.Storyboards), "task_id", taskID)
 	}
 
+	// AI未给出时长的镜头使用剧本预设的默认镜头时长
+	if shotDuration := loadEpisodePreset(s.db, episodeID).ShotDuration; shotDuration > 0 {
+		for i := range result.Storyboards {
+			if result.Storyboards[i].Duration <= 0 {
+				result.Storyboards[i].Duration = shotDuration
+			}
+		}
+	}
+
 	// 计算总时长（所有分镜时长之和）
 	totalDuration := 0
 	for _, sb := range result.Storyboards {
@@ -893,6 +905,10 @@ type CreateStoryboardRequest struct {
 
 // CreateStoryboard 创建单个分镜
 func (s *StoryboardService) CreateStoryboard(req *CreateStoryboardRequest) (*models.Storyboard, error) {
+	if req.Duration <= 0 {
+		req.Duration = loadEpisodePreset(s.db, req.EpisodeID).ShotDuration
+	}
+
 	// 构建Storyboard对象
 	sb := Storyboard{
 		ShotNumber:  req.StoryboardNumber,
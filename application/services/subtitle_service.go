@@ -0,0 +1,251 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	models "github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/external/ffmpeg"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/subtitle"
+	"gorm.io/gorm"
+)
+
+const (
+	subtitleLeadIn       = 200 * time.Millisecond
+	subtitleMaxLineRunes = 16
+)
+
+type SubtitleService struct {
+	db          *gorm.DB
+	fileStorage storage.Storage
+	log         *logger.Logger
+}
+
+func NewSubtitleService(db *gorm.DB, fileStorage storage.Storage, log *logger.Logger) *SubtitleService {
+	return &SubtitleService{
+		db:          db,
+		fileStorage: fileStorage,
+		log:         log,
+	}
+}
+
+// SubtitleSettings 成片字幕设置
+type SubtitleSettings struct {
+	Enabled      bool   `json:"enabled"`       // 生成 SRT/WebVTT 外挂字幕并保存为素材
+	BurnIn       bool   `json:"burn_in"`       // 将字幕烧录进画面
+	FontName     string `json:"font_name"`     // 烧录字体
+	FontSize     int    `json:"font_size"`     // 烧录字号
+	PrimaryColor string `json:"primary_color"` // 字体颜色 #RRGGBB
+	OutlineColor string `json:"outline_color"` // 描边颜色 #RRGGBB
+	MarginV      int    `json:"margin_v"`      // 底部边距
+}
+
+// CuesFromClips 按合成顺序将分镜对白转换为字幕
+func (s *SubtitleService) CuesFromClips(scenes []models.SceneClip) []subtitle.Cue {
+	sorted := make([]models.SceneClip, len(scenes))
+	copy(sorted, scenes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Order < sorted[j].Order
+	})
+
+	var cues []subtitle.Cue
+	var offset float64
+	for _, clip := range sorted {
+		clipDuration := clipTimelineDuration(clip)
+		if clip.SceneID != 0 {
+			var storyboard models.Storyboard
+			if err := s.db.Select("id", "dialogue").Where("id = ?", clip.SceneID).First(&storyboard).Error; err == nil && storyboard.Dialogue != nil {
+				lines := subtitle.ParseDialogue(*storyboard.Dialogue)
+				cues = append(cues, subtitle.Distribute(lines,
+					secondsToDuration(offset), secondsToDuration(clipDuration),
+					subtitleLeadIn, subtitleMaxLineRunes)...)
+			}
+		}
+		offset += clipDuration
+	}
+	return cues
+}
+
+// EpisodeCues 获取剧集字幕：优先使用最近一次完成的合成顺序，否则按分镜编号排列
+func (s *SubtitleService) EpisodeCues(episodeID uint) ([]subtitle.Cue, error) {
+	var episode models.Episode
+	if err := s.db.Where("id = ?", episodeID).First(&episode).Error; err != nil {
+		return nil, fmt.Errorf("episode not found")
+	}
+
+	var merge models.VideoMerge
+	if err := s.db.Where("episode_id = ? AND status = ?", episodeID, models.VideoMergeStatusCompleted).
+		Order("completed_at DESC").First(&merge).Error; err == nil {
+		var scenes []models.SceneClip
+		if err := json.Unmarshal(merge.Scenes, &scenes); err == nil && len(scenes) > 0 {
+			// 成片带片头时字幕整体后移
+			var options MergeOutputOptions
+			if len(merge.Options) > 0 {
+				_ = json.Unmarshal(merge.Options, &options)
+			}
+			return subtitle.Shift(s.CuesFromClips(scenes), secondsToDuration(options.MainOffset)), nil
+		}
+	}
+
+	var storyboards []models.Storyboard
+	if err := s.db.Where("episode_id = ?", episodeID).Order("storyboard_number ASC").Find(&storyboards).Error; err != nil {
+		return nil, err
+	}
+	scenes := make([]models.SceneClip, len(storyboards))
+	for i, sb := range storyboards {
+		scenes[i] = models.SceneClip{SceneID: sb.ID, Duration: float64(sb.Duration), Order: i}
+	}
+	return s.CuesFromClips(scenes), nil
+}
+
+// RenderEpisodeSubtitles 生成剧集字幕文本
+func (s *SubtitleService) RenderEpisodeSubtitles(episodeID uint, format subtitle.Format) (string, error) {
+	cues, err := s.EpisodeCues(episodeID)
+	if err != nil {
+		return "", err
+	}
+	return subtitle.Render(cues, format), nil
+}
+
+// SubtitleFiles 写入存储的字幕文件（存储 key）
+type SubtitleFiles struct {
+	SRTPath string
+	VTTPath string
+}
+
+// WriteSubtitleFiles 将字幕写入存储
+func (s *SubtitleService) WriteSubtitleFiles(episodeID uint, cues []subtitle.Cue) (*SubtitleFiles, error) {
+	baseName := fmt.Sprintf("episode_%d_%d", episodeID, time.Now().UnixNano())
+	files := &SubtitleFiles{}
+	for _, format := range []subtitle.Format{subtitle.FormatSRT, subtitle.FormatVTT} {
+		relPath := "subtitles/" + baseName + format.Extension()
+		if err := s.fileStorage.Put(relPath, strings.NewReader(subtitle.Render(cues, format)), format.ContentType()); err != nil {
+			return nil, fmt.Errorf("failed to write %s subtitles: %w", format, err)
+		}
+		if format == subtitle.FormatSRT {
+			files.SRTPath = relPath
+		} else {
+			files.VTTPath = relPath
+		}
+	}
+	return files, nil
+}
+
+// AttachSubtitleAssets 将字幕文件登记为剧集素材，每集每种格式只保留一个素材，重复生成时更新为最新文件
+func (s *SubtitleService) AttachSubtitleAssets(episodeID, dramaID uint, files *SubtitleFiles) ([]models.Asset, error) {
+	var assets []models.Asset
+	for _, item := range []struct {
+		path   string
+		format subtitle.Format
+	}{
+		{files.SRTPath, subtitle.FormatSRT},
+		{files.VTTPath, subtitle.FormatVTT},
+	} {
+		if item.path == "" {
+			continue
+		}
+		localPath := item.path
+		format := string(item.format)
+		mimeType := item.format.ContentType()
+		var fileSize *int64
+		if info, err := s.fileStorage.Stat(localPath); err == nil {
+			size := info.Size
+			fileSize = &size
+		}
+
+		var asset models.Asset
+		err := s.db.Where("episode_id = ? AND type = ? AND format = ?", episodeID, models.AssetTypeSubtitle, format).
+			Order("id ASC").First(&asset).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return assets, fmt.Errorf("failed to load subtitle asset: %w", err)
+		}
+
+		if err == nil {
+			// 旧字幕文件不再被引用，由存储清理回收
+			asset.URL = s.fileStorage.GetURL(localPath)
+			asset.LocalPath = &localPath
+			asset.MimeType = &mimeType
+			asset.FileSize = fileSize
+			if err := s.db.Model(&asset).Select("url", "local_path", "mime_type", "file_size").Updates(&asset).Error; err != nil {
+				return assets, fmt.Errorf("failed to update subtitle asset: %w", err)
+			}
+		} else {
+			epID := episodeID
+			drID := dramaID
+			asset = models.Asset{
+				DramaID:   &drID,
+				EpisodeID: &epID,
+				Name:      fmt.Sprintf("Subtitles_E%d.%s", episodeID, format),
+				Type:      models.AssetTypeSubtitle,
+				URL:       s.fileStorage.GetURL(localPath),
+				LocalPath: &localPath,
+				MimeType:  &mimeType,
+				Format:    &format,
+				FileSize:  fileSize,
+			}
+			if err := s.db.Create(&asset).Error; err != nil {
+				return assets, fmt.Errorf("failed to create subtitle asset: %w", err)
+			}
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// burnSubtitles 将字幕烧录进正片画面，返回新的相对路径
+func (s *VideoMergeService) burnSubtitles(videoMerge *models.VideoMerge, cues []subtitle.Cue, settings *SubtitleSettings, mergedRelPath string) (string, error) {
+	srtFile, err := os.CreateTemp("", "burn_*.srt")
+	if err != nil {
+		return "", fmt.Errorf("failed to create subtitle file: %w", err)
+	}
+	defer os.Remove(srtFile.Name())
+	_, err = srtFile.WriteString(subtitle.RenderSRT(cues))
+	srtFile.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to write subtitle file: %w", err)
+	}
+
+	fileName := fmt.Sprintf("merged_%d_subtitled.mp4", time.Now().UnixNano())
+	relPath := filepath.Join("videos", "merged", fileName)
+	style := &ffmpeg.SubtitleStyle{
+		FontName:     settings.FontName,
+		FontSize:     settings.FontSize,
+		PrimaryColor: settings.PrimaryColor,
+		OutlineColor: settings.OutlineColor,
+		Outline:      2,
+		MarginV:      settings.MarginV,
+	}
+	if _, err := s.ffmpeg.BurnSubtitles(
+		filepath.Join(s.storagePath, mergedRelPath),
+		srtFile.Name(),
+		filepath.Join(s.storagePath, relPath),
+		style,
+	); err != nil {
+		return "", err
+	}
+
+	s.log.Infow("Subtitles burned into episode", "merge_id", videoMerge.ID, "cues", len(cues), "output", relPath)
+	return relPath, nil
+}
+
+// attachSubtitles 按成片时间轴写入外挂字幕并登记为素材，mainOffset 为正片前片头和标题卡的总时长
+func (s *VideoMergeService) attachSubtitles(videoMerge *models.VideoMerge, cues []subtitle.Cue, mainOffset float64) error {
+	files, err := s.subtitleService.WriteSubtitleFiles(videoMerge.EpisodeID, subtitle.Shift(cues, secondsToDuration(mainOffset)))
+	if err != nil {
+		return err
+	}
+	_, err = s.subtitleService.AttachSubtitleAssets(videoMerge.EpisodeID, videoMerge.DramaID, files)
+	return err
+}
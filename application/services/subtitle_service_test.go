@@ -0,0 +1,89 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+func TestEpisodeCuesFollowBrandedTimeline(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		drama, episode := createTestEpisode(t, db)
+		dialogue := "Hero: We leave at midnight."
+		storyboard := &models.Storyboard{EpisodeID: episode.ID, StoryboardNumber: 1, Dialogue: &dialogue, Duration: 4}
+		mustCreate(t, db, storyboard)
+
+		// 成片前插入 5 秒片头，字幕整体后移
+		completedAt := time.Now()
+		merge := &models.VideoMerge{EpisodeID: episode.ID, DramaID: drama.ID, Provider: "ffmpeg", Status: models.VideoMergeStatusCompleted,
+			Scenes:      datatypes.JSON(fmt.Sprintf(`[{"scene_id":%d,"duration":4,"order":0}]`, storyboard.ID)),
+			Options:     datatypes.JSON(`{"main_offset":5}`),
+			CompletedAt: &completedAt}
+		mustCreate(t, db, merge)
+
+		cues, err := NewSubtitleService(db, testStorage(t, cfg), logger.NewLogger(false)).EpisodeCues(episode.ID)
+		if err != nil {
+			t.Fatalf("episode cues: %v", err)
+		}
+		if len(cues) != 1 || cues[0].Start < 5*time.Second || cues[0].End > 9*time.Second {
+			t.Fatalf("cues not shifted by intro: %+v", cues)
+		}
+	})
+}
+
+func TestAttachSubtitleAssetsReusesEpisodeAsset(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		drama, episode := createTestEpisode(t, db)
+		subtitleService := NewSubtitleService(db, testStorage(t, cfg), logger.NewLogger(false))
+
+		var first []models.Asset
+		for run := 0; run < 2; run++ {
+			files, err := subtitleService.WriteSubtitleFiles(episode.ID, nil)
+			if err != nil {
+				t.Fatalf("write subtitles: %v", err)
+			}
+			assets, err := subtitleService.AttachSubtitleAssets(episode.ID, drama.ID, files)
+			if err != nil {
+				t.Fatalf("attach subtitles: %v", err)
+			}
+			if len(assets) != 2 {
+				t.Fatalf("assets = %d, want 2", len(assets))
+			}
+			if run == 0 {
+				first = assets
+				continue
+			}
+			// 重复生成时沿用同一素材并指向最新文件
+			for i, asset := range assets {
+				if asset.ID != first[i].ID {
+					t.Fatalf("asset %d recreated as %d", first[i].ID, asset.ID)
+				}
+			}
+			if *assets[0].LocalPath != files.SRTPath || *assets[1].LocalPath != files.VTTPath {
+				t.Fatalf("assets not updated to latest files: %s, %s", *assets[0].LocalPath, *assets[1].LocalPath)
+			}
+		}
+
+		var count int64
+		if err := db.Model(&models.Asset{}).Where("episode_id = ? AND type = ?", episode.ID, models.AssetTypeSubtitle).Count(&count).Error; err != nil {
+			t.Fatalf("count assets: %v", err)
+		}
+		if count != 2 {
+			t.Fatalf("subtitle assets = %d, want 2", count)
+		}
+		var saved models.Asset
+		if err := db.First(&saved, first[0].ID).Error; err != nil {
+			t.Fatalf("load asset: %v", err)
+		}
+		if saved.URL == first[0].URL {
+			t.Fatalf("asset url not updated: %s", saved.URL)
+		}
+	})
+}
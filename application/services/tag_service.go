@@ -0,0 +1,220 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	models "github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+type TagService struct {
+	db  *gorm.DB
+	log *logger.Logger
+}
+
+func NewTagService(db *gorm.DB, log *logger.Logger) *TagService {
+	return &TagService{
+		db:  db,
+		log: log,
+	}
+}
+
+type CreateTagRequest struct {
+	DramaID *uint   `json:"drama_id"` // 为空时创建全局标签
+	Name    string  `json:"name" binding:"required"`
+	Color   *string `json:"color"`
+}
+
+type UpdateTagRequest struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+type ListTagsRequest struct {
+	DramaID       *uint // 为空时只返回全局标签
+	IncludeGlobal bool  // 指定剧本时是否同时返回全局标签
+}
+
+// ListTags 获取标签列表及各标签关联的素材数量
+func (s *TagService) ListTags(req *ListTagsRequest) ([]models.Tag, error) {
+	query := s.db.Model(&models.Tag{})
+	if req.DramaID != nil {
+		if req.IncludeGlobal {
+			query = query.Where("drama_id = ? OR drama_id IS NULL", *req.DramaID)
+		} else {
+			query = query.Where("drama_id = ?", *req.DramaID)
+		}
+	} else {
+		query = query.Where("drama_id IS NULL")
+	}
+
+	var tags []models.Tag
+	if err := query.Order("name ASC").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return tags, nil
+	}
+
+	tagIDs := make([]uint, len(tags))
+	for i, tag := range tags {
+		tagIDs[i] = tag.ID
+	}
+
+	// 统计未删除素材数量，指定剧本时只统计该剧本内的素材
+	type tagCount struct {
+		TagID uint
+		Count int64
+	}
+	var counts []tagCount
+	countQuery := s.db.Table("asset_tags").
+		Select("asset_tags.tag_id AS tag_id, COUNT(*) AS count").
+		Joins("JOIN assets ON assets.id = asset_tags.asset_id AND assets.deleted_at IS NULL").
+		Where("asset_tags.tag_id IN ?", tagIDs)
+	if req.DramaID != nil {
+		countQuery = countQuery.Where("assets.drama_id = ?", *req.DramaID)
+	}
+	if err := countQuery.Group("asset_tags.tag_id").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	countMap := make(map[uint]int64, len(counts))
+	for _, c := range counts {
+		countMap[c.TagID] = c.Count
+	}
+	for i := range tags {
+		tags[i].AssetCount = countMap[tags[i].ID]
+	}
+
+	return tags, nil
+}
+
+func (s *TagService) CreateTag(req *CreateTagRequest) (*models.Tag, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("tag name is required")
+	}
+
+	if req.DramaID != nil {
+		var drama models.Drama
+		if err := s.db.Where("id = ?", *req.DramaID).First(&drama).Error; err != nil {
+			return nil, fmt.Errorf("drama not found")
+		}
+	}
+
+	if err := s.checkDuplicate(req.DramaID, name, 0); err != nil {
+		return nil, err
+	}
+
+	tag := &models.Tag{
+		DramaID: req.DramaID,
+		Name:    name,
+		Color:   req.Color,
+	}
+	if err := s.db.Create(tag).Error; err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+
+	return tag, nil
+}
+
+func (s *TagService) UpdateTag(tagID uint, req *UpdateTagRequest) (*models.Tag, error) {
+	var tag models.Tag
+	if err := s.db.Where("id = ?", tagID).First(&tag).Error; err != nil {
+		return nil, fmt.Errorf("tag not found")
+	}
+
+	updates := make(map[string]interface{})
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("tag name is required")
+		}
+		if err := s.checkDuplicate(tag.DramaID, name, tag.ID); err != nil {
+			return nil, err
+		}
+		updates["name"] = name
+	}
+	if req.Color != nil {
+		updates["color"] = *req.Color
+	}
+
+	if len(updates) > 0 {
+		if err := s.db.Model(&tag).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update tag: %w", err)
+		}
+	}
+
+	if err := s.db.First(&tag, tagID).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// DeleteTag 删除标签并解除与素材的关联
+func (s *TagService) DeleteTag(tagID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tag_id = ?", tagID).Delete(&models.AssetTag{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", tagID).Delete(&models.Tag{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("tag not found")
+		}
+		return nil
+	})
+}
+
+// checkDuplicate 同一剧本（或全局）下标签名不可重复
+func (s *TagService) checkDuplicate(dramaID *uint, name string, excludeID uint) error {
+	query := s.db.Model(&models.Tag{}).Where("name = ?", name)
+	if dramaID != nil {
+		query = query.Where("drama_id = ?", *dramaID)
+	} else {
+		query = query.Where("drama_id IS NULL")
+	}
+	if excludeID != 0 {
+		query = query.Where("id <> ?", excludeID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("tag already exists: %s", name)
+	}
+	return nil
+}
+
+// resolveAssetTags 校验标签ID，只允许全局标签或与素材同剧本的标签
+func resolveAssetTags(db *gorm.DB, dramaID *uint, tagIDs []uint) ([]models.Tag, error) {
+	if len(tagIDs) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	var tags []models.Tag
+	if err := db.Where("id IN ?", tagIDs).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	found := make(map[uint]bool, len(tags))
+	for _, tag := range tags {
+		if tag.DramaID != nil && (dramaID == nil || *tag.DramaID != *dramaID) {
+			return nil, fmt.Errorf("tag %d does not belong to this drama", tag.ID)
+		}
+		found[tag.ID] = true
+	}
+	for _, id := range tagIDs {
+		if !found[id] {
+			return nil, fmt.Errorf("tag not found: %d", id)
+		}
+	}
+
+	return tags, nil
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+func TestTagNameUniquePerDrama(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		drama, _ := createTestEpisode(t, db)
+		tagService := NewTagService(db, logger.NewLogger(false))
+
+		tag, err := tagService.CreateTag(&CreateTagRequest{DramaID: &drama.ID, Name: "夜景"})
+		if err != nil {
+			t.Fatalf("create tag: %v", err)
+		}
+		// 绕过服务层重名检查，由唯一索引兜底
+		if err := db.Create(&models.Tag{DramaID: &drama.ID, Name: "夜景"}).Error; err == nil {
+			t.Fatal("expected unique index to reject duplicate tag name")
+		}
+
+		// 全局标签 drama_id 为空，同样不可重名
+		mustCreate(t, db, &models.Tag{Name: "通用"})
+		if err := db.Create(&models.Tag{Name: "通用"}).Error; err == nil {
+			t.Fatal("expected unique index to reject duplicate global tag name")
+		}
+
+		if err := tagService.DeleteTag(tag.ID); err != nil {
+			t.Fatalf("delete tag: %v", err)
+		}
+		if _, err := tagService.CreateTag(&CreateTagRequest{DramaID: &drama.ID, Name: "夜景"}); err != nil {
+			t.Fatalf("recreate deleted tag name: %v", err)
+		}
+	})
+}
+
+func TestAssetRejectsForeignDramaTag(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		drama, _ := createTestEpisode(t, db)
+		other := &models.Drama{Title: "other"}
+		mustCreate(t, db, other)
+		foreign := &models.Tag{DramaID: &other.ID, Name: "foreign"}
+		mustCreate(t, db, foreign)
+
+		assetService := newTestAssetService(t, db, cfg)
+		asset := &models.Asset{DramaID: &drama.ID, Name: "original", Type: models.AssetTypeImage, URL: "a.png"}
+		mustCreate(t, db, asset)
+
+		name := "renamed"
+		_, err := assetService.UpdateAsset(asset.ID, &UpdateAssetRequest{Name: &name, TagIDs: []uint{foreign.ID}})
+		if want := fmt.Sprintf("tag %d does not belong to this drama", foreign.ID); err == nil || err.Error() != want {
+			t.Fatalf("expected foreign tag error, got %v", err)
+		}
+
+		// 标签非法时不应部分更新其他字段
+		var saved models.Asset
+		if err := db.First(&saved, asset.ID).Error; err != nil {
+			t.Fatalf("load asset: %v", err)
+		}
+		if saved.Name != "original" {
+			t.Fatalf("asset name = %q, want unchanged", saved.Name)
+		}
+	})
+}
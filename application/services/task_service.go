@@ -2,6 +2,7 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,6 +12,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// TaskStatusCancelled 已取消的任务不再接受状态更新，后台处理结束后结果被丢弃
+const TaskStatusCancelled = "cancelled"
+
 type TaskService struct {
 	db  *gorm.DB
 	log *logger.Logger
@@ -55,7 +59,7 @@ func (s *TaskService) UpdateTaskStatus(taskID, status string, progress int, mess
 	}
 
 	return s.db.Model(&models.AsyncTask{}).
-		Where("id = ?", taskID).
+		Where("id = ? AND status <> ?", taskID, TaskStatusCancelled).
 		Updates(updates).Error
 }
 
@@ -63,7 +67,7 @@ func (s *TaskService) UpdateTaskStatus(taskID, status string, progress int, mess
 func (s *TaskService) UpdateTaskError(taskID string, err error) error {
 	now := time.Now()
 	return s.db.Model(&models.AsyncTask{}).
-		Where("id = ?", taskID).
+		Where("id = ? AND status <> ?", taskID, TaskStatusCancelled).
 		Updates(map[string]interface{}{
 			"status":       "failed",
 			"error":        err.Error(),
@@ -82,7 +86,7 @@ func (s *TaskService) UpdateTaskResult(taskID string, result interface{}) error
 
 	now := time.Now()
 	return s.db.Model(&models.AsyncTask{}).
-		Where("id = ?", taskID).
+		Where("id = ? AND status <> ?", taskID, TaskStatusCancelled).
 		Updates(map[string]interface{}{
 			"status":       "completed",
 			"progress":     100,
@@ -111,3 +115,131 @@ func (s *TaskService) GetTasksByResource(resourceID string) ([]*models.AsyncTask
 	}
 	return tasks, nil
 }
+
+// ListTasks 按状态与类型查询最近的任务，limit 为 0 时默认 50 条
+func (s *TaskService) ListTasks(status, taskType string, limit int) ([]*models.AsyncTask, error) {
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := s.db.Model(&models.AsyncTask{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if taskType != "" {
+		query = query.Where("type = ?", taskType)
+	}
+
+	var tasks []*models.AsyncTask
+	if err := query.Order("created_at DESC").Limit(limit).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// CancelTask 取消未完成的任务
+func (s *TaskService) CancelTask(taskID string) (*models.AsyncTask, error) {
+	task, err := s.GetTask(taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("task not found")
+		}
+		return nil, err
+	}
+	if task.Status != "pending" && task.Status != "processing" {
+		return nil, fmt.Errorf("task already %s", task.Status)
+	}
+
+	now := time.Now()
+	if err := s.db.Model(task).Updates(map[string]interface{}{
+		"status":       TaskStatusCancelled,
+		"message":      "任务已取消",
+		"completed_at": &now,
+		"updated_at":   now,
+	}).Error; err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// IsTaskCancelled 判断任务是否已被取消，长任务在阶段之间检查以尽早退出
+func (s *TaskService) IsTaskCancelled(taskID string) bool {
+	var count int64
+	s.db.Model(&models.AsyncTask{}).Where("id = ? AND status = ?", taskID, TaskStatusCancelled).Count(&count)
+	return count > 0
+}
+
+// WaitForTask 轮询等待任务结束（完成、失败或取消），超时返回错误；timeout 为 0 表示不限
+func (s *TaskService) WaitForTask(taskID string, interval, timeout time.Duration) (*models.AsyncTask, error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	for {
+		task, err := s.GetTask(taskID)
+		if err != nil {
+			return nil, err
+		}
+		switch task.Status {
+		case "completed", "failed", TaskStatusCancelled:
+			return task, nil
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return task, fmt.Errorf("timed out waiting for task %s", taskID)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// RecordProgress 一批生成记录的完成情况
+type RecordProgress struct {
+	Total     int
+	Completed int
+	Failed    int
+}
+
+// WaitForRecords 轮询等待生成记录（图片、视频、合成）全部进入 completed 或 failed 状态，返回最终进度
+// 每轮查询后调用 onPoll（汇报进度、检查取消），其返回错误时停止等待；timeout 为 0 表示不限
+func (s *TaskService) WaitForRecords(label string, model interface{}, ids []uint, interval, timeout time.Duration, onPoll func(RecordProgress) error) (RecordProgress, error) {
+	progress := RecordProgress{Total: len(ids)}
+	if len(ids) == 0 {
+		return progress, nil
+	}
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	for {
+		var rows []struct {
+			ID     uint
+			Status string
+		}
+		if err := s.db.Model(model).Where("id IN ?", ids).Select("id, status").Scan(&rows).Error; err != nil {
+			return progress, err
+		}
+		progress.Completed, progress.Failed = 0, 0
+		for _, row := range rows {
+			switch row.Status {
+			case "completed":
+				progress.Completed++
+			case "failed":
+				progress.Failed++
+			}
+		}
+
+		if onPoll != nil {
+			if err := onPoll(progress); err != nil {
+				return progress, err
+			}
+		}
+		if progress.Completed+progress.Failed >= progress.Total {
+			return progress, nil
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return progress, fmt.Errorf("%s等待超时", label)
+		}
+		time.Sleep(interval)
+	}
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -20,20 +21,20 @@ type VideoGenerationService struct {
 	db              *gorm.DB
 	transferService *ResourceTransferService
 	log             *logger.Logger
-	localStorage    *storage.LocalStorage
+	fileStorage     storage.Storage
 	aiService       *AIService
 	ffmpeg          *ffmpeg.FFmpeg
 	promptI18n      *PromptI18n
 }
 
-func NewVideoGenerationService(db *gorm.DB, transferService *ResourceTransferService, localStorage *storage.LocalStorage, aiService *AIService, log *logger.Logger, promptI18n *PromptI18n) *VideoGenerationService {
+func NewVideoGenerationService(db *gorm.DB, transferService *ResourceTransferService, fileStorage storage.Storage, aiService *AIService, log *logger.Logger, promptI18n *PromptI18n) *VideoGenerationService {
 	service := &VideoGenerationService{
 		db:              db,
-		localStorage:    localStorage,
+		fileStorage:     fileStorage,
 		transferService: transferService,
 		aiService:       aiService,
 		log:             log,
-		ffmpeg:          ffmpeg.NewFFmpeg(log),
+		ffmpeg:          ffmpeg.NewFFmpeg(log, mediaCache(fileStorage)),
 		promptI18n:      promptI18n,
 	}
 
@@ -69,6 +70,7 @@ type GenerateVideoRequest struct {
 	Duration     *int    `json:"duration"`
 	FPS          *int    `json:"fps"`
 	AspectRatio  *string `json:"aspect_ratio"`
+	Resolution   *string `json:"resolution"`
 	Style        *string `json:"style"`
 	MotionLevel  *int    `json:"motion_level"`
 	CameraMotion *string `json:"camera_motion"`
@@ -76,6 +78,9 @@ type GenerateVideoRequest struct {
 }
 
 func (s *VideoGenerationService) GenerateVideo(request *GenerateVideoRequest) (*models.VideoGeneration, error) {
+	// 请求未指定的参数沿用剧本生成预设
+	applyVideoPreset(request, loadDramaPresetByID(s.db, request.DramaID))
+
 	if request.StoryboardID != nil {
 		var storyboard models.Storyboard
 		if err := s.db.Preload("Episode").Where("id = ?", *request.StoryboardID).First(&storyboard).Error; err != nil {
@@ -110,6 +115,7 @@ func (s *VideoGenerationService) GenerateVideo(request *GenerateVideoRequest) (*
 		Duration:     request.Duration,
 		FPS:          request.FPS,
 		AspectRatio:  request.AspectRatio,
+		Resolution:   request.Resolution,
 		Style:        request.Style,
 		MotionLevel:  request.MotionLevel,
 		CameraMotion: request.CameraMotion,
@@ -117,6 +123,14 @@ func (s *VideoGenerationService) GenerateVideo(request *GenerateVideoRequest) (*
 		Status:       models.VideoStatusPending,
 	}
 
+	// 多图参考模式自动附加分镜关联角色的参考图
+	multiple := request.ReferenceMode == "multiple" || (request.ReferenceMode == "" && request.ImageURL == "" &&
+		request.FirstFrameURL == nil && request.LastFrameURL == nil && len(request.ReferenceImageURLs) > 0)
+	if multiple && request.StoryboardID != nil {
+		request.ReferenceImageURLs = mergeReferenceImages(request.ReferenceImageURLs,
+			storyboardCharacterReferenceImages(s.db, *request.StoryboardID), maxReferenceImages)
+	}
+
 	// 根据参考图模式处理不同的参数
 	if request.ReferenceMode != "" {
 		videoGen.ReferenceMode = &request.ReferenceMode
@@ -224,6 +238,9 @@ func (s *VideoGenerationService) ProcessVideoGeneration(videoGenID uint) {
 	if videoGen.AspectRatio != nil {
 		opts = append(opts, video.WithAspectRatio(*videoGen.AspectRatio))
 	}
+	if videoGen.Resolution != nil {
+		opts = append(opts, video.WithResolution(*videoGen.Resolution))
+	}
 	if videoGen.Style != nil {
 		opts = append(opts, video.WithStyle(*videoGen.Style))
 	}
@@ -281,6 +298,18 @@ func (s *VideoGenerationService) ProcessVideoGeneration(videoGenID uint) {
 		}
 	}
 
+	// 分镜主要角色的参考图作为 Minimax 主体参考，仅 S2V 系列模型使用，其他模型不查询也不转换
+	if minimax, ok := client.(*video.MinimaxClient); ok && videoGen.StoryboardID != nil && video.SupportsSubjectReference(minimaxModel(minimax, videoGen.Model)) {
+		if subject := storyboardSubjectReference(s.db, *videoGen.StoryboardID); subject != "" {
+			subjectBase64, err := s.convertImageToBase64(subject)
+			if err != nil {
+				s.log.Warnw("Failed to convert subject reference to base64, using original URL", "error", err)
+				subjectBase64 = subject
+			}
+			opts = append(opts, video.WithSubjectReference([]string{subjectBase64}))
+		}
+	}
+
 	// 构造imageURL参数（单图模式使用，其他模式传空字符串）
 	// 如果是本地图片，转换为base64
 	imageURL := ""
@@ -450,8 +479,8 @@ func (s *VideoGenerationService) completeVideoGeneration(videoGenID uint, videoU
 	var localVideoPath *string
 
 	// 下载视频到本地存储并保存相对路径到数据库
-	if s.localStorage != nil && videoURL != "" {
-		downloadResult, err := s.localStorage.DownloadFromURLWithPath(videoURL, "videos")
+	if s.fileStorage != nil && videoURL != "" {
+		downloadResult, err := s.fileStorage.DownloadFromURLWithPath(videoURL, "videos")
 		if err != nil {
 			s.log.Warnw("Failed to download video to local storage",
 				"error", err,
@@ -470,7 +499,7 @@ func (s *VideoGenerationService) completeVideoGeneration(videoGenID uint, videoU
 	// 特别是当 AI 服务返回的 duration 为 0 或 nil 时，必须探测
 	shouldProbe := localVideoPath != nil && s.ffmpeg != nil && (duration == nil || *duration == 0)
 	if shouldProbe {
-		absPath := s.localStorage.GetAbsolutePath(*localVideoPath)
+		absPath := s.fileStorage.GetAbsolutePath(*localVideoPath)
 		if probedDuration, err := s.ffmpeg.GetVideoDuration(absPath); err == nil {
 			// 转换为整数秒（向上取整）
 			durationInt := int(probedDuration + 0.5)
@@ -487,7 +516,7 @@ func (s *VideoGenerationService) completeVideoGeneration(videoGenID uint, videoU
 		}
 	} else if localVideoPath != nil && s.ffmpeg != nil && duration != nil && *duration > 0 {
 		// 即使有 duration，也验证一下（可选）
-		absPath := s.localStorage.GetAbsolutePath(*localVideoPath)
+		absPath := s.fileStorage.GetAbsolutePath(*localVideoPath)
 		if probedDuration, err := s.ffmpeg.GetVideoDuration(absPath); err == nil {
 			durationInt := int(probedDuration + 0.5)
 			if durationInt != *duration {
@@ -502,8 +531,8 @@ func (s *VideoGenerationService) completeVideoGeneration(videoGenID uint, videoU
 	}
 
 	// 下载首帧图片到本地存储（仅用于缓存，不更新数据库）
-	if firstFrameURL != nil && *firstFrameURL != "" && s.localStorage != nil {
-		_, err := s.localStorage.DownloadFromURL(*firstFrameURL, "video_frames")
+	if firstFrameURL != nil && *firstFrameURL != "" && s.fileStorage != nil {
+		_, err := s.fileStorage.DownloadFromURL(*firstFrameURL, "video_frames")
 		if err != nil {
 			s.log.Warnw("Failed to download first frame to local storage",
 				"error", err,
@@ -572,6 +601,14 @@ func (s *VideoGenerationService) updateVideoGenError(videoGenID uint, errorMsg s
 	}
 }
 
+// minimaxModel 本次生成实际使用的 Minimax 模型，未指定时为客户端默认模型
+func minimaxModel(client *video.MinimaxClient, model string) string {
+	if model != "" {
+		return model
+	}
+	return client.Model
+}
+
 func (s *VideoGenerationService) getVideoClient(provider string, modelName string) (video.VideoClient, error) {
 	// 根据模型名称获取AI配置
 	var config *models.AIServiceConfig
@@ -756,7 +793,21 @@ func (s *VideoGenerationService) BatchGenerateVideosForEpisode(episodeID string)
 }
 
 func (s *VideoGenerationService) DeleteVideoGeneration(id uint) error {
-	return s.db.Delete(&models.VideoGeneration{}, id).Error
+	var videoGen models.VideoGeneration
+	if err := s.db.Select("id", "local_path").Where("id = ?", id).First(&videoGen).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	result := s.db.Delete(&models.VideoGeneration{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		releaseStorageKey(s.db, s.log, videoGen.LocalPath)
+	}
+	return nil
 }
 
 // convertImageToBase64 将图片转换为base64格式
@@ -768,7 +819,7 @@ func (s *VideoGenerationService) convertImageToBase64(imageURL string) (string,
 	}
 
 	// 尝试从本地存储读取
-	if s.localStorage != nil {
+	if s.fileStorage != nil {
 		var relativePath string
 
 		// 1. 检查是否是本地URL（包含 /static/）
@@ -785,7 +836,7 @@ func (s *VideoGenerationService) convertImageToBase64(imageURL string) (string,
 
 		// 如果识别出相对路径，尝试读取本地文件
 		if relativePath != "" {
-			absPath := s.localStorage.GetAbsolutePath(relativePath)
+			absPath := s.fileStorage.GetAbsolutePath(relativePath)
 
 			// 使用工具函数转换为base64
 			base64Str, err := utils.ImageToBase64(absPath)
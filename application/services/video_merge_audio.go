@@ -0,0 +1,294 @@
+package services
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	models "github.com/drama-generator/backend/domain/models"
+	"github.com/drama-generator/backend/infrastructure/external/ffmpeg"
+)
+
+// MergeOutputOptions 视频合成后处理选项，随合成记录一起保存
+type MergeOutputOptions struct {
+	Output     *OutputFormatSettings `json:"output,omitempty"`
+	AudioMix   *AudioMixSettings     `json:"audio_mix,omitempty"`
+	Subtitles  *SubtitleSettings     `json:"subtitles,omitempty"`
+	Branding   *bool                 `json:"branding,omitempty"`    // 应用剧本品牌设置（片头片尾、水印、标题卡），剧集定稿默认开启
+	HLS        *HLSSettings          `json:"hls,omitempty"`         // 定稿后打包为多码率 HLS
+	Variant    bool                  `json:"variant,omitempty"`     // 附加画幅版本，完成后不更新剧集视频（内部状态，由 startMerge 写入）
+	MainOffset float64               `json:"main_offset,omitempty"` // 正片在成片中的起始时间（秒），应用品牌片头后写入（内部状态）
+}
+
+// AudioMixSettings 成片混音设置
+type AudioMixSettings struct {
+	Enabled        bool     `json:"enabled"`
+	BGMAssetID     *uint    `json:"bgm_asset_id,omitempty"` // 全集默认背景音乐，未指定时按分镜/剧集/剧本查找
+	BGMVolume      float64  `json:"bgm_volume"`             // 默认 0.35
+	DialogueVolume float64  `json:"dialogue_volume"`        // 默认 1.0
+	EffectVolume   float64  `json:"effect_volume"`          // 默认 0.8
+	KeepOriginal   *bool    `json:"keep_original"`          // 是否保留视频片段原声，默认保留
+	OriginalVolume float64  `json:"original_volume"`        // 默认 0.6
+	Ducking        *bool    `json:"ducking"`                // 对白时压低背景音乐，默认开启
+	TargetLUFS     *float64 `json:"target_lufs"`            // 响度标准化目标，默认 -14
+}
+
+const (
+	defaultBGMVolume      = 0.35
+	defaultDialogueVolume = 1.0
+	defaultEffectVolume   = 0.8
+	defaultOriginalVolume = 0.6
+	defaultTargetLUFS     = -14.0
+	dialogueLeadIn        = 0.2 // 对白相对分镜开头的延迟（秒）
+)
+
+// clipTimelineDuration 片段在成片中占用的时长（与 FFmpeg 合成时的 offset 计算一致）
+func clipTimelineDuration(clip models.SceneClip) float64 {
+	if clip.EndTime > 0 && clip.StartTime >= 0 && clip.EndTime > clip.StartTime {
+		return clip.EndTime - clip.StartTime
+	}
+	return clip.Duration
+}
+
+// targetLUFS 响度标准化目标，0 表示不做标准化
+func (settings *AudioMixSettings) targetLUFS() float64 {
+	if settings.TargetLUFS != nil {
+		return *settings.TargetLUFS
+	}
+	return defaultTargetLUFS
+}
+
+// resolveAssetPath 优先使用 local_path，否则回退到 URL
+func (s *VideoMergeService) resolveAssetPath(asset *models.Asset) string {
+	if asset.LocalPath != nil && *asset.LocalPath != "" {
+		if filepath.IsAbs(*asset.LocalPath) || strings.HasPrefix(*asset.LocalPath, s.storagePath) {
+			return *asset.LocalPath
+		}
+		return s.fileStorage.GetAbsolutePath(*asset.LocalPath)
+	}
+	return storageMediaPath(s.fileStorage, s.storagePath, s.baseURL, asset.URL)
+}
+
+// audioDuration 音频素材时长（秒）：优先通过 ffprobe 探测精确时长，失败时回退到素材记录的整秒时长
+func (s *VideoMergeService) audioDuration(asset *models.Asset, path string) (float64, error) {
+	duration, err := s.ffmpeg.GetVideoDuration(path)
+	if err == nil && duration > 0 {
+		return duration, nil
+	}
+	if asset.Duration != nil && *asset.Duration > 0 {
+		return float64(*asset.Duration), nil
+	}
+	return 0, err
+}
+
+// findAudioAssets 查找分镜关联的指定分类音频素材
+func (s *VideoMergeService) findAudioAssets(storyboardID uint, category string) []models.Asset {
+	var assets []models.Asset
+	s.db.Where("storyboard_id = ? AND type = ? AND category = ?", storyboardID, models.AssetTypeAudio, category).
+		Order("created_at ASC").
+		Find(&assets)
+	return assets
+}
+
+// findAudioAssetByPrompt 在剧本素材库中按名称/描述匹配音频素材（对应分镜的 BgmPrompt/SoundEffect）
+func (s *VideoMergeService) findAudioAssetByPrompt(dramaID uint, category, prompt string) *models.Asset {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return nil
+	}
+	var asset models.Asset
+	if err := s.db.Where("drama_id = ? AND type = ? AND category = ? AND (name = ? OR description = ?)",
+		dramaID, models.AssetTypeAudio, category, prompt, prompt).
+		Order("created_at DESC").
+		First(&asset).Error; err != nil {
+		return nil
+	}
+	return &asset
+}
+
+// findDefaultBGM 查找全集默认背景音乐：指定素材 > 剧集级 > 剧本级
+func (s *VideoMergeService) findDefaultBGM(videoMerge *models.VideoMerge, settings *AudioMixSettings) *models.Asset {
+	var asset models.Asset
+	if settings.BGMAssetID != nil {
+		if err := s.db.Where("id = ? AND type = ?", *settings.BGMAssetID, models.AssetTypeAudio).First(&asset).Error; err == nil {
+			return &asset
+		}
+		s.log.Warnw("BGM asset not found", "asset_id", *settings.BGMAssetID)
+	}
+	if err := s.db.Where("episode_id = ? AND storyboard_id IS NULL AND type = ? AND category = ?",
+		videoMerge.EpisodeID, models.AssetTypeAudio, models.AssetCategoryBGM).
+		Order("created_at DESC").First(&asset).Error; err == nil {
+		return &asset
+	}
+	if err := s.db.Where("drama_id = ? AND episode_id IS NULL AND storyboard_id IS NULL AND type = ? AND category = ?",
+		videoMerge.DramaID, models.AssetTypeAudio, models.AssetCategoryBGM).
+		Order("created_at DESC").First(&asset).Error; err == nil {
+		return &asset
+	}
+	return nil
+}
+
+// buildAudioMixOptions 根据分镜在合成顺序中的位置构建混音轨道
+func (s *VideoMergeService) buildAudioMixOptions(videoMerge *models.VideoMerge, scenes []models.SceneClip, settings *AudioMixSettings) *ffmpeg.AudioMixOptions {
+	opts := &ffmpeg.AudioMixOptions{
+		KeepOriginal:   true,
+		OriginalVolume: defaultOriginalVolume,
+		DuckBGM:        true,
+	}
+	if settings.KeepOriginal != nil {
+		opts.KeepOriginal = *settings.KeepOriginal
+	}
+	if settings.OriginalVolume > 0 {
+		opts.OriginalVolume = settings.OriginalVolume
+	}
+	if settings.Ducking != nil {
+		opts.DuckBGM = *settings.Ducking
+	}
+
+	bgmVolume := settings.BGMVolume
+	if bgmVolume <= 0 {
+		bgmVolume = defaultBGMVolume
+	}
+	dialogueVolume := settings.DialogueVolume
+	if dialogueVolume <= 0 {
+		dialogueVolume = defaultDialogueVolume
+	}
+	effectVolume := settings.EffectVolume
+	if effectVolume <= 0 {
+		effectVolume = defaultEffectVolume
+	}
+
+	defaultBGM := s.findDefaultBGM(videoMerge, settings)
+
+	// 背景音乐段落：相邻分镜使用同一首时合并为一段循环播放
+	var currentBGM *models.Asset
+	var bgmStart float64
+
+	flushBGM := func(end float64) {
+		if currentBGM != nil && end > bgmStart {
+			opts.BGM = append(opts.BGM, ffmpeg.AudioTrack{
+				Path:     s.resolveAssetPath(currentBGM),
+				Offset:   bgmStart,
+				Duration: end - bgmStart,
+				Volume:   bgmVolume,
+				Loop:     true,
+			})
+		}
+	}
+
+	var offset float64
+	for _, clip := range scenes {
+		clipDuration := clipTimelineDuration(clip)
+
+		var storyboard models.Storyboard
+		hasStoryboard := clip.SceneID != 0 && s.db.Where("id = ?", clip.SceneID).First(&storyboard).Error == nil
+
+		var clipBGM *models.Asset
+		if hasStoryboard {
+			// 对白：同一分镜的多条对白依次排列，超出分镜时长的部分截断，不延续到下一个分镜
+			dialogueOffset := offset + dialogueLeadIn
+			clipEnd := offset + clipDuration
+			for _, asset := range s.findAudioAssets(storyboard.ID, models.AssetCategoryDialogue) {
+				if dialogueOffset >= clipEnd {
+					s.log.Warnw("Dialogue exceeds storyboard duration, skipping", "asset_id", asset.ID, "storyboard_id", storyboard.ID)
+					break
+				}
+				asset := asset
+				path := s.resolveAssetPath(&asset)
+				duration, err := s.audioDuration(&asset, path)
+				if err != nil {
+					s.log.Warnw("Failed to get dialogue duration, skipping", "asset_id", asset.ID, "error", err)
+					continue
+				}
+				track := ffmpeg.AudioTrack{
+					Path:   path,
+					Offset: dialogueOffset,
+					Volume: dialogueVolume,
+				}
+				if dialogueOffset+duration > clipEnd {
+					track.Duration = clipEnd - dialogueOffset
+				}
+				opts.Dialogue = append(opts.Dialogue, track)
+				dialogueOffset += duration
+			}
+
+			// 音效：分镜关联素材优先，其次按 SoundEffect 描述匹配
+			effects := s.findAudioAssets(storyboard.ID, models.AssetCategorySoundEffect)
+			if len(effects) == 0 && storyboard.SoundEffect != nil {
+				if asset := s.findAudioAssetByPrompt(videoMerge.DramaID, models.AssetCategorySoundEffect, *storyboard.SoundEffect); asset != nil {
+					effects = append(effects, *asset)
+				}
+			}
+			for _, asset := range effects {
+				asset := asset
+				opts.Effects = append(opts.Effects, ffmpeg.AudioTrack{
+					Path:     s.resolveAssetPath(&asset),
+					Offset:   offset,
+					Duration: clipDuration,
+					Volume:   effectVolume,
+				})
+			}
+
+			// 背景音乐：分镜关联素材优先，其次按 BgmPrompt 匹配
+			if bgms := s.findAudioAssets(storyboard.ID, models.AssetCategoryBGM); len(bgms) > 0 {
+				clipBGM = &bgms[0]
+			} else if storyboard.BgmPrompt != nil {
+				clipBGM = s.findAudioAssetByPrompt(videoMerge.DramaID, models.AssetCategoryBGM, *storyboard.BgmPrompt)
+			}
+		}
+		if clipBGM == nil {
+			clipBGM = defaultBGM
+		}
+
+		if currentBGM == nil || clipBGM == nil || currentBGM.ID != clipBGM.ID {
+			flushBGM(offset)
+			currentBGM = clipBGM
+			bgmStart = offset
+		}
+
+		offset += clipDuration
+	}
+	flushBGM(offset)
+
+	return opts
+}
+
+// applyAudioMix 对合成结果进行混音，返回新的相对路径
+func (s *VideoMergeService) applyAudioMix(videoMerge *models.VideoMerge, scenes []models.SceneClip, settings *AudioMixSettings, mergedRelPath string) (string, error) {
+	opts := s.buildAudioMixOptions(videoMerge, scenes, settings)
+	if len(opts.Dialogue) == 0 && len(opts.BGM) == 0 && len(opts.Effects) == 0 {
+		s.log.Infow("No dialogue, BGM or sound effect assets found, skipping audio mix", "merge_id", videoMerge.ID)
+		return mergedRelPath, nil
+	}
+
+	fileName := fmt.Sprintf("merged_%d_mixed.mp4", time.Now().UnixNano())
+	relPath := filepath.Join("videos", "merged", fileName)
+	opts.VideoPath = filepath.Join(s.storagePath, mergedRelPath)
+	opts.OutputPath = filepath.Join(s.storagePath, relPath)
+
+	if _, err := s.ffmpeg.MixSoundtrack(opts); err != nil {
+		return "", err
+	}
+
+	s.log.Infow("Episode soundtrack mixed",
+		"merge_id", videoMerge.ID,
+		"dialogue", len(opts.Dialogue),
+		"bgm", len(opts.BGM),
+		"effects", len(opts.Effects),
+		"output", relPath)
+
+	return relPath, nil
+}
+
+// normalizeLoudness 对成片做响度标准化，返回新的相对路径
+func (s *VideoMergeService) normalizeLoudness(videoMerge *models.VideoMerge, targetLUFS float64, relPath string) (string, error) {
+	fileName := fmt.Sprintf("merged_%d_loudnorm.mp4", time.Now().UnixNano())
+	outputRelPath := filepath.Join("videos", "merged", fileName)
+	if _, err := s.ffmpeg.NormalizeLoudness(filepath.Join(s.storagePath, relPath), filepath.Join(s.storagePath, outputRelPath), targetLUFS); err != nil {
+		return "", err
+	}
+
+	s.log.Infow("Episode loudness normalized", "merge_id", videoMerge.ID, "target_lufs", targetLUFS, "output", outputRelPath)
+	return outputRelPath, nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/drama-generator/backend/domain/models"
+	"gorm.io/gorm"
+)
+
+func TestDialogueTracksLaidOutSequentially(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		drama, episode := createTestEpisode(t, db)
+		storyboard := &models.Storyboard{EpisodeID: episode.ID, StoryboardNumber: 1}
+		mustCreate(t, db, storyboard)
+
+		// 中间一条没有时长且无法探测，应跳过而不是与其他对白叠在一起
+		category := models.AssetCategoryDialogue
+		two, three := 2, 3
+		for i, duration := range []*int{&two, nil, &three} {
+			asset := &models.Asset{DramaID: &drama.ID, EpisodeID: &episode.ID, StoryboardID: &storyboard.ID,
+				Name: "line", Type: models.AssetTypeAudio, Category: &category, Duration: duration,
+				URL: cfg.Storage.BaseURL + "/audio/dialogue/missing.mp3"}
+			if err := db.Create(asset).Error; err != nil {
+				t.Fatalf("create dialogue %d: %v", i, err)
+			}
+		}
+
+		service := newTestVideoMergeService(t, db, cfg)
+		merge := &models.VideoMerge{EpisodeID: episode.ID, DramaID: drama.ID}
+		opts := service.buildAudioMixOptions(merge, []models.SceneClip{{SceneID: storyboard.ID, Duration: 10}}, &AudioMixSettings{Enabled: true})
+
+		if len(opts.Dialogue) != 2 {
+			t.Fatalf("expected 2 dialogue tracks, got %d", len(opts.Dialogue))
+		}
+		if opts.Dialogue[0].Offset != dialogueLeadIn || opts.Dialogue[1].Offset != dialogueLeadIn+2 {
+			t.Fatalf("expected offsets %.1f and %.1f, got %.1f and %.1f",
+				dialogueLeadIn, dialogueLeadIn+2, opts.Dialogue[0].Offset, opts.Dialogue[1].Offset)
+		}
+		if opts.Dialogue[1].Duration != 0 {
+			t.Fatalf("dialogue within clip should not be trimmed, got %.1f", opts.Dialogue[1].Duration)
+		}
+
+		// 分镜只有 4 秒：第二条对白截断在分镜末尾，不延续到下一个分镜
+		opts = service.buildAudioMixOptions(merge, []models.SceneClip{{SceneID: storyboard.ID, Duration: 4}}, &AudioMixSettings{Enabled: true})
+		if len(opts.Dialogue) != 2 {
+			t.Fatalf("expected 2 dialogue tracks, got %d", len(opts.Dialogue))
+		}
+		if got, want := opts.Dialogue[1].Duration, 4-dialogueLeadIn-2; math.Abs(got-want) > 1e-9 {
+			t.Fatalf("expected last dialogue trimmed to %.1f, got %.1f", want, got)
+		}
+
+		// 分镜短于首条对白之后的时长：后续对白不再排入
+		opts = service.buildAudioMixOptions(merge, []models.SceneClip{{SceneID: storyboard.ID, Duration: 2}}, &AudioMixSettings{Enabled: true})
+		if len(opts.Dialogue) != 1 || opts.Dialogue[0].Duration != 2-dialogueLeadIn {
+			t.Fatalf("expected a single trimmed dialogue track, got %+v", opts.Dialogue)
+		}
+	})
+}
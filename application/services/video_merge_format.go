@@ -0,0 +1,57 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/drama-generator/backend/infrastructure/external/ffmpeg"
+)
+
+// OutputFormatSettings 成片画幅设置
+type OutputFormatSettings struct {
+	AspectRatio string `json:"aspect_ratio"` // 9:16, 16:9, 1:1
+	Width       int    `json:"width"`        // 为空时按画幅比例使用预设分辨率
+	Height      int    `json:"height"`
+	FitMode     string `json:"fit_mode"` // pad, blur, crop, smart_crop
+}
+
+// 预设分辨率
+var aspectRatioPresets = map[string][2]int{
+	"9:16": {1080, 1920},
+	"16:9": {1920, 1080},
+	"1:1":  {1080, 1080},
+	"4:3":  {1440, 1080},
+	"3:4":  {1080, 1440},
+}
+
+// toFFmpegFormat 校验并转换为 FFmpeg 输出参数
+func (o *OutputFormatSettings) toFFmpegFormat() (*ffmpeg.OutputFormat, error) {
+	fitMode, err := ffmpeg.ParseFitMode(o.FitMode)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := o.Width, o.Height
+	if width <= 0 || height <= 0 {
+		preset, ok := aspectRatioPresets[strings.TrimSpace(o.AspectRatio)]
+		if !ok {
+			return nil, fmt.Errorf("unsupported aspect ratio: %s", o.AspectRatio)
+		}
+		width, height = preset[0], preset[1]
+	}
+
+	// libx264 要求宽高为偶数
+	if width%2 != 0 || height%2 != 0 {
+		return nil, fmt.Errorf("resolution must be even: %dx%d", width, height)
+	}
+
+	return &ffmpeg.OutputFormat{Width: width, Height: height, FitMode: fitMode}, nil
+}
+
+// Label 用于标题和日志的画幅描述
+func (o *OutputFormatSettings) Label() string {
+	if o.Width > 0 && o.Height > 0 {
+		return fmt.Sprintf("%dx%d", o.Width, o.Height)
+	}
+	return o.AspectRatio
+}
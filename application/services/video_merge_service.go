@@ -7,12 +7,16 @@ import (
 	"path/filepath"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	models "github.com/drama-generator/backend/domain/models"
 	"github.com/drama-generator/backend/infrastructure/external/ffmpeg"
+	"github.com/drama-generator/backend/infrastructure/storage"
 	"github.com/drama-generator/backend/pkg/logger"
+	"github.com/drama-generator/backend/pkg/subtitle"
 	"github.com/drama-generator/backend/pkg/video"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -20,18 +24,24 @@ type VideoMergeService struct {
 	db              *gorm.DB
 	aiService       *AIService
 	transferService *ResourceTransferService
+	subtitleService *SubtitleService
+	hlsService      *HLSService
 	ffmpeg          *ffmpeg.FFmpeg
+	fileStorage     storage.Storage
 	storagePath     string
 	baseURL         string
 	log             *logger.Logger
 }
 
-func NewVideoMergeService(db *gorm.DB, transferService *ResourceTransferService, storagePath, baseURL string, log *logger.Logger) *VideoMergeService {
+func NewVideoMergeService(db *gorm.DB, transferService *ResourceTransferService, fileStorage storage.Storage, storagePath, baseURL string, log *logger.Logger) *VideoMergeService {
 	return &VideoMergeService{
 		db:              db,
 		aiService:       NewAIService(db, log),
 		transferService: transferService,
-		ffmpeg:          ffmpeg.NewFFmpeg(log),
+		subtitleService: NewSubtitleService(db, fileStorage, log),
+		hlsService:      NewHLSService(db, fileStorage, storagePath, baseURL, log),
+		ffmpeg:          ffmpeg.NewFFmpeg(log, mediaCache(fileStorage)),
+		fileStorage:     fileStorage,
 		storagePath:     storagePath,
 		baseURL:         baseURL,
 		log:             log,
@@ -39,15 +49,21 @@ func NewVideoMergeService(db *gorm.DB, transferService *ResourceTransferService,
 }
 
 type MergeVideoRequest struct {
-	EpisodeID string             `json:"episode_id" binding:"required"`
-	DramaID   string             `json:"drama_id" binding:"required"`
-	Title     string             `json:"title"`
-	Scenes    []models.SceneClip `json:"scenes" binding:"required,min=1"`
-	Provider  string             `json:"provider"`
-	Model     string             `json:"model"`
+	EpisodeID string              `json:"episode_id" binding:"required"`
+	DramaID   string              `json:"drama_id" binding:"required"`
+	Title     string              `json:"title"`
+	Scenes    []models.SceneClip  `json:"scenes" binding:"required,min=1"`
+	Provider  string              `json:"provider"`
+	Model     string              `json:"model"`
+	Options   *MergeOutputOptions `json:"options"`
 }
 
 func (s *VideoMergeService) MergeVideos(req *MergeVideoRequest) (*models.VideoMerge, error) {
+	return s.startMerge(req, false)
+}
+
+// startMerge 创建合成记录并在后台处理；Variant 与 MainOffset 属于内部状态，不接受请求传入的值
+func (s *VideoMergeService) startMerge(req *MergeVideoRequest, variant bool) (*models.VideoMerge, error) {
 	// 验证episode权限
 	var episode models.Episode
 	if err := s.db.Preload("Drama").Where("id = ?", req.EpisodeID).First(&episode).Error; err != nil {
@@ -76,6 +92,25 @@ func (s *VideoMergeService) MergeVideos(req *MergeVideoRequest) (*models.VideoMe
 		"scenes_count", len(req.Scenes),
 		"scenes_json", string(scenesJSON))
 
+	var optionsJSON []byte
+	if req.Options != nil || variant {
+		var options MergeOutputOptions
+		if req.Options != nil {
+			options = *req.Options
+		}
+		if options.Output != nil {
+			if _, err := options.Output.toFFmpegFormat(); err != nil {
+				return nil, err
+			}
+		}
+		options.Variant = variant
+		options.MainOffset = 0
+		optionsJSON, err = json.Marshal(options)
+		if err != nil {
+			return nil, fmt.Errorf("failed to serialize options: %w", err)
+		}
+	}
+
 	epID, _ := strconv.ParseUint(req.EpisodeID, 10, 32)
 	dramaID, _ := strconv.ParseUint(req.DramaID, 10, 32)
 
@@ -86,6 +121,7 @@ func (s *VideoMergeService) MergeVideos(req *MergeVideoRequest) (*models.VideoMe
 		Provider:  provider,
 		Model:     &req.Model,
 		Scenes:    scenesJSON,
+		Options:   optionsJSON,
 		Status:    models.VideoMergeStatusPending,
 	}
 
@@ -120,13 +156,101 @@ func (s *VideoMergeService) processMergeVideo(mergeID uint) {
 		return
 	}
 
+	var options MergeOutputOptions
+	if len(videoMerge.Options) > 0 {
+		if err := json.Unmarshal(videoMerge.Options, &options); err != nil {
+			s.log.Warnw("Failed to parse merge options, skipping post-processing", "error", err, "id", mergeID)
+		}
+	}
+
+	var format *ffmpeg.OutputFormat
+	if options.Output != nil {
+		format, err = options.Output.toFFmpegFormat()
+		if err != nil {
+			s.updateMergeError(mergeID, err.Error())
+			return
+		}
+	}
+
 	// 调用视频合并API
-	result, err := s.mergeVideoClips(client, scenes)
+	result, err := s.mergeVideoClips(client, scenes, format)
 	if err != nil {
 		s.updateMergeError(mergeID, err.Error())
 		return
 	}
 
+	// 合成后处理：混音、字幕烧录、品牌包装、响度标准化，外挂字幕按最终时间轴生成
+	mixed := false
+	if result.Completed && options.AudioMix != nil && options.AudioMix.Enabled {
+		mixedPath, err := s.applyAudioMix(&videoMerge, scenes, options.AudioMix, result.VideoURL)
+		if err != nil {
+			s.updateMergeError(mergeID, fmt.Sprintf("audio mix failed: %v", err))
+			return
+		}
+		mixed = mixedPath != result.VideoURL
+		result.VideoURL = mixedPath
+	}
+	var cues []subtitle.Cue
+	if result.Completed && options.Subtitles != nil && (options.Subtitles.Enabled || options.Subtitles.BurnIn) {
+		cues = s.subtitleService.CuesFromClips(scenes)
+		if len(cues) == 0 {
+			s.log.Infow("No dialogue found, skipping subtitles", "merge_id", mergeID)
+		}
+	}
+	if len(cues) > 0 && options.Subtitles.BurnIn {
+		subtitledPath, err := s.burnSubtitles(&videoMerge, cues, options.Subtitles, result.VideoURL)
+		if err != nil {
+			s.updateMergeError(mergeID, fmt.Sprintf("subtitles failed: %v", err))
+			return
+		}
+		result.VideoURL = subtitledPath
+	}
+	if result.Completed && options.Branding != nil && *options.Branding {
+		var branding models.DramaBranding
+		hasBranding := s.db.Where("drama_id = ? AND enabled = ?", videoMerge.DramaID, true).First(&branding).Error == nil
+
+		// 封面取自正片，避免截到片头或标题卡
+		if !options.Variant && videoMerge.EpisodeID != 0 {
+			var coverBranding *models.DramaBranding
+			if hasBranding {
+				coverBranding = &branding
+			}
+			if err := s.generateCover(&videoMerge, coverBranding, result.VideoURL); err != nil {
+				s.log.Warnw("Failed to generate episode cover", "error", err, "merge_id", mergeID)
+			}
+		}
+
+		if hasBranding {
+			brandedPath, mainOffset, err := s.applyBranding(&videoMerge, &branding, result.VideoURL)
+			if err != nil {
+				s.updateMergeError(mergeID, fmt.Sprintf("branding failed: %v", err))
+				return
+			}
+			result.VideoURL = brandedPath
+			if mainOffset > 0 {
+				options.MainOffset = mainOffset
+				if optionsJSON, err := json.Marshal(options); err == nil {
+					s.db.Model(&videoMerge).Update("options", datatypes.JSON(optionsJSON))
+				}
+			}
+		}
+	}
+	// 响度标准化放在最后，片头片尾的音频也一并标准化
+	if mixed && options.AudioMix.targetLUFS() != 0 {
+		normalizedPath, err := s.normalizeLoudness(&videoMerge, options.AudioMix.targetLUFS(), result.VideoURL)
+		if err != nil {
+			s.updateMergeError(mergeID, fmt.Sprintf("loudness normalization failed: %v", err))
+			return
+		}
+		result.VideoURL = normalizedPath
+	}
+	if len(cues) > 0 && options.Subtitles.Enabled {
+		if err := s.attachSubtitles(&videoMerge, cues, options.MainOffset); err != nil {
+			s.updateMergeError(mergeID, fmt.Sprintf("subtitles failed: %v", err))
+			return
+		}
+	}
+
 	if !result.Completed {
 		s.db.Model(&videoMerge).Updates(map[string]interface{}{
 			"status":  models.VideoMergeStatusProcessing,
@@ -136,10 +260,18 @@ func (s *VideoMergeService) processMergeVideo(mergeID uint) {
 		return
 	}
 
+	// FFmpeg 在本地存储目录生成成片，写入存储后端（S3 时上传）
+	if !strings.HasPrefix(result.VideoURL, "http://") && !strings.HasPrefix(result.VideoURL, "https://") {
+		if _, err := publishMedia(s.fileStorage, s.storagePath, result.VideoURL); err != nil {
+			s.updateMergeError(mergeID, err.Error())
+			return
+		}
+	}
+
 	s.completeMerge(mergeID, result)
 }
 
-func (s *VideoMergeService) mergeVideoClips(client video.VideoClient, scenes []models.SceneClip) (*video.VideoResult, error) {
+func (s *VideoMergeService) mergeVideoClips(client video.VideoClient, scenes []models.SceneClip, format *ffmpeg.OutputFormat) (*video.VideoResult, error) {
 	if len(scenes) == 0 {
 		return nil, fmt.Errorf("no scenes to merge")
 	}
@@ -189,13 +321,14 @@ func (s *VideoMergeService) mergeVideoClips(client video.VideoClient, scenes []m
 	}
 
 	// 生成输出文件名
-	fileName := fmt.Sprintf("merged_%d.mp4", time.Now().Unix())
+	fileName := fmt.Sprintf("merged_%d.mp4", time.Now().UnixNano())
 	outputPath := filepath.Join(videoDir, fileName)
 
 	// 使用FFmpeg合成视频
 	mergedPath, err := s.ffmpeg.MergeVideos(&ffmpeg.MergeOptions{
 		OutputPath: outputPath,
 		Clips:      clips,
+		Format:     format,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("ffmpeg merge failed: %w", err)
@@ -270,13 +403,25 @@ func (s *VideoMergeService) completeMerge(mergeID uint, result *video.VideoResul
 
 	s.db.Model(&models.VideoMerge{}).Where("id = ?", mergeID).Updates(updates)
 
+	// 附加画幅版本不覆盖剧集的最终视频
+	var options MergeOutputOptions
+	if len(videoMerge.Options) > 0 {
+		json.Unmarshal(videoMerge.Options, &options)
+	}
+
 	// 更新episode的状态和最终视频URL
-	if videoMerge.EpisodeID != 0 {
+	if videoMerge.EpisodeID != 0 && !options.Variant {
 		s.db.Model(&models.Episode{}).Where("id = ?", videoMerge.EpisodeID).Updates(map[string]interface{}{
 			"status":    "completed",
 			"video_url": finalVideoURL,
 		})
 		s.log.Infow("Episode finalized", "episode_id", videoMerge.EpisodeID, "video_url", finalVideoURL)
+
+		if options.HLS != nil && options.HLS.Enabled {
+			if _, err := s.hlsService.PackageEpisode(videoMerge.EpisodeID, options.HLS); err != nil {
+				s.log.Errorw("Failed to start HLS packaging", "error", err, "episode_id", videoMerge.EpisodeID)
+			}
+		}
 	}
 
 	s.log.Infow("Video merge completed", "id", mergeID, "url", finalVideoURL)
@@ -404,8 +549,10 @@ func getAssetIDString(assetID interface{}) string {
 
 // FinalizeEpisodeRequest 完成剧集制作请求
 type FinalizeEpisodeRequest struct {
-	EpisodeID string         `json:"episode_id"`
-	Clips     []TimelineClip `json:"clips"`
+	EpisodeID string                 `json:"episode_id"`
+	Clips     []TimelineClip         `json:"clips"`
+	Variants  []OutputFormatSettings `json:"variants"` // 同时渲染多个画幅，第一个（或 output）作为剧集主视频
+	MergeOutputOptions
 }
 
 // FinalizeEpisode 完成集数制作，根据时间线场景顺序合成最终视频
@@ -621,6 +768,21 @@ func (s *VideoMergeService) FinalizeEpisode(episodeID string, timelineData *Fina
 		Scenes:    sceneClips,
 		Provider:  "doubao", // 默认使用doubao
 	}
+	var variants []OutputFormatSettings
+	var options MergeOutputOptions
+	if timelineData != nil {
+		options = timelineData.MergeOutputOptions
+		variants = timelineData.Variants
+		if options.Output == nil && len(variants) > 0 {
+			options.Output = &variants[0]
+			variants = variants[1:]
+		}
+	}
+	if options.Branding == nil {
+		branding := true
+		options.Branding = &branding
+	}
+	finalReq.Options = &options
 
 	// 执行视频合成
 	videoMerge, err := s.MergeVideos(finalReq)
@@ -628,6 +790,23 @@ func (s *VideoMergeService) FinalizeEpisode(episodeID string, timelineData *Fina
 		return nil, fmt.Errorf("failed to start video merge: %w", err)
 	}
 
+	// 其他画幅版本各自创建合成任务
+	var variantMergeIDs []uint
+	for i := range variants {
+		variantOptions := *finalReq.Options
+		variantOptions.Output = &variants[i]
+		variantReq := *finalReq
+		variantReq.Title = fmt.Sprintf("%s (%s)", title, variants[i].Label())
+		variantReq.Scenes = append([]models.SceneClip(nil), sceneClips...)
+		variantReq.Options = &variantOptions
+
+		variantMerge, err := s.startMerge(&variantReq, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to start %s variant merge: %w", variants[i].Label(), err)
+		}
+		variantMergeIDs = append(variantMergeIDs, variantMerge.ID)
+	}
+
 	// 更新episode状态为processing
 	s.db.Model(&episode).Updates(map[string]interface{}{
 		"status": "processing",
@@ -640,6 +819,10 @@ func (s *VideoMergeService) FinalizeEpisode(episodeID string, timelineData *Fina
 		"scenes_count": len(sceneClips),
 	}
 
+	if len(variantMergeIDs) > 0 {
+		result["variant_merge_ids"] = variantMergeIDs
+	}
+
 	// 如果有跳过的场景，添加提示信息
 	if len(skippedScenes) > 0 {
 		result["skipped_scenes"] = skippedScenes
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/drama-generator/backend/domain/models"
+	"gorm.io/gorm"
+)
+
+func TestMergeVideosIgnoresInternalOptions(t *testing.T) {
+	forEachDatabase(t, func(t *testing.T, db *gorm.DB) {
+		cfg := testConfig(t)
+		drama, episode := createTestEpisode(t, db)
+		service := newTestVideoMergeService(t, db, cfg)
+
+		// 客户端传入的 variant/main_offset 不应写入合成记录，否则会跳过剧集更新并平移字幕
+		merge, err := service.MergeVideos(&MergeVideoRequest{
+			EpisodeID: fmt.Sprint(episode.ID),
+			DramaID:   fmt.Sprint(drama.ID),
+			Scenes:    []models.SceneClip{{VideoURL: "clip.mp4", Duration: 3}},
+			Options:   &MergeOutputOptions{Variant: true, MainOffset: 7},
+		})
+		if err != nil {
+			t.Fatalf("merge videos: %v", err)
+		}
+
+		var options MergeOutputOptions
+		if err := json.Unmarshal(merge.Options, &options); err != nil {
+			t.Fatalf("decode options: %v", err)
+		}
+		if options.Variant || options.MainOffset != 0 {
+			t.Fatalf("internal options persisted: %+v", options)
+		}
+
+		// 未配置视频服务，后台处理很快失败；等待结束后再关闭数据库
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			var saved models.VideoMerge
+			if err := db.First(&saved, merge.ID).Error; err == nil && saved.Status == models.VideoMergeStatusFailed {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("merge did not finish")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/infrastructure/database"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+)
+
+// 素材元数据回填工具：为已有素材补全尺寸、时长、编码、帧率、码率等信息
+//
+//	go run ./cmd/backfill-metadata            # 仅处理未探测过的素材
+//	go run ./cmd/backfill-metadata -force     # 重新探测全部素材
+//	go run ./cmd/backfill-metadata -previews  # 同时生成缺失的缩略图、雪碧图、波形
+func main() {
+	force := flag.Bool("force", false, "重新探测全部素材（默认只处理未探测过的素材）")
+	batchSize := flag.Int("batch", 100, "每批处理的素材数量")
+	previews := flag.Bool("previews", false, "同时生成缺失的预览资源（缩略图、封面帧、雪碧图、波形）")
+	flag.Parse()
+
+	fmt.Println("=== 素材元数据回填 ===")
+	fmt.Println("开始时间:", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Println()
+
+	logr := logger.NewLogger(false)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logr.Fatalw("加载配置失败", "error", err)
+	}
+
+	db, err := database.NewDatabase(cfg.Database)
+	if err != nil {
+		logr.Fatalw("数据库连接失败", "error", err)
+	}
+	if err := database.CheckMigrations(db); err != nil {
+		logr.Fatalw("数据库表结构未迁移", "error", err)
+	}
+
+	fileStorage, err := storage.NewStorage(cfg.Storage)
+	if err != nil {
+		logr.Fatalw("初始化存储失败", "error", err)
+	}
+
+	assetService := services.NewAssetService(db, fileStorage, cfg.Storage.LocalPath, cfg.Storage.BaseURL, logr)
+	stats, err := assetService.BackfillMetadata(*force, *batchSize)
+	if err != nil {
+		logr.Fatalw("元数据回填失败", "error", err)
+	}
+
+	fmt.Println()
+	fmt.Printf("元数据 处理: %d  成功: %d  失败: %d\n", stats.Total, stats.Success, stats.Failed)
+
+	if *previews {
+		previewStats, err := assetService.BackfillPreviews(*force, *batchSize)
+		if err != nil {
+			logr.Fatalw("预览回填失败", "error", err)
+		}
+		fmt.Printf("预览 处理: %d  成功: %d  失败: %d\n", previewStats.Total, previewStats.Success, previewStats.Failed)
+	}
+	fmt.Println("结束时间:", time.Now().Format("2006-01-02 15:04:05"))
+}
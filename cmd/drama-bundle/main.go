@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/infrastructure/database"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+)
+
+// 剧本导入导出工具：在不同实例之间迁移剧本（数据 + 媒体文件）
+//
+//	go run ./cmd/drama-bundle export -drama 12 -o drama_12.zip
+//	go run ./cmd/drama-bundle import drama_12.zip
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	logr := logger.NewLogger(false)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logr.Fatalw("加载配置失败", "error", err)
+	}
+
+	db, err := database.NewDatabase(cfg.Database)
+	if err != nil {
+		logr.Fatalw("数据库连接失败", "error", err)
+	}
+	if err := database.CheckMigrations(db); err != nil {
+		logr.Fatalw("数据库表结构未迁移", "error", err)
+	}
+
+	fileStorage, err := storage.NewStorage(cfg.Storage)
+	if err != nil {
+		logr.Fatalw("初始化存储失败", "error", err)
+	}
+	bundleService := services.NewDramaBundleService(db, cfg, storage.NewDedupStorage(fileStorage, db), logr)
+
+	switch os.Args[1] {
+	case "export":
+		fs := flag.NewFlagSet("export", flag.ExitOnError)
+		dramaID := fs.Uint("drama", 0, "要导出的剧本ID")
+		output := fs.String("o", "", "输出文件，默认 drama_<id>.zip")
+		fs.Parse(os.Args[2:])
+		if *dramaID == 0 {
+			usage()
+		}
+		if *output == "" {
+			*output = fmt.Sprintf("drama_%d.zip", *dramaID)
+		}
+
+		f, err := os.Create(*output)
+		if err != nil {
+			logr.Fatalw("创建输出文件失败", "error", err)
+		}
+		bundle, err := bundleService.ExportDrama(*dramaID, f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(*output)
+			logr.Fatalw("导出失败", "error", err)
+		}
+		fmt.Printf("已导出《%s》: %d 集, %d 个分镜, %d 个媒体文件 -> %s\n",
+			bundle.Drama.Title, len(bundle.Episodes), len(bundle.Storyboards), len(bundle.Media), *output)
+
+	case "import":
+		if len(os.Args) < 3 {
+			usage()
+		}
+		f, err := os.Open(os.Args[2])
+		if err != nil {
+			logr.Fatalw("打开文件失败", "error", err)
+		}
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			logr.Fatalw("读取文件失败", "error", err)
+		}
+
+		drama, err := bundleService.ImportDrama(f, info.Size())
+		if err != nil {
+			logr.Fatalw("导入失败", "error", err)
+		}
+		fmt.Printf("已导入《%s》，新剧本ID: %d\n", drama.Title, drama.ID)
+
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "用法:")
+	fmt.Fprintln(os.Stderr, "  drama-bundle export -drama <id> [-o file.zip]")
+	fmt.Fprintln(os.Stderr, "  drama-bundle import <file.zip>")
+	os.Exit(2)
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/infrastructure/database"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// dramactl 无界面运维与批量生产工具，直接复用应用层服务，异步任务在本进程内执行并等待完成
+//
+//	go run ./cmd/dramactl drama create -title "夜行列车" ep1.txt ep2.txt
+//	go run ./cmd/dramactl storyboard generate -episode 12
+//	go run ./cmd/dramactl images generate -episode 12
+//	go run ./cmd/dramactl videos generate -episode 12
+//	go run ./cmd/dramactl episode finalize -episode 12
+//
+// 任一步骤失败时以非零状态码退出，便于在脚本中串联整夜的生产任务
+
+// app 命令共享的依赖
+type app struct {
+	cfg         *config.Config
+	db          *gorm.DB
+	log         *logger.Logger
+	fileStorage storage.Storage
+	transfer    *services.ResourceTransferService
+	tasks       *services.TaskService
+}
+
+type command struct {
+	usage string
+	run   func(a *app, args []string) error
+}
+
+var commands = map[string]map[string]command{
+	"drama": {
+		"create": {"drama create -title <标题> [-genre 类型] [-style 风格] <剧本文件>...   每个文件创建一集", dramaCreate},
+		"list":   {"drama list [-keyword 关键字]", dramaList},
+	},
+	"storyboard": {
+		"generate": {"storyboard generate -episode <ID> [-model 模型]   拆解分镜并等待完成", storyboardGenerate},
+	},
+	"images": {
+		"generate": {"images generate -episode <ID>   为分镜批量生成图片并等待完成", imagesGenerate},
+	},
+	"videos": {
+		"generate": {"videos generate -episode <ID>   用已完成的分镜图片批量生成视频并等待完成", videosGenerate},
+	},
+	"episode": {
+		"finalize": {"episode finalize -episode <ID>   按分镜顺序合成成片并等待完成", episodeFinalize},
+	},
+	"bundle": {
+		"export": {"bundle export -drama <ID> [-o file.zip]", bundleExport},
+		"import": {"bundle import <file.zip>", bundleImport},
+	},
+	"gc": {
+		"run": {"gc run [-dry-run] [-min-age 7] [-purge-after 30]", gcRun},
+	},
+	"tasks": {
+		"list":   {"tasks list [-status 状态] [-type 类型] [-limit 50]", tasksList},
+		"cancel": {"tasks cancel <任务ID>", tasksCancel},
+		"wait":   {"tasks wait <任务ID>", tasksWait},
+	},
+	"ai-config": {
+		"list":    {"ai-config list [-type text|image|video]", aiConfigList},
+		"create":  {"ai-config create -type <类型> -name <名称> -provider <服务商> -base-url <URL> -api-key <KEY> -model <m1,m2> [-default] [-priority N]", aiConfigCreate},
+		"enable":  {"ai-config enable|disable -id <ID>", aiConfigEnable},
+		"disable": {"", aiConfigDisable},
+		"delete":  {"ai-config delete -id <ID>", aiConfigDelete},
+		"test":    {"ai-config test -id <ID>   测试连接", aiConfigTest},
+	},
+}
+
+func main() {
+	if len(os.Args) < 3 {
+		usage()
+	}
+	group, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+	cmd, ok := group[os.Args[2]]
+	if !ok {
+		usage()
+	}
+
+	a, err := newApp()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "初始化失败:", err)
+		os.Exit(1)
+	}
+	if err := cmd.run(a, os.Args[3:]); err != nil {
+		fmt.Fprintln(os.Stderr, "执行失败:", err)
+		os.Exit(1)
+	}
+}
+
+func newApp() (*app, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("加载配置失败: %w", err)
+	}
+	logr := logger.NewLogger(cfg.App.Debug)
+
+	db, err := database.NewDatabase(cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("数据库连接失败: %w", err)
+	}
+	if err := database.CheckMigrations(db); err != nil {
+		return nil, err
+	}
+
+	fileStorage, err := storage.NewStorage(cfg.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("初始化存储失败: %w", err)
+	}
+	dedupStorage := storage.NewDedupStorage(fileStorage, db)
+
+	return &app{
+		cfg:         cfg,
+		db:          db,
+		log:         logr,
+		fileStorage: dedupStorage,
+		transfer:    services.NewResourceTransferService(db, dedupStorage, logr),
+		tasks:       services.NewTaskService(db, logr),
+	}, nil
+}
+
+func usage() {
+	groups := make([]string, 0, len(commands))
+	for name := range commands {
+		groups = append(groups, name)
+	}
+	sort.Strings(groups)
+
+	fmt.Fprintln(os.Stderr, "用法: dramactl <命令组> <命令> [参数]")
+	for _, name := range groups {
+		actions := make([]string, 0, len(commands[name]))
+		for action := range commands[name] {
+			actions = append(actions, action)
+		}
+		sort.Strings(actions)
+		for _, action := range actions {
+			if u := commands[name][action].usage; u != "" {
+				fmt.Fprintln(os.Stderr, "  dramactl "+strings.TrimSpace(u))
+			}
+		}
+	}
+	os.Exit(2)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/domain/models"
+)
+
+func bundleExport(a *app, args []string) error {
+	fs := flag.NewFlagSet("bundle export", flag.ExitOnError)
+	dramaID := fs.Uint("drama", 0, "要导出的剧本ID")
+	output := fs.String("o", "", "输出文件，默认 drama_<id>.zip")
+	fs.Parse(args)
+	if *dramaID == 0 {
+		return errors.New("需要 -drama")
+	}
+	if *output == "" {
+		*output = fmt.Sprintf("drama_%d.zip", *dramaID)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	bundle, err := services.NewDramaBundleService(a.db, a.cfg, a.fileStorage, a.log).ExportDrama(*dramaID, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(*output)
+		return err
+	}
+	fmt.Printf("已导出《%s》: %d 集, %d 个分镜, %d 个媒体文件 -> %s\n",
+		bundle.Drama.Title, len(bundle.Episodes), len(bundle.Storyboards), len(bundle.Media), *output)
+	return nil
+}
+
+func bundleImport(a *app, args []string) error {
+	if len(args) != 1 {
+		return errors.New("需要一个 zip 文件")
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	drama, err := services.NewDramaBundleService(a.db, a.cfg, a.fileStorage, a.log).ImportDrama(f, info.Size())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已导入剧本 #%d 《%s》\n", drama.ID, drama.Title)
+	return nil
+}
+
+func gcRun(a *app, args []string) error {
+	fs := flag.NewFlagSet("gc run", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "只输出报告，不移动或删除文件")
+	minAge := fs.Int("min-age", 7, "只隔离修改时间早于 N 天的孤儿文件")
+	purgeAfter := fs.Int("purge-after", 30, "隔离超过 N 天的文件将被删除")
+	fs.Parse(args)
+
+	report, err := services.NewStorageGCService(a.db, a.cfg, a.log).Run(services.StorageGCOptions{
+		DryRun:         *dryRun,
+		MinAgeDays:     *minAge,
+		PurgeAfterDays: *purgeAfter,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("扫描 %d 个文件 (%d 字节)，被引用 %d 个\n", report.ScannedFiles, report.ScannedBytes, report.ReferencedFiles)
+	fmt.Printf("孤儿 %d 个 (%d 字节)，隔离 %d 个，删除 %d 个\n", report.OrphanFiles, report.OrphanBytes, report.QuarantinedFiles, report.PurgedFiles)
+	if report.DryRun {
+		fmt.Println("dry-run：未移动或删除任何文件")
+	}
+	return nil
+}
+
+func tasksList(a *app, args []string) error {
+	fs := flag.NewFlagSet("tasks list", flag.ExitOnError)
+	status := fs.String("status", "", "按状态过滤：pending, processing, completed, failed, cancelled")
+	taskType := fs.String("type", "", "按任务类型过滤")
+	limit := fs.Int("limit", 50, "最多列出的数量")
+	fs.Parse(args)
+
+	tasks, err := a.tasks.ListTasks(*status, *taskType, *limit)
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		fmt.Printf("%s  %-10s %3d%%  %-24s resource=%s  %s\n",
+			t.ID, t.Status, t.Progress, t.Type, t.ResourceID, t.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+	return nil
+}
+
+func tasksCancel(a *app, args []string) error {
+	if len(args) != 1 {
+		return errors.New("需要任务ID")
+	}
+	task, err := a.tasks.CancelTask(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已取消任务 %s (%s)\n", task.ID, task.Type)
+	return nil
+}
+
+func tasksWait(a *app, args []string) error {
+	fs := flag.NewFlagSet("tasks wait", flag.ExitOnError)
+	wait := addWaitFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errors.New("需要任务ID")
+	}
+
+	task, err := a.waitTask(fs.Arg(0), wait)
+	if err != nil {
+		return err
+	}
+	fmt.Println("任务完成:", task.Result)
+	return nil
+}
+
+func aiConfigList(a *app, args []string) error {
+	fs := flag.NewFlagSet("ai-config list", flag.ExitOnError)
+	serviceType := fs.String("type", "", "服务类型：text, image, video")
+	fs.Parse(args)
+
+	configs, err := services.NewAIService(a.db, a.log).ListConfigs(*serviceType)
+	if err != nil {
+		return err
+	}
+	for _, c := range configs {
+		flags := []string{}
+		if c.IsDefault {
+			flags = append(flags, "默认")
+		}
+		if !c.IsActive {
+			flags = append(flags, "已停用")
+		}
+		fmt.Printf("#%-4d %-6s %-12s %-20s 优先级 %-3d %s  %s\n",
+			c.ID, c.ServiceType, c.Provider, c.Name, c.Priority, strings.Join(c.Model, ","), strings.Join(flags, " "))
+	}
+	return nil
+}
+
+func aiConfigCreate(a *app, args []string) error {
+	fs := flag.NewFlagSet("ai-config create", flag.ExitOnError)
+	req := &services.CreateAIConfigRequest{}
+	model := fs.String("model", "", "模型名称，多个用逗号分隔")
+	fs.StringVar(&req.ServiceType, "type", "", "服务类型：text, image, video")
+	fs.StringVar(&req.Name, "name", "", "配置名称")
+	fs.StringVar(&req.Provider, "provider", "", "服务商")
+	fs.StringVar(&req.BaseURL, "base-url", "", "接口地址")
+	fs.StringVar(&req.APIKey, "api-key", "", "API Key")
+	fs.StringVar(&req.Endpoint, "endpoint", "", "生成接口路径，默认按服务商自动设置")
+	fs.StringVar(&req.QueryEndpoint, "query-endpoint", "", "查询接口路径")
+	fs.IntVar(&req.Priority, "priority", 0, "优先级，数值越大越优先")
+	fs.BoolVar(&req.IsDefault, "default", false, "设为该类型的默认配置")
+	fs.Parse(args)
+
+	req.Model = splitModels(*model)
+	if req.ServiceType == "" || req.Name == "" || req.Provider == "" || req.BaseURL == "" || req.APIKey == "" || len(req.Model) == 0 {
+		return errors.New("需要 -type -name -provider -base-url -api-key -model")
+	}
+
+	config, err := services.NewAIService(a.db, a.log).CreateConfig(req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已创建配置 #%d %s\n", config.ID, config.Name)
+	return nil
+}
+
+func aiConfigEnable(a *app, args []string) error {
+	return setAIConfigActive(a, "ai-config enable", args, true)
+}
+
+func aiConfigDisable(a *app, args []string) error {
+	return setAIConfigActive(a, "ai-config disable", args, false)
+}
+
+func setAIConfigActive(a *app, name string, args []string, active bool) error {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	id := fs.Uint("id", 0, "配置ID")
+	fs.Parse(args)
+	if *id == 0 {
+		return errors.New("需要 -id")
+	}
+
+	// UpdateConfig 会整体覆盖 is_active/is_default，这里只改启用状态
+	result := a.db.Model(&models.AIServiceConfig{}).Where("id = ?", *id).Update("is_active", active)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("配置 #%d 不存在", *id)
+	}
+	fmt.Printf("配置 #%d 已%s\n", *id, map[bool]string{true: "启用", false: "停用"}[active])
+	return nil
+}
+
+func aiConfigDelete(a *app, args []string) error {
+	fs := flag.NewFlagSet("ai-config delete", flag.ExitOnError)
+	id := fs.Uint("id", 0, "配置ID")
+	fs.Parse(args)
+	if *id == 0 {
+		return errors.New("需要 -id")
+	}
+
+	if err := services.NewAIService(a.db, a.log).DeleteConfig(*id); err != nil {
+		return err
+	}
+	fmt.Printf("已删除配置 #%d\n", *id)
+	return nil
+}
+
+func aiConfigTest(a *app, args []string) error {
+	fs := flag.NewFlagSet("ai-config test", flag.ExitOnError)
+	id := fs.Uint("id", 0, "配置ID")
+	fs.Parse(args)
+	if *id == 0 {
+		return errors.New("需要 -id")
+	}
+
+	aiService := services.NewAIService(a.db, a.log)
+	config, err := aiService.GetConfig(*id)
+	if err != nil {
+		return err
+	}
+	if err := aiService.TestConnection(&services.TestConnectionRequest{
+		BaseURL:  config.BaseURL,
+		APIKey:   config.APIKey,
+		Model:    config.Model,
+		Provider: config.Provider,
+		Endpoint: config.Endpoint,
+	}); err != nil {
+		return fmt.Errorf("连接失败: %w", err)
+	}
+	fmt.Printf("配置 #%d 连接正常\n", config.ID)
+	return nil
+}
+
+func splitModels(value string) models.ModelField {
+	var result models.ModelField
+	for _, m := range strings.Split(value, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			result = append(result, m)
+		}
+	}
+	return result
+}
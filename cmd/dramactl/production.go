@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/domain/models"
+)
+
+func dramaCreate(a *app, args []string) error {
+	fs := flag.NewFlagSet("drama create", flag.ExitOnError)
+	title := fs.String("title", "", "剧本标题")
+	description := fs.String("description", "", "剧本简介")
+	genre := fs.String("genre", "", "类型")
+	style := fs.String("style", "", "画面风格")
+	fs.Parse(args)
+	if *title == "" || fs.NArg() == 0 {
+		return errors.New("需要 -title 和至少一个剧本文件")
+	}
+
+	// 先读取全部文件，避免创建到一半失败
+	var episodes []models.Episode
+	for i, path := range fs.Args() {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("读取剧本文件失败: %w", err)
+		}
+		script := strings.TrimSpace(string(content))
+		if script == "" {
+			return fmt.Errorf("剧本文件为空: %s", path)
+		}
+		episodes = append(episodes, models.Episode{
+			EpisodeNum:    i + 1,
+			Title:         strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+			ScriptContent: &script,
+		})
+	}
+
+	dramaService := services.NewDramaService(a.db, a.cfg, a.log)
+	drama, err := dramaService.CreateDrama(&services.CreateDramaRequest{
+		Title:       *title,
+		Description: *description,
+		Genre:       *genre,
+		Style:       *style,
+	})
+	if err != nil {
+		return err
+	}
+	dramaID := fmt.Sprintf("%d", drama.ID)
+	if err := dramaService.SaveEpisodes(dramaID, &services.SaveEpisodesRequest{Episodes: episodes}); err != nil {
+		return err
+	}
+
+	var created []models.Episode
+	a.db.Where("drama_id = ?", drama.ID).Order("episode_number ASC").Find(&created)
+	fmt.Printf("已创建剧本 #%d 《%s》\n", drama.ID, drama.Title)
+	for _, ep := range created {
+		fmt.Printf("  第%d集 #%d %s\n", ep.EpisodeNum, ep.ID, ep.Title)
+	}
+	return nil
+}
+
+func dramaList(a *app, args []string) error {
+	fs := flag.NewFlagSet("drama list", flag.ExitOnError)
+	keyword := fs.String("keyword", "", "按标题或简介搜索")
+	limit := fs.Int("limit", 50, "最多列出的数量")
+	fs.Parse(args)
+
+	dramas, total, err := services.NewDramaService(a.db, a.cfg, a.log).ListDramas(&services.DramaListQuery{
+		Page:     1,
+		PageSize: *limit,
+		Keyword:  *keyword,
+	})
+	if err != nil {
+		return err
+	}
+	for _, d := range dramas {
+		fmt.Printf("#%-6d %-12s %d集  %s\n", d.ID, d.Status, len(d.Episodes), d.Title)
+	}
+	fmt.Printf("共 %d 个剧本\n", total)
+	return nil
+}
+
+func storyboardGenerate(a *app, args []string) error {
+	fs := flag.NewFlagSet("storyboard generate", flag.ExitOnError)
+	episodeID := fs.String("episode", "", "章节ID")
+	model := fs.String("model", "", "文本模型，默认使用默认配置")
+	wait := addWaitFlags(fs)
+	fs.Parse(args)
+	if *episodeID == "" {
+		return errors.New("需要 -episode")
+	}
+
+	taskID, err := services.NewStoryboardService(a.db, a.cfg, a.log).GenerateStoryboard(*episodeID, *model)
+	if err != nil {
+		return err
+	}
+	if _, err := a.waitTask(taskID, wait); err != nil {
+		return err
+	}
+
+	var count int64
+	a.db.Model(&models.Storyboard{}).Where("episode_id = ?", *episodeID).Count(&count)
+	fmt.Printf("分镜生成完成，共 %d 个镜头\n", count)
+	return nil
+}
+
+func imagesGenerate(a *app, args []string) error {
+	fs := flag.NewFlagSet("images generate", flag.ExitOnError)
+	episodeID := fs.String("episode", "", "章节ID")
+	wait := addWaitFlags(fs)
+	fs.Parse(args)
+	if *episodeID == "" {
+		return errors.New("需要 -episode")
+	}
+
+	imageService := services.NewImageGenerationService(a.db, a.cfg, a.transfer, a.fileStorage, a.log)
+	generations, err := imageService.BatchGenerateImagesForEpisode(*episodeID)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(generations))
+	for _, g := range generations {
+		ids = append(ids, g.ID)
+	}
+	fmt.Printf("已提交 %d 个图片生成任务\n", len(ids))
+
+	failed, err := a.waitRecords("图片生成", &models.ImageGeneration{}, ids, wait)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个图片生成失败", failed)
+	}
+	return nil
+}
+
+func videosGenerate(a *app, args []string) error {
+	fs := flag.NewFlagSet("videos generate", flag.ExitOnError)
+	episodeID := fs.String("episode", "", "章节ID")
+	wait := addWaitFlags(fs)
+	fs.Parse(args)
+	if *episodeID == "" {
+		return errors.New("需要 -episode")
+	}
+
+	videoService := services.NewVideoGenerationService(a.db, a.transfer, a.fileStorage,
+		services.NewAIService(a.db, a.log), a.log, services.NewPromptI18n(a.cfg))
+	generations, err := videoService.BatchGenerateVideosForEpisode(*episodeID)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, 0, len(generations))
+	for _, g := range generations {
+		ids = append(ids, g.ID)
+	}
+	fmt.Printf("已提交 %d 个视频生成任务\n", len(ids))
+
+	failed, err := a.waitRecords("视频生成", &models.VideoGeneration{}, ids, wait)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个视频生成失败", failed)
+	}
+	return nil
+}
+
+func episodeFinalize(a *app, args []string) error {
+	fs := flag.NewFlagSet("episode finalize", flag.ExitOnError)
+	episodeID := fs.String("episode", "", "章节ID")
+	wait := addWaitFlags(fs)
+	fs.Parse(args)
+	if *episodeID == "" {
+		return errors.New("需要 -episode")
+	}
+
+	mergeService := services.NewVideoMergeService(a.db, a.transfer, a.fileStorage, a.cfg.Storage.LocalPath, a.cfg.Storage.BaseURL, a.log)
+	result, err := mergeService.FinalizeEpisode(*episodeID, nil)
+	if err != nil {
+		return err
+	}
+	if warning, ok := result["warning"].(string); ok {
+		fmt.Println("警告:", warning)
+	}
+
+	ids := []uint{}
+	if mergeID, ok := result["merge_id"].(uint); ok {
+		ids = append(ids, mergeID)
+	}
+	if variantIDs, ok := result["variant_merge_ids"].([]uint); ok {
+		ids = append(ids, variantIDs...)
+	}
+	failed, err := a.waitRecords("视频合成", &models.VideoMerge{}, ids, wait)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个合成任务失败", failed)
+	}
+
+	var episode models.Episode
+	if err := a.db.Where("id = ?", *episodeID).First(&episode).Error; err == nil && episode.VideoURL != nil {
+		fmt.Println("成片:", *episode.VideoURL)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/domain/models"
+)
+
+// waitOptions 等待异步任务的轮询参数
+type waitOptions struct {
+	interval time.Duration
+	timeout  time.Duration
+}
+
+func addWaitFlags(fs *flag.FlagSet) *waitOptions {
+	opts := &waitOptions{}
+	fs.DurationVar(&opts.interval, "interval", 5*time.Second, "轮询间隔")
+	fs.DurationVar(&opts.timeout, "timeout", 2*time.Hour, "最长等待时间，0 表示不限")
+	return opts
+}
+
+// waitTask 等待 AsyncTask 结束，失败或取消时返回错误
+func (a *app) waitTask(taskID string, opts *waitOptions) (*models.AsyncTask, error) {
+	fmt.Printf("等待任务 %s ...\n", taskID)
+	task, err := a.tasks.WaitForTask(taskID, opts.interval, opts.timeout)
+	if err != nil {
+		return task, err
+	}
+	switch task.Status {
+	case "failed":
+		return task, fmt.Errorf("任务 %s 失败: %s", taskID, task.Error)
+	case "cancelled":
+		return task, fmt.Errorf("任务 %s 已取消", taskID)
+	}
+	return task, nil
+}
+
+// waitRecords 等待生成记录（图片、视频、合成）全部进入 completed 或 failed 状态，返回失败数
+func (a *app) waitRecords(label string, model interface{}, ids []uint, opts *waitOptions) (int, error) {
+	lastReport := ""
+	progress, err := a.tasks.WaitForRecords(label, model, ids, opts.interval, opts.timeout, func(p services.RecordProgress) error {
+		report := fmt.Sprintf("%s: %d/%d 完成, %d 失败", label, p.Completed, p.Total, p.Failed)
+		if report != lastReport {
+			fmt.Printf("[%s] %s\n", time.Now().Format("15:04:05"), report)
+			lastReport = report
+		}
+		return nil
+	})
+	return progress.Failed, err
+}
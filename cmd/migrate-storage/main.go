@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/infrastructure/database"
+	"github.com/drama-generator/backend/infrastructure/storage"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+)
+
+// 存储迁移工具：将本地存储目录上传到 S3 兼容存储桶（MinIO 等），并改写数据库中的 URL/local_path
+// 需先在配置中填写 storage.s3，迁移完成后将 storage.type 改为 s3
+//
+//	go run ./cmd/migrate-storage -dry-run     # 仅统计待上传文件与待改写行数
+//	go run ./cmd/migrate-storage              # 上传文件并改写数据库
+//	go run ./cmd/migrate-storage -skip-db     # 只上传文件（可重复执行，已存在的文件跳过）
+func main() {
+	dryRun := flag.Bool("dry-run", false, "只统计，不上传也不修改数据库")
+	skipFiles := flag.Bool("skip-files", false, "跳过文件上传，只改写数据库")
+	skipDB := flag.Bool("skip-db", false, "跳过数据库改写，只上传文件")
+	flag.Parse()
+
+	fmt.Println("=== 存储迁移：本地目录 -> 存储桶 ===")
+	fmt.Println("开始时间:", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Println()
+
+	logr := logger.NewLogger(false)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logr.Fatalw("加载配置失败", "error", err)
+	}
+
+	target, err := storage.NewS3Storage(cfg.Storage.S3, "")
+	if err != nil {
+		logr.Fatalw("初始化存储桶失败", "error", err)
+	}
+	if !*dryRun {
+		if err := target.EnsureBucket(); err != nil {
+			logr.Fatalw("存储桶不可用", "error", err)
+		}
+	}
+
+	db, err := database.NewDatabase(cfg.Database)
+	if err != nil {
+		logr.Fatalw("数据库连接失败", "error", err)
+	}
+
+	service := services.NewStorageMigrationService(db, cfg.Storage, target, logr)
+	stats := &services.StorageMigrationStats{}
+
+	if !*skipFiles {
+		if err := service.MigrateFiles(*dryRun, stats); err != nil {
+			logr.Fatalw("文件上传失败", "error", err)
+		}
+	}
+
+	// 有文件上传失败时不改写数据库，避免引用指向不存在的对象
+	if !*skipDB && stats.Failed == 0 {
+		if err := service.RewriteReferences(*dryRun, stats); err != nil {
+			logr.Fatalw("数据库改写失败", "error", err)
+		}
+	}
+
+	fmt.Println()
+	if *dryRun {
+		fmt.Println("[dry-run] 未做任何修改")
+	}
+	fmt.Printf("文件 总数: %d  上传: %d  跳过: %d  失败: %d  大小: %.1f MB\n",
+		stats.Files, stats.Uploaded, stats.Skipped, stats.Failed, float64(stats.Bytes)/1024/1024)
+	if stats.Failed > 0 && !*skipDB {
+		fmt.Println("存在上传失败的文件，已跳过数据库改写，请修复后重新执行")
+	}
+
+	columns := make([]string, 0, len(stats.RowsRewritten))
+	for column := range stats.RowsRewritten {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	for _, column := range columns {
+		fmt.Printf("  %-40s %d 行\n", column, stats.RowsRewritten[column])
+	}
+	fmt.Println("结束时间:", time.Now().Format("2006-01-02 15:04:05"))
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/drama-generator/backend/application/services"
+	"github.com/drama-generator/backend/infrastructure/database"
+	"github.com/drama-generator/backend/pkg/config"
+	"github.com/drama-generator/backend/pkg/logger"
+)
+
+// 存储清理工具：将不再被数据库引用的文件移入 .quarantine 隔离目录，隔离期满后删除
+// 可配合 cron 定期执行；仅支持本地存储，S3/MinIO 请使用存储桶生命周期规则
+//
+//	go run ./cmd/storage-gc -dry-run                     # 只输出报告
+//	go run ./cmd/storage-gc -min-age 7 -purge-after 30   # 隔离 7 天前的孤儿文件，删除隔离满 30 天的文件
+func main() {
+	dryRun := flag.Bool("dry-run", false, "只输出报告，不移动或删除文件")
+	minAge := flag.Int("min-age", 7, "只隔离修改时间早于 N 天的孤儿文件")
+	purgeAfter := flag.Int("purge-after", 30, "隔离超过 N 天的文件将被删除")
+	list := flag.Int("list", 50, "报告中列出的孤儿文件数量")
+	flag.Parse()
+
+	fmt.Println("=== 存储清理 ===")
+	fmt.Println("开始时间:", time.Now().Format("2006-01-02 15:04:05"))
+	fmt.Println()
+
+	logr := logger.NewLogger(false)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logr.Fatalw("加载配置失败", "error", err)
+	}
+
+	db, err := database.NewDatabase(cfg.Database)
+	if err != nil {
+		logr.Fatalw("数据库连接失败", "error", err)
+	}
+
+	gcService := services.NewStorageGCService(db, cfg, logr)
+	report, err := gcService.Run(services.StorageGCOptions{
+		DryRun:         *dryRun,
+		MinAgeDays:     *minAge,
+		PurgeAfterDays: *purgeAfter,
+		MaxListed:      *list,
+	})
+	if err != nil {
+		logr.Fatalw("存储清理失败", "error", err)
+	}
+
+	fmt.Println()
+	if report.DryRun {
+		fmt.Println("[dry-run] 未做任何修改")
+	}
+	fmt.Printf("扫描文件: %d (%.1f MB)  被引用: %d\n", report.ScannedFiles, mb(report.ScannedBytes), report.ReferencedFiles)
+	fmt.Printf("孤儿文件: %d (%.1f MB)\n", report.OrphanFiles, mb(report.OrphanBytes))
+	fmt.Printf("移入隔离: %d (%.1f MB)\n", report.QuarantinedFiles, mb(report.QuarantinedBytes))
+	fmt.Printf("隔离期满删除: %d (%.1f MB)\n", report.PurgedFiles, mb(report.PurgedBytes))
+	for _, f := range report.Orphans {
+		fmt.Printf("  %s  %s  %d\n", f.ModTime.Format("2006-01-02"), f.Key, f.Size)
+	}
+	if report.Truncated {
+		fmt.Println("  ...")
+	}
+	fmt.Println("结束时间:", time.Now().Format("2006-01-02 15:04:05"))
+}
+
+func mb(bytes int64) float64 {
+	return float64(bytes) / 1024 / 1024
+}
@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -27,6 +28,13 @@ type Asset struct {
 	ThumbnailURL *string   `gorm:"type:varchar(1000)" json:"thumbnail_url,omitempty"`
 	LocalPath    *string   `gorm:"type:varchar(500)" json:"local_path"`
 
+	// 预览资源：视频封面帧/雪碧图，音频波形
+	PosterURL   *string        `gorm:"type:varchar(1000)" json:"poster_url,omitempty"`
+	SpriteURL   *string        `gorm:"type:varchar(1000)" json:"sprite_url,omitempty"`
+	SpriteInfo  datatypes.JSON `gorm:"type:json" json:"sprite_info,omitempty"`
+	WaveformURL *string        `gorm:"type:varchar(1000)" json:"waveform_url,omitempty"` // 波形图 PNG
+	PeaksURL    *string        `gorm:"type:varchar(1000)" json:"peaks_url,omitempty"`    // 波形峰值 JSON
+
 	FileSize *int64  `json:"file_size,omitempty"`
 	MimeType *string `gorm:"type:varchar(100)" json:"mime_type,omitempty"`
 	Width    *int    `json:"width,omitempty"`
@@ -34,12 +42,22 @@ type Asset struct {
 	Duration *int    `json:"duration,omitempty"`
 	Format   *string `gorm:"type:varchar(50)" json:"format,omitempty"`
 
+	// 媒体探测信息
+	Codec      *string    `gorm:"type:varchar(50)" json:"codec,omitempty"` // 视频编码，音频素材为音频编码
+	AudioCodec *string    `gorm:"type:varchar(50)" json:"audio_codec,omitempty"`
+	FPS        *float64   `json:"fps,omitempty"`
+	Bitrate    *int64     `json:"bitrate,omitempty"` // bps
+	HasAudio   *bool      `json:"has_audio,omitempty"`
+	ProbedAt   *time.Time `json:"probed_at,omitempty"`
+
 	ImageGenID *uint           `gorm:"index" json:"image_gen_id,omitempty"`
 	ImageGen   ImageGeneration `gorm:"foreignKey:ImageGenID" json:"image_gen,omitempty"`
 
 	VideoGenID *uint           `gorm:"index" json:"video_gen_id,omitempty"`
 	VideoGen   VideoGeneration `gorm:"foreignKey:VideoGenID" json:"video_gen,omitempty"`
 
+	Tags []Tag `gorm:"many2many:asset_tags;" json:"tags,omitempty"`
+
 	IsFavorite bool `gorm:"default:false" json:"is_favorite"`
 	ViewCount  int  `gorm:"default:0" json:"view_count"`
 }
@@ -47,9 +65,17 @@ type Asset struct {
 type AssetType string
 
 const (
-	AssetTypeImage AssetType = "image"
-	AssetTypeVideo AssetType = "video"
-	AssetTypeAudio AssetType = "audio"
+	AssetTypeImage    AssetType = "image"
+	AssetTypeVideo    AssetType = "video"
+	AssetTypeAudio    AssetType = "audio"
+	AssetTypeSubtitle AssetType = "subtitle"
+)
+
+// 音频素材分类，用于成片混音
+const (
+	AssetCategoryDialogue    = "dialogue"     // 对白配音（TTS）
+	AssetCategoryBGM         = "bgm"          // 背景音乐
+	AssetCategorySoundEffect = "sound_effect" // 音效
 )
 
 func (Asset) TableName() string {
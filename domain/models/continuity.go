@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// ContinuityIssue 连贯性检查发现的问题，按章节列出供人工复核
+// 重新检查时未复核的问题会被替换，已解决、已忽略的问题按 Fingerprint 保留且不再重复报告
+type ContinuityIssue struct {
+	ID           uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	DramaID      uint           `gorm:"not null;index" json:"drama_id"`
+	EpisodeID    uint           `gorm:"not null;index" json:"episode_id"`
+	StoryboardID *uint          `gorm:"index" json:"storyboard_id,omitempty"`
+	Type         string         `gorm:"type:varchar(50);not null" json:"type"`     // unlinked_character, unlinked_prop, time_of_day_change, character_after_exit, costume_change
+	Severity     string         `gorm:"type:varchar(20);not null" json:"severity"` // warning, error
+	Status       string         `gorm:"type:varchar(20);not null;index" json:"status"`
+	Message      string         `gorm:"type:text;not null" json:"message"`
+	Details      datatypes.JSON `gorm:"type:json" json:"details"`                      // 相关的角色、道具、时间等
+	Fingerprint  string         `gorm:"type:varchar(255);not null" json:"fingerprint"` // 类型 + 分镜 + 对象，用于识别同一问题
+	CreatedAt    time.Time      `gorm:"not null;autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time      `gorm:"not null;autoUpdateTime" json:"updated_at"`
+}
+
+func (ContinuityIssue) TableName() string {
+	return "continuity_issues"
+}
+
+const (
+	ContinuityIssueUnlinkedCharacter  = "unlinked_character"
+	ContinuityIssueUnlinkedProp       = "unlinked_prop"
+	ContinuityIssueTimeOfDayChange    = "time_of_day_change"
+	ContinuityIssueCharacterAfterExit = "character_after_exit"
+	ContinuityIssueCostumeChange      = "costume_change"
+)
+
+const (
+	ContinuityStatusOpen     = "open"
+	ContinuityStatusResolved = "resolved"
+	ContinuityStatusIgnored  = "ignored"
+)
@@ -63,6 +63,22 @@ func (c *Character) TableName() string {
 	return "characters"
 }
 
+// 角色参考图视角
+const (
+	CharacterViewFront   = "front"   // 正面全身
+	CharacterViewSide    = "side"    // 侧面全身
+	CharacterViewBack    = "back"    // 背面全身
+	CharacterViewCloseup = "closeup" // 面部特写（多种表情）
+	CharacterViewSheet   = "sheet"   // 以上视角合成的一张设定图
+)
+
+// CharacterReferenceImage Character.ReferenceImages 中的一项；手动上传的旧数据为字符串数组，View 为空
+type CharacterReferenceImage struct {
+	View      string `json:"view"`
+	ImageURL  string `json:"image_url"`
+	LocalPath string `json:"local_path,omitempty"`
+}
+
 type Episode struct {
 	ID            uint           `gorm:"primaryKey;autoIncrement" json:"id"`
 	DramaID       uint           `gorm:"not null;index" json:"drama_id"`
@@ -73,6 +89,7 @@ type Episode struct {
 	Duration      int            `gorm:"default:0" json:"duration"` // 总时长（秒）
 	Status        string         `gorm:"type:varchar(20);default:'draft'" json:"status"`
 	VideoURL      *string        `gorm:"type:varchar(500)" json:"video_url"`
+	HLSURL        *string        `gorm:"column:hls_url;type:varchar(500)" json:"hls_url"` // HLS 主播放列表
 	Thumbnail     *string        `gorm:"type:varchar(500)" json:"thumbnail"`
 	CreatedAt     time.Time      `gorm:"not null;autoCreateTime" json:"created_at"`
 	UpdatedAt     time.Time      `gorm:"not null;autoUpdateTime" json:"updated_at"`
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// DramaBranding 剧本品牌设置：片头、片尾、水印、标题卡，成片导出时应用
+type DramaBranding struct {
+	ID      uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	DramaID uint `gorm:"not null;uniqueIndex" json:"drama_id"`
+	Enabled bool `json:"enabled"` // 零值需要写入数据库，默认值由 BrandingService 填充
+
+	IntroVideo *string `gorm:"type:varchar(1000)" json:"intro_video"` // 片头视频（URL 或存储相对路径）
+	OutroVideo *string `gorm:"type:varchar(1000)" json:"outro_video"` // 片尾视频
+
+	LogoImage         *string `gorm:"type:varchar(1000)" json:"logo_image"`       // 水印图片
+	WatermarkPosition string  `gorm:"type:varchar(20)" json:"watermark_position"` // top-left, top-right, bottom-left, bottom-right, center
+	WatermarkOpacity  float64 `json:"watermark_opacity"`                          // 0-1
+	WatermarkScale    float64 `json:"watermark_scale"`                            // 相对画面宽度的比例
+	WatermarkMargin   int     `json:"watermark_margin"`                           // 距离边缘像素
+
+	TitleCardText     *string `gorm:"type:varchar(500)" json:"title_card_text"`      // 标题卡文字，支持 {drama} {episode} {title} 占位符
+	TitleCardDuration float64 `json:"title_card_duration"`                           // 标题卡时长（秒）
+	TitleCardFontFile *string `gorm:"type:varchar(500)" json:"title_card_font_file"` // 字体文件路径，中文标题需指定
+	TitleCardColor    string  `gorm:"type:varchar(20)" json:"title_card_color"`
+	TitleCardBgColor  string  `gorm:"type:varchar(20)" json:"title_card_bg_color"`
+
+	CoverTime float64 `json:"cover_time"` // 封面截帧时间（秒，相对正片）
+
+	CreatedAt time.Time `gorm:"not null;autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;autoUpdateTime" json:"updated_at"`
+}
+
+func (DramaBranding) TableName() string {
+	return "drama_brandings"
+}
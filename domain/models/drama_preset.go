@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// DramaPreset 剧本生成预设：默认模型、画面比例、分辨率、镜头时长、反向提示词、种子策略和参考图模式
+// 生成请求未指定对应参数时使用，字段为空表示沿用全局默认
+type DramaPreset struct {
+	ID      uint `gorm:"primaryKey;autoIncrement" json:"id"`
+	DramaID uint `gorm:"not null;uniqueIndex" json:"drama_id"`
+
+	TextModel   string `gorm:"type:varchar(100)" json:"text_model"`
+	ImageModel  string `gorm:"type:varchar(100)" json:"image_model"`
+	VideoModel  string `gorm:"type:varchar(100)" json:"video_model"`
+	TTSProvider string `gorm:"type:varchar(50)" json:"tts_provider"`
+	TTSVoice    string `gorm:"type:varchar(100)" json:"tts_voice"`
+
+	AspectRatio    string `gorm:"type:varchar(20)" json:"aspect_ratio"` // 16:9, 9:16, 1:1, 4:3, 3:4, 21:9
+	Resolution     string `gorm:"type:varchar(20)" json:"resolution"`   // 720p, 1080p, 1440p, 2160p
+	ShotDuration   int    `gorm:"default:0" json:"shot_duration"`       // 默认镜头时长（秒），0 表示不设置
+	NegativePrompt string `gorm:"type:text" json:"negative_prompt"`
+
+	SeedPolicy string `gorm:"type:varchar(20)" json:"seed_policy"` // random, fixed, per_shot
+	Seed       *int64 `json:"seed"`                                // fixed 时所有生成使用该种子，per_shot 时与镜头 ID 相加
+
+	ReferenceMode string `gorm:"type:varchar(20)" json:"reference_mode"` // single, first_last, multiple, none
+
+	CreatedAt time.Time `gorm:"not null;autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;autoUpdateTime" json:"updated_at"`
+}
+
+func (DramaPreset) TableName() string {
+	return "drama_presets"
+}
+
+const (
+	SeedPolicyRandom  = "random"
+	SeedPolicyFixed   = "fixed"
+	SeedPolicyPerShot = "per_shot"
+)
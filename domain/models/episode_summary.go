@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// EpisodeSummary 章节剧情摘要，生成后续章节剧本时作为前情提要
+// ScriptHash 与当前剧本内容不一致时摘要已过期，需要重新生成
+type EpisodeSummary struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	EpisodeID  uint      `gorm:"not null;uniqueIndex" json:"episode_id"`
+	Summary    string    `gorm:"type:text;not null" json:"summary"`
+	ScriptHash string    `gorm:"type:varchar(64);not null" json:"script_hash"` // 剧本内容的 SHA-256
+	CreatedAt  time.Time `gorm:"not null;autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"not null;autoUpdateTime" json:"updated_at"`
+}
+
+func (EpisodeSummary) TableName() string {
+	return "episode_summaries"
+}
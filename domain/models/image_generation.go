@@ -75,4 +75,6 @@ const (
 	ImageTypeScene      ImageType = "scene"      // 场景图片
 	ImageTypeProp       ImageType = "prop"       // 道具图片
 	ImageTypeStoryboard ImageType = "storyboard" // 分镜图片
+
+	ImageTypeCharacterReference ImageType = "character_reference" // 角色参考图（三视图/表情），FrameType 记录视角
 )
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// EpisodePipeline 章节一键生产流水线：从剧本到成片，按阶段依次执行
+type EpisodePipeline struct {
+	ID           uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	EpisodeID    uint           `gorm:"not null;index" json:"episode_id"`
+	DramaID      uint           `gorm:"not null;index" json:"drama_id"`
+	Status       string         `gorm:"type:varchar(20);not null;index" json:"status"` // running, awaiting_approval, failed, completed, cancelled
+	CurrentStage *string        `gorm:"type:varchar(50)" json:"current_stage,omitempty"`
+	Options      datatypes.JSON `gorm:"type:json" json:"options"`
+	Error        *string        `gorm:"type:text" json:"error,omitempty"`
+	CreatedAt    time.Time      `gorm:"not null;autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time      `gorm:"not null;autoUpdateTime" json:"updated_at"`
+	CompletedAt  *time.Time     `json:"completed_at,omitempty"`
+
+	Stages []PipelineStage `gorm:"foreignKey:PipelineID" json:"stages,omitempty"`
+}
+
+func (EpisodePipeline) TableName() string {
+	return "episode_pipelines"
+}
+
+// PipelineStage 流水线阶段，每次执行对应一个 AsyncTask
+type PipelineStage struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	PipelineID  uint       `gorm:"not null;uniqueIndex:idx_pipeline_stages_name" json:"pipeline_id"`
+	Name        string     `gorm:"type:varchar(50);not null;uniqueIndex:idx_pipeline_stages_name" json:"name"`
+	Seq         int        `gorm:"not null" json:"seq"`
+	DependsOn   string     `gorm:"type:varchar(200)" json:"depends_on"`     // 依赖的阶段，逗号分隔
+	Status      string     `gorm:"type:varchar(20);not null" json:"status"` // pending, running, completed, skipped, failed, cancelled
+	TaskID      *string    `gorm:"type:varchar(36)" json:"task_id,omitempty"`
+	Message     *string    `gorm:"type:varchar(500)" json:"message,omitempty"`
+	Error       *string    `gorm:"type:text" json:"error,omitempty"`
+	ApprovedAt  *time.Time `json:"approved_at,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"not null;autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"not null;autoUpdateTime" json:"updated_at"`
+}
+
+func (PipelineStage) TableName() string {
+	return "pipeline_stages"
+}
+
+const (
+	PipelineStatusRunning          = "running"
+	PipelineStatusAwaitingApproval = "awaiting_approval"
+	PipelineStatusFailed           = "failed"
+	PipelineStatusCompleted        = "completed"
+	PipelineStatusCancelled        = "cancelled"
+)
+
+const (
+	PipelineStagePending   = "pending"
+	PipelineStageRunning   = "running"
+	PipelineStageCompleted = "completed"
+	PipelineStageSkipped   = "skipped"
+	PipelineStageFailed    = "failed"
+	PipelineStageCancelled = "cancelled"
+)
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ResourceTransfer 记录生成资源转存到本地存储失败的情况（转存成功后删除记录）
+type ResourceTransfer struct {
+	ID           uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ResourceType string     `gorm:"type:varchar(30);not null;uniqueIndex:idx_resource_transfers_resource" json:"resource_type"` // image_generation, video_generation, character, scene, prop
+	ResourceID   uint       `gorm:"not null;uniqueIndex:idx_resource_transfers_resource" json:"resource_id"`
+	DramaID      uint       `gorm:"not null;index" json:"drama_id"`
+	SourceURL    string     `gorm:"type:text;not null" json:"source_url"`
+	Status       string     `gorm:"type:varchar(20);not null;index" json:"status"` // failed, expired
+	Attempts     int        `gorm:"not null;default:0" json:"attempts"`
+	LastError    *string    `gorm:"type:text" json:"last_error,omitempty"`
+	NextRetryAt  *time.Time `json:"next_retry_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"not null;autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"not null;autoUpdateTime" json:"updated_at"`
+}
+
+func (ResourceTransfer) TableName() string {
+	return "resource_transfers"
+}
+
+const (
+	ResourceTransferFailed  = "failed"  // 下载失败，等待重试
+	ResourceTransferExpired = "expired" // 源URL已过期，无法再转存
+)
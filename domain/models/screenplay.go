@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// EpisodeScreenplay 从 Fountain / FDX 导入的结构化剧本（场景、动作、对白），Episode.ScriptContent 保存其纯文本
+type EpisodeScreenplay struct {
+	ID         uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	EpisodeID  uint           `gorm:"not null;uniqueIndex" json:"episode_id"`
+	Format     string         `gorm:"type:varchar(20);not null" json:"format"` // fountain, fdx
+	SourceName string         `gorm:"type:varchar(255)" json:"source_name"`    // 导入的文件名
+	Content    datatypes.JSON `gorm:"type:json" json:"content"`                // screenplay.Episode
+	CreatedAt  time.Time      `gorm:"not null;autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time      `gorm:"not null;autoUpdateTime" json:"updated_at"`
+}
+
+func (EpisodeScreenplay) TableName() string {
+	return "episode_screenplays"
+}
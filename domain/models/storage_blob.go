@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// StorageBlob 按 SHA-256 内容哈希去重的存储文件
+type StorageBlob struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Hash        string    `gorm:"type:char(64);not null;uniqueIndex" json:"hash"`
+	Key         string    `gorm:"column:storage_key;type:varchar(500);not null;index" json:"key"` // 存储 key（相对路径）
+	Size        int64     `gorm:"not null" json:"size"`
+	ContentType string    `gorm:"type:varchar(100)" json:"content_type"`
+	RefCount    int       `gorm:"not null;default:0" json:"ref_count"`
+	CreatedAt   time.Time `gorm:"not null;autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"not null;autoUpdateTime" json:"updated_at"`
+}
+
+func (StorageBlob) TableName() string {
+	return "storage_blobs"
+}
+
+// StorageURLCache 远程URL到内容哈希的映射，同一URL不再重复下载
+type StorageURLCache struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	URLHash    string    `gorm:"type:char(64);not null;uniqueIndex" json:"url_hash"` // URL 的 SHA-256，URL 可能超过索引长度
+	URL        string    `gorm:"type:text;not null" json:"url"`
+	BlobHash   string    `gorm:"type:char(64);not null;index" json:"blob_hash"`
+	CreatedAt  time.Time `gorm:"not null;autoCreateTime" json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+func (StorageURLCache) TableName() string {
+	return "storage_url_caches"
+}
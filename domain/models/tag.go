@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Tag 素材标签，DramaID 为空时为全局标签；删除为物理删除
+// 全局标签名的唯一约束（idx_tags_global_name）为部分索引/生成列，只在迁移文件中定义
+type Tag struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	DramaID   *uint     `gorm:"uniqueIndex:idx_tags_drama_name" json:"drama_id"`
+	Name      string    `gorm:"type:varchar(50);not null;uniqueIndex:idx_tags_drama_name" json:"name"`
+	Color     *string   `gorm:"type:varchar(20)" json:"color"`
+	CreatedAt time.Time `gorm:"not null;autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;autoUpdateTime" json:"updated_at"`
+
+	AssetCount int64 `gorm:"-" json:"asset_count"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// AssetTag 素材与标签关联表
+type AssetTag struct {
+	AssetID uint `gorm:"primaryKey" json:"asset_id"`
+	TagID   uint `gorm:"primaryKey;index" json:"tag_id"`
+}
+
+func (AssetTag) TableName() string {
+	return "asset_tags"
+}
@@ -25,6 +25,7 @@ type VideoMerge struct {
 	Model       *string          `gorm:"type:varchar(100)" json:"model,omitempty"`
 	Status      VideoMergeStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
 	Scenes      datatypes.JSON   `gorm:"type:json;not null" json:"scenes"`
+	Options     datatypes.JSON   `gorm:"type:json" json:"options,omitempty"` // 合成后处理选项（混音等）
 	MergedURL   *string          `gorm:"type:varchar(500)" json:"merged_url,omitempty"`
 	Duration    *int             `gorm:"type:int" json:"duration,omitempty"`
 	TaskID      *string          `gorm:"type:varchar(100)" json:"task_id,omitempty"`
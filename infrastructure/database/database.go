@@ -9,6 +9,7 @@ import (
 	"github.com/drama-generator/backend/domain/models"
 	"github.com/drama-generator/backend/pkg/config"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	_ "modernc.org/sqlite"
@@ -39,6 +40,8 @@ func NewDatabase(cfg config.DatabaseConfig) (*gorm.DB, error) {
 			DriverName: "sqlite",
 			DSN:        dsnWithParams,
 		}, gormConfig)
+	} else if cfg.Type == "postgres" {
+		db, err = gorm.Open(postgres.Open(dsn), gormConfig)
 	} else {
 		db, err = gorm.Open(mysql.Open(dsn), gormConfig)
 	}
@@ -66,9 +69,18 @@ func NewDatabase(cfg config.DatabaseConfig) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
+	// search_path 指向的 schema 不存在时建表会失败
+	if cfg.Type == "postgres" && cfg.Schema != "" {
+		if err := db.Exec("CREATE SCHEMA IF NOT EXISTS " + db.Statement.Quote(cfg.Schema)).Error; err != nil {
+			return nil, fmt.Errorf("failed to create schema %s: %w", cfg.Schema, err)
+		}
+	}
+
 	return db, nil
 }
 
+// AutoMigrate 按模型定义同步表结构，仅用于接管 AutoMigrate 时代创建的旧数据库
+// 表结构变更请在 migrations/ 下添加版本化迁移文件，服务启动时不再自动迁移
 func AutoMigrate(db *gorm.DB) error {
 	return db.AutoMigrate(
 		// 核心模型
@@ -84,14 +96,20 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.ImageGeneration{},
 		&models.VideoGeneration{},
 		&models.VideoMerge{},
+		&models.DramaBranding{},
 
 		// AI配置
 		&models.AIServiceConfig{},
 		&models.AIServiceProvider{},
 
 		// 资源管理
+		&models.Tag{},
+		&models.AssetTag{},
 		&models.Asset{},
 		&models.CharacterLibrary{},
+		&models.StorageBlob{},
+		&models.StorageURLCache{},
+		&models.ResourceTransfer{},
 
 		// 任务管理
 		&models.AsyncTask{},
@@ -0,0 +1,25 @@
+package database
+
+import "gorm.io/gorm"
+
+// IsPostgres 判断连接是否为 PostgreSQL
+func IsPostgres(db *gorm.DB) bool {
+	return db.Dialector.Name() == "postgres"
+}
+
+// AsText 返回按文本读取列的 SQL 表达式
+// PostgreSQL 的 json 列不支持 LIKE、REPLACE 与字符串比较，需要显式转换；SQLite/MySQL 原样返回
+func AsText(db *gorm.DB, column string) string {
+	if IsPostgres(db) {
+		return "CAST(" + column + " AS TEXT)"
+	}
+	return column
+}
+
+// AsJSON 将文本表达式转换为 json 列可接受的值，用于写回 json 列
+func AsJSON(db *gorm.DB, expr string) string {
+	if IsPostgres(db) {
+		return "CAST(" + expr + " AS JSON)"
+	}
+	return expr
+}
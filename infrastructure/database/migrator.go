@@ -0,0 +1,261 @@
+package database
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drama-generator/backend/migrations"
+	"gorm.io/gorm"
+)
+
+// baselineVersion 基线迁移版本，对应 AutoMigrate 时代的完整表结构
+const baselineVersion = 1
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration 一个版本化迁移
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// SchemaMigration schema_migrations 表中的已执行记录
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Name      string    `gorm:"type:varchar(200);not null" json:"name"`
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus 迁移状态
+type MigrationStatus struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// LoadMigrations 读取当前数据库方言的全部迁移，按版本号升序
+func LoadMigrations(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(migrations.FS, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %s: %w", dialect, err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s/%s", dialect, entry.Name())
+		}
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(migrations.FS, path.Join(dialect, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d used by both %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result, nil
+}
+
+// MigrateUp 按顺序执行所有未执行的迁移，返回本次执行的迁移
+func MigrateUp(db *gorm.DB) ([]Migration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	all, applied, err := loadMigrationState(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range all {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if m.Version == baselineVersion {
+			if err := adoptLegacySchema(db); err != nil {
+				return done, err
+			}
+		}
+		if err := runMigration(db, m, m.Up, true); err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// MigrateDown 按倒序回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func MigrateDown(db *gorm.DB, steps int) ([]Migration, error) {
+	all, applied, err := loadMigrationState(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(all) - 1; i >= 0 && len(done) < steps; i-- {
+		m := all[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if m.Down == "" {
+			return done, fmt.Errorf("migration %d_%s has no down file", m.Version, m.Name)
+		}
+		if err := runMigration(db, m, m.Down, false); err != nil {
+			return done, err
+		}
+		done = append(done, m)
+	}
+	return done, nil
+}
+
+// MigrationStatuses 列出全部迁移及其执行状态
+func MigrationStatuses(db *gorm.DB) ([]MigrationStatus, error) {
+	all, applied, err := loadMigrationState(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(all))
+	for _, m := range all {
+		status := MigrationStatus{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Version]; ok {
+			appliedAt := record.AppliedAt
+			status.Applied = true
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// CheckMigrations 启动时检查表结构是否已迁移到最新版本，存在未执行的迁移时返回错误
+func CheckMigrations(db *gorm.DB) error {
+	statuses, err := MigrationStatuses(db)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, status := range statuses {
+		if !status.Applied {
+			pending = append(pending, fmt.Sprintf("%d_%s", status.Version, status.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database schema is not up to date, pending migrations: %s (run `migrate up` first)", strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// loadMigrationState 读取迁移文件与已执行记录；已执行但文件缺失的版本视为错误（数据库比程序新）
+// 只读操作，schema_migrations 表不存在时视为尚未执行任何迁移，由 MigrateUp 负责建表
+func loadMigrationState(db *gorm.DB) ([]Migration, map[int64]SchemaMigration, error) {
+	all, err := LoadMigrations(db.Dialector.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !db.Migrator().HasTable(&SchemaMigration{}) {
+		return all, map[int64]SchemaMigration{}, nil
+	}
+
+	var records []SchemaMigration
+	if err := db.Order("version").Find(&records).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to load schema_migrations: %w", err)
+	}
+
+	known := make(map[int64]bool, len(all))
+	for _, m := range all {
+		known[m.Version] = true
+	}
+	applied := make(map[int64]SchemaMigration, len(records))
+	for _, record := range records {
+		if !known[record.Version] {
+			return nil, nil, fmt.Errorf("database has migration %d_%s that this build does not know about", record.Version, record.Name)
+		}
+		applied[record.Version] = record
+	}
+	return all, applied, nil
+}
+
+// runMigration 在事务中执行迁移语句并更新 schema_migrations
+// 注意：MySQL 的 DDL 会隐式提交，失败时可能停留在部分执行的状态
+func runMigration(db *gorm.DB, m Migration, script string, up bool) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range splitStatements(script) {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("migration %d_%s failed: %w\n%s", m.Version, m.Name, err, stmt)
+			}
+		}
+		if up {
+			return tx.Create(&SchemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		}
+		return tx.Delete(&SchemaMigration{}, m.Version).Error
+	})
+}
+
+// adoptLegacySchema 接管由 AutoMigrate 创建的旧数据库：补齐旧版本缺失的列与索引，之后由基线迁移记录版本
+func adoptLegacySchema(db *gorm.DB) error {
+	if !db.Migrator().HasTable("dramas") {
+		return nil
+	}
+	if err := AutoMigrate(db); err != nil {
+		return fmt.Errorf("failed to adopt legacy schema: %w", err)
+	}
+	return nil
+}
+
+// splitStatements 按行尾分号拆分 SQL 脚本，忽略 -- 注释行
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
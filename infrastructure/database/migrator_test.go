@@ -0,0 +1,58 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	_ "modernc.org/sqlite"
+)
+
+func TestCheckMigrationsIsReadOnly(t *testing.T) {
+	db, err := gorm.Open(sqlite.Dialector{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")},
+		&gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	err = CheckMigrations(db)
+	if err == nil || !strings.Contains(err.Error(), "pending migrations") {
+		t.Fatalf("expected pending migrations error, got %v", err)
+	}
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		t.Fatal("check migrations must not create schema_migrations")
+	}
+
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	if err := CheckMigrations(db); err != nil {
+		t.Fatalf("check migrations after migrate up: %v", err)
+	}
+}
+
+func TestMigrationsRoundTrip(t *testing.T) {
+	db, err := gorm.Open(sqlite.Dialector{DriverName: "sqlite", DSN: filepath.Join(t.TempDir(), "test.db")},
+		&gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+
+	applied, err := MigrateUp(db)
+	if err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+	// 回滚到基线之后再重新执行，校验每个 down 文件都能撤销对应的 up
+	if _, err := MigrateDown(db, len(applied)-1); err != nil {
+		t.Fatalf("migrate down: %v", err)
+	}
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatalf("migrate up again: %v", err)
+	}
+	if err := CheckMigrations(db); err != nil {
+		t.Fatalf("check migrations after round trip: %v", err)
+	}
+}
@@ -0,0 +1,265 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// AudioTrack 混音轨道中的一段音频
+type AudioTrack struct {
+	Path     string  // 本地文件路径或远程URL
+	Offset   float64 // 在成片中的起始时间（秒）
+	Duration float64 // 最长播放时长（秒），0 表示播放完整音频
+	Volume   float64 // 音量倍数，0 按 1.0 处理
+	Loop     bool    // 是否循环播放直到 Duration
+}
+
+// AudioMixOptions 成片混音参数
+type AudioMixOptions struct {
+	VideoPath      string       // 已合成的视频
+	OutputPath     string       // 输出视频路径
+	Dialogue       []AudioTrack // 对白（TTS）
+	BGM            []AudioTrack // 背景音乐段落
+	Effects        []AudioTrack // 音效
+	KeepOriginal   bool         // 是否保留视频原声
+	OriginalVolume float64      // 原声音量倍数
+	DuckBGM        bool         // 对白出现时压低背景音乐
+}
+
+// MixSoundtrack 将对白、背景音乐和音效混合进视频，视频流直接复制
+func (f *FFmpeg) MixSoundtrack(opts *AudioMixOptions) (string, error) {
+	if opts.VideoPath == "" || opts.OutputPath == "" {
+		return "", fmt.Errorf("video path and output path are required")
+	}
+
+	totalDuration, err := f.GetVideoDuration(opts.VideoPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to get video duration: %w", err)
+	}
+
+	// 远程音频先下载到临时目录
+	var tempFiles []string
+	defer func() { f.cleanup(tempFiles) }()
+
+	localize := func(tracks []AudioTrack, prefix string) ([]AudioTrack, error) {
+		result := make([]AudioTrack, 0, len(tracks))
+		for i, track := range tracks {
+			if track.Path == "" {
+				continue
+			}
+			if strings.HasPrefix(track.Path, "http://") || strings.HasPrefix(track.Path, "https://") {
+				ext := filepath.Ext(strings.Split(track.Path, "?")[0])
+				if ext == "" || len(ext) > 5 {
+					ext = ".audio"
+				}
+				dest := filepath.Join(f.tempDir, fmt.Sprintf("%s_%d_%d%s", prefix, os.Getpid(), i, ext))
+				if _, err := f.downloadVideo(track.Path, dest); err != nil {
+					return nil, fmt.Errorf("failed to download %s track %d: %w", prefix, i, err)
+				}
+				tempFiles = append(tempFiles, dest)
+				track.Path = dest
+			} else if _, err := os.Stat(track.Path); err != nil {
+				f.log.Warnw("Audio track not found, skipping", "type", prefix, "path", track.Path)
+				continue
+			}
+			result = append(result, track)
+		}
+		return result, nil
+	}
+
+	dialogue, err := localize(opts.Dialogue, "dialogue")
+	if err != nil {
+		return "", err
+	}
+	bgm, err := localize(opts.BGM, "bgm")
+	if err != nil {
+		return "", err
+	}
+	effects, err := localize(opts.Effects, "sfx")
+	if err != nil {
+		return "", err
+	}
+
+	keepOriginal := opts.KeepOriginal && f.hasAudioStream(opts.VideoPath)
+	inputs, filterComplex, err := soundtrackFilter(dialogue, bgm, effects, keepOriginal, opts.OriginalVolume, opts.DuckBGM, totalDuration)
+	if err != nil {
+		return "", err
+	}
+	args := append([]string{"-i", opts.VideoPath}, inputs...)
+
+	if err := os.MkdirAll(filepath.Dir(opts.OutputPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	args = append(args,
+		"-filter_complex", filterComplex,
+		"-map", "0:v",
+		"-map", "[aout]",
+		"-c:v", "copy",
+		"-c:a", "aac",
+		"-b:a", "192k",
+		"-movflags", "+faststart",
+		"-y",
+		opts.OutputPath,
+	)
+
+	f.log.Infow("Mixing soundtrack",
+		"dialogue", len(dialogue),
+		"bgm", len(bgm),
+		"effects", len(effects),
+		"filter", filterComplex)
+
+	cmd := exec.Command("ffmpeg", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		f.log.Errorw("FFmpeg audio mix failed", "error", err, "output", string(output))
+		return "", fmt.Errorf("ffmpeg audio mix failed: %w, output: %s", err, string(output))
+	}
+
+	f.log.Infow("Soundtrack mixed successfully", "output", opts.OutputPath)
+	return opts.OutputPath, nil
+}
+
+// soundtrackFilter 生成混音的音频输入参数与 filter_complex（视频为 0 号输入）
+// 各轨道按 Offset 延迟、按 Duration 截断；duck 时对白经 sidechain 压低背景音乐，最终按视频时长截断
+func soundtrackFilter(dialogue, bgm, effects []AudioTrack, keepOriginal bool, originalVolume float64, duck bool, totalDuration float64) (inputs []string, filter string, err error) {
+	var filters []string
+	inputIndex := 1
+
+	// addTrack 为单条轨道添加输入并生成 trim/volume/adelay 滤镜，返回输出标签
+	addTrack := func(track AudioTrack, label string) string {
+		if track.Loop {
+			inputs = append(inputs, "-stream_loop", "-1")
+		}
+		inputs = append(inputs, "-i", track.Path)
+
+		chain := []string{"aresample=44100", "aformat=channel_layouts=stereo"}
+		if track.Duration > 0 {
+			chain = append(chain, fmt.Sprintf("atrim=0:%.3f", track.Duration), "asetpts=PTS-STARTPTS")
+		}
+		volume := track.Volume
+		if volume <= 0 {
+			volume = 1.0
+		}
+		if volume != 1.0 {
+			chain = append(chain, fmt.Sprintf("volume=%.3f", volume))
+		}
+		if track.Offset > 0 {
+			delayMs := int(math.Round(track.Offset * 1000))
+			chain = append(chain, fmt.Sprintf("adelay=%d|%d", delayMs, delayMs))
+		}
+
+		filters = append(filters, fmt.Sprintf("[%d:a]%s[%s]", inputIndex, strings.Join(chain, ","), label))
+		inputIndex++
+		return "[" + label + "]"
+	}
+
+	// mixLabels 将多条轨道混合为一条
+	mixLabels := func(labels []string, out string) string {
+		if len(labels) == 1 {
+			filters = append(filters, fmt.Sprintf("%sanull[%s]", labels[0], out))
+		} else {
+			filters = append(filters, fmt.Sprintf("%samix=inputs=%d:duration=longest:dropout_transition=0:normalize=0[%s]",
+				strings.Join(labels, ""), len(labels), out))
+		}
+		return "[" + out + "]"
+	}
+
+	var busLabels []string
+
+	// 视频原声
+	if keepOriginal {
+		volume := originalVolume
+		if volume <= 0 {
+			volume = 1.0
+		}
+		filters = append(filters, fmt.Sprintf("[0:a]aresample=44100,aformat=channel_layouts=stereo,volume=%.3f[orig]", volume))
+		busLabels = append(busLabels, "[orig]")
+	}
+
+	// 对白
+	var dialogueBus string
+	if len(dialogue) > 0 {
+		var labels []string
+		for i, track := range dialogue {
+			labels = append(labels, addTrack(track, fmt.Sprintf("d%d", i)))
+		}
+		dialogueBus = mixLabels(labels, "dlg")
+	}
+
+	// 背景音乐
+	var bgmBus string
+	if len(bgm) > 0 {
+		var labels []string
+		for i, track := range bgm {
+			labels = append(labels, addTrack(track, fmt.Sprintf("b%d", i)))
+		}
+		bgmBus = mixLabels(labels, "bgm")
+	}
+
+	// 对白压低背景音乐（sidechain ducking）
+	if bgmBus != "" && dialogueBus != "" && duck {
+		filters = append(filters, fmt.Sprintf("%sasplit=2[dlgmain][dlgsc]", dialogueBus))
+		filters = append(filters, fmt.Sprintf("%s[dlgsc]sidechaincompress=threshold=0.03:ratio=8:attack=20:release=400[bgmducked]", bgmBus))
+		dialogueBus = "[dlgmain]"
+		bgmBus = "[bgmducked]"
+	}
+	if dialogueBus != "" {
+		busLabels = append(busLabels, dialogueBus)
+	}
+	if bgmBus != "" {
+		busLabels = append(busLabels, bgmBus)
+	}
+
+	// 音效
+	for i, track := range effects {
+		busLabels = append(busLabels, addTrack(track, fmt.Sprintf("s%d", i)))
+	}
+
+	if len(busLabels) == 0 {
+		return nil, "", fmt.Errorf("no audio tracks to mix")
+	}
+
+	mixed := mixLabels(busLabels, "mix")
+
+	// 按视频时长截断，响度标准化在成片完成后由 NormalizeLoudness 统一处理
+	filters = append(filters, fmt.Sprintf("%satrim=0:%.3f,aresample=44100[aout]", mixed, totalDuration))
+	return inputs, strings.Join(filters, ";"), nil
+}
+
+// NormalizeLoudness 对成片音频做响度标准化，视频流直接复制
+// 应在片头片尾等所有音频拼接完成后调用，保证整片响度一致
+func (f *FFmpeg) NormalizeLoudness(inputPath, outputPath string, targetLUFS float64) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	args := []string{
+		"-i", inputPath,
+		"-map", "0:v",
+		"-map", "0:a",
+		"-af", fmt.Sprintf("loudnorm=I=%.1f:TP=-1.5:LRA=11,aresample=44100", targetLUFS),
+		"-c:v", "copy",
+		"-c:a", "aac",
+		"-b:a", "192k",
+		"-movflags", "+faststart",
+		"-y",
+		outputPath,
+	}
+
+	f.log.Infow("Normalizing loudness", "input", inputPath, "target_lufs", targetLUFS)
+
+	cmd := exec.Command("ffmpeg", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		f.log.Errorw("FFmpeg loudness normalization failed", "error", err, "output", string(output))
+		return "", fmt.Errorf("ffmpeg loudness normalization failed: %w, output: %s", err, string(output))
+	}
+
+	f.log.Infow("Loudness normalized successfully", "output", outputPath)
+	return outputPath, nil
+}
@@ -0,0 +1,117 @@
+package ffmpeg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSoundtrackFilterOffsetsAndTrim(t *testing.T) {
+	// 第二条对白紧接第一条：起点为 0.2 秒延迟加上第一条的探测时长
+	leadIn, firstDuration := 0.2, 1.003
+	dialogue := []AudioTrack{
+		{Path: "d0.mp3", Offset: leadIn},
+		{Path: "d1.mp3", Offset: leadIn + firstDuration, Duration: 1.51, Volume: 1.0},
+	}
+	bgm := []AudioTrack{{Path: "bgm.mp3", Offset: 0, Duration: 12.5, Volume: 0.35, Loop: true}}
+
+	inputs, filter, err := soundtrackFilter(dialogue, bgm, nil, false, 0, true, 12.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 视频为 0 号输入，轨道依次编号；循环播放的背景音乐在输入前加 -stream_loop
+	wantInputs := "-i d0.mp3 -i d1.mp3 -stream_loop -1 -i bgm.mp3"
+	if got := strings.Join(inputs, " "); got != wantInputs {
+		t.Fatalf("inputs = %q, want %q", got, wantInputs)
+	}
+
+	for _, want := range []string{
+		// 偏移按毫秒四舍五入，不能因浮点误差少 1ms
+		"[1:a]aresample=44100,aformat=channel_layouts=stereo,adelay=200|200[d0]",
+		"[2:a]aresample=44100,aformat=channel_layouts=stereo,atrim=0:1.510,asetpts=PTS-STARTPTS,adelay=1203|1203[d1]",
+		// 先截断再延迟：截断长度是音频本身的时长，与偏移无关
+		"[3:a]aresample=44100,aformat=channel_layouts=stereo,atrim=0:12.500,asetpts=PTS-STARTPTS,volume=0.350[b0]",
+		"[mix]atrim=0:12.500,aresample=44100[aout]",
+	} {
+		if !strings.Contains(filter, want) {
+			t.Fatalf("filter missing %q:\n%s", want, filter)
+		}
+	}
+}
+
+func TestSoundtrackFilterDucking(t *testing.T) {
+	dialogue := []AudioTrack{{Path: "d0.mp3", Offset: 1}}
+	bgm := []AudioTrack{{Path: "bgm.mp3", Loop: true, Duration: 10}}
+	effects := []AudioTrack{{Path: "door.wav", Offset: 3, Duration: 2, Volume: 0.8}}
+
+	tests := []struct {
+		name     string
+		dialogue []AudioTrack
+		duck     bool
+		original bool
+		want     []string
+		absent   []string
+	}{
+		{
+			name:     "dialogue ducks bgm",
+			dialogue: dialogue,
+			duck:     true,
+			want: []string{
+				"[dlg]asplit=2[dlgmain][dlgsc]",
+				"[bgm][dlgsc]sidechaincompress=threshold=0.03:ratio=8:attack=20:release=400[bgmducked]",
+				"[dlgmain][bgmducked][s0]amix=inputs=3:",
+			},
+		},
+		{
+			name:     "ducking disabled",
+			dialogue: dialogue,
+			want:     []string{"[dlg][bgm][s0]amix=inputs=3:"},
+			absent:   []string{"sidechaincompress"},
+		},
+		{
+			name:   "no dialogue to duck against",
+			duck:   true,
+			want:   []string{"[b0]anull[bgm]", "[bgm][s0]amix=inputs=2:"},
+			absent: []string{"sidechaincompress", "[dlg"},
+		},
+		{
+			name:     "original audio stays out of the sidechain",
+			dialogue: dialogue,
+			duck:     true,
+			original: true,
+			want: []string{
+				"[0:a]aresample=44100,aformat=channel_layouts=stereo,volume=0.600[orig]",
+				"[orig][dlgmain][bgmducked][s0]amix=inputs=4:",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, filter, err := soundtrackFilter(tt.dialogue, bgm, effects, tt.original, 0.6, tt.duck, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(filter, want) {
+					t.Fatalf("filter missing %q:\n%s", want, filter)
+				}
+			}
+			for _, absent := range tt.absent {
+				if strings.Contains(filter, absent) {
+					t.Fatalf("filter should not contain %q:\n%s", absent, filter)
+				}
+			}
+		})
+	}
+}
+
+func TestSoundtrackFilterRequiresTracks(t *testing.T) {
+	if _, _, err := soundtrackFilter(nil, nil, nil, false, 0, true, 5); err == nil {
+		t.Fatal("expected error without audio tracks")
+	}
+	// 只有原声时仍需输出 [aout]，以便统一截断
+	_, filter, err := soundtrackFilter(nil, nil, nil, true, 0, true, 5)
+	if err != nil || !strings.HasSuffix(filter, "[orig]anull[mix];[mix]atrim=0:5.000,aresample=44100[aout]") {
+		t.Fatalf("unexpected original-only filter %q: %v", filter, err)
+	}
+}
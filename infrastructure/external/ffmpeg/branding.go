@@ -0,0 +1,303 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// WatermarkOptions 水印设置
+type WatermarkOptions struct {
+	ImagePath string
+	Position  string  // top-left, top-right, bottom-left, bottom-right, center
+	Opacity   float64 // 0-1
+	Scale     float64 // 相对画面宽度
+	Margin    int
+}
+
+// TitleCardOptions 标题卡设置
+type TitleCardOptions struct {
+	Text      string
+	Duration  float64
+	FontFile  string
+	FontColor string // #RRGGBB
+	BgColor   string // #RRGGBB
+}
+
+// BrandingOptions 成片包装参数
+type BrandingOptions struct {
+	VideoPath  string
+	OutputPath string
+	IntroPath  string
+	OutroPath  string
+	Watermark  *WatermarkOptions
+	TitleCard  *TitleCardOptions
+}
+
+// MainOffset 计算正片在品牌成片中的起始时间（片头 + 标题卡时长，秒）
+func (f *FFmpeg) MainOffset(opts *BrandingOptions) (float64, error) {
+	var offset float64
+	if opts.IntroPath != "" {
+		duration, err := f.GetVideoDuration(opts.IntroPath)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get intro duration: %w", err)
+		}
+		offset += duration
+	}
+	if opts.TitleCard != nil && strings.TrimSpace(opts.TitleCard.Text) != "" {
+		offset += titleCardDuration(opts.TitleCard)
+	}
+	return offset, nil
+}
+
+// titleCardDuration 标题卡时长，默认 3 秒
+func titleCardDuration(card *TitleCardOptions) float64 {
+	if card.Duration <= 0 {
+		return 3
+	}
+	return card.Duration
+}
+
+// ApplyBranding 拼接片头/标题卡/正片/片尾，并为正片添加水印
+func (f *FFmpeg) ApplyBranding(opts *BrandingOptions) (string, error) {
+	width, height := f.getVideoResolution(opts.VideoPath)
+
+	var tempFiles []string
+	defer func() { f.cleanup(tempFiles) }()
+
+	args := []string{}
+	var filters []string
+	var segments []string // 按顺序拼接的 [视频][音频] 标签
+	inputIndex := 0
+
+	// addSilence 为无音轨的片段生成静音输入
+	addSilence := func(duration float64) int {
+		args = append(args, "-f", "lavfi", "-t", fmt.Sprintf("%.3f", duration),
+			"-i", "anullsrc=channel_layout=stereo:sample_rate=44100")
+		inputIndex++
+		return inputIndex - 1
+	}
+
+	normalizeVideo := fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2:color=black,setsar=1,fps=30,format=yuv420p",
+		width, height, width, height)
+	normalizeAudio := "aresample=44100,aformat=channel_layouts=stereo"
+
+	// addClip 添加视频片段（片头/正片/片尾），返回该片段的视频标签
+	addClip := func(path, name string) (string, error) {
+		args = append(args, "-i", path)
+		videoIndex := inputIndex
+		inputIndex++
+
+		audioLabel := fmt.Sprintf("[%d:a]", videoIndex)
+		if !f.hasAudioStream(path) {
+			duration, err := f.GetVideoDuration(path)
+			if err != nil {
+				return "", fmt.Errorf("failed to get %s duration: %w", name, err)
+			}
+			audioLabel = fmt.Sprintf("[%d:a]", addSilence(duration))
+		}
+
+		filters = append(filters,
+			fmt.Sprintf("[%d:v]%s[%sv]", videoIndex, normalizeVideo, name),
+			fmt.Sprintf("%s%s[%sa]", audioLabel, normalizeAudio, name))
+		return "[" + name + "v]", nil
+	}
+
+	if opts.IntroPath != "" {
+		introLabel, err := addClip(opts.IntroPath, "intro")
+		if err != nil {
+			return "", err
+		}
+		segments = append(segments, introLabel+"[introa]")
+	}
+
+	// 标题卡
+	if opts.TitleCard != nil && strings.TrimSpace(opts.TitleCard.Text) != "" {
+		card := opts.TitleCard
+		duration := titleCardDuration(card)
+
+		// 使用 textfile 避免转义问题
+		textFile := filepath.Join(f.tempDir, fmt.Sprintf("titlecard_%d.txt", time.Now().UnixNano()))
+		if err := os.WriteFile(textFile, []byte(card.Text), 0644); err != nil {
+			return "", fmt.Errorf("failed to write title card text: %w", err)
+		}
+		tempFiles = append(tempFiles, textFile)
+
+		args = append(args, "-f", "lavfi",
+			"-i", fmt.Sprintf("color=c=%s:s=%dx%d:d=%.3f:r=30", ffmpegColor(card.BgColor, "black"), width, height, duration))
+		colorIndex := inputIndex
+		inputIndex++
+		silenceIndex := addSilence(duration)
+
+		filters = append(filters,
+			fmt.Sprintf("[%d:v]%s,setsar=1,format=yuv420p[titlev]", colorIndex, drawTextFilter(textFile, card, "h/14")),
+			fmt.Sprintf("[%d:a]%s[titlea]", silenceIndex, normalizeAudio))
+		segments = append(segments, "[titlev][titlea]")
+	}
+
+	mainLabel, err := addClip(opts.VideoPath, "main")
+	if err != nil {
+		return "", err
+	}
+
+	// 水印只叠加在正片上
+	if opts.Watermark != nil && opts.Watermark.ImagePath != "" {
+		wm := opts.Watermark
+		args = append(args, "-i", wm.ImagePath)
+		logoIndex := inputIndex
+		inputIndex++
+
+		scale := wm.Scale
+		if scale <= 0 || scale > 1 {
+			scale = 0.15
+		}
+		opacity := wm.Opacity
+		if opacity <= 0 || opacity > 1 {
+			opacity = 0.8
+		}
+		logoWidth := int(float64(width)*scale) / 2 * 2
+		filters = append(filters,
+			fmt.Sprintf("[%d:v]format=rgba,scale=%d:-1,colorchannelmixer=aa=%.2f[logo]", logoIndex, logoWidth, opacity),
+			fmt.Sprintf("%s[logo]overlay=%s:format=auto,format=yuv420p[mainwm]", mainLabel, overlayPosition(wm.Position, wm.Margin)))
+		mainLabel = "[mainwm]"
+	}
+	segments = append(segments, mainLabel+"[maina]")
+
+	if opts.OutroPath != "" {
+		outroLabel, err := addClip(opts.OutroPath, "outro")
+		if err != nil {
+			return "", err
+		}
+		segments = append(segments, outroLabel+"[outroa]")
+	}
+
+	// 拼接所有片段
+	filters = append(filters, fmt.Sprintf("%sconcat=n=%d:v=1:a=1[outv][outa]", strings.Join(segments, ""), len(segments)))
+
+	if err := os.MkdirAll(filepath.Dir(opts.OutputPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filterComplex := strings.Join(filters, ";")
+	args = append(args,
+		"-filter_complex", filterComplex,
+		"-map", "[outv]",
+		"-map", "[outa]",
+		"-c:v", "libx264",
+		"-preset", "medium",
+		"-crf", "23",
+		"-c:a", "aac",
+		"-b:a", "192k",
+		"-movflags", "+faststart",
+		"-y",
+		opts.OutputPath,
+	)
+
+	f.log.Infow("Applying branding", "segments", segments, "filter", filterComplex)
+
+	cmd := exec.Command("ffmpeg", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		f.log.Errorw("FFmpeg branding failed", "error", err, "output", string(output))
+		return "", fmt.Errorf("ffmpeg branding failed: %w, output: %s", err, string(output))
+	}
+
+	f.log.Infow("Branding applied successfully", "output", opts.OutputPath)
+	return opts.OutputPath, nil
+}
+
+// ExtractCover 从视频截取封面，可选叠加标题文字
+func (f *FFmpeg) ExtractCover(videoPath, outputPath string, at float64, title *TitleCardOptions) (string, error) {
+	if duration, err := f.GetVideoDuration(videoPath); err == nil && at >= duration {
+		at = duration / 2
+	}
+	if at < 0 {
+		at = 0
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	args := []string{
+		"-ss", fmt.Sprintf("%.2f", at),
+		"-i", videoPath,
+		"-frames:v", "1",
+		"-q:v", "2",
+	}
+
+	if title != nil && strings.TrimSpace(title.Text) != "" {
+		textFile := filepath.Join(f.tempDir, fmt.Sprintf("cover_%d.txt", time.Now().UnixNano()))
+		if err := os.WriteFile(textFile, []byte(title.Text), 0644); err != nil {
+			return "", fmt.Errorf("failed to write cover text: %w", err)
+		}
+		defer os.Remove(textFile)
+
+		// 底部半透明底条 + 标题文字
+		filter := "drawbox=x=0:y=ih*0.78:w=iw:h=ih*0.16:color=black@0.45:t=fill," +
+			strings.Replace(drawTextFilter(textFile, title, "h/16"), "y=(h-text_h)/2", "y=h*0.86-text_h/2", 1)
+		args = append(args, "-vf", filter)
+	}
+
+	args = append(args, "-y", outputPath)
+
+	cmd := exec.Command("ffmpeg", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		f.log.Errorw("FFmpeg cover extraction failed", "error", err, "output", string(output))
+		return "", fmt.Errorf("ffmpeg cover extraction failed: %w, output: %s", err, string(output))
+	}
+
+	f.log.Infow("Cover extracted", "video", videoPath, "output", outputPath, "at", at)
+	return outputPath, nil
+}
+
+// drawTextFilter 生成居中文字滤镜
+func drawTextFilter(textFile string, card *TitleCardOptions, fontSize string) string {
+	parts := []string{
+		fmt.Sprintf("textfile='%s'", escapeFilterValue(textFile)),
+		"fontcolor=" + ffmpegColor(card.FontColor, "white"),
+		"fontsize=" + fontSize,
+		"x=(w-text_w)/2",
+		"y=(h-text_h)/2",
+		"line_spacing=12",
+	}
+	if card.FontFile != "" {
+		parts = append(parts, fmt.Sprintf("fontfile='%s'", escapeFilterValue(card.FontFile)))
+	}
+	return "drawtext=" + strings.Join(parts, ":")
+}
+
+// overlayPosition 水印位置表达式
+func overlayPosition(position string, margin int) string {
+	if margin < 0 {
+		margin = 0
+	}
+	switch position {
+	case "top-left":
+		return fmt.Sprintf("%d:%d", margin, margin)
+	case "bottom-left":
+		return fmt.Sprintf("%d:H-h-%d", margin, margin)
+	case "bottom-right":
+		return fmt.Sprintf("W-w-%d:H-h-%d", margin, margin)
+	case "center":
+		return "(W-w)/2:(H-h)/2"
+	default:
+		return fmt.Sprintf("W-w-%d:%d", margin, margin)
+	}
+}
+
+// ffmpegColor 将 #RRGGBB 转换为 FFmpeg 颜色
+func ffmpegColor(color, fallback string) string {
+	color = strings.TrimSpace(color)
+	if color == "" {
+		return fallback
+	}
+	if strings.HasPrefix(color, "#") {
+		return "0x" + color[1:]
+	}
+	return color
+}
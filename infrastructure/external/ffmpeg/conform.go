@@ -0,0 +1,192 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"image"
+	_ "image/png"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FitMode 片段画幅与目标画幅不一致时的处理方式
+type FitMode string
+
+const (
+	FitModePad       FitMode = "pad"        // 等比缩放后加黑边
+	FitModeBlur      FitMode = "blur"       // 等比缩放，背景使用模糊放大的画面填充
+	FitModeCrop      FitMode = "crop"       // 等比放大后居中裁剪
+	FitModeSmartCrop FitMode = "smart_crop" // 等比放大后按画面细节最丰富的区域裁剪
+)
+
+// OutputFormat 目标输出画幅
+type OutputFormat struct {
+	Width   int
+	Height  int
+	FitMode FitMode
+}
+
+// ParseFitMode 解析画幅适配方式，默认加黑边
+func ParseFitMode(mode string) (FitMode, error) {
+	switch FitMode(strings.ToLower(strings.TrimSpace(mode))) {
+	case "", FitModePad:
+		return FitModePad, nil
+	case FitModeBlur:
+		return FitModeBlur, nil
+	case FitModeCrop:
+		return FitModeCrop, nil
+	case FitModeSmartCrop, "smart":
+		return FitModeSmartCrop, nil
+	default:
+		return "", fmt.Errorf("unsupported fit mode: %s", mode)
+	}
+}
+
+// conformFilter 生成将片段统一到目标画幅的视频滤镜
+func (f *FFmpeg) conformFilter(inputPath string, format *OutputFormat) string {
+	w, h := format.Width, format.Height
+
+	switch format.FitMode {
+	case FitModeBlur:
+		return fmt.Sprintf("split=2[bg][fg];"+
+			"[bg]scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d,boxblur=20:2[bgb];"+
+			"[fg]scale=%d:%d:force_original_aspect_ratio=decrease[fgs];"+
+			"[bgb][fgs]overlay=(W-w)/2:(H-h)/2,setsar=1,format=yuv420p",
+			w, h, w, h, w, h)
+	case FitModeCrop:
+		return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d,setsar=1,format=yuv420p", w, h, w, h)
+	case FitModeSmartCrop:
+		x, y := f.smartCropOffset(inputPath, w, h)
+		return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=increase,crop=%d:%d:%s:%s,setsar=1,format=yuv420p", w, h, w, h, x, y)
+	default:
+		return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2:color=black,setsar=1,format=yuv420p", w, h, w, h)
+	}
+}
+
+// smartCropOffset 抽取片段中间一帧，按梯度能量找出细节最丰富的裁剪窗口
+// 返回 crop 滤镜的 x、y 表达式；分析失败时回退到居中裁剪
+func (f *FFmpeg) smartCropOffset(inputPath string, targetW, targetH int) (string, string) {
+	const centerX, centerY = "(iw-ow)/2", "(ih-oh)/2"
+
+	srcW, srcH := f.getVideoResolution(inputPath)
+	if srcW <= 0 || srcH <= 0 {
+		return centerX, centerY
+	}
+
+	// 计算放大后的尺寸（force_original_aspect_ratio=increase）
+	scale := float64(targetW) / float64(srcW)
+	if s := float64(targetH) / float64(srcH); s > scale {
+		scale = s
+	}
+	scaledW := int(float64(srcW)*scale + 0.5)
+	scaledH := int(float64(srcH)*scale + 0.5)
+	horizontal := scaledW > targetW
+	if !horizontal && scaledH <= targetH {
+		return centerX, centerY
+	}
+
+	framePath := filepath.Join(f.tempDir, fmt.Sprintf("smartcrop_%d.png", time.Now().UnixNano()))
+	defer os.Remove(framePath)
+
+	seek := "0"
+	if duration, err := f.GetVideoDuration(inputPath); err == nil {
+		seek = fmt.Sprintf("%.2f", duration/2)
+	}
+	cmd := exec.Command("ffmpeg",
+		"-ss", seek,
+		"-i", inputPath,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale=%d:%d", scaledW, scaledH),
+		"-y",
+		framePath,
+	)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		f.log.Warnw("Smart crop frame extraction failed, using center crop", "error", err, "output", string(output))
+		return centerX, centerY
+	}
+
+	file, err := os.Open(framePath)
+	if err != nil {
+		return centerX, centerY
+	}
+	defer file.Close()
+	img, _, err := image.Decode(file)
+	if err != nil {
+		f.log.Warnw("Smart crop frame decode failed, using center crop", "error", err)
+		return centerX, centerY
+	}
+
+	window := targetH
+	if horizontal {
+		window = targetW
+	}
+	offset := bestCropOffset(edgeEnergyProfile(img, horizontal), window)
+
+	f.log.Infow("Smart crop offset", "input", inputPath, "horizontal", horizontal, "offset", offset)
+	if horizontal {
+		return fmt.Sprintf("%d", offset), centerY
+	}
+	return centerX, fmt.Sprintf("%d", offset)
+}
+
+// edgeEnergyProfile 计算每列（horizontal）或每行的梯度能量
+func edgeEnergyProfile(img image.Image, horizontal bool) []float64 {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	luma := func(x, y int) float64 {
+		r, g, b, _ := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+		return 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)
+	}
+
+	size := height
+	if horizontal {
+		size = width
+	}
+	profile := make([]float64, size)
+
+	// 隔点采样以控制计算量
+	const step = 2
+	for y := 1; y < height; y += step {
+		for x := 1; x < width; x += step {
+			c := luma(x, y)
+			energy := abs(c-luma(x-1, y)) + abs(c-luma(x, y-1))
+			if horizontal {
+				profile[x] += energy
+			} else {
+				profile[y] += energy
+			}
+		}
+	}
+	return profile
+}
+
+// bestCropOffset 在能量分布上滑动窗口，返回能量最大窗口的起点
+func bestCropOffset(profile []float64, window int) int {
+	if window >= len(profile) {
+		return 0
+	}
+
+	var sum float64
+	for i := 0; i < window; i++ {
+		sum += profile[i]
+	}
+	best, bestOffset := sum, 0
+	for i := window; i < len(profile); i++ {
+		sum += profile[i] - profile[i-window]
+		if sum > best {
+			best = sum
+			bestOffset = i - window + 1
+		}
+	}
+	return bestOffset
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,97 @@
+package ffmpeg
+
+import (
+	"image"
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"github.com/drama-generator/backend/pkg/logger"
+)
+
+func TestParseFitMode(t *testing.T) {
+	tests := []struct {
+		input string
+		want  FitMode
+	}{
+		{"", FitModePad},
+		{"PAD", FitModePad},
+		{" blur ", FitModeBlur},
+		{"crop", FitModeCrop},
+		{"smart", FitModeSmartCrop},
+		{"smart_crop", FitModeSmartCrop},
+	}
+	for _, tt := range tests {
+		got, err := ParseFitMode(tt.input)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseFitMode(%q) = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+	if _, err := ParseFitMode("stretch"); err == nil {
+		t.Error("expected unsupported fit mode error")
+	}
+}
+
+func TestConformFilter(t *testing.T) {
+	f := &FFmpeg{log: logger.NewLogger(false), tempDir: t.TempDir()}
+	// 竖屏 1080x1920；所有模式都以 setsar=1,format=yuv420p 结尾，保证 xfade/concat 的输入一致
+	tests := []struct {
+		mode FitMode
+		want string
+	}{
+		{FitModePad, "scale=1080:1920:force_original_aspect_ratio=decrease,pad=1080:1920:(ow-iw)/2:(oh-ih)/2:color=black,setsar=1,format=yuv420p"},
+		{FitModeCrop, "scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920,setsar=1,format=yuv420p"},
+		{FitModeBlur, "split=2[bg][fg];" +
+			"[bg]scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920,boxblur=20:2[bgb];" +
+			"[fg]scale=1080:1920:force_original_aspect_ratio=decrease[fgs];" +
+			"[bgb][fgs]overlay=(W-w)/2:(H-h)/2,setsar=1,format=yuv420p"},
+		// 无法抽帧分析时回退到居中裁剪
+		{FitModeSmartCrop, "scale=1080:1920:force_original_aspect_ratio=increase,crop=1080:1920:(iw-ow)/2:(ih-oh)/2,setsar=1,format=yuv420p"},
+	}
+	for _, tt := range tests {
+		got := f.conformFilter(filepath.Join(t.TempDir(), "missing.mp4"), &OutputFormat{Width: 1080, Height: 1920, FitMode: tt.mode})
+		if got != tt.want {
+			t.Errorf("%s filter\n got: %s\nwant: %s", tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestBestCropOffset(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile []float64
+		window  int
+		want    int
+	}{
+		{"window covers frame", []float64{5, 1, 1}, 3, 0},
+		{"window wider than frame", []float64{5, 1, 1}, 4, 0},
+		{"detail at the end", []float64{0, 0, 0, 1, 9, 9}, 2, 4},
+		{"detail in the middle", []float64{1, 0, 7, 8, 0, 1}, 2, 2},
+		{"ties keep the first window", []float64{3, 0, 0, 3}, 1, 0},
+	}
+	for _, tt := range tests {
+		if got := bestCropOffset(tt.profile, tt.window); got != tt.want {
+			t.Errorf("%s: bestCropOffset = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEdgeEnergyFollowsDetail(t *testing.T) {
+	// 160x40 的画面只在 x=121 处有一条竖线，裁剪成 40 宽时窗口应覆盖竖线
+	img := image.NewGray(image.Rect(0, 0, 160, 40))
+	for y := 0; y < 40; y++ {
+		img.SetGray(121, y, color.Gray{Y: 255})
+	}
+
+	profile := edgeEnergyProfile(img, true)
+	if len(profile) != 160 {
+		t.Fatalf("expected one value per column, got %d", len(profile))
+	}
+	offset := bestCropOffset(profile, 40)
+	if offset > 121 || offset+40 <= 121 {
+		t.Fatalf("crop window [%d, %d) misses the detail at x=121", offset, offset+40)
+	}
+	if vertical := edgeEnergyProfile(img, false); len(vertical) != 40 {
+		t.Fatalf("expected one value per row, got %d", len(vertical))
+	}
+}
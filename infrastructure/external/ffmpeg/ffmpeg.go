@@ -16,15 +16,24 @@ import (
 type FFmpeg struct {
 	log     *logger.Logger
 	tempDir string
+	cache   MediaCache
 }
 
-func NewFFmpeg(log *logger.Logger) *FFmpeg {
+// MediaCache 远程媒体缓存，返回可直接读取的本地文件路径
+type MediaCache interface {
+	Fetch(url string) (string, error)
+}
+
+// NewFFmpeg 创建 FFmpeg 封装
+// cache 为远程媒体缓存，下载合成片段、音轨等远程文件时优先使用，可为 nil
+func NewFFmpeg(log *logger.Logger, cache MediaCache) *FFmpeg {
 	tempDir := filepath.Join(os.TempDir(), "drama-video-merge")
 	os.MkdirAll(tempDir, 0755)
 
 	return &FFmpeg{
 		log:     log,
 		tempDir: tempDir,
+		cache:   cache,
 	}
 }
 
@@ -39,6 +48,7 @@ type VideoClip struct {
 type MergeOptions struct {
 	OutputPath string
 	Clips      []VideoClip
+	Format     *OutputFormat // 目标画幅，为空时保持原分辨率（以最大分辨率为准）
 }
 
 func (f *FFmpeg) MergeVideos(opts *MergeOptions) (string, error) {
@@ -54,7 +64,7 @@ func (f *FFmpeg) MergeVideos(opts *MergeOptions) (string, error) {
 
 	for i, clip := range opts.Clips {
 		// 下载原始视频
-		downloadPath := filepath.Join(f.tempDir, fmt.Sprintf("download_%d_%d.mp4", time.Now().Unix(), i))
+		downloadPath := filepath.Join(f.tempDir, fmt.Sprintf("download_%d_%d.mp4", time.Now().UnixNano(), i))
 		localPath, err := f.downloadVideo(clip.URL, downloadPath)
 		if err != nil {
 			f.cleanup(downloadedPaths)
@@ -63,9 +73,15 @@ func (f *FFmpeg) MergeVideos(opts *MergeOptions) (string, error) {
 		}
 		downloadedPaths = append(downloadedPaths, localPath)
 
+		// 统一画幅（按目标分辨率缩放/填充/裁剪）
+		videoFilter := ""
+		if opts.Format != nil {
+			videoFilter = f.conformFilter(localPath, opts.Format)
+		}
+
 		// 裁剪视频片段（根据StartTime和EndTime）
-		trimmedPath := filepath.Join(f.tempDir, fmt.Sprintf("trimmed_%d_%d.mp4", time.Now().Unix(), i))
-		err = f.trimVideo(localPath, trimmedPath, clip.StartTime, clip.EndTime)
+		trimmedPath := filepath.Join(f.tempDir, fmt.Sprintf("trimmed_%d_%d.mp4", time.Now().UnixNano(), i))
+		err = f.trimVideo(localPath, trimmedPath, clip.StartTime, clip.EndTime, videoFilter)
 		if err != nil {
 			f.cleanup(downloadedPaths)
 			f.cleanup(trimmedPaths)
@@ -91,7 +107,7 @@ func (f *FFmpeg) MergeVideos(opts *MergeOptions) (string, error) {
 	}
 
 	// 合并裁剪后的视频片段（支持转场效果）
-	err := f.concatenateVideosWithTransitions(trimmedPaths, opts.Clips, opts.OutputPath)
+	err := f.concatenateVideosWithTransitions(trimmedPaths, opts.Clips, opts.OutputPath, opts.Format)
 
 	// 清理裁剪后的临时文件
 	f.cleanup(trimmedPaths)
@@ -134,7 +150,15 @@ func (f *FFmpeg) downloadVideo(url, destPath string) (string, error) {
 		}
 	}
 
-	// 远程 URL，需要下载
+	// 远程 URL，优先从媒体缓存获取，避免重复下载
+	if f.cache != nil {
+		cachedPath, err := f.cache.Fetch(url)
+		if err == nil {
+			return f.downloadVideo(cachedPath, destPath)
+		}
+		f.log.Warnw("Media cache fetch failed, downloading directly", "url", url, "error", err)
+	}
+
 	f.log.Infow("Downloading video", "url", url, "dest", destPath)
 
 	resp, err := http.Get(url)
@@ -161,20 +185,27 @@ func (f *FFmpeg) downloadVideo(url, destPath string) (string, error) {
 	return destPath, nil
 }
 
-func (f *FFmpeg) trimVideo(inputPath, outputPath string, startTime, endTime float64) error {
+func (f *FFmpeg) trimVideo(inputPath, outputPath string, startTime, endTime float64, videoFilter string) error {
 	f.log.Infow("Trimming video",
 		"input", inputPath,
 		"output", outputPath,
 		"start", startTime,
-		"end", endTime)
+		"end", endTime,
+		"video_filter", videoFilter)
+
+	// 统一画幅时追加视频滤镜
+	var filterArgs []string
+	if videoFilter != "" {
+		filterArgs = []string{"-vf", videoFilter, "-r", "30"}
+	}
 
 	// 如果startTime和endTime都为0，或者endTime <= startTime，复制整个视频
 	// 使用重新编码而非-c copy以确保输出文件完整性
 	if (startTime == 0 && endTime == 0) || endTime <= startTime {
 		f.log.Infow("No valid trim range, re-encoding entire video")
 
-		cmd := exec.Command("ffmpeg",
-			"-i", inputPath,
+		args := append([]string{"-i", inputPath}, filterArgs...)
+		args = append(args,
 			"-c:v", "libx264",
 			"-preset", "fast",
 			"-crf", "23",
@@ -184,6 +215,7 @@ func (f *FFmpeg) trimVideo(inputPath, outputPath string, startTime, endTime floa
 			"-y",
 			outputPath,
 		)
+		cmd := exec.Command("ffmpeg", args...)
 
 		output, err := cmd.CombinedOutput()
 		if err != nil {
@@ -199,37 +231,27 @@ func (f *FFmpeg) trimVideo(inputPath, outputPath string, startTime, endTime floa
 	// -ss: 开始时间（秒）
 	// -to/-t: 结束时间或持续时间
 	// 使用重新编码而非-c copy以确保输出文件完整性，避免Windows环境下流信息丢失
-	var cmd *exec.Cmd
+	args := []string{
+		"-i", inputPath,
+		"-ss", fmt.Sprintf("%.2f", startTime),
+	}
 	if endTime > 0 {
 		// 有明确的结束时间
-		cmd = exec.Command("ffmpeg",
-			"-i", inputPath,
-			"-ss", fmt.Sprintf("%.2f", startTime),
-			"-to", fmt.Sprintf("%.2f", endTime),
-			"-c:v", "libx264",
-			"-preset", "fast",
-			"-crf", "23",
-			"-c:a", "aac",
-			"-b:a", "128k",
-			"-movflags", "+faststart",
-			"-y",
-			outputPath,
-		)
-	} else {
-		// 只有开始时间，裁剪到视频末尾
-		cmd = exec.Command("ffmpeg",
-			"-i", inputPath,
-			"-ss", fmt.Sprintf("%.2f", startTime),
-			"-c:v", "libx264",
-			"-preset", "fast",
-			"-crf", "23",
-			"-c:a", "aac",
-			"-b:a", "128k",
-			"-movflags", "+faststart",
-			"-y",
-			outputPath,
-		)
+		args = append(args, "-to", fmt.Sprintf("%.2f", endTime))
 	}
+	// 否则只有开始时间，裁剪到视频末尾
+	args = append(args, filterArgs...)
+	args = append(args,
+		"-c:v", "libx264",
+		"-preset", "fast",
+		"-crf", "23",
+		"-c:a", "aac",
+		"-b:a", "128k",
+		"-movflags", "+faststart",
+		"-y",
+		outputPath,
+	)
+	cmd := exec.Command("ffmpeg", args...)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
@@ -241,7 +263,7 @@ func (f *FFmpeg) trimVideo(inputPath, outputPath string, startTime, endTime floa
 	return nil
 }
 
-func (f *FFmpeg) concatenateVideosWithTransitions(inputPaths []string, clips []VideoClip, outputPath string) error {
+func (f *FFmpeg) concatenateVideosWithTransitions(inputPaths []string, clips []VideoClip, outputPath string, format *OutputFormat) error {
 	if len(inputPaths) == 0 {
 		return fmt.Errorf("no input paths")
 	}
@@ -269,12 +291,12 @@ func (f *FFmpeg) concatenateVideosWithTransitions(inputPaths []string, clips []V
 
 	// 使用xfade滤镜添加转场效果
 	f.log.Infow("Merging with transitions", "clips_count", len(inputPaths))
-	return f.mergeWithXfade(inputPaths, clips, outputPath)
+	return f.mergeWithXfade(inputPaths, clips, outputPath, format)
 }
 
 func (f *FFmpeg) concatenateVideos(inputPaths []string, outputPath string) error {
 	// 创建文件列表
-	listFile := filepath.Join(f.tempDir, fmt.Sprintf("filelist_%d.txt", time.Now().Unix()))
+	listFile := filepath.Join(f.tempDir, fmt.Sprintf("filelist_%d.txt", time.Now().UnixNano()))
 	defer os.Remove(listFile)
 
 	var content strings.Builder
@@ -310,7 +332,7 @@ func (f *FFmpeg) concatenateVideos(inputPaths []string, outputPath string) error
 	return nil
 }
 
-func (f *FFmpeg) mergeWithXfade(inputPaths []string, clips []VideoClip, outputPath string) error {
+func (f *FFmpeg) mergeWithXfade(inputPaths []string, clips []VideoClip, outputPath string, format *OutputFormat) error {
 	// 使用xfade滤镜进行转场
 	// 构建输入参数
 	args := []string{}
@@ -330,18 +352,22 @@ func (f *FFmpeg) mergeWithXfade(inputPaths []string, clips []VideoClip, outputPa
 	}
 	f.log.Infow("Overall audio detection", "has_any_audio", hasAnyAudio, "audio_streams", audioStreams)
 
-	// 检测视频分辨率，找到最大分辨率作为目标分辨率
+	// 检测视频分辨率，找到最大分辨率作为目标分辨率（指定画幅时直接使用目标分辨率）
 	maxWidth := 0
 	maxHeight := 0
-	for i, path := range inputPaths {
-		width, height := f.getVideoResolution(path)
-		if width > maxWidth {
-			maxWidth = width
-		}
-		if height > maxHeight {
-			maxHeight = height
+	if format != nil {
+		maxWidth, maxHeight = format.Width, format.Height
+	} else {
+		for i, path := range inputPaths {
+			width, height := f.getVideoResolution(path)
+			if width > maxWidth {
+				maxWidth = width
+			}
+			if height > maxHeight {
+				maxHeight = height
+			}
+			f.log.Infow("Video resolution detection", "index", i, "width", width, "height", height)
 		}
-		f.log.Infow("Video resolution detection", "index", i, "width", width, "height", height)
 	}
 	f.log.Infow("Target resolution", "width", maxWidth, "height", maxHeight)
 
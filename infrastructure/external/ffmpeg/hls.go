@@ -0,0 +1,150 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HLSRendition HLS 码率档位
+type HLSRendition struct {
+	Name         string // 子目录名，如 720p
+	Height       int    // 输出高度，宽度按比例缩放
+	VideoBitrate string // 如 2800k
+	AudioBitrate string // 如 128k
+}
+
+// DefaultHLSRenditions 默认码率阶梯
+var DefaultHLSRenditions = []HLSRendition{
+	{Name: "1080p", Height: 1080, VideoBitrate: "5000k", AudioBitrate: "192k"},
+	{Name: "720p", Height: 720, VideoBitrate: "2800k", AudioBitrate: "128k"},
+	{Name: "480p", Height: 480, VideoBitrate: "1400k", AudioBitrate: "128k"},
+	{Name: "360p", Height: 360, VideoBitrate: "800k", AudioBitrate: "96k"},
+}
+
+// HLSOptions HLS 打包参数
+type HLSOptions struct {
+	InputPath       string
+	OutputDir       string // master.m3u8 及各档位子目录的输出目录
+	Renditions      []HLSRendition
+	SegmentDuration int // 分片时长（秒）
+}
+
+// PackageHLS 将视频转码为多码率 HLS，返回 master.m3u8 路径和实际输出的档位
+func (f *FFmpeg) PackageHLS(opts *HLSOptions) (string, []HLSRendition, error) {
+	inputPath := opts.InputPath
+	if strings.HasPrefix(inputPath, "http://") || strings.HasPrefix(inputPath, "https://") {
+		dest := filepath.Join(f.tempDir, fmt.Sprintf("hls_source_%d.mp4", time.Now().UnixNano()))
+		if _, err := f.downloadVideo(inputPath, dest); err != nil {
+			return "", nil, fmt.Errorf("failed to download source video: %w", err)
+		}
+		defer os.Remove(dest)
+		inputPath = dest
+	}
+
+	renditions := opts.Renditions
+	if len(renditions) == 0 {
+		renditions = DefaultHLSRenditions
+	}
+	segmentDuration := opts.SegmentDuration
+	if segmentDuration <= 0 {
+		segmentDuration = 6
+	}
+
+	// 竖屏视频以短边为档位基准，不放大超过源分辨率的档位
+	width, height := f.getVideoResolution(inputPath)
+	shortSide := height
+	portrait := width > 0 && width < height
+	if portrait {
+		shortSide = width
+	}
+	var selected []HLSRendition
+	for _, r := range renditions {
+		if shortSide <= 0 || r.Height <= shortSide {
+			selected = append(selected, r)
+		}
+	}
+	if len(selected) == 0 {
+		lowest := renditions[len(renditions)-1]
+		lowest.Height = shortSide
+		selected = []HLSRendition{lowest}
+	}
+
+	if err := os.MkdirAll(opts.OutputDir, 0755); err != nil {
+		return "", nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	hasAudio := f.hasAudioStream(inputPath)
+
+	// 拆分视频流并按档位缩放
+	splitLabels := make([]string, len(selected))
+	var filters []string
+	for i := range selected {
+		splitLabels[i] = fmt.Sprintf("[v%d]", i)
+	}
+	filters = append(filters, fmt.Sprintf("[0:v]split=%d%s", len(selected), strings.Join(splitLabels, "")))
+	for i, r := range selected {
+		scale := fmt.Sprintf("scale=-2:%d", r.Height)
+		if portrait {
+			scale = fmt.Sprintf("scale=%d:-2", r.Height)
+		}
+		filters = append(filters, fmt.Sprintf("[v%d]%s,setsar=1[v%dout]", i, scale, i))
+	}
+
+	args := []string{"-i", inputPath, "-filter_complex", strings.Join(filters, ";")}
+	var streamMap []string
+	for i, r := range selected {
+		args = append(args,
+			"-map", fmt.Sprintf("[v%dout]", i),
+			fmt.Sprintf("-c:v:%d", i), "libx264",
+			fmt.Sprintf("-b:v:%d", i), r.VideoBitrate,
+			fmt.Sprintf("-maxrate:v:%d", i), r.VideoBitrate,
+			fmt.Sprintf("-bufsize:v:%d", i), r.VideoBitrate,
+		)
+		entry := fmt.Sprintf("v:%d,name:%s", i, r.Name)
+		if hasAudio {
+			args = append(args,
+				"-map", "0:a:0",
+				fmt.Sprintf("-c:a:%d", i), "aac",
+				fmt.Sprintf("-b:a:%d", i), r.AudioBitrate,
+			)
+			entry = fmt.Sprintf("v:%d,a:%d,name:%s", i, i, r.Name)
+		}
+		streamMap = append(streamMap, entry)
+	}
+
+	// 固定关键帧间隔，保证各档位分片对齐以便切换码率
+	gop := segmentDuration * 30
+	args = append(args,
+		"-preset", "veryfast",
+		"-r", "30",
+		"-g", fmt.Sprintf("%d", gop),
+		"-keyint_min", fmt.Sprintf("%d", gop),
+		"-sc_threshold", "0",
+		"-f", "hls",
+		"-hls_time", fmt.Sprintf("%d", segmentDuration),
+		"-hls_playlist_type", "vod",
+		"-hls_flags", "independent_segments",
+		"-hls_segment_filename", filepath.Join(opts.OutputDir, "%v", "segment_%03d.ts"),
+		"-master_pl_name", "master.m3u8",
+		"-var_stream_map", strings.Join(streamMap, " "),
+		"-y",
+		filepath.Join(opts.OutputDir, "%v", "index.m3u8"),
+	)
+
+	f.log.Infow("Packaging HLS", "input", inputPath, "renditions", len(selected), "output_dir", opts.OutputDir)
+
+	cmd := exec.Command("ffmpeg", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		f.log.Errorw("FFmpeg HLS packaging failed", "error", err, "output", string(output))
+		return "", nil, fmt.Errorf("ffmpeg hls packaging failed: %w, output: %s", err, string(output))
+	}
+
+	masterPath := filepath.Join(opts.OutputDir, "master.m3u8")
+	f.log.Infow("HLS packaging completed", "master", masterPath)
+	return masterPath, selected, nil
+}
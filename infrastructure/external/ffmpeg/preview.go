@@ -0,0 +1,167 @@
+package ffmpeg
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// SpriteInfo 雪碧图布局，前端按 interval 定位帧
+type SpriteInfo struct {
+	Columns     int     `json:"columns"`
+	Rows        int     `json:"rows"`
+	Count       int     `json:"count"`
+	FrameWidth  int     `json:"frame_width"`
+	FrameHeight int     `json:"frame_height"`
+	Interval    float64 `json:"interval"` // 相邻帧间隔（秒）
+}
+
+// WaveformPeaks 音频波形峰值（0-1）
+type WaveformPeaks struct {
+	SampleRate     int       `json:"sample_rate"`
+	SamplesPerPeak int       `json:"samples_per_peak"`
+	Duration       float64   `json:"duration"`
+	Peaks          []float64 `json:"peaks"`
+}
+
+// ExtractFrame 截取单帧，width > 0 时按宽度等比缩放（不放大）
+func (f *FFmpeg) ExtractFrame(inputPath, outputPath string, at float64, width int) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	args := []string{"-ss", fmt.Sprintf("%.2f", math.Max(at, 0)), "-i", inputPath, "-frames:v", "1", "-q:v", "3"}
+	if width > 0 {
+		args = append(args, "-vf", fmt.Sprintf("scale='min(%d,iw)':-2", width))
+	}
+	args = append(args, "-y", outputPath)
+
+	return f.run("frame extraction", args)
+}
+
+// ResizeImage 按最大宽度等比缩放图片（不放大）
+func (f *FFmpeg) ResizeImage(inputPath, outputPath string, maxWidth int) error {
+	return f.ExtractFrame(inputPath, outputPath, 0, maxWidth)
+}
+
+// GenerateSprite 均匀截取 count 帧并拼接为雪碧图
+func (f *FFmpeg) GenerateSprite(inputPath, outputPath string, count, columns, frameWidth int) (*SpriteInfo, error) {
+	if count <= 0 {
+		count = 10
+	}
+	if columns <= 0 || columns > count {
+		columns = count
+	}
+	if frameWidth <= 0 {
+		frameWidth = 160
+	}
+
+	duration, err := f.GetVideoDuration(inputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := f.getVideoResolution(inputPath)
+	frameHeight := int(math.Round(float64(frameWidth)*float64(height)/float64(width)/2)) * 2
+	rows := (count + columns - 1) / columns
+	interval := duration / float64(count)
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filter := fmt.Sprintf("fps=1/%.4f,scale=%d:%d,tile=%dx%d", interval, frameWidth, frameHeight, columns, rows)
+	args := []string{"-i", inputPath, "-vf", filter, "-frames:v", "1", "-q:v", "4", "-y", outputPath}
+	if err := f.run("sprite generation", args); err != nil {
+		return nil, err
+	}
+
+	return &SpriteInfo{
+		Columns:     columns,
+		Rows:        rows,
+		Count:       count,
+		FrameWidth:  frameWidth,
+		FrameHeight: frameHeight,
+		Interval:    interval,
+	}, nil
+}
+
+// GenerateWaveformImage 生成波形图 PNG
+func (f *FFmpeg) GenerateWaveformImage(inputPath, outputPath string, width, height int, color string) error {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filter := fmt.Sprintf("aformat=channel_layouts=mono,showwavespic=s=%dx%d:colors=%s", width, height, ffmpegColor(color, "0x4F8EF7"))
+	args := []string{"-i", inputPath, "-filter_complex", filter, "-frames:v", "1", "-y", outputPath}
+	return f.run("waveform generation", args)
+}
+
+// ExtractPeaks 解码为单声道 PCM 并计算 count 个峰值
+func (f *FFmpeg) ExtractPeaks(inputPath string, count int) (*WaveformPeaks, error) {
+	if count <= 0 {
+		count = 800
+	}
+	const sampleRate = 8000
+
+	cmd := exec.Command("ffmpeg",
+		"-v", "error",
+		"-i", inputPath,
+		"-vn",
+		"-ac", "1",
+		"-ar", fmt.Sprintf("%d", sampleRate),
+		"-f", "s16le",
+		"-",
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		f.log.Errorw("FFmpeg peak extraction failed", "error", err, "input", inputPath)
+		return nil, fmt.Errorf("ffmpeg peak extraction failed: %w", err)
+	}
+
+	total := len(output) / 2
+	if total == 0 {
+		return nil, fmt.Errorf("no audio samples decoded")
+	}
+
+	perPeak := (total + count - 1) / count
+	peaks := make([]float64, 0, count)
+	for start := 0; start < total; start += perPeak {
+		end := start + perPeak
+		if end > total {
+			end = total
+		}
+		var peak int
+		for i := start; i < end; i++ {
+			sample := int(int16(binary.LittleEndian.Uint16(output[i*2:])))
+			if sample < 0 {
+				sample = -sample
+			}
+			if sample > peak {
+				peak = sample
+			}
+		}
+		peaks = append(peaks, math.Round(float64(peak)/32768*1000)/1000)
+	}
+
+	return &WaveformPeaks{
+		SampleRate:     sampleRate,
+		SamplesPerPeak: perPeak,
+		Duration:       float64(total) / sampleRate,
+		Peaks:          peaks,
+	}, nil
+}
+
+// run 执行 ffmpeg 命令并记录失败输出
+func (f *FFmpeg) run(action string, args []string) error {
+	cmd := exec.Command("ffmpeg", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		f.log.Errorw("FFmpeg "+action+" failed", "error", err, "output", string(output))
+		return fmt.Errorf("ffmpeg %s failed: %w, output: %s", action, err, string(output))
+	}
+	return nil
+}
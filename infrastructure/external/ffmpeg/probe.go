@@ -0,0 +1,119 @@
+package ffmpeg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MediaInfo ffprobe 探测结果
+type MediaInfo struct {
+	FormatName string
+	Duration   float64 // 秒
+	Size       int64   // 字节
+	Bitrate    int64   // bps
+	Width      int
+	Height     int
+	VideoCodec string
+	AudioCodec string
+	FPS        float64
+	HasVideo   bool
+	HasAudio   bool
+}
+
+type probeOutput struct {
+	Streams []struct {
+		CodecType    string `json:"codec_type"`
+		CodecName    string `json:"codec_name"`
+		Width        int    `json:"width"`
+		Height       int    `json:"height"`
+		AvgFrameRate string `json:"avg_frame_rate"`
+		RFrameRate   string `json:"r_frame_rate"`
+		Disposition  struct {
+			AttachedPic int `json:"attached_pic"`
+		} `json:"disposition"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+		Size       string `json:"size"`
+		BitRate    string `json:"bit_rate"`
+	} `json:"format"`
+}
+
+// Probe 使用 ffprobe 获取媒体文件的格式、编码、分辨率、帧率等信息，支持本地路径和URL
+func (f *FFmpeg) Probe(path string) (*MediaInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "ffprobe",
+		"-v", "error",
+		"-print_format", "json",
+		"-show_format",
+		"-show_streams",
+		path,
+	)
+
+	output, err := cmd.Output()
+	if err != nil {
+		f.log.Warnw("ffprobe failed", "path", path, "error", err)
+		return nil, fmt.Errorf("ffprobe failed: %w", err)
+	}
+
+	var probe probeOutput
+	if err := json.Unmarshal(output, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+
+	info := &MediaInfo{
+		FormatName: probe.Format.FormatName,
+	}
+	info.Duration, _ = strconv.ParseFloat(probe.Format.Duration, 64)
+	info.Size, _ = strconv.ParseInt(probe.Format.Size, 10, 64)
+	info.Bitrate, _ = strconv.ParseInt(probe.Format.BitRate, 10, 64)
+
+	for _, stream := range probe.Streams {
+		switch stream.CodecType {
+		case "video":
+			// 音频文件中的封面图不算视频流
+			if info.HasVideo || stream.Disposition.AttachedPic == 1 {
+				continue
+			}
+			info.HasVideo = true
+			info.VideoCodec = stream.CodecName
+			info.Width = stream.Width
+			info.Height = stream.Height
+			info.FPS = parseFrameRate(stream.AvgFrameRate)
+			if info.FPS == 0 {
+				info.FPS = parseFrameRate(stream.RFrameRate)
+			}
+		case "audio":
+			if info.HasAudio {
+				continue
+			}
+			info.HasAudio = true
+			info.AudioCodec = stream.CodecName
+		}
+	}
+
+	return info, nil
+}
+
+// parseFrameRate 解析 "30000/1001" 形式的帧率
+func parseFrameRate(rate string) float64 {
+	num, den, found := strings.Cut(rate, "/")
+	if !found {
+		value, _ := strconv.ParseFloat(rate, 64)
+		return value
+	}
+	n, err1 := strconv.ParseFloat(num, 64)
+	d, err2 := strconv.ParseFloat(den, 64)
+	if err1 != nil || err2 != nil || d == 0 {
+		return 0
+	}
+	return n / d
+}
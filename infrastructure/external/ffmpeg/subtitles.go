@@ -0,0 +1,100 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// SubtitleStyle 烧录字幕样式（ASS force_style）
+type SubtitleStyle struct {
+	FontName     string // 字体名称
+	FontSize     int    // 字号
+	PrimaryColor string // 字体颜色，&HBBGGRR 或 #RRGGBB
+	OutlineColor string // 描边颜色
+	Outline      int    // 描边宽度
+	MarginV      int    // 底部边距
+	Bold         bool
+}
+
+// BurnSubtitles 将字幕文件烧录进视频画面，音频直接复制
+func (f *FFmpeg) BurnSubtitles(inputPath, subtitlePath, outputPath string, style *SubtitleStyle) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	filter := fmt.Sprintf("subtitles=filename='%s'", escapeFilterValue(subtitlePath))
+	if forceStyle := style.forceStyle(); forceStyle != "" {
+		filter += fmt.Sprintf(":force_style='%s'", forceStyle)
+	}
+
+	args := []string{
+		"-i", inputPath,
+		"-vf", filter,
+		"-c:v", "libx264",
+		"-preset", "medium",
+		"-crf", "23",
+	}
+	if f.hasAudioStream(inputPath) {
+		args = append(args, "-c:a", "copy")
+	}
+	args = append(args, "-movflags", "+faststart", "-y", outputPath)
+
+	f.log.Infow("Burning subtitles", "input", inputPath, "subtitles", subtitlePath, "filter", filter)
+
+	cmd := exec.Command("ffmpeg", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		f.log.Errorw("FFmpeg subtitle burn failed", "error", err, "output", string(output))
+		return "", fmt.Errorf("ffmpeg subtitle burn failed: %w, output: %s", err, string(output))
+	}
+
+	f.log.Infow("Subtitles burned successfully", "output", outputPath)
+	return outputPath, nil
+}
+
+func (s *SubtitleStyle) forceStyle() string {
+	if s == nil {
+		return ""
+	}
+	var parts []string
+	if s.FontName != "" {
+		parts = append(parts, "FontName="+s.FontName)
+	}
+	if s.FontSize > 0 {
+		parts = append(parts, fmt.Sprintf("FontSize=%d", s.FontSize))
+	}
+	if s.PrimaryColor != "" {
+		parts = append(parts, "PrimaryColour="+assColor(s.PrimaryColor))
+	}
+	if s.OutlineColor != "" {
+		parts = append(parts, "OutlineColour="+assColor(s.OutlineColor))
+	}
+	if s.Outline > 0 {
+		parts = append(parts, fmt.Sprintf("Outline=%d", s.Outline))
+	}
+	if s.MarginV > 0 {
+		parts = append(parts, fmt.Sprintf("MarginV=%d", s.MarginV))
+	}
+	if s.Bold {
+		parts = append(parts, "Bold=1")
+	}
+	return strings.Join(parts, ",")
+}
+
+// assColor 将 #RRGGBB 转换为 ASS 使用的 &H00BBGGRR
+func assColor(color string) string {
+	if strings.HasPrefix(color, "#") && len(color) == 7 {
+		return fmt.Sprintf("&H00%s%s%s", color[5:7], color[3:5], color[1:3])
+	}
+	return color
+}
+
+// escapeFilterValue 转义滤镜参数中的特殊字符
+func escapeFilterValue(value string) string {
+	value = filepath.ToSlash(value)
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`, `:`, `\:`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/drama-generator/backend/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MediaCacheCategory 仅供本地工具读取的远程媒体缓存目录，存储迁移时不上传
+const MediaCacheCategory = "cache"
+
+// DedupStorage 在任意存储后端之上按 SHA-256 内容哈希去重
+// 下载与上传的文件以 "<category>/<hash前2位>/<hash><ext>" 为 key 保存，相同内容只存一份；
+// storage_blobs 记录引用计数，storage_url_caches 记录远程URL对应的内容哈希
+type DedupStorage struct {
+	Storage
+	db *gorm.DB
+}
+
+func NewDedupStorage(backend Storage, db *gorm.DB) *DedupStorage {
+	return &DedupStorage{Storage: backend, db: db}
+}
+
+// Upload 按内容哈希保存上传文件，返回访问URL
+func (s *DedupStorage) Upload(file io.Reader, filename string, category string) (string, error) {
+	key, err := s.store(file, strings.ToLower(filepath.Ext(filename)), category, "", true)
+	if err != nil {
+		return "", err
+	}
+	return s.GetURL(key), nil
+}
+
+func (s *DedupStorage) DownloadFromURL(url, category string) (string, error) {
+	result, err := s.DownloadFromURLWithPath(url, category)
+	if err != nil {
+		return "", err
+	}
+	return result.URL, nil
+}
+
+// DownloadFromURLWithPath 下载远程文件；同一URL或相同内容已存在时直接复用
+func (s *DedupStorage) DownloadFromURLWithPath(url, category string) (*DownloadResult, error) {
+	key, err := s.fetch(url, category, true)
+	if err != nil {
+		return nil, err
+	}
+	return &DownloadResult{
+		URL:          s.GetURL(key),
+		RelativePath: key,
+		AbsolutePath: s.GetAbsolutePath(key),
+	}, nil
+}
+
+// Fetch 获取远程媒体的本地文件路径（供 ffmpeg 使用），不增加引用计数
+// 本存储自身的URL直接返回对应文件，其余URL下载一次后缓存
+func (s *DedupStorage) Fetch(url string) (string, error) {
+	if key, ok := s.KeyFromURL(url); ok {
+		if _, err := s.Stat(key); err == nil {
+			return s.GetAbsolutePath(key), nil
+		}
+	}
+	key, err := s.fetch(url, MediaCacheCategory, false)
+	if err != nil {
+		return "", err
+	}
+	return s.GetAbsolutePath(key), nil
+}
+
+// Delete 减少引用计数，计数归零时删除文件；非去重文件直接删除
+func (s *DedupStorage) Delete(key string) error {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return err
+	}
+
+	var blob models.StorageBlob
+	if err := s.db.Where("storage_key = ?", key).First(&blob).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return s.Storage.Delete(key)
+		}
+		return err
+	}
+
+	if blob.RefCount > 1 {
+		return s.db.Model(&models.StorageBlob{}).Where("id = ?", blob.ID).
+			UpdateColumn("ref_count", gorm.Expr("ref_count - 1")).Error
+	}
+
+	if err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("blob_hash = ?", blob.Hash).Delete(&models.StorageURLCache{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.StorageBlob{}, blob.ID).Error
+	}); err != nil {
+		return err
+	}
+	return s.Storage.Delete(key)
+}
+
+// Forget 删除 key 对应的去重记录（文件已被外部移除时调用）
+func (s *DedupStorage) Forget(key string) error {
+	var hashes []string
+	if err := s.db.Model(&models.StorageBlob{}).Where("storage_key = ?", key).Pluck("hash", &hashes).Error; err != nil {
+		return err
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("blob_hash IN ?", hashes).Delete(&models.StorageURLCache{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("hash IN ?", hashes).Delete(&models.StorageBlob{}).Error
+	})
+}
+
+// fetch 通过URL缓存或下载获取文件 key
+func (s *DedupStorage) fetch(url, category string, addRef bool) (string, error) {
+	urlHash := hashString(url)
+
+	var cached models.StorageURLCache
+	if err := s.db.Where("url_hash = ?", urlHash).First(&cached).Error; err == nil {
+		if blob, ok := s.lookup(cached.BlobHash); ok {
+			s.db.Model(&models.StorageURLCache{}).Where("id = ?", cached.ID).UpdateColumn("last_used_at", time.Now())
+			if addRef {
+				s.addRef(blob.ID)
+			}
+			return blob.Key, nil
+		}
+	}
+
+	resp, err := fetchURL(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	contentType := resp.Header.Get("Content-Type")
+	key, err := s.store(resp.Body, getFileExtension(url, contentType), category, contentType, addRef)
+	if err != nil {
+		return "", err
+	}
+
+	var blob models.StorageBlob
+	if err := s.db.Where("storage_key = ?", key).First(&blob).Error; err == nil {
+		s.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "url_hash"}},
+			DoUpdates: clause.AssignmentColumns([]string{"blob_hash", "last_used_at"}),
+		}).Create(&models.StorageURLCache{
+			URLHash:    urlHash,
+			URL:        url,
+			BlobHash:   blob.Hash,
+			LastUsedAt: time.Now(),
+		})
+	}
+
+	return key, nil
+}
+
+// store 边写临时文件边计算哈希；内容已存在时复用原文件，否则以哈希为 key 写入后端
+func (s *DedupStorage) store(file io.Reader, ext, category, contentType string, addRef bool) (string, error) {
+	tmp, err := os.CreateTemp("", "dedup-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hasher), file)
+	if err != nil {
+		return "", fmt.Errorf("failed to save file: %w", err)
+	}
+	hash := hex.EncodeToString(hasher.Sum(nil))
+
+	if blob, ok := s.lookup(hash); ok {
+		if addRef {
+			s.addRef(blob.ID)
+		}
+		return blob.Key, nil
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	if ext == "" {
+		ext = ".bin"
+	}
+	key := fmt.Sprintf("%s/%s/%s%s", category, hash[:2], hash, ext)
+	if err := s.Storage.Put(key, tmp, contentType); err != nil {
+		return "", err
+	}
+
+	refCount := 0
+	if addRef {
+		refCount = 1
+	}
+	err = s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hash"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"storage_key": key, "ref_count": gorm.Expr("ref_count + ?", refCount)}),
+	}).Create(&models.StorageBlob{
+		Hash:        hash,
+		Key:         key,
+		Size:        size,
+		ContentType: contentType,
+		RefCount:    refCount,
+	}).Error
+	if err != nil {
+		return "", fmt.Errorf("failed to save blob: %w", err)
+	}
+
+	return key, nil
+}
+
+// lookup 查找内容哈希对应的文件；记录存在但文件已丢失（如被清理）时删除记录
+func (s *DedupStorage) lookup(hash string) (*models.StorageBlob, bool) {
+	var blob models.StorageBlob
+	if err := s.db.Where("hash = ?", hash).First(&blob).Error; err != nil {
+		return nil, false
+	}
+	if _, err := s.Stat(blob.Key); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			s.Forget(blob.Key)
+		}
+		return nil, false
+	}
+	return &blob, true
+}
+
+// RetainKey 为 key 对应的去重文件增加一次引用，数据库记录复制已有文件引用时调用；非去重文件忽略
+func RetainKey(db *gorm.DB, key string) error {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return err
+	}
+	return db.Model(&models.StorageBlob{}).Where("storage_key = ?", key).
+		UpdateColumn("ref_count", gorm.Expr("ref_count + 1")).Error
+}
+
+// ReleaseKey 减少 key 对应去重文件的引用计数，引用该文件的记录被删除时调用；非去重文件忽略
+// 计数归零时不直接删除文件（可能仍有未计数的引用），由存储清理按数据库引用扫描回收
+func ReleaseKey(db *gorm.DB, key string) error {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return err
+	}
+	return db.Model(&models.StorageBlob{}).Where("storage_key = ? AND ref_count > 0", key).
+		UpdateColumn("ref_count", gorm.Expr("ref_count - 1")).Error
+}
+
+func (s *DedupStorage) addRef(blobID uint) {
+	s.db.Model(&models.StorageBlob{}).Where("id = ?", blobID).UpdateColumn("ref_count", gorm.Expr("ref_count + 1"))
+}
+
+func hashString(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -29,37 +30,124 @@ func NewLocalStorage(basePath, baseURL string) (*LocalStorage, error) {
 }
 
 func (s *LocalStorage) Upload(file io.Reader, filename string, category string) (string, error) {
-	dir := filepath.Join(s.basePath, category)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create category directory: %w", err)
+	key := filepath.ToSlash(filepath.Join(category, newFilename(filename)))
+	if err := s.Put(key, file, ""); err != nil {
+		return "", err
 	}
+	return s.GetURL(key), nil
+}
 
-	timestamp := time.Now().Format("20060102_150405")
-	newFilename := fmt.Sprintf("%s_%s", timestamp, filename)
-	filePath := filepath.Join(dir, newFilename)
+// Put 按 key 写入文件，先写临时文件再重命名，避免读到半截文件
+func (s *LocalStorage) Put(key string, file io.Reader, contentType string) error {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return err
+	}
 
-	dst, err := os.Create(filePath)
+	filePath := s.GetAbsolutePath(key)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return fmt.Errorf("failed to create category directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
 	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, file); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save file: %w", err)
 	}
-	defer dst.Close()
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to save file: %w", err)
+	}
+	return nil
+}
 
-	if _, err := io.Copy(dst, file); err != nil {
-		return "", fmt.Errorf("failed to save file: %w", err)
+// PutFile 写入本地文件，文件已在 key 对应的位置时不做处理
+func (s *LocalStorage) PutFile(key, localPath, contentType string) error {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return err
+	}
+	if filepath.Clean(localPath) == filepath.Clean(s.GetAbsolutePath(key)) {
+		return nil
 	}
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+	return s.Put(key, f, contentType)
+}
 
-	url := fmt.Sprintf("%s/%s/%s", s.baseURL, category, newFilename)
-	return url, nil
+func (s *LocalStorage) Download(key string) (io.ReadCloser, error) {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(s.GetAbsolutePath(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return file, err
 }
 
-func (s *LocalStorage) Delete(url string) error {
+func (s *LocalStorage) Delete(key string) error {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(s.GetAbsolutePath(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
 	return nil
 }
 
+func (s *LocalStorage) Stat(key string) (*FileInfo, error) {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(s.GetAbsolutePath(key))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if stat.IsDir() {
+		return nil, ErrNotFound
+	}
+	return &FileInfo{
+		Key:         key,
+		Size:        stat.Size(),
+		ContentType: mime.TypeByExtension(filepath.Ext(key)),
+		ModTime:     stat.ModTime(),
+	}, nil
+}
+
 func (s *LocalStorage) GetURL(path string) string {
 	return fmt.Sprintf("%s/%s", s.baseURL, path)
 }
 
+// PresignURL 本地文件经 /static 公开访问，直接返回访问URL
+func (s *LocalStorage) PresignURL(key string, expires time.Duration) (string, error) {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return "", err
+	}
+	return s.GetURL(key), nil
+}
+
+func (s *LocalStorage) KeyFromURL(url string) (string, bool) {
+	return trimURLPrefix(url, s.baseURL)
+}
+
 // DownloadResult 下载结果，包含URL和相对路径
 type DownloadResult struct {
 	URL          string // 完整的访问URL
@@ -78,57 +166,24 @@ func (s *LocalStorage) DownloadFromURL(url, category string) (string, error) {
 
 // DownloadFromURLWithPath 从远程URL下载文件到本地存储，返回详细信息
 func (s *LocalStorage) DownloadFromURLWithPath(url, category string) (*DownloadResult, error) {
-	// CRITICAL FIX: Add HTTP client with timeout to prevent hanging indefinitely
-	// Without timeout, the download can hang forever if the remote server is unresponsive
-	// 5 minute timeout is reasonable for large video/image files
-	client := &http.Client{
-		Timeout: 5 * time.Minute,
-	}
-	// 发送HTTP请求下载文件
-	resp, err := client.Get(url)
+	resp, err := fetchURL(url)
 	if err != nil {
-		return nil, fmt.Errorf("failed to download file: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to download file: HTTP %d", resp.StatusCode)
-	}
-
 	// 从URL或Content-Type推断文件扩展名
 	ext := getFileExtension(url, resp.Header.Get("Content-Type"))
+	key := filepath.ToSlash(filepath.Join(category, uniqueFilename(ext)))
 
-	// 创建目录
-	dir := filepath.Join(s.basePath, category)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create category directory: %w", err)
-	}
-
-	// 生成唯一文件名（时间戳 + UUID 前8位）
-	timestamp := time.Now().Format("20060102_150405")
-	uniqueID := uuid.New().String()[:8]
-	filename := fmt.Sprintf("%s_%s%s", timestamp, uniqueID, ext)
-	filePath := filepath.Join(dir, filename)
-
-	// 保存文件
-	dst, err := os.Create(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create file: %w", err)
-	}
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, resp.Body); err != nil {
-		return nil, fmt.Errorf("failed to save file: %w", err)
+	if err := s.Put(key, resp.Body, resp.Header.Get("Content-Type")); err != nil {
+		return nil, err
 	}
 
-	// 返回详细信息
-	relativePath := filepath.Join(category, filename)
-	localURL := fmt.Sprintf("%s/%s/%s", s.baseURL, category, filename)
-	
 	return &DownloadResult{
-		URL:          localURL,
-		RelativePath: relativePath,
-		AbsolutePath: filePath,
+		URL:          s.GetURL(key),
+		RelativePath: key,
+		AbsolutePath: s.GetAbsolutePath(key),
 	}, nil
 }
 
@@ -137,6 +192,30 @@ func (s *LocalStorage) GetAbsolutePath(relativePath string) string {
 	return filepath.Join(s.basePath, relativePath)
 }
 
+// fetchURL 请求远程文件，非 200 状态视为失败
+func fetchURL(url string) (*http.Response, error) {
+	// CRITICAL FIX: Add HTTP client with timeout to prevent hanging indefinitely
+	// Without timeout, the download can hang forever if the remote server is unresponsive
+	// 5 minute timeout is reasonable for large video/image files
+	client := &http.Client{
+		Timeout: 5 * time.Minute,
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download file: %w", &HTTPStatusError{StatusCode: resp.StatusCode})
+	}
+	return resp, nil
+}
+
+// uniqueFilename 生成唯一文件名（时间戳 + UUID 前8位）
+func uniqueFilename(ext string) string {
+	return fmt.Sprintf("%s_%s%s", time.Now().Format("20060102_150405"), uuid.New().String()[:8], ext)
+}
+
 // getFileExtension 从URL或Content-Type推断文件扩展名
 func getFileExtension(url, contentType string) string {
 	// 首先尝试从URL获取扩展名
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLocalStoragePutFile(t *testing.T) {
+	s, err := NewLocalStorage(t.TempDir(), "http://localhost/static")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 已在存储目录中的文件原样保留
+	inPlace := s.GetAbsolutePath("videos/merged/a.mp4")
+	os.MkdirAll(filepath.Dir(inPlace), 0755)
+	if err := os.WriteFile(inPlace, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutFile("videos/merged/a.mp4", inPlace, ""); err != nil {
+		t.Fatalf("put in place: %v", err)
+	}
+	if data, _ := os.ReadFile(inPlace); string(data) != "a" {
+		t.Fatalf("in-place file changed: %q", data)
+	}
+
+	// 存储目录外的文件复制到 key
+	outside := filepath.Join(t.TempDir(), "b.mp4")
+	if err := os.WriteFile(outside, []byte("b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PutFile("videos/b.mp4", outside, ""); err != nil {
+		t.Fatalf("put outside file: %v", err)
+	}
+	if info, err := s.Stat("videos/b.mp4"); err != nil || info.Size != 1 {
+		t.Fatalf("stat copied file: %+v %v", info, err)
+	}
+}
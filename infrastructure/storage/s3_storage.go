@@ -0,0 +1,545 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/drama-generator/backend/pkg/config"
+)
+
+const (
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	s3Algorithm       = "AWS4-HMAC-SHA256"
+	s3MaxPresign      = 7 * 24 * time.Hour
+)
+
+// S3Storage S3 兼容对象存储（AWS S3、MinIO 等），使用 Signature V4 签名
+// 同时在 cacheDir 中保留本地副本，供 ffmpeg 等需要文件路径的工具使用
+type S3Storage struct {
+	cfg       config.S3Config
+	endpoint  *url.URL
+	publicURL string
+	cacheDir  string
+	client    *http.Client
+}
+
+func NewS3Storage(cfg config.S3Config, cacheDir string) (*S3Storage, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 endpoint and bucket are required")
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("s3 access key and secret key are required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.PresignExpiry <= 0 {
+		cfg.PresignExpiry = 3600
+	}
+
+	rawEndpoint := cfg.Endpoint
+	if !strings.Contains(rawEndpoint, "://") {
+		scheme := "http"
+		if cfg.UseSSL {
+			scheme = "https"
+		}
+		rawEndpoint = scheme + "://" + rawEndpoint
+	}
+	endpoint, err := url.Parse(strings.TrimRight(rawEndpoint, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid s3 endpoint: %w", err)
+	}
+
+	if cacheDir != "" {
+		if err := os.MkdirAll(cacheDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create storage cache directory: %w", err)
+		}
+	}
+
+	s := &S3Storage{
+		cfg:      cfg,
+		endpoint: endpoint,
+		cacheDir: cacheDir,
+		client:   &http.Client{Timeout: 10 * time.Minute},
+	}
+	s.publicURL = strings.TrimRight(cfg.PublicURL, "/")
+	if s.publicURL == "" {
+		s.publicURL = s.bucketURL()
+	}
+
+	if cfg.CreateBucket {
+		if err := s.EnsureBucket(); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// EnsureBucket 存储桶不存在时创建
+func (s *S3Storage) EnsureBucket() error {
+	resp, err := s.do(http.MethodHead, "", nil, 0, nil)
+	if err != nil {
+		return fmt.Errorf("failed to check bucket: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("failed to check bucket: HTTP %d", resp.StatusCode)
+	}
+
+	var body []byte
+	if s.cfg.Region != "us-east-1" {
+		body = []byte(fmt.Sprintf(`<CreateBucketConfiguration xmlns="http://s3.amazonaws.com/doc/2006-03-01/"><LocationConstraint>%s</LocationConstraint></CreateBucketConfiguration>`, s.cfg.Region))
+	}
+	resp, err = s.do(http.MethodPut, "", bytes.NewReader(body), int64(len(body)), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create bucket: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("create bucket", resp)
+	}
+	return nil
+}
+
+func (s *S3Storage) Upload(file io.Reader, filename string, category string) (string, error) {
+	key := filepath.ToSlash(filepath.Join(category, newFilename(filename)))
+	if err := s.Put(key, file, ""); err != nil {
+		return "", err
+	}
+	return s.GetURL(key), nil
+}
+
+// Put 上传对象。S3 要求 Content-Length，先写入本地缓存再上传
+func (s *S3Storage) Put(key string, file io.Reader, contentType string) error {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return err
+	}
+
+	cachePath, cleanup, err := s.writeCache(key, file)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	return s.PutFile(key, cachePath, contentType)
+}
+
+// PutFile 上传本地文件到指定 key（不写入缓存）
+func (s *S3Storage) PutFile(key, localPath, contentType string) error {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(key))
+	}
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(http.MethodPut, key, f, stat.Size(), header)
+	if err != nil {
+		return fmt.Errorf("failed to upload object: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("upload object", resp)
+	}
+	return nil
+}
+
+func (s *S3Storage) Download(key string) (io.ReadCloser, error) {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(http.MethodGet, key, nil, 0, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download object: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, s3Error("download object", resp)
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Storage) Delete(key string) error {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(http.MethodDelete, key, nil, 0, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error("delete object", resp)
+	}
+	if s.cacheDir != "" {
+		os.Remove(filepath.Join(s.cacheDir, filepath.FromSlash(key)))
+	}
+	return nil
+}
+
+func (s *S3Storage) Stat(key string) (*FileInfo, error) {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(http.MethodHead, key, nil, 0, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to stat object: HTTP %d", resp.StatusCode)
+	}
+
+	info := &FileInfo{
+		Key:         key,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	info.Size, _ = strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64)
+	if modTime, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.ModTime = modTime
+	}
+	return info, nil
+}
+
+func (s *S3Storage) GetURL(key string) string {
+	return fmt.Sprintf("%s/%s", s.publicURL, strings.TrimPrefix(key, "/"))
+}
+
+// PresignURL 生成预签名 GET URL，expires <= 0 时使用配置的默认有效期
+func (s *S3Storage) PresignURL(key string, expires time.Duration) (string, error) {
+	key, err := normalizeKey(key)
+	if err != nil {
+		return "", err
+	}
+	if expires <= 0 {
+		expires = time.Duration(s.cfg.PresignExpiry) * time.Second
+	}
+	if expires > s3MaxPresign {
+		expires = s3MaxPresign
+	}
+	return s.presign(http.MethodGet, key, expires, time.Now().UTC()), nil
+}
+
+func (s *S3Storage) KeyFromURL(url string) (string, bool) {
+	if key, ok := trimURLPrefix(url, s.publicURL); ok {
+		return key, true
+	}
+	return trimURLPrefix(url, s.bucketURL())
+}
+
+// GetAbsolutePath 返回本地缓存路径，缓存不存在时从存储桶拉取
+func (s *S3Storage) GetAbsolutePath(key string) string {
+	key, err := normalizeKey(key)
+	if err != nil || s.cacheDir == "" {
+		return key
+	}
+	cachePath := filepath.Join(s.cacheDir, filepath.FromSlash(key))
+	if _, err := os.Stat(cachePath); err == nil {
+		return cachePath
+	}
+
+	body, err := s.Download(key)
+	if err != nil {
+		return cachePath
+	}
+	defer body.Close()
+	s.writeCache(key, body)
+	return cachePath
+}
+
+func (s *S3Storage) DownloadFromURL(url, category string) (string, error) {
+	result, err := s.DownloadFromURLWithPath(url, category)
+	if err != nil {
+		return "", err
+	}
+	return result.URL, nil
+}
+
+func (s *S3Storage) DownloadFromURLWithPath(url, category string) (*DownloadResult, error) {
+	resp, err := fetchURL(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	ext := getFileExtension(url, resp.Header.Get("Content-Type"))
+	key := filepath.ToSlash(filepath.Join(category, uniqueFilename(ext)))
+
+	cachePath, cleanup, err := s.writeCache(key, resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.PutFile(key, cachePath, resp.Header.Get("Content-Type")); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	return &DownloadResult{
+		URL:          s.GetURL(key),
+		RelativePath: key,
+		AbsolutePath: cachePath,
+	}, nil
+}
+
+// writeCache 将内容写入本地缓存；未配置缓存目录时写入临时文件，cleanup 负责删除
+func (s *S3Storage) writeCache(key string, file io.Reader) (string, func(), error) {
+	dir := os.TempDir()
+	cachePath := ""
+	if s.cacheDir != "" {
+		cachePath = filepath.Join(s.cacheDir, filepath.FromSlash(key))
+		dir = filepath.Dir(cachePath)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, ".upload-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := io.Copy(tmp, file); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", nil, fmt.Errorf("failed to save file: %w", err)
+	}
+	tmp.Close()
+
+	if cachePath == "" {
+		return tmp.Name(), func() { os.Remove(tmp.Name()) }, nil
+	}
+	if err := os.Rename(tmp.Name(), cachePath); err != nil {
+		os.Remove(tmp.Name())
+		return "", nil, fmt.Errorf("failed to save file: %w", err)
+	}
+	return cachePath, func() {}, nil
+}
+
+// objectURL 返回对象（key 为空时为存储桶）的请求地址
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	basePath := strings.TrimRight(u.Path, "/")
+	if s.cfg.PathStyle {
+		u.Path = basePath + "/" + s.cfg.Bucket
+		if key != "" {
+			u.Path += "/" + key
+		}
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = basePath + "/" + key
+	}
+	// 与签名使用相同的路径编码
+	u.RawPath = s3EscapePath(u.Path)
+	return &u
+}
+
+// bucketURL 返回存储桶的访问前缀（不含结尾的 /）
+func (s *S3Storage) bucketURL() string {
+	return strings.TrimRight(s.objectURL("").String(), "/")
+}
+
+// do 发送签名请求
+func (s *S3Storage) do(method, key string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	u := s.objectURL(key)
+	req, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, values := range header {
+		for _, v := range values {
+			req.Header.Add(k, v)
+		}
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+
+	s.sign(req, time.Now().UTC())
+	return s.client.Do(req)
+}
+
+// sign 以请求头方式签名（Signature V4）
+// 签名 host、content-type、range 与全部 x-amz-* 请求头；未设置 x-amz-content-sha256 时负载不签名
+func (s *S3Storage) sign(req *http.Request, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	req.Header.Set("x-amz-date", amzDate)
+	payloadHash := req.Header.Get("x-amz-content-sha256")
+	if payloadHash == "" {
+		payloadHash = s3UnsignedPayload
+		req.Header.Set("x-amz-content-sha256", payloadHash)
+	}
+
+	headerValues := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if name == "content-type" || name == "range" || strings.HasPrefix(name, "x-amz-") {
+			headerValues[name] = strings.Join(values, ",")
+		}
+	}
+	signedHeaders := make([]string, 0, len(headerValues))
+	for name := range headerValues {
+		signedHeaders = append(signedHeaders, name)
+	}
+	sort.Strings(signedHeaders)
+
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(headerValues[h]) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3EscapePath(req.URL.Path),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(now)
+	signature := s.signature(now, scope, canonicalRequest)
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.cfg.AccessKey, scope, strings.Join(signedHeaders, ";"), signature))
+}
+
+// presign 以查询参数方式签名
+func (s *S3Storage) presign(method, key string, expires time.Duration, now time.Time) string {
+	u := s.objectURL(key)
+	scope := s.scope(now)
+
+	query := url.Values{}
+	query.Set("X-Amz-Algorithm", s3Algorithm)
+	query.Set("X-Amz-Credential", s.cfg.AccessKey+"/"+scope)
+	query.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		s3EscapePath(u.Path),
+		s3CanonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		s3UnsignedPayload,
+	}, "\n")
+
+	query.Set("X-Amz-Signature", s.signature(now, scope, canonicalRequest))
+	u.RawQuery = s3CanonicalQuery(query)
+	return u.String()
+}
+
+func (s *S3Storage) scope(now time.Time) string {
+	return fmt.Sprintf("%s/%s/s3/aws4_request", now.Format("20060102"), s.cfg.Region)
+}
+
+func (s *S3Storage) signature(now time.Time, scope, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		now.Format("20060102T150405Z"),
+		scope,
+		hex.EncodeToString(hash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), now.Format("20060102"))
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3EscapePath 按 S3 规则逐段编码路径（保留 /）
+func s3EscapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, seg := range segments {
+		segments[i] = s3Escape(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3CanonicalQuery 按 key 排序并编码查询参数
+func s3CanonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), values[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, s3Escape(k)+"="+s3Escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3Escape RFC 3986 编码，仅保留 A-Z a-z 0-9 - _ . ~
+func s3Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// s3Error 读取 S3 错误响应
+func s3Error(action string, resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("failed to %s: HTTP %d: %s", action, resp.StatusCode, strings.TrimSpace(string(body)))
+}
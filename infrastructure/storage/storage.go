@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/drama-generator/backend/pkg/config"
+)
+
+// ErrNotFound 文件不存在
+var ErrNotFound = errors.New("storage: file not found")
+
+// HTTPStatusError 下载远程文件时服务端返回非 200 状态码
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d", e.StatusCode)
+}
+
+// Storage 文件存储抽象
+// key 为相对存储根目录的路径（如 images/20240101_xxx.png），与数据库中 local_path 字段一致
+type Storage interface {
+	// Upload 以 "时间戳_文件名" 保存到 category 目录，返回访问URL
+	Upload(file io.Reader, filename string, category string) (string, error)
+	// Put 按指定 key 写入文件
+	Put(key string, file io.Reader, contentType string) error
+	// PutFile 将本地文件（如 ffmpeg 在 GetAbsolutePath 位置生成的输出）写入指定 key
+	PutFile(key, localPath, contentType string) error
+	// Download 读取文件内容，调用方负责关闭
+	Download(key string) (io.ReadCloser, error)
+	// Delete 删除文件，文件不存在时不报错
+	Delete(key string) error
+	// Stat 获取文件信息，不存在时返回 ErrNotFound
+	Stat(key string) (*FileInfo, error)
+	// GetURL 返回文件的公开访问URL
+	GetURL(key string) string
+	// PresignURL 返回带有效期的访问URL
+	PresignURL(key string, expires time.Duration) (string, error)
+	// KeyFromURL 从访问URL解析出 key，非本存储的URL返回 false
+	KeyFromURL(url string) (string, bool)
+	// GetAbsolutePath 返回可供 ffmpeg 等本地工具读取的文件路径
+	GetAbsolutePath(key string) string
+	// DownloadFromURL 从远程URL下载文件到存储，返回访问URL
+	DownloadFromURL(url, category string) (string, error)
+	// DownloadFromURLWithPath 从远程URL下载文件到存储，返回详细信息
+	DownloadFromURLWithPath(url, category string) (*DownloadResult, error)
+}
+
+// FileInfo 存储文件信息
+type FileInfo struct {
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type"`
+	ModTime     time.Time `json:"mod_time"`
+}
+
+// NewStorage 根据配置创建存储后端
+func NewStorage(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Type {
+	case "", "local":
+		return NewLocalStorage(cfg.LocalPath, cfg.BaseURL)
+	case "s3", "minio":
+		return NewS3Storage(cfg.S3, cfg.LocalPath)
+	default:
+		return nil, fmt.Errorf("unsupported storage type: %s", cfg.Type)
+	}
+}
+
+// normalizeKey 规范化 key，拒绝跳出存储根目录的路径
+func normalizeKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, "\\", "/")
+	key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	if key == "" || key == "." {
+		return "", fmt.Errorf("invalid storage key")
+	}
+	return key, nil
+}
+
+// newFilename 生成 "时间戳_文件名" 形式的文件名
+func newFilename(filename string) string {
+	return fmt.Sprintf("%s_%s", time.Now().Format("20060102_150405"), filename)
+}
+
+// trimURLPrefix 去掉URL前缀与查询参数，得到 key
+func trimURLPrefix(url, prefix string) (string, bool) {
+	prefix = strings.TrimRight(prefix, "/") + "/"
+	if prefix == "/" || !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+	key := strings.TrimPrefix(url, prefix)
+	if idx := strings.IndexAny(key, "?#"); idx != -1 {
+		key = key[:idx]
+	}
+	key, err := normalizeKey(key)
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
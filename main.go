@@ -11,7 +11,9 @@ import (
 	"time"
 
 	"github.com/drama-generator/backend/api/routes"
+	"github.com/drama-generator/backend/application/services"
 	"github.com/drama-generator/backend/infrastructure/database"
+	"github.com/drama-generator/backend/infrastructure/scheduler"
 	"github.com/drama-generator/backend/infrastructure/storage"
 	"github.com/drama-generator/backend/pkg/config"
 	"github.com/drama-generator/backend/pkg/logger"
@@ -35,20 +37,34 @@ func main() {
 	}
 	logr.Info("Database connected successfully")
 
-	// 自动迁移数据库表结构
-	if err := database.AutoMigrate(db); err != nil {
-		logr.Fatal("Failed to migrate database", "error", err)
+	// 表结构由 migrate up 管理，存在未执行的迁移时拒绝启动
+	if err := database.CheckMigrations(db); err != nil {
+		logr.Fatal("Database schema check failed", "error", err)
 	}
-	logr.Info("Database tables migrated successfully")
-
-	// 初始化本地存储
-	var localStorage *storage.LocalStorage
-	if cfg.Storage.Type == "local" {
-		localStorage, err = storage.NewLocalStorage(cfg.Storage.LocalPath, cfg.Storage.BaseURL)
-		if err != nil {
-			logr.Fatal("Failed to initialize local storage", "error", err)
-		}
-		logr.Info("Local storage initialized successfully", "path", cfg.Storage.LocalPath)
+	logr.Info("Database schema is up to date")
+
+	// 初始化文件存储
+	fileStorage, err := storage.NewStorage(cfg.Storage)
+	if err != nil {
+		logr.Fatal("Failed to initialize storage", "error", err)
+	}
+	logr.Infow("Storage initialized successfully", "type", cfg.Storage.Type, "path", cfg.Storage.LocalPath)
+
+	// 下载的媒体按内容哈希去重，合成等操作通过该存储复用已下载的远程文件
+	dedupStorage := storage.NewDedupStorage(fileStorage, db)
+
+	// 定时将服务商返回的临时URL转存到本地存储
+	transferScheduler := scheduler.NewResourceTransferScheduler(
+		services.NewResourceTransferService(db, dedupStorage, logr), logr)
+	if err := transferScheduler.Start(); err != nil {
+		logr.Warnw("Failed to start resource transfer scheduler", "error", err)
+	}
+
+	// 上次进程中断的流水线标记为失败，可通过 resume 继续
+	if n, err := services.NewPipelineService(db, cfg, nil, dedupStorage, logr).MarkInterruptedPipelines(); err != nil {
+		logr.Warnw("Failed to mark interrupted pipelines", "error", err)
+	} else if n > 0 {
+		logr.Infow("Marked interrupted pipelines as failed", "count", n)
 	}
 
 	if cfg.App.Debug {
@@ -57,7 +73,7 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	router := routes.SetupRouter(cfg, db, logr, localStorage)
+	router := routes.SetupRouter(cfg, db, logr, dedupStorage)
 
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -91,6 +107,8 @@ func main() {
 	logr.Info("Shutting down server...")
 
 	// 清理资源
+	transferScheduler.Stop()
+
 	// CRITICAL FIX: Properly close database connection to prevent resource leaks
 	// SQLite connections should be closed gracefully to avoid database lock issues
 	sqlDB, err := db.DB()
@@ -0,0 +1,9 @@
+// Package migrations 内嵌各数据库方言的版本化 SQL 迁移文件
+//
+// 文件命名：<dialect>/<版本号>_<名称>.up.sql 与对应的 .down.sql，版本号递增且不可复用
+package migrations
+
+import "embed"
+
+//go:embed sqlite/*.sql mysql/*.sql postgres/*.sql
+var FS embed.FS
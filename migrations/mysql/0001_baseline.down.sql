@@ -0,0 +1,26 @@
+-- 回滚基线：删除全部表（数据将丢失）
+
+DROP TABLE IF EXISTS `async_tasks`;
+DROP TABLE IF EXISTS `resource_transfers`;
+DROP TABLE IF EXISTS `storage_url_caches`;
+DROP TABLE IF EXISTS `storage_blobs`;
+DROP TABLE IF EXISTS `character_libraries`;
+DROP TABLE IF EXISTS `asset_tags`;
+DROP TABLE IF EXISTS `assets`;
+DROP TABLE IF EXISTS `tags`;
+DROP TABLE IF EXISTS `ai_service_providers`;
+DROP TABLE IF EXISTS `ai_service_configs`;
+DROP TABLE IF EXISTS `drama_brandings`;
+DROP TABLE IF EXISTS `video_merges`;
+DROP TABLE IF EXISTS `video_generations`;
+DROP TABLE IF EXISTS `image_generations`;
+DROP TABLE IF EXISTS `frame_prompts`;
+DROP TABLE IF EXISTS `storyboard_characters`;
+DROP TABLE IF EXISTS `storyboard_props`;
+DROP TABLE IF EXISTS `props`;
+DROP TABLE IF EXISTS `storyboards`;
+DROP TABLE IF EXISTS `scenes`;
+DROP TABLE IF EXISTS `episode_characters`;
+DROP TABLE IF EXISTS `characters`;
+DROP TABLE IF EXISTS `episodes`;
+DROP TABLE IF EXISTS `dramas`;
@@ -0,0 +1,503 @@
+-- 基线：与 AutoMigrate 创建的表结构一致（MySQL）
+-- 已有数据库首次执行时表已存在，语句均为 IF NOT EXISTS
+
+CREATE TABLE IF NOT EXISTS `dramas` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `title` varchar(200) NOT NULL,
+    `description` text,
+    `genre` varchar(50),
+    `style` varchar(50) DEFAULT 'realistic',
+    `total_episodes` bigint DEFAULT 1,
+    `total_duration` bigint DEFAULT 0,
+    `status` varchar(20) NOT NULL DEFAULT 'draft',
+    `thumbnail` varchar(500),
+    `tags` JSON,
+    `metadata` JSON,
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    `deleted_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_dramas_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `episodes` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `drama_id` bigint unsigned NOT NULL,
+    `episode_number` bigint NOT NULL,
+    `title` varchar(200) NOT NULL,
+    `script_content` longtext,
+    `description` text,
+    `duration` bigint DEFAULT 0,
+    `status` varchar(20) DEFAULT 'draft',
+    `video_url` varchar(500),
+    `hls_url` varchar(500),
+    `thumbnail` varchar(500),
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    `deleted_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_episodes_drama_id` (`drama_id`),
+    INDEX `idx_episodes_deleted_at` (`deleted_at`),
+    CONSTRAINT `fk_dramas_episodes` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `characters` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `drama_id` bigint unsigned NOT NULL,
+    `name` varchar(100) NOT NULL,
+    `role` varchar(50),
+    `description` text,
+    `appearance` text,
+    `personality` text,
+    `voice_style` varchar(200),
+    `image_url` varchar(500),
+    `local_path` text,
+    `reference_images` JSON,
+    `seed_value` varchar(100),
+    `sort_order` bigint DEFAULT 0,
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    `deleted_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_characters_drama_id` (`drama_id`),
+    INDEX `idx_characters_deleted_at` (`deleted_at`),
+    CONSTRAINT `fk_dramas_characters` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `episode_characters` (
+    `character_id` bigint unsigned,
+    `episode_id` bigint unsigned,
+    PRIMARY KEY (`character_id`,`episode_id`),
+    CONSTRAINT `fk_episode_characters_character` FOREIGN KEY (`character_id`) REFERENCES `characters`(`id`),
+    CONSTRAINT `fk_episode_characters_episode` FOREIGN KEY (`episode_id`) REFERENCES `episodes`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `scenes` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `drama_id` bigint unsigned NOT NULL,
+    `episode_id` bigint unsigned,
+    `location` varchar(200) NOT NULL,
+    `time` varchar(100) NOT NULL,
+    `prompt` text NOT NULL,
+    `storyboard_count` bigint DEFAULT 1,
+    `image_url` varchar(500),
+    `local_path` text,
+    `status` varchar(20) DEFAULT 'pending',
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    `deleted_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_scenes_drama_id` (`drama_id`),
+    INDEX `idx_scenes_episode_id` (`episode_id`),
+    INDEX `idx_scenes_deleted_at` (`deleted_at`),
+    CONSTRAINT `fk_episodes_scenes` FOREIGN KEY (`episode_id`) REFERENCES `episodes`(`id`),
+    CONSTRAINT `fk_dramas_scenes` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `storyboards` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `episode_id` bigint unsigned NOT NULL,
+    `scene_id` bigint unsigned,
+    `storyboard_number` bigint NOT NULL,
+    `title` varchar(255),
+    `location` varchar(255),
+    `time` varchar(255),
+    `shot_type` varchar(100),
+    `angle` varchar(100),
+    `movement` varchar(100),
+    `action` text,
+    `result` text,
+    `atmosphere` text,
+    `image_prompt` text,
+    `video_prompt` text,
+    `bgm_prompt` text,
+    `sound_effect` varchar(255),
+    `dialogue` text,
+    `description` text,
+    `duration` bigint DEFAULT 5,
+    `composed_image` text,
+    `video_url` text,
+    `status` varchar(20) DEFAULT 'pending',
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_storyboards_episode_id` (`episode_id`),
+    INDEX `idx_storyboards_scene_id` (`scene_id`),
+    INDEX `idx_storyboards_deleted_at` (`deleted_at`),
+    CONSTRAINT `fk_storyboards_background` FOREIGN KEY (`scene_id`) REFERENCES `scenes`(`id`),
+    CONSTRAINT `fk_episodes_storyboards` FOREIGN KEY (`episode_id`) REFERENCES `episodes`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `props` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `drama_id` bigint unsigned NOT NULL,
+    `name` varchar(100) NOT NULL,
+    `type` varchar(50),
+    `description` text,
+    `prompt` text,
+    `image_url` varchar(500),
+    `local_path` text,
+    `reference_images` JSON,
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    `deleted_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_props_drama_id` (`drama_id`),
+    INDEX `idx_props_deleted_at` (`deleted_at`),
+    CONSTRAINT `fk_dramas_props` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `storyboard_props` (
+    `prop_id` bigint unsigned,
+    `storyboard_id` bigint unsigned,
+    PRIMARY KEY (`prop_id`,`storyboard_id`),
+    CONSTRAINT `fk_storyboard_props_prop` FOREIGN KEY (`prop_id`) REFERENCES `props`(`id`),
+    CONSTRAINT `fk_storyboard_props_storyboard` FOREIGN KEY (`storyboard_id`) REFERENCES `storyboards`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `storyboard_characters` (
+    `storyboard_id` bigint unsigned,
+    `character_id` bigint unsigned,
+    PRIMARY KEY (`storyboard_id`,`character_id`),
+    CONSTRAINT `fk_storyboard_characters_storyboard` FOREIGN KEY (`storyboard_id`) REFERENCES `storyboards`(`id`),
+    CONSTRAINT `fk_storyboard_characters_character` FOREIGN KEY (`character_id`) REFERENCES `characters`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `frame_prompts` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `storyboard_id` bigint unsigned NOT NULL,
+    `frame_type` varchar(20) NOT NULL,
+    `prompt` text NOT NULL,
+    `description` text,
+    `layout` varchar(50),
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_frame_prompts_storyboard` (`storyboard_id`),
+    INDEX `idx_frame_prompts_type` (`frame_type`)
+);
+
+CREATE TABLE IF NOT EXISTS `image_generations` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `storyboard_id` bigint unsigned,
+    `drama_id` bigint unsigned NOT NULL,
+    `scene_id` bigint unsigned,
+    `character_id` bigint unsigned,
+    `prop_id` bigint unsigned,
+    `image_type` varchar(20) DEFAULT 'storyboard',
+    `frame_type` varchar(20),
+    `provider` varchar(50) NOT NULL,
+    `prompt` text NOT NULL,
+    `negative_prompt` text,
+    `model` varchar(100),
+    `size` varchar(20),
+    `quality` varchar(20),
+    `style` varchar(50),
+    `steps` bigint,
+    `cfg_scale` double,
+    `seed` bigint,
+    `image_url` text,
+    `minio_url` text,
+    `local_path` text,
+    `status` varchar(20) NOT NULL DEFAULT 'pending',
+    `task_id` varchar(200),
+    `error_msg` text,
+    `width` bigint,
+    `height` bigint,
+    `reference_images` JSON,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `completed_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_image_generations_storyboard_id` (`storyboard_id`),
+    INDEX `idx_image_generations_drama_id` (`drama_id`),
+    INDEX `idx_image_generations_scene_id` (`scene_id`),
+    INDEX `idx_image_generations_character_id` (`character_id`),
+    INDEX `idx_image_generations_prop_id` (`prop_id`),
+    INDEX `idx_image_generations_image_type` (`image_type`),
+    CONSTRAINT `fk_image_generations_storyboard` FOREIGN KEY (`storyboard_id`) REFERENCES `storyboards`(`id`),
+    CONSTRAINT `fk_image_generations_drama` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`),
+    CONSTRAINT `fk_image_generations_scene` FOREIGN KEY (`scene_id`) REFERENCES `scenes`(`id`),
+    CONSTRAINT `fk_image_generations_character` FOREIGN KEY (`character_id`) REFERENCES `characters`(`id`),
+    CONSTRAINT `fk_image_generations_prop` FOREIGN KEY (`prop_id`) REFERENCES `props`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `video_generations` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `storyboard_id` bigint unsigned,
+    `drama_id` bigint unsigned NOT NULL,
+    `provider` varchar(50) NOT NULL,
+    `prompt` text NOT NULL,
+    `model` varchar(100),
+    `image_gen_id` bigint unsigned,
+    `reference_mode` varchar(20),
+    `image_url` varchar(1000),
+    `first_frame_url` varchar(1000),
+    `last_frame_url` varchar(1000),
+    `reference_image_urls` text,
+    `duration` bigint,
+    `fps` bigint,
+    `resolution` varchar(50),
+    `aspect_ratio` varchar(20),
+    `style` varchar(100),
+    `motion_level` bigint,
+    `camera_motion` varchar(100),
+    `seed` bigint,
+    `video_url` varchar(1000),
+    `minio_url` varchar(1000),
+    `local_path` varchar(500),
+    `status` varchar(20) NOT NULL DEFAULT 'pending',
+    `task_id` varchar(200),
+    `error_msg` text,
+    `completed_at` datetime(3) NULL,
+    `width` bigint,
+    `height` bigint,
+    PRIMARY KEY (`id`),
+    INDEX `idx_video_generations_deleted_at` (`deleted_at`),
+    INDEX `idx_video_generations_storyboard_id` (`storyboard_id`),
+    INDEX `idx_video_generations_drama_id` (`drama_id`),
+    INDEX `idx_video_generations_provider` (`provider`),
+    INDEX `idx_video_generations_image_gen_id` (`image_gen_id`),
+    INDEX `idx_video_generations_status` (`status`),
+    INDEX `idx_video_generations_task_id` (`task_id`),
+    CONSTRAINT `fk_video_generations_storyboard` FOREIGN KEY (`storyboard_id`) REFERENCES `storyboards`(`id`),
+    CONSTRAINT `fk_video_generations_drama` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`),
+    CONSTRAINT `fk_video_generations_image_gen` FOREIGN KEY (`image_gen_id`) REFERENCES `image_generations`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `video_merges` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `episode_id` bigint unsigned NOT NULL,
+    `drama_id` bigint unsigned NOT NULL,
+    `title` varchar(200),
+    `provider` varchar(50) NOT NULL,
+    `model` varchar(100),
+    `status` varchar(20) NOT NULL DEFAULT 'pending',
+    `scenes` JSON NOT NULL,
+    `options` JSON,
+    `merged_url` varchar(500),
+    `duration` bigint,
+    `task_id` varchar(100),
+    `error_msg` text,
+    `created_at` datetime(3) NOT NULL,
+    `completed_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_video_merges_episode_id` (`episode_id`),
+    INDEX `idx_video_merges_drama_id` (`drama_id`),
+    INDEX `idx_video_merges_deleted_at` (`deleted_at`),
+    CONSTRAINT `fk_video_merges_episode` FOREIGN KEY (`episode_id`) REFERENCES `episodes`(`id`),
+    CONSTRAINT `fk_video_merges_drama` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `drama_brandings` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `drama_id` bigint unsigned NOT NULL,
+    `enabled` boolean DEFAULT true,
+    `intro_video` varchar(1000),
+    `outro_video` varchar(1000),
+    `logo_image` varchar(1000),
+    `watermark_position` varchar(20) DEFAULT 'top-right',
+    `watermark_opacity` double DEFAULT 0.8,
+    `watermark_scale` double DEFAULT 0.15,
+    `watermark_margin` bigint DEFAULT 24,
+    `title_card_text` varchar(500),
+    `title_card_duration` double DEFAULT 3,
+    `title_card_font_file` varchar(500),
+    `title_card_color` varchar(20) DEFAULT '#FFFFFF',
+    `title_card_bg_color` varchar(20) DEFAULT '#000000',
+    `cover_time` double DEFAULT 1,
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_drama_brandings_drama_id` (`drama_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `ai_service_configs` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `service_type` varchar(50) NOT NULL,
+    `provider` varchar(50),
+    `name` varchar(100) NOT NULL,
+    `base_url` varchar(255) NOT NULL,
+    `api_key` varchar(255) NOT NULL,
+    `model` text,
+    `endpoint` varchar(255),
+    `query_endpoint` varchar(255),
+    `priority` bigint DEFAULT 0,
+    `is_default` boolean DEFAULT false,
+    `is_active` boolean DEFAULT true,
+    `settings` text,
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `ai_service_providers` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `name` varchar(100) NOT NULL,
+    `display_name` varchar(100) NOT NULL,
+    `service_type` varchar(50) NOT NULL,
+    `default_url` varchar(255),
+    `description` text,
+    `is_active` boolean DEFAULT true,
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_ai_service_providers_name` (`name`)
+);
+
+CREATE TABLE IF NOT EXISTS `tags` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `drama_id` bigint unsigned,
+    `name` varchar(50) NOT NULL,
+    `color` varchar(20),
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    `deleted_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_tags_drama_name` (`drama_id`,`name`),
+    INDEX `idx_tags_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `assets` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    `drama_id` bigint unsigned,
+    `episode_id` bigint unsigned,
+    `storyboard_id` bigint unsigned,
+    `storyboard_num` bigint,
+    `name` varchar(200) NOT NULL,
+    `description` text,
+    `type` varchar(20) NOT NULL,
+    `category` varchar(50),
+    `url` varchar(1000) NOT NULL,
+    `thumbnail_url` varchar(1000),
+    `local_path` varchar(500),
+    `poster_url` varchar(1000),
+    `sprite_url` varchar(1000),
+    `sprite_info` JSON,
+    `waveform_url` varchar(1000),
+    `peaks_url` varchar(1000),
+    `file_size` bigint,
+    `mime_type` varchar(100),
+    `width` bigint,
+    `height` bigint,
+    `duration` bigint,
+    `format` varchar(50),
+    `codec` varchar(50),
+    `audio_codec` varchar(50),
+    `fps` double,
+    `bitrate` bigint,
+    `has_audio` boolean,
+    `probed_at` datetime(3) NULL,
+    `image_gen_id` bigint unsigned,
+    `video_gen_id` bigint unsigned,
+    `is_favorite` boolean DEFAULT false,
+    `view_count` bigint DEFAULT 0,
+    PRIMARY KEY (`id`),
+    INDEX `idx_assets_deleted_at` (`deleted_at`),
+    INDEX `idx_assets_drama_id` (`drama_id`),
+    INDEX `idx_assets_episode_id` (`episode_id`),
+    INDEX `idx_assets_storyboard_id` (`storyboard_id`),
+    INDEX `idx_assets_type` (`type`),
+    INDEX `idx_assets_category` (`category`),
+    INDEX `idx_assets_image_gen_id` (`image_gen_id`),
+    INDEX `idx_assets_video_gen_id` (`video_gen_id`),
+    CONSTRAINT `fk_assets_drama` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`),
+    CONSTRAINT `fk_assets_image_gen` FOREIGN KEY (`image_gen_id`) REFERENCES `image_generations`(`id`),
+    CONSTRAINT `fk_assets_video_gen` FOREIGN KEY (`video_gen_id`) REFERENCES `video_generations`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `asset_tags` (
+    `asset_id` bigint unsigned,
+    `tag_id` bigint unsigned,
+    PRIMARY KEY (`asset_id`,`tag_id`),
+    CONSTRAINT `fk_asset_tags_asset` FOREIGN KEY (`asset_id`) REFERENCES `assets`(`id`),
+    CONSTRAINT `fk_asset_tags_tag` FOREIGN KEY (`tag_id`) REFERENCES `tags`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `character_libraries` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `name` varchar(100) NOT NULL,
+    `category` varchar(50),
+    `image_url` varchar(500) NOT NULL,
+    `local_path` varchar(500),
+    `description` text,
+    `tags` varchar(500),
+    `source_type` varchar(20) DEFAULT 'generated',
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    `deleted_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_character_libraries_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `storage_blobs` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `hash` char(64) NOT NULL,
+    `storage_key` varchar(500) NOT NULL,
+    `size` bigint NOT NULL,
+    `content_type` varchar(100),
+    `ref_count` bigint NOT NULL DEFAULT 0,
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_storage_blobs_hash` (`hash`),
+    INDEX `idx_storage_blobs_key` (`storage_key`)
+);
+
+CREATE TABLE IF NOT EXISTS `storage_url_caches` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `url_hash` char(64) NOT NULL,
+    `url` text NOT NULL,
+    `blob_hash` char(64) NOT NULL,
+    `created_at` datetime(3) NOT NULL,
+    `last_used_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_storage_url_caches_url_hash` (`url_hash`),
+    INDEX `idx_storage_url_caches_blob_hash` (`blob_hash`)
+);
+
+CREATE TABLE IF NOT EXISTS `resource_transfers` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `resource_type` varchar(30) NOT NULL,
+    `resource_id` bigint unsigned NOT NULL,
+    `drama_id` bigint unsigned NOT NULL,
+    `source_url` text NOT NULL,
+    `status` varchar(20) NOT NULL,
+    `attempts` bigint NOT NULL DEFAULT 0,
+    `last_error` text,
+    `next_retry_at` datetime(3) NULL,
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_resource_transfers_resource` (`resource_type`,`resource_id`),
+    INDEX `idx_resource_transfers_drama_id` (`drama_id`),
+    INDEX `idx_resource_transfers_status` (`status`)
+);
+
+CREATE TABLE IF NOT EXISTS `async_tasks` (
+    `id` varchar(36),
+    `type` varchar(50) NOT NULL,
+    `status` varchar(20) NOT NULL,
+    `progress` bigint DEFAULT 0,
+    `message` varchar(500),
+    `error` text,
+    `result` text,
+    `resource_id` varchar(36),
+    `created_at` datetime(3) NULL,
+    `updated_at` datetime(3) NULL,
+    `completed_at` datetime(3) NULL,
+    `deleted_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_async_tasks_type` (`type`),
+    INDEX `idx_async_tasks_status` (`status`),
+    INDEX `idx_async_tasks_resource_id` (`resource_id`),
+    INDEX `idx_async_tasks_deleted_at` (`deleted_at`)
+);
@@ -0,0 +1,38 @@
+-- 章节一键生产流水线及其阶段
+
+CREATE TABLE `episode_pipelines` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `episode_id` bigint unsigned NOT NULL,
+    `drama_id` bigint unsigned NOT NULL,
+    `status` varchar(20) NOT NULL,
+    `current_stage` varchar(50),
+    `options` JSON,
+    `error` text,
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    `completed_at` datetime(3) NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_episode_pipelines_episode_id` (`episode_id`),
+    INDEX `idx_episode_pipelines_drama_id` (`drama_id`),
+    INDEX `idx_episode_pipelines_status` (`status`)
+);
+
+CREATE TABLE `pipeline_stages` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `pipeline_id` bigint unsigned NOT NULL,
+    `name` varchar(50) NOT NULL,
+    `seq` bigint NOT NULL,
+    `depends_on` varchar(200),
+    `status` varchar(20) NOT NULL,
+    `task_id` varchar(36),
+    `message` varchar(500),
+    `error` text,
+    `approved_at` datetime(3) NULL,
+    `started_at` datetime(3) NULL,
+    `completed_at` datetime(3) NULL,
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_pipeline_stages_name` (`pipeline_id`,`name`),
+    CONSTRAINT `fk_episode_pipelines_stages` FOREIGN KEY (`pipeline_id`) REFERENCES `episode_pipelines`(`id`)
+);
@@ -0,0 +1,13 @@
+-- 导入剧本时保留的结构化剧本
+
+CREATE TABLE `episode_screenplays` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `episode_id` bigint unsigned NOT NULL,
+    `format` varchar(20) NOT NULL,
+    `source_name` varchar(255),
+    `content` JSON,
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_episode_screenplays_episode_id` (`episode_id`)
+);
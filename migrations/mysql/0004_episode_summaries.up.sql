@@ -0,0 +1,12 @@
+-- 章节剧情摘要，用于生成后续章节剧本时保持连贯
+
+CREATE TABLE `episode_summaries` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `episode_id` bigint unsigned NOT NULL,
+    `summary` text NOT NULL,
+    `script_hash` varchar(64) NOT NULL,
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_episode_summaries_episode_id` (`episode_id`)
+);
@@ -0,0 +1,21 @@
+-- 连贯性检查发现的问题
+
+CREATE TABLE `continuity_issues` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `drama_id` bigint unsigned NOT NULL,
+    `episode_id` bigint unsigned NOT NULL,
+    `storyboard_id` bigint unsigned,
+    `type` varchar(50) NOT NULL,
+    `severity` varchar(20) NOT NULL,
+    `status` varchar(20) NOT NULL,
+    `message` text NOT NULL,
+    `details` JSON,
+    `fingerprint` varchar(255) NOT NULL,
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    INDEX `idx_continuity_issues_drama_id` (`drama_id`),
+    INDEX `idx_continuity_issues_episode_id` (`episode_id`),
+    INDEX `idx_continuity_issues_storyboard_id` (`storyboard_id`),
+    INDEX `idx_continuity_issues_status` (`status`)
+);
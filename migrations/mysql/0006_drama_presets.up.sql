@@ -0,0 +1,22 @@
+-- 剧本生成预设
+
+CREATE TABLE `drama_presets` (
+    `id` bigint unsigned AUTO_INCREMENT,
+    `drama_id` bigint unsigned NOT NULL,
+    `text_model` varchar(100),
+    `image_model` varchar(100),
+    `video_model` varchar(100),
+    `tts_provider` varchar(50),
+    `tts_voice` varchar(100),
+    `aspect_ratio` varchar(20),
+    `resolution` varchar(20),
+    `shot_duration` bigint DEFAULT 0,
+    `negative_prompt` text,
+    `seed_policy` varchar(20),
+    `seed` bigint,
+    `reference_mode` varchar(20),
+    `created_at` datetime(3) NOT NULL,
+    `updated_at` datetime(3) NOT NULL,
+    PRIMARY KEY (`id`),
+    UNIQUE INDEX `idx_drama_presets_drama_id` (`drama_id`)
+);
@@ -0,0 +1,7 @@
+-- 回滚：标签名索引恢复为普通索引，恢复软删除列
+
+DROP INDEX `idx_tags_global_name` ON `tags`;
+ALTER TABLE `tags` DROP COLUMN `global_name`;
+DROP INDEX `idx_tags_drama_name` ON `tags`;
+CREATE INDEX `idx_tags_drama_name` ON `tags`(`drama_id`,`name`);
+ALTER TABLE `tags` ADD COLUMN `deleted_at` datetime(3) NULL, ADD INDEX `idx_tags_deleted_at` (`deleted_at`);
@@ -0,0 +1,11 @@
+-- 同一剧本下标签名唯一，全局标签（drama_id 为空）之间标签名唯一
+-- 标签改为物理删除：清理已软删除的标签及其关联后删除 deleted_at 列
+-- MySQL 不支持部分索引，全局标签名通过生成列 global_name（仅全局标签有值）建唯一索引
+
+DELETE FROM `asset_tags` WHERE `tag_id` IN (SELECT `id` FROM `tags` WHERE `deleted_at` IS NOT NULL);
+DELETE FROM `tags` WHERE `deleted_at` IS NOT NULL;
+ALTER TABLE `tags` DROP INDEX `idx_tags_deleted_at`, DROP COLUMN `deleted_at`;
+DROP INDEX `idx_tags_drama_name` ON `tags`;
+CREATE UNIQUE INDEX `idx_tags_drama_name` ON `tags`(`drama_id`,`name`);
+ALTER TABLE `tags` ADD COLUMN `global_name` varchar(50) AS (CASE WHEN `drama_id` IS NULL THEN `name` END) STORED;
+CREATE UNIQUE INDEX `idx_tags_global_name` ON `tags`(`global_name`);
@@ -0,0 +1,503 @@
+-- 基线：与 AutoMigrate 创建的表结构一致（PostgreSQL）
+-- JSON 列使用 json 而非 jsonb：文件引用扫描按原始文本匹配，jsonb 会规范化空白
+
+CREATE TABLE IF NOT EXISTS "dramas" (
+    "id" bigserial,
+    "title" varchar(200) NOT NULL,
+    "description" text,
+    "genre" varchar(50),
+    "style" varchar(50) DEFAULT 'realistic',
+    "total_episodes" bigint DEFAULT 1,
+    "total_duration" bigint DEFAULT 0,
+    "status" varchar(20) NOT NULL DEFAULT 'draft',
+    "thumbnail" varchar(500),
+    "tags" json,
+    "metadata" json,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_dramas_deleted_at" ON "dramas" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "episodes" (
+    "id" bigserial,
+    "drama_id" bigint NOT NULL,
+    "episode_number" bigint NOT NULL,
+    "title" varchar(200) NOT NULL,
+    "script_content" text,
+    "description" text,
+    "duration" bigint DEFAULT 0,
+    "status" varchar(20) DEFAULT 'draft',
+    "video_url" varchar(500),
+    "hls_url" varchar(500),
+    "thumbnail" varchar(500),
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_dramas_episodes" FOREIGN KEY ("drama_id") REFERENCES "dramas"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_episodes_deleted_at" ON "episodes" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_episodes_drama_id" ON "episodes" ("drama_id");
+
+CREATE TABLE IF NOT EXISTS "characters" (
+    "id" bigserial,
+    "drama_id" bigint NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "role" varchar(50),
+    "description" text,
+    "appearance" text,
+    "personality" text,
+    "voice_style" varchar(200),
+    "image_url" varchar(500),
+    "local_path" text,
+    "reference_images" json,
+    "seed_value" varchar(100),
+    "sort_order" bigint DEFAULT 0,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_dramas_characters" FOREIGN KEY ("drama_id") REFERENCES "dramas"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_characters_deleted_at" ON "characters" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_characters_drama_id" ON "characters" ("drama_id");
+
+CREATE TABLE IF NOT EXISTS "episode_characters" (
+    "character_id" bigint,
+    "episode_id" bigint,
+    PRIMARY KEY ("character_id","episode_id"),
+    CONSTRAINT "fk_episode_characters_character" FOREIGN KEY ("character_id") REFERENCES "characters"("id"),
+    CONSTRAINT "fk_episode_characters_episode" FOREIGN KEY ("episode_id") REFERENCES "episodes"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "scenes" (
+    "id" bigserial,
+    "drama_id" bigint NOT NULL,
+    "episode_id" bigint,
+    "location" varchar(200) NOT NULL,
+    "time" varchar(100) NOT NULL,
+    "prompt" text NOT NULL,
+    "storyboard_count" bigint DEFAULT 1,
+    "image_url" varchar(500),
+    "local_path" text,
+    "status" varchar(20) DEFAULT 'pending',
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_episodes_scenes" FOREIGN KEY ("episode_id") REFERENCES "episodes"("id"),
+    CONSTRAINT "fk_dramas_scenes" FOREIGN KEY ("drama_id") REFERENCES "dramas"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_scenes_deleted_at" ON "scenes" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_scenes_episode_id" ON "scenes" ("episode_id");
+CREATE INDEX IF NOT EXISTS "idx_scenes_drama_id" ON "scenes" ("drama_id");
+
+CREATE TABLE IF NOT EXISTS "storyboards" (
+    "id" bigserial,
+    "episode_id" bigint NOT NULL,
+    "scene_id" bigint,
+    "storyboard_number" bigint NOT NULL,
+    "title" varchar(255),
+    "location" varchar(255),
+    "time" varchar(255),
+    "shot_type" varchar(100),
+    "angle" varchar(100),
+    "movement" varchar(100),
+    "action" text,
+    "result" text,
+    "atmosphere" text,
+    "image_prompt" text,
+    "video_prompt" text,
+    "bgm_prompt" text,
+    "sound_effect" varchar(255),
+    "dialogue" text,
+    "description" text,
+    "duration" bigint DEFAULT 5,
+    "composed_image" text,
+    "video_url" text,
+    "status" varchar(20) DEFAULT 'pending',
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_storyboards_background" FOREIGN KEY ("scene_id") REFERENCES "scenes"("id"),
+    CONSTRAINT "fk_episodes_storyboards" FOREIGN KEY ("episode_id") REFERENCES "episodes"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_storyboards_deleted_at" ON "storyboards" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_storyboards_scene_id" ON "storyboards" ("scene_id");
+CREATE INDEX IF NOT EXISTS "idx_storyboards_episode_id" ON "storyboards" ("episode_id");
+
+CREATE TABLE IF NOT EXISTS "props" (
+    "id" bigserial,
+    "drama_id" bigint NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "type" varchar(50),
+    "description" text,
+    "prompt" text,
+    "image_url" varchar(500),
+    "local_path" text,
+    "reference_images" json,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_dramas_props" FOREIGN KEY ("drama_id") REFERENCES "dramas"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_props_deleted_at" ON "props" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_props_drama_id" ON "props" ("drama_id");
+
+CREATE TABLE IF NOT EXISTS "storyboard_props" (
+    "prop_id" bigint,
+    "storyboard_id" bigint,
+    PRIMARY KEY ("prop_id","storyboard_id"),
+    CONSTRAINT "fk_storyboard_props_prop" FOREIGN KEY ("prop_id") REFERENCES "props"("id"),
+    CONSTRAINT "fk_storyboard_props_storyboard" FOREIGN KEY ("storyboard_id") REFERENCES "storyboards"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "storyboard_characters" (
+    "storyboard_id" bigint,
+    "character_id" bigint,
+    PRIMARY KEY ("storyboard_id","character_id"),
+    CONSTRAINT "fk_storyboard_characters_storyboard" FOREIGN KEY ("storyboard_id") REFERENCES "storyboards"("id"),
+    CONSTRAINT "fk_storyboard_characters_character" FOREIGN KEY ("character_id") REFERENCES "characters"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "frame_prompts" (
+    "id" bigserial,
+    "storyboard_id" bigint NOT NULL,
+    "frame_type" varchar(20) NOT NULL,
+    "prompt" text NOT NULL,
+    "description" text,
+    "layout" varchar(50),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_frame_prompts_type" ON "frame_prompts" ("frame_type");
+CREATE INDEX IF NOT EXISTS "idx_frame_prompts_storyboard" ON "frame_prompts" ("storyboard_id");
+
+CREATE TABLE IF NOT EXISTS "image_generations" (
+    "id" bigserial,
+    "storyboard_id" bigint,
+    "drama_id" bigint NOT NULL,
+    "scene_id" bigint,
+    "character_id" bigint,
+    "prop_id" bigint,
+    "image_type" varchar(20) DEFAULT 'storyboard',
+    "frame_type" varchar(20),
+    "provider" varchar(50) NOT NULL,
+    "prompt" text NOT NULL,
+    "negative_prompt" text,
+    "model" varchar(100),
+    "size" varchar(20),
+    "quality" varchar(20),
+    "style" varchar(50),
+    "steps" bigint,
+    "cfg_scale" decimal,
+    "seed" bigint,
+    "image_url" text,
+    "minio_url" text,
+    "local_path" text,
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "task_id" varchar(200),
+    "error_msg" text,
+    "width" bigint,
+    "height" bigint,
+    "reference_images" json,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "completed_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_image_generations_prop" FOREIGN KEY ("prop_id") REFERENCES "props"("id"),
+    CONSTRAINT "fk_image_generations_storyboard" FOREIGN KEY ("storyboard_id") REFERENCES "storyboards"("id"),
+    CONSTRAINT "fk_image_generations_drama" FOREIGN KEY ("drama_id") REFERENCES "dramas"("id"),
+    CONSTRAINT "fk_image_generations_scene" FOREIGN KEY ("scene_id") REFERENCES "scenes"("id"),
+    CONSTRAINT "fk_image_generations_character" FOREIGN KEY ("character_id") REFERENCES "characters"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_image_generations_image_type" ON "image_generations" ("image_type");
+CREATE INDEX IF NOT EXISTS "idx_image_generations_prop_id" ON "image_generations" ("prop_id");
+CREATE INDEX IF NOT EXISTS "idx_image_generations_character_id" ON "image_generations" ("character_id");
+CREATE INDEX IF NOT EXISTS "idx_image_generations_scene_id" ON "image_generations" ("scene_id");
+CREATE INDEX IF NOT EXISTS "idx_image_generations_drama_id" ON "image_generations" ("drama_id");
+CREATE INDEX IF NOT EXISTS "idx_image_generations_storyboard_id" ON "image_generations" ("storyboard_id");
+
+CREATE TABLE IF NOT EXISTS "video_generations" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "storyboard_id" bigint,
+    "drama_id" bigint NOT NULL,
+    "provider" varchar(50) NOT NULL,
+    "prompt" text NOT NULL,
+    "model" varchar(100),
+    "image_gen_id" bigint,
+    "reference_mode" varchar(20),
+    "image_url" varchar(1000),
+    "first_frame_url" varchar(1000),
+    "last_frame_url" varchar(1000),
+    "reference_image_urls" text,
+    "duration" bigint,
+    "fps" bigint,
+    "resolution" varchar(50),
+    "aspect_ratio" varchar(20),
+    "style" varchar(100),
+    "motion_level" bigint,
+    "camera_motion" varchar(100),
+    "seed" bigint,
+    "video_url" varchar(1000),
+    "minio_url" varchar(1000),
+    "local_path" varchar(500),
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "task_id" varchar(200),
+    "error_msg" text,
+    "completed_at" timestamptz,
+    "width" bigint,
+    "height" bigint,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_video_generations_storyboard" FOREIGN KEY ("storyboard_id") REFERENCES "storyboards"("id"),
+    CONSTRAINT "fk_video_generations_drama" FOREIGN KEY ("drama_id") REFERENCES "dramas"("id"),
+    CONSTRAINT "fk_video_generations_image_gen" FOREIGN KEY ("image_gen_id") REFERENCES "image_generations"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_video_generations_task_id" ON "video_generations" ("task_id");
+CREATE INDEX IF NOT EXISTS "idx_video_generations_status" ON "video_generations" ("status");
+CREATE INDEX IF NOT EXISTS "idx_video_generations_image_gen_id" ON "video_generations" ("image_gen_id");
+CREATE INDEX IF NOT EXISTS "idx_video_generations_provider" ON "video_generations" ("provider");
+CREATE INDEX IF NOT EXISTS "idx_video_generations_drama_id" ON "video_generations" ("drama_id");
+CREATE INDEX IF NOT EXISTS "idx_video_generations_storyboard_id" ON "video_generations" ("storyboard_id");
+CREATE INDEX IF NOT EXISTS "idx_video_generations_deleted_at" ON "video_generations" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "video_merges" (
+    "id" bigserial,
+    "episode_id" bigint NOT NULL,
+    "drama_id" bigint NOT NULL,
+    "title" varchar(200),
+    "provider" varchar(50) NOT NULL,
+    "model" varchar(100),
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "scenes" json NOT NULL,
+    "options" json,
+    "merged_url" varchar(500),
+    "duration" bigint,
+    "task_id" varchar(100),
+    "error_msg" text,
+    "created_at" timestamptz NOT NULL,
+    "completed_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_video_merges_episode" FOREIGN KEY ("episode_id") REFERENCES "episodes"("id"),
+    CONSTRAINT "fk_video_merges_drama" FOREIGN KEY ("drama_id") REFERENCES "dramas"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_video_merges_deleted_at" ON "video_merges" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_video_merges_drama_id" ON "video_merges" ("drama_id");
+CREATE INDEX IF NOT EXISTS "idx_video_merges_episode_id" ON "video_merges" ("episode_id");
+
+CREATE TABLE IF NOT EXISTS "drama_brandings" (
+    "id" bigserial,
+    "drama_id" bigint NOT NULL,
+    "enabled" boolean DEFAULT true,
+    "intro_video" varchar(1000),
+    "outro_video" varchar(1000),
+    "logo_image" varchar(1000),
+    "watermark_position" varchar(20) DEFAULT 'top-right',
+    "watermark_opacity" decimal DEFAULT 0.8,
+    "watermark_scale" decimal DEFAULT 0.15,
+    "watermark_margin" bigint DEFAULT 24,
+    "title_card_text" varchar(500),
+    "title_card_duration" decimal DEFAULT 3,
+    "title_card_font_file" varchar(500),
+    "title_card_color" varchar(20) DEFAULT '#FFFFFF',
+    "title_card_bg_color" varchar(20) DEFAULT '#000000',
+    "cover_time" decimal DEFAULT 1,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_drama_brandings_drama_id" ON "drama_brandings" ("drama_id");
+
+CREATE TABLE IF NOT EXISTS "ai_service_configs" (
+    "id" bigserial,
+    "service_type" varchar(50) NOT NULL,
+    "provider" varchar(50),
+    "name" varchar(100) NOT NULL,
+    "base_url" varchar(255) NOT NULL,
+    "api_key" varchar(255) NOT NULL,
+    "model" text,
+    "endpoint" varchar(255),
+    "query_endpoint" varchar(255),
+    "priority" bigint DEFAULT 0,
+    "is_default" boolean DEFAULT false,
+    "is_active" boolean DEFAULT true,
+    "settings" text,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "ai_service_providers" (
+    "id" bigserial,
+    "name" varchar(100) NOT NULL,
+    "display_name" varchar(100) NOT NULL,
+    "service_type" varchar(50) NOT NULL,
+    "default_url" varchar(255),
+    "description" text,
+    "is_active" boolean DEFAULT true,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_ai_service_providers_name" ON "ai_service_providers" ("name");
+
+CREATE TABLE IF NOT EXISTS "tags" (
+    "id" bigserial,
+    "drama_id" bigint,
+    "name" varchar(50) NOT NULL,
+    "color" varchar(20),
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_tags_deleted_at" ON "tags" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_tags_drama_name" ON "tags" ("drama_id","name");
+
+CREATE TABLE IF NOT EXISTS "assets" (
+    "id" bigserial,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    "drama_id" bigint,
+    "episode_id" bigint,
+    "storyboard_id" bigint,
+    "storyboard_num" bigint,
+    "name" varchar(200) NOT NULL,
+    "description" text,
+    "type" varchar(20) NOT NULL,
+    "category" varchar(50),
+    "url" varchar(1000) NOT NULL,
+    "thumbnail_url" varchar(1000),
+    "local_path" varchar(500),
+    "poster_url" varchar(1000),
+    "sprite_url" varchar(1000),
+    "sprite_info" json,
+    "waveform_url" varchar(1000),
+    "peaks_url" varchar(1000),
+    "file_size" bigint,
+    "mime_type" varchar(100),
+    "width" bigint,
+    "height" bigint,
+    "duration" bigint,
+    "format" varchar(50),
+    "codec" varchar(50),
+    "audio_codec" varchar(50),
+    "fps" decimal,
+    "bitrate" bigint,
+    "has_audio" boolean,
+    "probed_at" timestamptz,
+    "image_gen_id" bigint,
+    "video_gen_id" bigint,
+    "is_favorite" boolean DEFAULT false,
+    "view_count" bigint DEFAULT 0,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_assets_drama" FOREIGN KEY ("drama_id") REFERENCES "dramas"("id"),
+    CONSTRAINT "fk_assets_image_gen" FOREIGN KEY ("image_gen_id") REFERENCES "image_generations"("id"),
+    CONSTRAINT "fk_assets_video_gen" FOREIGN KEY ("video_gen_id") REFERENCES "video_generations"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_assets_video_gen_id" ON "assets" ("video_gen_id");
+CREATE INDEX IF NOT EXISTS "idx_assets_image_gen_id" ON "assets" ("image_gen_id");
+CREATE INDEX IF NOT EXISTS "idx_assets_category" ON "assets" ("category");
+CREATE INDEX IF NOT EXISTS "idx_assets_type" ON "assets" ("type");
+CREATE INDEX IF NOT EXISTS "idx_assets_storyboard_id" ON "assets" ("storyboard_id");
+CREATE INDEX IF NOT EXISTS "idx_assets_episode_id" ON "assets" ("episode_id");
+CREATE INDEX IF NOT EXISTS "idx_assets_drama_id" ON "assets" ("drama_id");
+CREATE INDEX IF NOT EXISTS "idx_assets_deleted_at" ON "assets" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "asset_tags" (
+    "asset_id" bigint,
+    "tag_id" bigint,
+    PRIMARY KEY ("asset_id","tag_id"),
+    CONSTRAINT "fk_asset_tags_tag" FOREIGN KEY ("tag_id") REFERENCES "tags"("id"),
+    CONSTRAINT "fk_asset_tags_asset" FOREIGN KEY ("asset_id") REFERENCES "assets"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "character_libraries" (
+    "id" bigserial,
+    "name" varchar(100) NOT NULL,
+    "category" varchar(50),
+    "image_url" varchar(500) NOT NULL,
+    "local_path" varchar(500),
+    "description" text,
+    "tags" varchar(500),
+    "source_type" varchar(20) DEFAULT 'generated',
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_character_libraries_deleted_at" ON "character_libraries" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "storage_blobs" (
+    "id" bigserial,
+    "hash" char(64) NOT NULL,
+    "storage_key" varchar(500) NOT NULL,
+    "size" bigint NOT NULL,
+    "content_type" varchar(100),
+    "ref_count" bigint NOT NULL DEFAULT 0,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_storage_blobs_key" ON "storage_blobs" ("storage_key");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_storage_blobs_hash" ON "storage_blobs" ("hash");
+
+CREATE TABLE IF NOT EXISTS "storage_url_caches" (
+    "id" bigserial,
+    "url_hash" char(64) NOT NULL,
+    "url" text NOT NULL,
+    "blob_hash" char(64) NOT NULL,
+    "created_at" timestamptz NOT NULL,
+    "last_used_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_storage_url_caches_blob_hash" ON "storage_url_caches" ("blob_hash");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_storage_url_caches_url_hash" ON "storage_url_caches" ("url_hash");
+
+CREATE TABLE IF NOT EXISTS "resource_transfers" (
+    "id" bigserial,
+    "resource_type" varchar(30) NOT NULL,
+    "resource_id" bigint NOT NULL,
+    "drama_id" bigint NOT NULL,
+    "source_url" text NOT NULL,
+    "status" varchar(20) NOT NULL,
+    "attempts" bigint NOT NULL DEFAULT 0,
+    "last_error" text,
+    "next_retry_at" timestamptz,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_resource_transfers_status" ON "resource_transfers" ("status");
+CREATE INDEX IF NOT EXISTS "idx_resource_transfers_drama_id" ON "resource_transfers" ("drama_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_resource_transfers_resource" ON "resource_transfers" ("resource_type","resource_id");
+
+CREATE TABLE IF NOT EXISTS "async_tasks" (
+    "id" varchar(36),
+    "type" varchar(50) NOT NULL,
+    "status" varchar(20) NOT NULL,
+    "progress" bigint DEFAULT 0,
+    "message" varchar(500),
+    "error" text,
+    "result" text,
+    "resource_id" varchar(36),
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "completed_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_async_tasks_deleted_at" ON "async_tasks" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_async_tasks_resource_id" ON "async_tasks" ("resource_id");
+CREATE INDEX IF NOT EXISTS "idx_async_tasks_status" ON "async_tasks" ("status");
+CREATE INDEX IF NOT EXISTS "idx_async_tasks_type" ON "async_tasks" ("type");
@@ -0,0 +1,38 @@
+-- 章节一键生产流水线及其阶段
+
+CREATE TABLE "episode_pipelines" (
+    "id" bigserial,
+    "episode_id" bigint NOT NULL,
+    "drama_id" bigint NOT NULL,
+    "status" varchar(20) NOT NULL,
+    "current_stage" varchar(50),
+    "options" json,
+    "error" text,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    "completed_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_episode_pipelines_episode_id" ON "episode_pipelines" ("episode_id");
+CREATE INDEX "idx_episode_pipelines_drama_id" ON "episode_pipelines" ("drama_id");
+CREATE INDEX "idx_episode_pipelines_status" ON "episode_pipelines" ("status");
+
+CREATE TABLE "pipeline_stages" (
+    "id" bigserial,
+    "pipeline_id" bigint NOT NULL,
+    "name" varchar(50) NOT NULL,
+    "seq" bigint NOT NULL,
+    "depends_on" varchar(200),
+    "status" varchar(20) NOT NULL,
+    "task_id" varchar(36),
+    "message" varchar(500),
+    "error" text,
+    "approved_at" timestamptz,
+    "started_at" timestamptz,
+    "completed_at" timestamptz,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_episode_pipelines_stages" FOREIGN KEY ("pipeline_id") REFERENCES "episode_pipelines"("id")
+);
+CREATE UNIQUE INDEX "idx_pipeline_stages_name" ON "pipeline_stages" ("pipeline_id","name");
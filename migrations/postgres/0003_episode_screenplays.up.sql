@@ -0,0 +1,13 @@
+-- 导入剧本时保留的结构化剧本
+
+CREATE TABLE "episode_screenplays" (
+    "id" bigserial,
+    "episode_id" bigint NOT NULL,
+    "format" varchar(20) NOT NULL,
+    "source_name" varchar(255),
+    "content" json,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "idx_episode_screenplays_episode_id" ON "episode_screenplays" ("episode_id");
@@ -0,0 +1,12 @@
+-- 章节剧情摘要，用于生成后续章节剧本时保持连贯
+
+CREATE TABLE "episode_summaries" (
+    "id" bigserial,
+    "episode_id" bigint NOT NULL,
+    "summary" text NOT NULL,
+    "script_hash" varchar(64) NOT NULL,
+    "created_at" timestamptz NOT NULL,
+    "updated_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "idx_episode_summaries_episode_id" ON "episode_summaries" ("episode_id");
@@ -0,0 +1,7 @@
+-- 回滚：标签名索引恢复为普通索引，恢复软删除列
+
+DROP INDEX IF EXISTS "idx_tags_global_name";
+DROP INDEX IF EXISTS "idx_tags_drama_name";
+CREATE INDEX "idx_tags_drama_name" ON "tags" ("drama_id","name");
+ALTER TABLE "tags" ADD COLUMN "deleted_at" timestamptz;
+CREATE INDEX "idx_tags_deleted_at" ON "tags" ("deleted_at");
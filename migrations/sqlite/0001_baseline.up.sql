@@ -0,0 +1,484 @@
+-- 基线：与 AutoMigrate 创建的表结构一致（SQLite）
+-- 已有数据库首次执行时表已存在，语句均为 IF NOT EXISTS
+
+CREATE TABLE IF NOT EXISTS `dramas` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `title` varchar(200) NOT NULL,
+    `description` text,
+    `genre` varchar(50),
+    `style` varchar(50) DEFAULT 'realistic',
+    `total_episodes` integer DEFAULT 1,
+    `total_duration` integer DEFAULT 0,
+    `status` varchar(20) NOT NULL DEFAULT 'draft',
+    `thumbnail` varchar(500),
+    `tags` JSON,
+    `metadata` JSON,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    `deleted_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_dramas_deleted_at` ON `dramas`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `episodes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `drama_id` integer NOT NULL,
+    `episode_number` integer NOT NULL,
+    `title` varchar(200) NOT NULL,
+    `script_content` longtext,
+    `description` text,
+    `duration` integer DEFAULT 0,
+    `status` varchar(20) DEFAULT 'draft',
+    `video_url` varchar(500),
+    `hls_url` varchar(500),
+    `thumbnail` varchar(500),
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    `deleted_at` datetime,
+    CONSTRAINT `fk_dramas_episodes` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_episodes_deleted_at` ON `episodes`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_episodes_drama_id` ON `episodes`(`drama_id`);
+
+CREATE TABLE IF NOT EXISTS `characters` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `drama_id` integer NOT NULL,
+    `name` varchar(100) NOT NULL,
+    `role` varchar(50),
+    `description` text,
+    `appearance` text,
+    `personality` text,
+    `voice_style` varchar(200),
+    `image_url` varchar(500),
+    `local_path` text,
+    `reference_images` JSON,
+    `seed_value` varchar(100),
+    `sort_order` integer DEFAULT 0,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    `deleted_at` datetime,
+    CONSTRAINT `fk_dramas_characters` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_characters_deleted_at` ON `characters`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_characters_drama_id` ON `characters`(`drama_id`);
+
+CREATE TABLE IF NOT EXISTS `episode_characters` (
+    `character_id` integer,
+    `episode_id` integer,
+    PRIMARY KEY (`character_id`,`episode_id`),
+    CONSTRAINT `fk_episode_characters_character` FOREIGN KEY (`character_id`) REFERENCES `characters`(`id`),
+    CONSTRAINT `fk_episode_characters_episode` FOREIGN KEY (`episode_id`) REFERENCES `episodes`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `scenes` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `drama_id` integer NOT NULL,
+    `episode_id` integer,
+    `location` varchar(200) NOT NULL,
+    `time` varchar(100) NOT NULL,
+    `prompt` text NOT NULL,
+    `storyboard_count` integer DEFAULT 1,
+    `image_url` varchar(500),
+    `local_path` text,
+    `status` varchar(20) DEFAULT 'pending',
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    `deleted_at` datetime,
+    CONSTRAINT `fk_episodes_scenes` FOREIGN KEY (`episode_id`) REFERENCES `episodes`(`id`),
+    CONSTRAINT `fk_dramas_scenes` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_scenes_deleted_at` ON `scenes`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_scenes_episode_id` ON `scenes`(`episode_id`);
+CREATE INDEX IF NOT EXISTS `idx_scenes_drama_id` ON `scenes`(`drama_id`);
+
+CREATE TABLE IF NOT EXISTS `storyboards` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `episode_id` integer NOT NULL,
+    `scene_id` integer,
+    `storyboard_number` integer NOT NULL,
+    `title` text,
+    `location` text,
+    `time` text,
+    `shot_type` text,
+    `angle` text,
+    `movement` text,
+    `action` text,
+    `result` text,
+    `atmosphere` text,
+    `image_prompt` text,
+    `video_prompt` text,
+    `bgm_prompt` text,
+    `sound_effect` text,
+    `dialogue` text,
+    `description` text,
+    `duration` integer DEFAULT 5,
+    `composed_image` text,
+    `video_url` text,
+    `status` varchar(20) DEFAULT 'pending',
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    CONSTRAINT `fk_storyboards_background` FOREIGN KEY (`scene_id`) REFERENCES `scenes`(`id`),
+    CONSTRAINT `fk_episodes_storyboards` FOREIGN KEY (`episode_id`) REFERENCES `episodes`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_storyboards_deleted_at` ON `storyboards`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_storyboards_scene_id` ON `storyboards`(`scene_id`);
+CREATE INDEX IF NOT EXISTS `idx_storyboards_episode_id` ON `storyboards`(`episode_id`);
+
+CREATE TABLE IF NOT EXISTS `storyboard_characters` (
+    `storyboard_id` integer,
+    `character_id` integer,
+    PRIMARY KEY (`storyboard_id`,`character_id`),
+    CONSTRAINT `fk_storyboard_characters_storyboard` FOREIGN KEY (`storyboard_id`) REFERENCES `storyboards`(`id`),
+    CONSTRAINT `fk_storyboard_characters_character` FOREIGN KEY (`character_id`) REFERENCES `characters`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `props` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `drama_id` integer NOT NULL,
+    `name` varchar(100) NOT NULL,
+    `type` varchar(50),
+    `description` text,
+    `prompt` text,
+    `image_url` varchar(500),
+    `local_path` text,
+    `reference_images` JSON,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    `deleted_at` datetime,
+    CONSTRAINT `fk_dramas_props` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_props_deleted_at` ON `props`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_props_drama_id` ON `props`(`drama_id`);
+
+CREATE TABLE IF NOT EXISTS `storyboard_props` (
+    `prop_id` integer,
+    `storyboard_id` integer,
+    PRIMARY KEY (`prop_id`,`storyboard_id`),
+    CONSTRAINT `fk_storyboard_props_storyboard` FOREIGN KEY (`storyboard_id`) REFERENCES `storyboards`(`id`),
+    CONSTRAINT `fk_storyboard_props_prop` FOREIGN KEY (`prop_id`) REFERENCES `props`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `frame_prompts` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `storyboard_id` integer NOT NULL,
+    `frame_type` text NOT NULL,
+    `prompt` text NOT NULL,
+    `description` text,
+    `layout` text,
+    `created_at` datetime,
+    `updated_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_frame_prompts_type` ON `frame_prompts`(`frame_type`);
+CREATE INDEX IF NOT EXISTS `idx_frame_prompts_storyboard` ON `frame_prompts`(`storyboard_id`);
+
+CREATE TABLE IF NOT EXISTS `image_generations` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `storyboard_id` integer,
+    `drama_id` integer NOT NULL,
+    `scene_id` integer,
+    `character_id` integer,
+    `prop_id` integer,
+    `image_type` text DEFAULT 'storyboard',
+    `frame_type` text,
+    `provider` text NOT NULL,
+    `prompt` text NOT NULL,
+    `negative_prompt` text,
+    `model` text,
+    `size` text,
+    `quality` text,
+    `style` text,
+    `steps` integer,
+    `cfg_scale` real,
+    `seed` integer,
+    `image_url` text,
+    `minio_url` text,
+    `local_path` text,
+    `status` text NOT NULL DEFAULT 'pending',
+    `task_id` text,
+    `error_msg` text,
+    `width` integer,
+    `height` integer,
+    `reference_images` JSON,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `completed_at` datetime,
+    CONSTRAINT `fk_image_generations_storyboard` FOREIGN KEY (`storyboard_id`) REFERENCES `storyboards`(`id`),
+    CONSTRAINT `fk_image_generations_drama` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`),
+    CONSTRAINT `fk_image_generations_scene` FOREIGN KEY (`scene_id`) REFERENCES `scenes`(`id`),
+    CONSTRAINT `fk_image_generations_character` FOREIGN KEY (`character_id`) REFERENCES `characters`(`id`),
+    CONSTRAINT `fk_image_generations_prop` FOREIGN KEY (`prop_id`) REFERENCES `props`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_image_generations_image_type` ON `image_generations`(`image_type`);
+CREATE INDEX IF NOT EXISTS `idx_image_generations_prop_id` ON `image_generations`(`prop_id`);
+CREATE INDEX IF NOT EXISTS `idx_image_generations_character_id` ON `image_generations`(`character_id`);
+CREATE INDEX IF NOT EXISTS `idx_image_generations_scene_id` ON `image_generations`(`scene_id`);
+CREATE INDEX IF NOT EXISTS `idx_image_generations_drama_id` ON `image_generations`(`drama_id`);
+CREATE INDEX IF NOT EXISTS `idx_image_generations_storyboard_id` ON `image_generations`(`storyboard_id`);
+
+CREATE TABLE IF NOT EXISTS `video_generations` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `storyboard_id` integer,
+    `drama_id` integer NOT NULL,
+    `provider` varchar(50) NOT NULL,
+    `prompt` text NOT NULL,
+    `model` varchar(100),
+    `image_gen_id` integer,
+    `reference_mode` varchar(20),
+    `image_url` varchar(1000),
+    `first_frame_url` varchar(1000),
+    `last_frame_url` varchar(1000),
+    `reference_image_urls` text,
+    `duration` integer,
+    `fps` integer,
+    `resolution` varchar(50),
+    `aspect_ratio` varchar(20),
+    `style` varchar(100),
+    `motion_level` integer,
+    `camera_motion` varchar(100),
+    `seed` integer,
+    `video_url` varchar(1000),
+    `minio_url` varchar(1000),
+    `local_path` varchar(500),
+    `status` varchar(20) NOT NULL DEFAULT 'pending',
+    `task_id` varchar(200),
+    `error_msg` text,
+    `completed_at` datetime,
+    `width` integer,
+    `height` integer,
+    CONSTRAINT `fk_video_generations_storyboard` FOREIGN KEY (`storyboard_id`) REFERENCES `storyboards`(`id`),
+    CONSTRAINT `fk_video_generations_drama` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`),
+    CONSTRAINT `fk_video_generations_image_gen` FOREIGN KEY (`image_gen_id`) REFERENCES `image_generations`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_video_generations_task_id` ON `video_generations`(`task_id`);
+CREATE INDEX IF NOT EXISTS `idx_video_generations_status` ON `video_generations`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_video_generations_image_gen_id` ON `video_generations`(`image_gen_id`);
+CREATE INDEX IF NOT EXISTS `idx_video_generations_provider` ON `video_generations`(`provider`);
+CREATE INDEX IF NOT EXISTS `idx_video_generations_drama_id` ON `video_generations`(`drama_id`);
+CREATE INDEX IF NOT EXISTS `idx_video_generations_storyboard_id` ON `video_generations`(`storyboard_id`);
+CREATE INDEX IF NOT EXISTS `idx_video_generations_deleted_at` ON `video_generations`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `video_merges` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `episode_id` integer NOT NULL,
+    `drama_id` integer NOT NULL,
+    `title` varchar(200),
+    `provider` varchar(50) NOT NULL,
+    `model` varchar(100),
+    `status` varchar(20) NOT NULL DEFAULT 'pending',
+    `scenes` JSON NOT NULL,
+    `options` JSON,
+    `merged_url` varchar(500),
+    `duration` integer,
+    `task_id` varchar(100),
+    `error_msg` text,
+    `created_at` datetime NOT NULL,
+    `completed_at` datetime,
+    `deleted_at` datetime,
+    CONSTRAINT `fk_video_merges_episode` FOREIGN KEY (`episode_id`) REFERENCES `episodes`(`id`),
+    CONSTRAINT `fk_video_merges_drama` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_video_merges_deleted_at` ON `video_merges`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_video_merges_drama_id` ON `video_merges`(`drama_id`);
+CREATE INDEX IF NOT EXISTS `idx_video_merges_episode_id` ON `video_merges`(`episode_id`);
+
+CREATE TABLE IF NOT EXISTS `drama_brandings` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `drama_id` integer NOT NULL,
+    `enabled` numeric DEFAULT true,
+    `intro_video` varchar(1000),
+    `outro_video` varchar(1000),
+    `logo_image` varchar(1000),
+    `watermark_position` varchar(20) DEFAULT 'top-right',
+    `watermark_opacity` real DEFAULT 0.8,
+    `watermark_scale` real DEFAULT 0.15,
+    `watermark_margin` integer DEFAULT 24,
+    `title_card_text` varchar(500),
+    `title_card_duration` real DEFAULT 3,
+    `title_card_font_file` varchar(500),
+    `title_card_color` varchar(20) DEFAULT '#FFFFFF',
+    `title_card_bg_color` varchar(20) DEFAULT '#000000',
+    `cover_time` real DEFAULT 1,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_drama_brandings_drama_id` ON `drama_brandings`(`drama_id`);
+
+CREATE TABLE IF NOT EXISTS `ai_service_configs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `service_type` varchar(50) NOT NULL,
+    `provider` varchar(50),
+    `name` varchar(100) NOT NULL,
+    `base_url` varchar(255) NOT NULL,
+    `api_key` varchar(255) NOT NULL,
+    `model` text,
+    `endpoint` varchar(255),
+    `query_endpoint` varchar(255),
+    `priority` integer DEFAULT 0,
+    `is_default` numeric DEFAULT false,
+    `is_active` numeric DEFAULT true,
+    `settings` text,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS `ai_service_providers` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` varchar(100) NOT NULL,
+    `display_name` varchar(100) NOT NULL,
+    `service_type` varchar(50) NOT NULL,
+    `default_url` varchar(255),
+    `description` text,
+    `is_active` numeric DEFAULT true,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_ai_service_providers_name` ON `ai_service_providers`(`name`);
+
+CREATE TABLE IF NOT EXISTS `tags` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `drama_id` integer,
+    `name` varchar(50) NOT NULL,
+    `color` varchar(20),
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    `deleted_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_tags_deleted_at` ON `tags`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_tags_drama_name` ON `tags`(`drama_id`,`name`);
+
+CREATE TABLE IF NOT EXISTS `assets` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `deleted_at` datetime,
+    `drama_id` integer,
+    `episode_id` integer,
+    `storyboard_id` integer,
+    `storyboard_num` integer,
+    `name` varchar(200) NOT NULL,
+    `description` text,
+    `type` varchar(20) NOT NULL,
+    `category` varchar(50),
+    `url` varchar(1000) NOT NULL,
+    `thumbnail_url` varchar(1000),
+    `local_path` varchar(500),
+    `poster_url` varchar(1000),
+    `sprite_url` varchar(1000),
+    `sprite_info` JSON,
+    `waveform_url` varchar(1000),
+    `peaks_url` varchar(1000),
+    `file_size` integer,
+    `mime_type` varchar(100),
+    `width` integer,
+    `height` integer,
+    `duration` integer,
+    `format` varchar(50),
+    `codec` varchar(50),
+    `audio_codec` varchar(50),
+    `fps` real,
+    `bitrate` integer,
+    `has_audio` numeric,
+    `probed_at` datetime,
+    `image_gen_id` integer,
+    `video_gen_id` integer,
+    `is_favorite` numeric DEFAULT false,
+    `view_count` integer DEFAULT 0,
+    CONSTRAINT `fk_assets_drama` FOREIGN KEY (`drama_id`) REFERENCES `dramas`(`id`),
+    CONSTRAINT `fk_assets_image_gen` FOREIGN KEY (`image_gen_id`) REFERENCES `image_generations`(`id`),
+    CONSTRAINT `fk_assets_video_gen` FOREIGN KEY (`video_gen_id`) REFERENCES `video_generations`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_assets_video_gen_id` ON `assets`(`video_gen_id`);
+CREATE INDEX IF NOT EXISTS `idx_assets_image_gen_id` ON `assets`(`image_gen_id`);
+CREATE INDEX IF NOT EXISTS `idx_assets_category` ON `assets`(`category`);
+CREATE INDEX IF NOT EXISTS `idx_assets_type` ON `assets`(`type`);
+CREATE INDEX IF NOT EXISTS `idx_assets_storyboard_id` ON `assets`(`storyboard_id`);
+CREATE INDEX IF NOT EXISTS `idx_assets_episode_id` ON `assets`(`episode_id`);
+CREATE INDEX IF NOT EXISTS `idx_assets_drama_id` ON `assets`(`drama_id`);
+CREATE INDEX IF NOT EXISTS `idx_assets_deleted_at` ON `assets`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `asset_tags` (
+    `asset_id` integer,
+    `tag_id` integer,
+    PRIMARY KEY (`asset_id`,`tag_id`),
+    CONSTRAINT `fk_asset_tags_asset` FOREIGN KEY (`asset_id`) REFERENCES `assets`(`id`),
+    CONSTRAINT `fk_asset_tags_tag` FOREIGN KEY (`tag_id`) REFERENCES `tags`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `character_libraries` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `name` varchar(100) NOT NULL,
+    `category` varchar(50),
+    `image_url` varchar(500) NOT NULL,
+    `local_path` varchar(500),
+    `description` text,
+    `tags` varchar(500),
+    `source_type` varchar(20) DEFAULT 'generated',
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    `deleted_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_character_libraries_deleted_at` ON `character_libraries`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `storage_blobs` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `hash` char(64) NOT NULL,
+    `storage_key` varchar(500) NOT NULL,
+    `size` integer NOT NULL,
+    `content_type` varchar(100),
+    `ref_count` integer NOT NULL DEFAULT 0,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL
+);
+CREATE INDEX IF NOT EXISTS `idx_storage_blobs_key` ON `storage_blobs`(`storage_key`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_storage_blobs_hash` ON `storage_blobs`(`hash`);
+
+CREATE TABLE IF NOT EXISTS `storage_url_caches` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `url_hash` char(64) NOT NULL,
+    `url` text NOT NULL,
+    `blob_hash` char(64) NOT NULL,
+    `created_at` datetime NOT NULL,
+    `last_used_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_storage_url_caches_blob_hash` ON `storage_url_caches`(`blob_hash`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_storage_url_caches_url_hash` ON `storage_url_caches`(`url_hash`);
+
+CREATE TABLE IF NOT EXISTS `resource_transfers` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `resource_type` varchar(30) NOT NULL,
+    `resource_id` integer NOT NULL,
+    `drama_id` integer NOT NULL,
+    `source_url` text NOT NULL,
+    `status` varchar(20) NOT NULL,
+    `attempts` integer NOT NULL DEFAULT 0,
+    `last_error` text,
+    `next_retry_at` datetime,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL
+);
+CREATE INDEX IF NOT EXISTS `idx_resource_transfers_status` ON `resource_transfers`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_resource_transfers_drama_id` ON `resource_transfers`(`drama_id`);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_resource_transfers_resource` ON `resource_transfers`(`resource_type`,`resource_id`);
+
+CREATE TABLE IF NOT EXISTS `async_tasks` (
+    `id` text,
+    `type` text NOT NULL,
+    `status` text NOT NULL,
+    `progress` integer DEFAULT 0,
+    `message` text,
+    `error` text,
+    `result` text,
+    `resource_id` text,
+    `created_at` datetime,
+    `updated_at` datetime,
+    `completed_at` datetime,
+    `deleted_at` datetime,
+    PRIMARY KEY (`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_async_tasks_deleted_at` ON `async_tasks`(`deleted_at`);
+CREATE INDEX IF NOT EXISTS `idx_async_tasks_resource_id` ON `async_tasks`(`resource_id`);
+CREATE INDEX IF NOT EXISTS `idx_async_tasks_status` ON `async_tasks`(`status`);
+CREATE INDEX IF NOT EXISTS `idx_async_tasks_type` ON `async_tasks`(`type`);
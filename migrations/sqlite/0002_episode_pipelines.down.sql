@@ -0,0 +1,4 @@
+-- 回滚：删除流水线表
+
+DROP TABLE IF EXISTS `pipeline_stages`;
+DROP TABLE IF EXISTS `episode_pipelines`;
@@ -0,0 +1,36 @@
+-- 章节一键生产流水线及其阶段
+
+CREATE TABLE `episode_pipelines` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `episode_id` integer NOT NULL,
+    `drama_id` integer NOT NULL,
+    `status` varchar(20) NOT NULL,
+    `current_stage` varchar(50),
+    `options` JSON,
+    `error` text,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    `completed_at` datetime
+);
+CREATE INDEX `idx_episode_pipelines_episode_id` ON `episode_pipelines`(`episode_id`);
+CREATE INDEX `idx_episode_pipelines_drama_id` ON `episode_pipelines`(`drama_id`);
+CREATE INDEX `idx_episode_pipelines_status` ON `episode_pipelines`(`status`);
+
+CREATE TABLE `pipeline_stages` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `pipeline_id` integer NOT NULL,
+    `name` varchar(50) NOT NULL,
+    `seq` integer NOT NULL,
+    `depends_on` varchar(200),
+    `status` varchar(20) NOT NULL,
+    `task_id` varchar(36),
+    `message` varchar(500),
+    `error` text,
+    `approved_at` datetime,
+    `started_at` datetime,
+    `completed_at` datetime,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL,
+    CONSTRAINT `fk_episode_pipelines_stages` FOREIGN KEY (`pipeline_id`) REFERENCES `episode_pipelines`(`id`)
+);
+CREATE UNIQUE INDEX `idx_pipeline_stages_name` ON `pipeline_stages`(`pipeline_id`,`name`);
@@ -0,0 +1,3 @@
+-- 回滚：删除结构化剧本表
+
+DROP TABLE IF EXISTS `episode_screenplays`;
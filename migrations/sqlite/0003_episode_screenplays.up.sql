@@ -0,0 +1,12 @@
+-- 导入剧本时保留的结构化剧本
+
+CREATE TABLE `episode_screenplays` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `episode_id` integer NOT NULL,
+    `format` varchar(20) NOT NULL,
+    `source_name` varchar(255),
+    `content` JSON,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL
+);
+CREATE UNIQUE INDEX `idx_episode_screenplays_episode_id` ON `episode_screenplays`(`episode_id`);
@@ -0,0 +1,3 @@
+-- 回滚：删除章节剧情摘要表
+
+DROP TABLE IF EXISTS `episode_summaries`;
@@ -0,0 +1,11 @@
+-- 章节剧情摘要，用于生成后续章节剧本时保持连贯
+
+CREATE TABLE `episode_summaries` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `episode_id` integer NOT NULL,
+    `summary` text NOT NULL,
+    `script_hash` varchar(64) NOT NULL,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL
+);
+CREATE UNIQUE INDEX `idx_episode_summaries_episode_id` ON `episode_summaries`(`episode_id`);
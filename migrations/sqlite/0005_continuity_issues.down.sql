@@ -0,0 +1,3 @@
+-- 回滚：删除连贯性问题表
+
+DROP TABLE IF EXISTS `continuity_issues`;
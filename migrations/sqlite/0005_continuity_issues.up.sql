@@ -0,0 +1,20 @@
+-- 连贯性检查发现的问题
+
+CREATE TABLE `continuity_issues` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `drama_id` integer NOT NULL,
+    `episode_id` integer NOT NULL,
+    `storyboard_id` integer,
+    `type` varchar(50) NOT NULL,
+    `severity` varchar(20) NOT NULL,
+    `status` varchar(20) NOT NULL,
+    `message` text NOT NULL,
+    `details` JSON,
+    `fingerprint` varchar(255) NOT NULL,
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL
+);
+CREATE INDEX `idx_continuity_issues_drama_id` ON `continuity_issues`(`drama_id`);
+CREATE INDEX `idx_continuity_issues_episode_id` ON `continuity_issues`(`episode_id`);
+CREATE INDEX `idx_continuity_issues_storyboard_id` ON `continuity_issues`(`storyboard_id`);
+CREATE INDEX `idx_continuity_issues_status` ON `continuity_issues`(`status`);
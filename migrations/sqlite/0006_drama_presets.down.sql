@@ -0,0 +1,3 @@
+-- 回滚：删除剧本生成预设表
+
+DROP TABLE IF EXISTS `drama_presets`;
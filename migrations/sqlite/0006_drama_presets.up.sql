@@ -0,0 +1,21 @@
+-- 剧本生成预设
+
+CREATE TABLE `drama_presets` (
+    `id` integer PRIMARY KEY AUTOINCREMENT,
+    `drama_id` integer NOT NULL,
+    `text_model` varchar(100),
+    `image_model` varchar(100),
+    `video_model` varchar(100),
+    `tts_provider` varchar(50),
+    `tts_voice` varchar(100),
+    `aspect_ratio` varchar(20),
+    `resolution` varchar(20),
+    `shot_duration` integer DEFAULT 0,
+    `negative_prompt` text,
+    `seed_policy` varchar(20),
+    `seed` integer,
+    `reference_mode` varchar(20),
+    `created_at` datetime NOT NULL,
+    `updated_at` datetime NOT NULL
+);
+CREATE UNIQUE INDEX `idx_drama_presets_drama_id` ON `drama_presets`(`drama_id`);
@@ -0,0 +1,10 @@
+-- 同一剧本下标签名唯一，全局标签（drama_id 为空）之间标签名唯一
+-- 标签改为物理删除：清理已软删除的标签及其关联后删除 deleted_at 列
+
+DELETE FROM `asset_tags` WHERE `tag_id` IN (SELECT `id` FROM `tags` WHERE `deleted_at` IS NOT NULL);
+DELETE FROM `tags` WHERE `deleted_at` IS NOT NULL;
+DROP INDEX IF EXISTS `idx_tags_deleted_at`;
+ALTER TABLE `tags` DROP COLUMN `deleted_at`;
+DROP INDEX IF EXISTS `idx_tags_drama_name`;
+CREATE UNIQUE INDEX `idx_tags_drama_name` ON `tags`(`drama_id`,`name`);
+CREATE UNIQUE INDEX `idx_tags_global_name` ON `tags`(`name`) WHERE `drama_id` IS NULL;
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/viper"
 )
@@ -30,7 +31,7 @@ type ServerConfig struct {
 }
 
 type DatabaseConfig struct {
-	Type     string `mapstructure:"type"` // sqlite, mysql
+	Type     string `mapstructure:"type"` // sqlite, mysql, postgres
 	Path     string `mapstructure:"path"` // SQLite数据库文件路径
 	Host     string `mapstructure:"host"`
 	Port     int    `mapstructure:"port"`
@@ -38,14 +39,31 @@ type DatabaseConfig struct {
 	Password string `mapstructure:"password"`
 	Database string `mapstructure:"database"`
 	Charset  string `mapstructure:"charset"`
+	SSLMode  string `mapstructure:"ssl_mode"` // PostgreSQL: disable, require, verify-ca, verify-full，默认 disable
+	Schema   string `mapstructure:"schema"`   // PostgreSQL schema，默认 public
 	MaxIdle  int    `mapstructure:"max_idle"`
 	MaxOpen  int    `mapstructure:"max_open"`
 }
 
 type StorageConfig struct {
-	Type      string `mapstructure:"type"`       // local, minio
-	LocalPath string `mapstructure:"local_path"` // 本地存储路径
-	BaseURL   string `mapstructure:"base_url"`   // 访问URL前缀
+	Type      string   `mapstructure:"type"`       // local, s3, minio（minio 使用 s3 后端）
+	LocalPath string   `mapstructure:"local_path"` // 本地存储路径（s3 模式下作为本地缓存目录）
+	BaseURL   string   `mapstructure:"base_url"`   // 访问URL前缀
+	S3        S3Config `mapstructure:"s3"`
+}
+
+// S3Config S3 兼容对象存储配置（AWS S3、MinIO 等）
+type S3Config struct {
+	Endpoint      string `mapstructure:"endpoint"`       // 如 localhost:9000 或 s3.amazonaws.com
+	Region        string `mapstructure:"region"`         // 默认 us-east-1
+	Bucket        string `mapstructure:"bucket"`         // 存储桶名称
+	AccessKey     string `mapstructure:"access_key"`     // 访问密钥 ID
+	SecretKey     string `mapstructure:"secret_key"`     // 访问密钥
+	UseSSL        bool   `mapstructure:"use_ssl"`        // 是否使用 https
+	PathStyle     bool   `mapstructure:"path_style"`     // 路径风格访问（MinIO 需开启）
+	PublicURL     string `mapstructure:"public_url"`     // 对外访问前缀，为空时使用 endpoint/bucket
+	PresignExpiry int    `mapstructure:"presign_expiry"` // 预签名URL有效期（秒），默认 3600
+	CreateBucket  bool   `mapstructure:"create_bucket"`  // 启动时自动创建存储桶
 }
 
 type AIConfig struct {
@@ -78,6 +96,25 @@ func (c *DatabaseConfig) DSN() string {
 	if c.Type == "sqlite" {
 		return c.Path
 	}
+	if c.Type == "postgres" {
+		sslMode := c.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		schema := c.Schema
+		if schema == "" {
+			schema = "public"
+		}
+		return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s search_path=%s",
+			pgQuote(c.Host),
+			c.Port,
+			pgQuote(c.User),
+			pgQuote(c.Password),
+			pgQuote(c.Database),
+			pgQuote(sslMode),
+			pgQuote(schema),
+		)
+	}
 	// MySQL DSN
 	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=%s&parseTime=True&loc=Local",
 		c.User,
@@ -88,3 +125,8 @@ func (c *DatabaseConfig) DSN() string {
 		c.Charset,
 	)
 }
+
+// pgQuote 按 libpq 关键字/值格式转义，值中可包含空格、引号与反斜杠
+func pgQuote(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
@@ -0,0 +1,408 @@
+// Package pdf 生成简单的 PDF 文档：文本、线条与 JPEG 图片
+//
+// 西文使用 Courier 等宽字体，中文使用阅读器内置的 STSong-Light（Adobe-GB1），不嵌入字体文件。
+// 坐标单位为 pt（1/72 英寸），原点在页面左上角。
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// 常用纸张尺寸（pt）
+const (
+	A4Width      = 595.28
+	A4Height     = 841.89
+	LetterWidth  = 612.0
+	LetterHeight = 792.0
+)
+
+// 西文等宽字体字宽（Courier 所有字符均为 600/1000 em），中文字符按全角 1000/1000 em
+const (
+	latinCharWidth = 0.6
+	cjkCharWidth   = 1.0
+)
+
+// Image 已加入文档的图片
+type Image struct {
+	Width  int
+	Height int
+	name   string
+	data   []byte
+	gray   bool
+}
+
+// Document PDF 文档
+type Document struct {
+	width, height float64
+	pages         []*bytes.Buffer
+	page          *bytes.Buffer
+	images        []*Image
+	fontSize      float64
+	bold          bool
+}
+
+// New 创建指定页面尺寸的文档
+func New(width, height float64) *Document {
+	return &Document{width: width, height: height, fontSize: 12}
+}
+
+// PageSize 页面宽高
+func (d *Document) PageSize() (float64, float64) {
+	return d.width, d.height
+}
+
+// PageCount 当前页数
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// AddPage 新增一页，后续绘制都在该页上
+func (d *Document) AddPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+}
+
+// SetFont 设置字号与是否加粗（加粗只对西文生效）
+func (d *Document) SetFont(size float64, bold bool) {
+	d.fontSize = size
+	d.bold = bold
+}
+
+// Font 当前字号与是否加粗
+func (d *Document) Font() (float64, bool) {
+	return d.fontSize, d.bold
+}
+
+// TextWidth 按当前字号计算文本宽度
+func (d *Document) TextWidth(s string) float64 {
+	w := 0.0
+	for _, r := range s {
+		w += runeWidth(r)
+	}
+	return w * d.fontSize
+}
+
+// Text 在 (x, y) 处输出单行文本，y 为文本基线
+func (d *Document) Text(x, y float64, s string) {
+	if d.page == nil || s == "" {
+		return
+	}
+	latin := "/F1"
+	if d.bold {
+		latin = "/F2"
+	}
+	fmt.Fprintf(d.page, "BT 1 0 0 1 %s %s Tm\n", num(x), num(d.height-y))
+	for _, run := range splitRuns(s) {
+		if run.cjk {
+			fmt.Fprintf(d.page, "/F3 %s Tf <%s> Tj\n", num(d.fontSize), utf16Hex(run.text))
+		} else {
+			fmt.Fprintf(d.page, "%s %s Tf (%s) Tj\n", latin, num(d.fontSize), escapeLatin(run.text))
+		}
+	}
+	d.page.WriteString("ET\n")
+}
+
+// TextRight 右对齐输出单行文本，right 为右边界
+func (d *Document) TextRight(right, y float64, s string) {
+	d.Text(right-d.TextWidth(s), y, s)
+}
+
+// TextCenter 在 [left, right] 内居中输出单行文本
+func (d *Document) TextCenter(left, right, y float64, s string) {
+	d.Text(left+(right-left-d.TextWidth(s))/2, y, s)
+}
+
+// Line 画线
+func (d *Document) Line(x1, y1, x2, y2, lineWidth float64) {
+	if d.page == nil {
+		return
+	}
+	fmt.Fprintf(d.page, "%s w %s %s m %s %s l S\n", num(lineWidth), num(x1), num(d.height-y1), num(x2), num(d.height-y2))
+}
+
+// Rect 画矩形边框，(x, y) 为左上角
+func (d *Document) Rect(x, y, w, h, lineWidth float64) {
+	if d.page == nil {
+		return
+	}
+	fmt.Fprintf(d.page, "%s w %s %s %s %s re S\n", num(lineWidth), num(x), num(d.height-y-h), num(w), num(h))
+}
+
+// AddJPEG 加入 JPEG 图片数据（直接嵌入，不重新编码）
+func (d *Document) AddJPEG(data []byte) (*Image, error) {
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid jpeg: %w", err)
+	}
+	img := &Image{Width: cfg.Width, Height: cfg.Height, data: data}
+	switch cfg.ColorModel {
+	case color.GrayModel:
+		img.gray = true
+	case color.YCbCrModel:
+	default:
+		// CMYK 等色彩空间重新编码为 RGB
+		decoded, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid jpeg: %w", err)
+		}
+		return d.AddImage(decoded)
+	}
+	return d.addImage(img), nil
+}
+
+// AddImage 加入图片，编码为 JPEG 嵌入
+func (d *Document) AddImage(src image.Image) (*Image, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, src, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, err
+	}
+	b := src.Bounds()
+	return d.addImage(&Image{Width: b.Dx(), Height: b.Dy(), data: buf.Bytes()}), nil
+}
+
+func (d *Document) addImage(img *Image) *Image {
+	img.name = fmt.Sprintf("/Im%d", len(d.images)+1)
+	d.images = append(d.images, img)
+	return img
+}
+
+// DrawImage 在 (x, y) 处按 w×h 绘制图片，(x, y) 为左上角
+func (d *Document) DrawImage(img *Image, x, y, w, h float64) {
+	if d.page == nil || img == nil {
+		return
+	}
+	fmt.Fprintf(d.page, "q %s 0 0 %s %s %s cm %s Do Q\n", num(w), num(h), num(x), num(d.height-y-h), img.name)
+}
+
+// WriteTo 输出 PDF 文件
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	pw := &pdfWriter{}
+	pw.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 对象编号：1 Catalog，2 Pages，3-5 字体，6 CIDFont，7 字体描述，之后是图片、页面与内容流
+	const (
+		catalogObj    = 1
+		pagesObj      = 2
+		fontObj       = 3
+		cidFontObj    = 6
+		descriptorObj = 7
+	)
+	imageObj := descriptorObj + 1
+	pageObj := imageObj + len(d.images)
+
+	pw.object(catalogObj, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj))
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", pageObj+i*2)
+	}
+	pw.object(pagesObj, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %s %s] >>",
+		strings.Join(kids, " "), len(d.pages), num(d.width), num(d.height)))
+
+	pw.object(fontObj, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	pw.object(fontObj+1, "<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+	pw.object(fontObj+2, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /STSong-Light /Encoding /UniGB-UTF16-H /DescendantFonts [%d 0 R] >>", cidFontObj))
+	pw.object(cidFontObj, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType0 /BaseFont /STSong-Light "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (GB1) /Supplement 4 >> /DW 1000 /FontDescriptor %d 0 R >>", descriptorObj))
+	pw.object(descriptorObj, "<< /Type /FontDescriptor /FontName /STSong-Light /Flags 6 /FontBBox [-25 -254 1000 880] "+
+		"/ItalicAngle 0 /Ascent 880 /Descent -120 /CapHeight 880 /StemV 93 >>")
+
+	xobjects := make([]string, len(d.images))
+	for i, img := range d.images {
+		colorSpace := "/DeviceRGB"
+		if img.gray {
+			colorSpace = "/DeviceGray"
+		}
+		pw.stream(imageObj+i, fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode",
+			img.Width, img.Height, colorSpace), img.data)
+		xobjects[i] = fmt.Sprintf("%s %d 0 R", img.name, imageObj+i)
+	}
+
+	resources := fmt.Sprintf("<< /Font << /F1 %d 0 R /F2 %d 0 R /F3 %d 0 R >>", fontObj, fontObj+1, fontObj+2)
+	if len(xobjects) > 0 {
+		resources += " /XObject << " + strings.Join(xobjects, " ") + " >>"
+	}
+	resources += " >>"
+
+	for i, content := range d.pages {
+		obj := pageObj + i*2
+		pw.object(obj, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /Resources %s /Contents %d 0 R >>", pagesObj, resources, obj+1))
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		zw.Write(content.Bytes())
+		zw.Close()
+		pw.stream(obj+1, "/Filter /FlateDecode", compressed.Bytes())
+	}
+
+	xref := pw.Len()
+	total := pageObj + len(d.pages)*2
+	fmt.Fprintf(pw, "xref\n0 %d\n0000000000 65535 f \n", total)
+	for i := 1; i < total; i++ {
+		fmt.Fprintf(pw, "%010d 00000 n \n", pw.offsets[i])
+	}
+	fmt.Fprintf(pw, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", total, catalogObj, xref)
+
+	n, err := w.Write(pw.Bytes())
+	return int64(n), err
+}
+
+// Bytes 返回 PDF 文件内容
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WrapText 按宽度折行，保留原有换行；西文按单词折行，中文按字折行
+func (d *Document) WrapText(s string, width float64) []string {
+	var lines []string
+	for _, para := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		lines = append(lines, d.wrapParagraph(para, width)...)
+	}
+	return lines
+}
+
+func (d *Document) wrapParagraph(s string, width float64) []string {
+	s = strings.TrimRight(s, " \t")
+	if s == "" {
+		return []string{""}
+	}
+
+	var lines []string
+	var line []rune
+	lineWidth := 0.0
+	lastSpace := -1 // line 中最后一个空格的位置
+	for _, r := range s {
+		if r == '\t' {
+			r = ' '
+		}
+		w := runeWidth(r) * d.fontSize
+		if lineWidth+w > width && len(line) > 0 && r != ' ' {
+			cut := len(line)
+			if lastSpace > 0 && !isCJK(r) {
+				cut = lastSpace
+			}
+			lines = append(lines, strings.TrimRight(string(line[:cut]), " "))
+			line = []rune(strings.TrimLeft(string(line[cut:]), " "))
+			lineWidth = 0
+			for _, lr := range line {
+				lineWidth += runeWidth(lr) * d.fontSize
+			}
+			lastSpace = -1
+		}
+		if r == ' ' {
+			lastSpace = len(line)
+		} else if isCJK(r) {
+			// 中文字符后可以直接断行
+			lastSpace = len(line) + 1
+		}
+		line = append(line, r)
+		lineWidth += w
+	}
+	return append(lines, strings.TrimRight(string(line), " "))
+}
+
+type textRun struct {
+	text string
+	cjk  bool
+}
+
+// splitRuns 按字体拆分文本：WinAnsi 可编码的字符用 Courier，其余用中文字体
+func splitRuns(s string) []textRun {
+	var runs []textRun
+	for _, r := range s {
+		cjk := isCJK(r)
+		if len(runs) > 0 && runs[len(runs)-1].cjk == cjk {
+			runs[len(runs)-1].text += string(r)
+			continue
+		}
+		runs = append(runs, textRun{text: string(r), cjk: cjk})
+	}
+	return runs
+}
+
+// isCJK 非 ASCII 字符使用中文字体输出
+func isCJK(r rune) bool {
+	return r > 0x7e
+}
+
+func runeWidth(r rune) float64 {
+	if isCJK(r) {
+		return cjkCharWidth
+	}
+	return latinCharWidth
+}
+
+func escapeLatin(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < 0x20:
+			sb.WriteByte(' ')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func utf16Hex(s string) string {
+	var sb strings.Builder
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&sb, "%04X", u)
+	}
+	return sb.String()
+}
+
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// pdfWriter 记录每个对象的偏移量，用于生成 xref 表
+type pdfWriter struct {
+	bytes.Buffer
+	offsets map[int]int
+}
+
+func (w *pdfWriter) object(id int, body string) {
+	w.begin(id)
+	fmt.Fprintf(w, "%s\nendobj\n", body)
+}
+
+func (w *pdfWriter) stream(id int, dict string, data []byte) {
+	w.begin(id)
+	fmt.Fprintf(w, "<< %s /Length %d >>\nstream\n", dict, len(data))
+	w.Write(data)
+	w.WriteString("\nendstream\nendobj\n")
+}
+
+func (w *pdfWriter) begin(id int) {
+	if w.offsets == nil {
+		w.offsets = make(map[int]int)
+	}
+	w.offsets[id] = w.Len()
+	fmt.Fprintf(w, "%d 0 obj\n", id)
+}
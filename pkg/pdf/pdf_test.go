@@ -0,0 +1,60 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	d := New(A4Width, A4Height)
+	d.AddPage()
+	d.Text(72, 72, "INT. 车站 - NIGHT (cont'd)")
+	d.Line(72, 80, 200, 80, 0.5)
+
+	img := image.NewRGBA(image.Rect(0, 0, 16, 9))
+	img.Set(1, 1, color.RGBA{R: 255, A: 255})
+	im, err := d.AddImage(img)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.AddPage()
+	d.DrawImage(im, 72, 72, 160, 90)
+
+	data, err := d.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// xref 表中的每个偏移量都应指向对应对象的开头
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if m == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(data[xref:], -1)
+	if len(entries) != 12 {
+		t.Fatalf("got %d objects, want 12", len(entries))
+	}
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(string(e[1]))
+		if want := fmt.Sprintf("%d 0 obj", i+1); !bytes.HasPrefix(data[offset:], []byte(want)) {
+			t.Errorf("object %d offset %d does not point to %q", i+1, offset, want)
+		}
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	d := New(A4Width, A4Height)
+	d.SetFont(10, false)
+	// Courier 10pt 每个西文字符 6pt，中文字符 10pt
+	lines := d.WrapText("hello world again\n中文字符按字折行", 60)
+	want := []string{"hello", "world", "again", "中文字符按字", "折行"}
+	if fmt.Sprint(lines) != fmt.Sprint(want) {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+}
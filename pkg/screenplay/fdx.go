@@ -0,0 +1,114 @@
+package screenplay
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// fdxDocument Final Draft 文件结构（只处理正文段落与标题页）
+type fdxDocument struct {
+	XMLName      xml.Name     `xml:"FinalDraft"`
+	DocumentType string       `xml:"DocumentType,attr,omitempty"`
+	Template     string       `xml:"Template,attr,omitempty"`
+	Version      string       `xml:"Version,attr,omitempty"`
+	Content      fdxContent   `xml:"Content"`
+	TitlePage    fdxTitlePage `xml:"TitlePage"`
+}
+
+type fdxTitlePage struct {
+	Content fdxContent `xml:"Content"`
+}
+
+type fdxContent struct {
+	Paragraphs []fdxParagraph `xml:"Paragraph"`
+}
+
+type fdxParagraph struct {
+	Type         string      `xml:"Type,attr,omitempty"`
+	Alignment    string      `xml:"Alignment,attr,omitempty"`
+	Number       string      `xml:"Number,attr,omitempty"`
+	Texts        []fdxText   `xml:"Text"`
+	DualDialogue *fdxContent `xml:"DualDialogue"`
+}
+
+type fdxText struct {
+	Value string `xml:",chardata"`
+}
+
+func (p fdxParagraph) text() string {
+	var sb strings.Builder
+	for _, t := range p.Texts {
+		sb.WriteString(t.Value)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// ParseFDX 解析 Final Draft（.fdx）剧本；分集标记（第N集 / Episode N）所在段落开始新的一集
+func ParseFDX(data []byte) (*Document, error) {
+	var fdx fdxDocument
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&fdx); err != nil {
+		return nil, fmt.Errorf("invalid fdx file: %w", err)
+	}
+
+	b := newBuilder()
+	for _, p := range fdx.TitlePage.Content.Paragraphs {
+		if text := p.text(); text != "" {
+			b.doc.Title = text
+			break
+		}
+	}
+
+	var paragraphs []fdxParagraph
+	for _, p := range fdx.Content.Paragraphs {
+		if p.DualDialogue != nil {
+			paragraphs = append(paragraphs, p.DualDialogue.Paragraphs...)
+			continue
+		}
+		paragraphs = append(paragraphs, p)
+	}
+
+	for _, p := range paragraphs {
+		text := p.text()
+		if text == "" {
+			continue
+		}
+		switch p.Type {
+		case "Character":
+			b.character(text)
+		case "Parenthetical":
+			b.parenthetical(text)
+		case "Dialogue", "Lyrics":
+			b.line(text)
+		case "Scene Heading":
+			if IsEpisodeMarker(text) {
+				b.startEpisode(text)
+			} else {
+				b.startScene(text)
+				if p.Number != "" {
+					b.scene.Number = p.Number
+				}
+			}
+		case "Transition":
+			b.transition(text)
+		case "New Act", "End of Act", "Cast List":
+			if IsEpisodeMarker(text) {
+				b.startEpisode(text)
+			}
+		default:
+			// Action、General、Shot 等按动作描述处理
+			if IsEpisodeMarker(text) {
+				b.startEpisode(text)
+			} else {
+				b.action(text, false)
+			}
+		}
+	}
+
+	doc := b.finish()
+	if len(doc.Episodes) == 1 && doc.Episodes[0].Title == "" {
+		doc.Episodes[0].Title = doc.Title
+	}
+	return doc, nil
+}
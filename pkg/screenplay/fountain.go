@@ -0,0 +1,148 @@
+package screenplay
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	boneyardPattern = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	notePattern     = regexp.MustCompile(`(?s)\[\[.*?\]\]`)
+	titleKeyPattern = regexp.MustCompile(`^([A-Za-z][A-Za-z ]*):\s*(.*)$`)
+	emphasisPattern = regexp.MustCompile(`[*_]+`)
+)
+
+// ParseFountain 解析 Fountain 格式剧本（https://fountain.io/syntax）
+//
+// 一级章节（# 标题）或单独一行的分集标记（第N集 / Episode N）开始新的一集；
+// 中文剧本的角色名没有大小写，需要用 @ 强制标记为角色，如 @陈峥
+func ParseFountain(text string) *Document {
+	text = strings.TrimPrefix(text, "\ufeff")
+	text = strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n")
+	text = boneyardPattern.ReplaceAllString(text, "")
+	text = notePattern.ReplaceAllString(text, "")
+
+	lines := strings.Split(text, "\n")
+	b := newBuilder()
+	start := parseTitlePage(lines, b.doc)
+
+	prevBlank := true
+	inDialogue := false
+	for i := start; i < len(lines); i++ {
+		raw := lines[i]
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			prevBlank = true
+			inDialogue = false
+			continue
+		}
+		nextBlank := i+1 >= len(lines) || strings.TrimSpace(lines[i+1]) == ""
+
+		switch {
+		case inDialogue:
+			if strings.HasPrefix(line, "(") || strings.HasPrefix(line, "（") {
+				b.parenthetical(line)
+			} else {
+				b.line(line)
+			}
+			prevBlank = false
+			continue
+		case strings.HasPrefix(line, "==="):
+			// 分页符
+		case strings.HasPrefix(line, "#"):
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			title := strings.TrimSpace(line[level:])
+			if level == 1 {
+				b.startEpisode(title)
+			}
+		case strings.HasPrefix(line, "="):
+			// 梗概（synopsis）不进入剧本正文
+		case prevBlank && IsEpisodeMarker(line):
+			b.startEpisode(line)
+		case strings.HasPrefix(line, ".") && !strings.HasPrefix(line, ".."):
+			b.startScene(strings.TrimSpace(line[1:]))
+		case prevBlank && IsSceneHeading(line):
+			b.startScene(line)
+		case strings.HasPrefix(line, ">") && strings.HasSuffix(line, "<"):
+			b.action(strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, ">"), "<")), false)
+		case strings.HasPrefix(line, ">"):
+			b.transition(strings.TrimSpace(line[1:]))
+		case prevBlank && nextBlank && isUpperLine(line) && strings.HasSuffix(line, "TO:"):
+			b.transition(line)
+		case strings.HasPrefix(line, "@") && !nextBlank:
+			b.character(line[1:])
+			inDialogue = true
+		case prevBlank && !nextBlank && isCharacterCue(line):
+			b.character(line)
+			inDialogue = true
+		case strings.HasPrefix(line, "!"):
+			b.action(cleanEmphasis(line[1:]), !prevBlank)
+		case strings.HasPrefix(line, "~"):
+			b.action(strings.TrimSpace(line[1:]), !prevBlank)
+		default:
+			b.action(cleanEmphasis(raw), !prevBlank)
+		}
+		prevBlank = false
+	}
+
+	doc := b.finish()
+	if len(doc.Episodes) == 1 && doc.Episodes[0].Title == "" {
+		doc.Episodes[0].Title = doc.Title
+	}
+	return doc
+}
+
+// parseTitlePage 读取开头的标题页（Key: Value），返回正文起始行
+func parseTitlePage(lines []string, doc *Document) int {
+	i := 0
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	if i >= len(lines) || !titleKeyPattern.MatchString(strings.TrimSpace(lines[i])) || IsSceneHeading(lines[i]) {
+		return 0
+	}
+
+	key := ""
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			return i + 1
+		}
+		if m := titleKeyPattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			key = strings.ToLower(m[1])
+			if key == "title" && m[2] != "" {
+				doc.Title = cleanEmphasis(m[2])
+			}
+			continue
+		}
+		// 缩进的续行
+		if key == "title" && doc.Title == "" {
+			doc.Title = cleanEmphasis(line)
+		}
+	}
+	return i
+}
+
+// isCharacterCue 全大写（忽略扩展标记）且至少包含一个大写字母的行视为角色名
+func isCharacterCue(line string) bool {
+	name, _ := splitCharacterCue(line)
+	return name != "" && isUpperLine(name) && !strings.HasSuffix(name, ":")
+}
+
+func isUpperLine(s string) bool {
+	hasUpper := false
+	for _, r := range s {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsUpper(r) {
+			hasUpper = true
+		}
+	}
+	return hasUpper
+}
+
+func cleanEmphasis(s string) string {
+	return strings.TrimSpace(emphasisPattern.ReplaceAllString(s, ""))
+}
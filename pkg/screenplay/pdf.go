@@ -0,0 +1,150 @@
+package screenplay
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/drama-generator/backend/pkg/pdf"
+)
+
+// 剧本排版（Letter 纸，Courier 12pt，单位 pt）：左边距 1.5 英寸，角色名 3.7 英寸，对白 2.5 英寸，表演提示 3.1 英寸
+const (
+	scriptFontSize      = 12.0
+	scriptLineHeight    = 12.0
+	scriptMarginTop     = 72.0
+	scriptMarginBottom  = 72.0
+	scriptLeft          = 108.0
+	scriptRight         = 540.0
+	scriptCharacterLeft = 266.4
+	scriptParenLeft     = 223.2
+	scriptParenWidth    = 144.0
+	scriptDialogueLeft  = 180.0
+	scriptDialogueWidth = 252.0
+)
+
+// pageLayout 自上而下排版，内容超出页面底部时自动换页
+type pageLayout struct {
+	doc         *pdf.Document
+	top, bottom float64
+	y           float64
+	pageNumber  int
+	onNewPage   func(l *pageLayout)
+	pageStarted bool
+}
+
+func (l *pageLayout) newPage() {
+	l.doc.AddPage()
+	l.pageNumber++
+	l.y = l.top
+	l.pageStarted = true
+	if l.onNewPage != nil {
+		size, bold := l.doc.Font()
+		l.onNewPage(l)
+		l.doc.SetFont(size, bold)
+	}
+}
+
+// baseline 当前行（高度 scriptLineHeight）的文本基线
+func (l *pageLayout) baseline() float64 {
+	return l.y + scriptLineHeight*0.8
+}
+
+// ensure 剩余空间不足 height 时换页
+func (l *pageLayout) ensure(height float64) {
+	if !l.pageStarted || l.y+height > l.bottom && l.y > l.top {
+		l.newPage()
+	}
+}
+
+// WritePDF 按通行的剧本格式排版输出 PDF；有剧名时生成标题页，每集从新的一页开始
+func WritePDF(doc *Document) ([]byte, error) {
+	d := pdf.New(pdf.LetterWidth, pdf.LetterHeight)
+	_, pageHeight := d.PageSize()
+
+	if doc.Title != "" {
+		d.AddPage()
+		d.SetFont(scriptFontSize, true)
+		d.TextCenter(0, pdf.LetterWidth, pageHeight/3, strings.ToUpper(doc.Title))
+	}
+
+	l := &pageLayout{doc: d, top: scriptMarginTop, bottom: pageHeight - scriptMarginBottom}
+	l.onNewPage = func(l *pageLayout) {
+		// 每集第一页不显示页码
+		if l.pageNumber > 1 {
+			d.SetFont(scriptFontSize, false)
+			d.TextRight(scriptRight, scriptMarginTop/2, fmt.Sprintf("%d.", l.pageNumber))
+		}
+	}
+
+	for i, ep := range doc.Episodes {
+		l.pageNumber = 0
+		l.pageStarted = false
+		l.ensure(0)
+		if doc.multiEpisode() {
+			d.SetFont(scriptFontSize, true)
+			d.TextCenter(scriptLeft, scriptRight, l.baseline(), strings.ToUpper(episodeHeading(i, ep.Title)))
+			l.y += scriptLineHeight * 3
+		}
+		for _, scene := range ep.Scenes {
+			writePDFScene(l, scene)
+		}
+	}
+	return d.Bytes()
+}
+
+func writePDFScene(l *pageLayout, scene *Scene) {
+	d := l.doc
+	if scene.Heading != "" {
+		d.SetFont(scriptFontSize, true)
+		lines := d.WrapText(strings.ToUpper(scene.Heading), scriptRight-scriptLeft)
+		// 场景标题不单独留在页尾
+		l.ensure(scriptLineHeight * float64(len(lines)+2))
+		if scene.Number != "" {
+			d.SetFont(scriptFontSize, false)
+			d.TextRight(scriptLeft-24, l.baseline(), scene.Number)
+			d.Text(scriptRight+24, l.baseline(), scene.Number)
+			d.SetFont(scriptFontSize, true)
+		}
+		writePDFLines(l, scriptLeft, lines)
+		l.y += scriptLineHeight
+	}
+
+	for _, el := range scene.Elements {
+		switch el.Type {
+		case ElementDialogue:
+			d.SetFont(scriptFontSize, false)
+			cue := el.Character
+			if el.Extension != "" {
+				cue += " (" + el.Extension + ")"
+			}
+			var paren []string
+			if el.Parenthetical != "" {
+				paren = d.WrapText(wrapParenthetical(el.Parenthetical), scriptParenWidth)
+			}
+			dialogue := d.WrapText(el.Text, scriptDialogueWidth)
+			// 角色名至少与两行内容在同一页
+			l.ensure(scriptLineHeight * float64(1+min(len(paren)+len(dialogue), 2)))
+			writePDFLines(l, scriptCharacterLeft, []string{strings.ToUpper(cue)})
+			writePDFLines(l, scriptParenLeft, paren)
+			writePDFLines(l, scriptDialogueLeft, dialogue)
+		case ElementTransition:
+			d.SetFont(scriptFontSize, false)
+			l.ensure(scriptLineHeight)
+			d.TextRight(scriptRight, l.baseline(), strings.ToUpper(el.Text))
+			l.y += scriptLineHeight
+		default:
+			d.SetFont(scriptFontSize, false)
+			writePDFLines(l, scriptLeft, d.WrapText(el.Text, scriptRight-scriptLeft))
+		}
+		l.y += scriptLineHeight
+	}
+}
+
+// writePDFLines 逐行输出，y 为下一行的顶部
+func writePDFLines(l *pageLayout, x float64, lines []string) {
+	for _, line := range lines {
+		l.ensure(scriptLineHeight)
+		l.doc.Text(x, l.baseline(), line)
+		l.y += scriptLineHeight
+	}
+}
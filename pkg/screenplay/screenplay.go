@@ -0,0 +1,391 @@
+package screenplay
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Format 剧本文件格式
+type Format string
+
+const (
+	FormatFountain Format = "fountain"
+	FormatFDX      Format = "fdx"
+	FormatPDF      Format = "pdf" // 仅用于导出
+)
+
+// ParseFormat 解析格式名称
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "fountain", "spmd":
+		return FormatFountain, nil
+	case "fdx", "finaldraft", "final_draft":
+		return FormatFDX, nil
+	case "pdf":
+		return FormatPDF, nil
+	default:
+		return "", fmt.Errorf("unsupported screenplay format: %s", s)
+	}
+}
+
+// FormatFromFilename 按扩展名判断格式，.txt 按 Fountain 处理
+func FormatFromFilename(name string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".fountain", ".spmd", ".txt":
+		return FormatFountain, nil
+	case ".fdx":
+		return FormatFDX, nil
+	default:
+		return "", fmt.Errorf("unsupported screenplay file: %s", name)
+	}
+}
+
+// Extension 文件扩展名
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// ContentType HTTP Content-Type
+func (f Format) ContentType() string {
+	switch f {
+	case FormatFDX:
+		return "application/xml; charset=utf-8"
+	case FormatPDF:
+		return "application/pdf"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Parse 按格式解析剧本
+func Parse(data []byte, format Format) (*Document, error) {
+	switch format {
+	case FormatFountain:
+		return ParseFountain(string(data)), nil
+	case FormatFDX:
+		return ParseFDX(data)
+	default:
+		return nil, fmt.Errorf("unsupported screenplay format: %s", format)
+	}
+}
+
+// ElementType 剧本元素类型
+type ElementType string
+
+const (
+	ElementAction     ElementType = "action"
+	ElementDialogue   ElementType = "dialogue"
+	ElementTransition ElementType = "transition"
+)
+
+// Document 解析后的剧本，按分集标记拆分为多集；没有分集标记时只有一集
+type Document struct {
+	Title    string     `json:"title,omitempty"`
+	Episodes []*Episode `json:"episodes"`
+}
+
+// Episode 一集剧本
+type Episode struct {
+	Title  string   `json:"title,omitempty"`
+	Scenes []*Scene `json:"scenes"`
+}
+
+// Scene 一场戏，Heading 为空表示第一个场景标题之前的内容
+type Scene struct {
+	Heading  string     `json:"heading,omitempty"`
+	Number   string     `json:"number,omitempty"`  // 场景编号，如 INT. HOUSE - DAY #12A# 中的 12A
+	IntExt   string     `json:"int_ext,omitempty"` // INT, EXT, INT/EXT, EST, 内景, 外景, 内/外景
+	Location string     `json:"location,omitempty"`
+	Time     string     `json:"time,omitempty"`
+	Elements []*Element `json:"elements"`
+}
+
+// Element 场景中的一段动作、对白或转场
+type Element struct {
+	Type          ElementType `json:"type"`
+	Character     string      `json:"character,omitempty"`     // 对白角色（不含扩展标记）
+	Extension     string      `json:"extension,omitempty"`     // 角色扩展，如 V.O.、O.S.、CONT'D
+	Parenthetical string      `json:"parenthetical,omitempty"` // 表演提示，如 （低声）
+	Text          string      `json:"text"`
+}
+
+// Characters 返回全剧出现过台词的角色（去重，保持首次出现顺序）
+func (d *Document) Characters() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, ep := range d.Episodes {
+		for _, name := range ep.Characters() {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// Characters 返回本集出现过台词的角色（去重，保持首次出现顺序）
+func (e *Episode) Characters() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, scene := range e.Scenes {
+		for _, el := range scene.Elements {
+			if el.Type == ElementDialogue && el.Character != "" && !seen[el.Character] {
+				seen[el.Character] = true
+				names = append(names, el.Character)
+			}
+		}
+	}
+	return names
+}
+
+// PlainText 渲染为纯文本剧本，对白使用 角色："台词" 格式，与分镜对白字段一致
+func (e *Episode) PlainText() string {
+	var blocks []string
+	for _, scene := range e.Scenes {
+		if scene.Heading != "" {
+			blocks = append(blocks, scene.Heading)
+		}
+		for _, el := range scene.Elements {
+			switch el.Type {
+			case ElementDialogue:
+				blocks = append(blocks, fmt.Sprintf("%s：%s\"%s\"", el.Character, el.Parenthetical, el.Text))
+			default:
+				blocks = append(blocks, el.Text)
+			}
+		}
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// 纯文本剧本中的对白行：角色："台词" / 角色：（低声）"台词"
+var plainDialoguePattern = regexp.MustCompile(`^([^\s：:"“”（()]{1,20})\s*[：:]\s*((?:（[^）]*）|\([^)]*\))?)\s*["“](.*)["”]$`)
+
+// ParseScript 解析章节的纯文本剧本（PlainText 的输出或 AI 生成的剧本）：
+// 按 Fountain 规则解析场景与动作，并把 角色："台词" 格式的行识别为对白
+func ParseScript(text string) *Document {
+	doc := ParseFountain(text)
+	for _, ep := range doc.Episodes {
+		for _, scene := range ep.Scenes {
+			var elements []*Element
+			for _, el := range scene.Elements {
+				if el.Type != ElementAction {
+					elements = append(elements, el)
+					continue
+				}
+				var action *Element
+				for _, line := range strings.Split(el.Text, "\n") {
+					if m := plainDialoguePattern.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+						elements = append(elements, &Element{Type: ElementDialogue, Character: m[1], Parenthetical: m[2], Text: m[3]})
+						action = nil
+						continue
+					}
+					if action == nil {
+						action = &Element{Type: ElementAction, Text: line}
+						elements = append(elements, action)
+					} else {
+						action.Text += "\n" + line
+					}
+				}
+			}
+			scene.Elements = elements
+		}
+	}
+	return doc
+}
+
+var (
+	// 分集标记：第3集 / 第十二集 / Episode 3
+	episodeMarkerPattern = regexp.MustCompile(`(?i)^(第\s*[0-9零一二三四五六七八九十百千]+\s*集|episode\s+\d+)`)
+	// 场景标题：INT. / EXT. / INT./EXT. / I/E / EST. 以及中文 内景 / 外景 / 内/外景
+	sceneHeadingPattern = regexp.MustCompile(`(?i)^((?:int\.?/ext|ext\.?/int|i/e|int|ext|est)[\. ]|(?:内/外景|内外景|内景|外景))`)
+	// 场景编号：INT. HOUSE - DAY #1A#
+	sceneNumberPattern = regexp.MustCompile(`\s*#[^#]*#\s*$`)
+)
+
+// 场景标题末尾常见的时间词，标题中没有 " - " 分隔时用于识别时间
+var timeOfDayWords = map[string]bool{
+	"DAY": true, "NIGHT": true, "MORNING": true, "EVENING": true, "AFTERNOON": true, "DAWN": true, "DUSK": true,
+	"CONTINUOUS": true, "LATER": true, "MOMENTS LATER": true, "SAME": true,
+	"日": true, "夜": true, "晨": true, "白天": true, "夜晚": true, "深夜": true, "清晨": true, "早晨": true,
+	"上午": true, "中午": true, "午后": true, "下午": true, "傍晚": true, "黄昏": true, "凌晨": true,
+}
+
+// IsEpisodeMarker 判断一行是否为分集标记
+func IsEpisodeMarker(line string) bool {
+	return episodeMarkerPattern.MatchString(strings.TrimSpace(line))
+}
+
+// IsSceneHeading 判断一行是否为场景标题
+func IsSceneHeading(line string) bool {
+	return sceneHeadingPattern.MatchString(strings.TrimSpace(line))
+}
+
+// ParseSceneHeading 拆分场景标题，如 "INT. KITCHEN - NIGHT" → INT, KITCHEN, NIGHT
+func ParseSceneHeading(heading string) *Scene {
+	heading = strings.TrimSpace(heading)
+	scene := &Scene{}
+	if m := sceneNumberPattern.FindString(heading); m != "" {
+		scene.Number = strings.Trim(strings.TrimSpace(m), "#")
+		heading = strings.TrimSpace(heading[:len(heading)-len(m)])
+	}
+	scene.Heading = heading
+
+	rest := heading
+	if m := sceneHeadingPattern.FindString(heading); m != "" {
+		scene.IntExt = normalizeIntExt(m)
+		rest = heading[len(m):]
+	}
+	rest = strings.Trim(rest, " .")
+
+	for _, sep := range []string{" - ", " – ", " — ", "–", "—"} {
+		if idx := strings.LastIndex(rest, sep); idx > 0 {
+			scene.Location = strings.TrimSpace(rest[:idx])
+			scene.Time = strings.TrimSpace(rest[idx+len(sep):])
+			return scene
+		}
+	}
+	// 没有明确分隔时，仅当末尾是时间词才拆分，避免拆开 SELF-STORAGE 这类地点
+	for _, sep := range []string{"-", " ", "　"} {
+		if idx := strings.LastIndex(rest, sep); idx > 0 {
+			last := strings.TrimSpace(rest[idx+len(sep):])
+			if timeOfDayWords[strings.ToUpper(last)] {
+				scene.Location = strings.TrimSpace(rest[:idx])
+				scene.Time = last
+				return scene
+			}
+		}
+	}
+	scene.Location = rest
+	return scene
+}
+
+func normalizeIntExt(prefix string) string {
+	p := strings.ToUpper(strings.TrimSpace(strings.TrimRight(prefix, ". ")))
+	p = strings.ReplaceAll(p, ".", "")
+	switch p {
+	case "INT/EXT", "EXT/INT", "I/E":
+		return "INT/EXT"
+	case "内外景":
+		return "内/外景"
+	}
+	return p
+}
+
+// builder 两种格式共用的文档构建器
+type builder struct {
+	doc      *Document
+	episode  *Episode
+	scene    *Scene
+	dialogue *Element
+	last     *Element
+}
+
+func newBuilder() *builder {
+	return &builder{doc: &Document{}}
+}
+
+func (b *builder) startEpisode(title string) {
+	b.episode = &Episode{Title: strings.TrimSpace(title)}
+	b.doc.Episodes = append(b.doc.Episodes, b.episode)
+	b.scene, b.dialogue, b.last = nil, nil, nil
+}
+
+func (b *builder) startScene(heading string) {
+	if b.episode == nil {
+		b.startEpisode("")
+	}
+	b.scene = ParseSceneHeading(heading)
+	b.episode.Scenes = append(b.episode.Scenes, b.scene)
+	b.dialogue, b.last = nil, nil
+}
+
+func (b *builder) add(el *Element) *Element {
+	if b.scene == nil {
+		b.startScene("")
+	}
+	b.scene.Elements = append(b.scene.Elements, el)
+	b.last = el
+	return el
+}
+
+// action 添加动作描述，join 为 true 时与紧邻的上一段动作合并
+func (b *builder) action(text string, join bool) {
+	b.dialogue = nil
+	if join && b.last != nil && b.last.Type == ElementAction {
+		b.last.Text += "\n" + text
+		return
+	}
+	b.add(&Element{Type: ElementAction, Text: text})
+}
+
+func (b *builder) transition(text string) {
+	b.dialogue = nil
+	b.add(&Element{Type: ElementTransition, Text: text})
+}
+
+// character 开始一段对白，cue 可带扩展标记，如 "JOHN (V.O.)"
+func (b *builder) character(cue string) {
+	name, ext := splitCharacterCue(cue)
+	b.dialogue = b.add(&Element{Type: ElementDialogue, Character: name, Extension: ext})
+}
+
+func (b *builder) parenthetical(text string) {
+	if b.dialogue == nil {
+		b.action(text, false)
+		return
+	}
+	if b.dialogue.Text == "" && b.dialogue.Parenthetical == "" {
+		b.dialogue.Parenthetical = text
+		return
+	}
+	b.line(text)
+}
+
+func (b *builder) line(text string) {
+	if b.dialogue == nil {
+		b.action(text, true)
+		return
+	}
+	if b.dialogue.Text != "" {
+		b.dialogue.Text += "\n"
+	}
+	b.dialogue.Text += text
+}
+
+// finish 去掉没有内容的集，返回文档
+func (b *builder) finish() *Document {
+	episodes := b.doc.Episodes[:0]
+	for _, ep := range b.doc.Episodes {
+		scenes := ep.Scenes[:0]
+		for _, scene := range ep.Scenes {
+			if scene.Heading != "" || len(scene.Elements) > 0 {
+				scenes = append(scenes, scene)
+			}
+		}
+		ep.Scenes = scenes
+		if len(ep.Scenes) > 0 {
+			episodes = append(episodes, ep)
+		}
+	}
+	b.doc.Episodes = episodes
+	return b.doc
+}
+
+// splitCharacterCue 拆分角色名与扩展标记，去掉双人对白标记 ^
+func splitCharacterCue(cue string) (string, string) {
+	cue = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(cue), "^"))
+	var exts []string
+	for {
+		open := strings.LastIndexAny(cue, "(（")
+		if open <= 0 || !strings.HasSuffix(cue, ")") && !strings.HasSuffix(cue, "）") {
+			break
+		}
+		inner := strings.TrimSpace(strings.TrimRight(strings.TrimLeft(cue[open:], "(（"), ")）"))
+		exts = append([]string{inner}, exts...)
+		cue = strings.TrimSpace(cue[:open])
+	}
+	return cue, strings.Join(exts, ", ")
+}
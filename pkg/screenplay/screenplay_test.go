@@ -0,0 +1,256 @@
+package screenplay
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleFountain = `Title: Midnight Train
+Author: Writers Room
+
+# Episode 1: Departure
+
+INT. TRAIN STATION - NIGHT
+
+Rain hammers the glass roof.
+A clock reads 11:58.
+
+JOHN (V.O.)
+(quietly)
+We only get one chance.
+
+@陈峥
+（低声）
+车要开了。
+
+CUT TO:
+
+EXT. PLATFORM #2#
+
+MARY
+Wait for me!
+
+# Episode 2: Arrival
+
+.外景 小镇街道 - 清晨
+
+@陈峥
+到了。
+`
+
+func TestParseFountain(t *testing.T) {
+	doc := ParseFountain(sampleFountain)
+
+	if doc.Title != "Midnight Train" {
+		t.Errorf("title = %q", doc.Title)
+	}
+	if len(doc.Episodes) != 2 {
+		t.Fatalf("got %d episodes, want 2", len(doc.Episodes))
+	}
+	if doc.Episodes[0].Title != "Episode 1: Departure" {
+		t.Errorf("episode title = %q", doc.Episodes[0].Title)
+	}
+
+	ep1 := doc.Episodes[0]
+	if len(ep1.Scenes) != 2 {
+		t.Fatalf("got %d scenes, want 2", len(ep1.Scenes))
+	}
+	station := ep1.Scenes[0]
+	if station.IntExt != "INT" || station.Location != "TRAIN STATION" || station.Time != "NIGHT" {
+		t.Errorf("scene heading = %+v", station)
+	}
+	if len(station.Elements) != 4 {
+		t.Fatalf("got %d elements, want 4: %+v", len(station.Elements), station.Elements)
+	}
+	if station.Elements[0].Type != ElementAction || station.Elements[0].Text != "Rain hammers the glass roof.\nA clock reads 11:58." {
+		t.Errorf("action = %+v", station.Elements[0])
+	}
+	john := station.Elements[1]
+	if john.Type != ElementDialogue || john.Character != "JOHN" || john.Extension != "V.O." || john.Parenthetical != "(quietly)" || john.Text != "We only get one chance." {
+		t.Errorf("dialogue = %+v", john)
+	}
+	chen := station.Elements[2]
+	if chen.Character != "陈峥" || chen.Parenthetical != "（低声）" || chen.Text != "车要开了。" {
+		t.Errorf("forced character dialogue = %+v", chen)
+	}
+	if station.Elements[3].Type != ElementTransition || station.Elements[3].Text != "CUT TO:" {
+		t.Errorf("transition = %+v", station.Elements[3])
+	}
+	if ep1.Scenes[1].Location != "PLATFORM" || ep1.Scenes[1].Heading != "EXT. PLATFORM" {
+		t.Errorf("scene number not stripped: %+v", ep1.Scenes[1])
+	}
+
+	street := doc.Episodes[1].Scenes[0]
+	if street.IntExt != "外景" || street.Location != "小镇街道" || street.Time != "清晨" {
+		t.Errorf("chinese heading = %+v", street)
+	}
+
+	if got, want := doc.Characters(), []string{"JOHN", "陈峥", "MARY"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("characters = %v, want %v", got, want)
+	}
+	if text := ep1.PlainText(); !strings.Contains(text, `陈峥：（低声）"车要开了。"`) {
+		t.Errorf("plain text missing dialogue:\n%s", text)
+	}
+}
+
+func TestParseFountainEpisodeMarkers(t *testing.T) {
+	doc := ParseFountain("第1集 初见\n\n内景 咖啡馆 夜\n\n她推门进来。\n\n第2集\n\n外景 街道-日\n\n雨停了。\n")
+	if len(doc.Episodes) != 2 {
+		t.Fatalf("got %d episodes, want 2", len(doc.Episodes))
+	}
+	if doc.Episodes[0].Title != "第1集 初见" {
+		t.Errorf("episode title = %q", doc.Episodes[0].Title)
+	}
+	if s := doc.Episodes[0].Scenes[0]; s.Location != "咖啡馆" || s.Time != "夜" {
+		t.Errorf("scene = %+v", s)
+	}
+	if s := doc.Episodes[1].Scenes[0]; s.Location != "街道" || s.Time != "日" {
+		t.Errorf("scene = %+v", s)
+	}
+}
+
+func TestParseSceneHeading(t *testing.T) {
+	tests := []struct {
+		heading, intExt, location, time string
+	}{
+		{"INT. KITCHEN - NIGHT", "INT", "KITCHEN", "NIGHT"},
+		{"INT./EXT. CAR - DAY", "INT/EXT", "CAR", "DAY"},
+		{"EXT. SELF-STORAGE UNIT", "EXT", "SELF-STORAGE UNIT", ""},
+		{"内景 办公室—深夜", "内景", "办公室", "深夜"},
+	}
+	for _, tt := range tests {
+		s := ParseSceneHeading(tt.heading)
+		if s.IntExt != tt.intExt || s.Location != tt.location || s.Time != tt.time {
+			t.Errorf("%q = (%q, %q, %q), want (%q, %q, %q)", tt.heading, s.IntExt, s.Location, s.Time, tt.intExt, tt.location, tt.time)
+		}
+	}
+}
+
+const sampleFDX = `<?xml version="1.0" encoding="UTF-8" standalone="no" ?>
+<FinalDraft DocumentType="Script" Template="No" Version="5">
+  <Content>
+    <Paragraph Type="Scene Heading"><Text>INT. OFFICE - DAY</Text></Paragraph>
+    <Paragraph Type="Action"><Text>Papers </Text><Text Style="Bold">everywhere</Text><Text>.</Text></Paragraph>
+    <Paragraph Type="Character"><Text>ALICE (CONT'D)</Text></Paragraph>
+    <Paragraph Type="Parenthetical"><Text>(sighing)</Text></Paragraph>
+    <Paragraph Type="Dialogue"><Text>Not again.</Text></Paragraph>
+    <Paragraph>
+      <DualDialogue>
+        <Paragraph Type="Character"><Text>BOB</Text></Paragraph>
+        <Paragraph Type="Dialogue"><Text>Sorry!</Text></Paragraph>
+      </DualDialogue>
+    </Paragraph>
+    <Paragraph Type="Transition"><Text>SMASH CUT TO:</Text></Paragraph>
+  </Content>
+  <TitlePage>
+    <Content>
+      <Paragraph Type="Title"><Text>Office Hours</Text></Paragraph>
+    </Content>
+  </TitlePage>
+</FinalDraft>`
+
+func TestParseFDX(t *testing.T) {
+	doc, err := ParseFDX([]byte(sampleFDX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "Office Hours" || len(doc.Episodes) != 1 || doc.Episodes[0].Title != "Office Hours" {
+		t.Fatalf("doc = %+v", doc)
+	}
+
+	scene := doc.Episodes[0].Scenes[0]
+	if scene.Location != "OFFICE" || scene.Time != "DAY" {
+		t.Errorf("scene = %+v", scene)
+	}
+	if len(scene.Elements) != 4 {
+		t.Fatalf("got %d elements, want 4", len(scene.Elements))
+	}
+	if scene.Elements[0].Text != "Papers everywhere." {
+		t.Errorf("action = %q", scene.Elements[0].Text)
+	}
+	alice := scene.Elements[1]
+	if alice.Character != "ALICE" || alice.Extension != "CONT'D" || alice.Parenthetical != "(sighing)" || alice.Text != "Not again." {
+		t.Errorf("dialogue = %+v", alice)
+	}
+	if scene.Elements[2].Character != "BOB" || scene.Elements[3].Type != ElementTransition {
+		t.Errorf("dual dialogue / transition = %+v %+v", scene.Elements[2], scene.Elements[3])
+	}
+
+	if _, err := ParseFDX([]byte("not xml")); err == nil {
+		t.Error("expected error for invalid fdx")
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	doc := ParseFountain(sampleFountain)
+	doc.Episodes[0].Scenes[0].Number = "1A"
+
+	fountain := ParseFountain(string(WriteFountain(doc)))
+	if !reflect.DeepEqual(fountain, doc) {
+		t.Errorf("fountain round trip:\n%s", WriteFountain(doc))
+	}
+
+	data, err := WriteFDX(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fdx, err := ParseFDX(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fdx.Title != doc.Title || len(fdx.Episodes) != 2 || fdx.Episodes[0].Scenes[0].Number != "1A" {
+		t.Fatalf("fdx round trip = %+v", fdx)
+	}
+	if got, want := fdx.Characters(), doc.Characters(); !reflect.DeepEqual(got, want) {
+		t.Errorf("fdx characters = %v, want %v", got, want)
+	}
+	if got, want := fdx.Episodes[0].PlainText(), doc.Episodes[0].PlainText(); got != want {
+		t.Errorf("fdx text = %q, want %q", got, want)
+	}
+}
+
+func TestParseScript(t *testing.T) {
+	ep := ParseFountain(sampleFountain).Episodes[0]
+	doc := ParseScript(ep.PlainText())
+	if len(doc.Episodes) != 1 {
+		t.Fatalf("got %d episodes", len(doc.Episodes))
+	}
+	if got, want := doc.Episodes[0].Characters(), []string{"JOHN", "陈峥", "MARY"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("characters = %v, want %v", got, want)
+	}
+	if got := doc.Episodes[0].PlainText(); got != ep.PlainText() {
+		t.Errorf("plain text = %q", got)
+	}
+}
+
+func TestShootingScript(t *testing.T) {
+	script := &ShootingScript{
+		Title:        "夜行列车",
+		EpisodeTitle: "第1集",
+		Shots: []Shot{
+			{Number: 1, Location: "车站", Time: "夜", ShotType: "近景", Angle: "平视", Movement: "推", Duration: 5, Action: "陈峥看表。", Dialogue: `陈峥："车要开了。"`},
+			{Number: 2, Dialogue: "（旁白）那一夜，谁也没有回来。"},
+		},
+	}
+
+	doc := script.Document()
+	scenes := doc.Episodes[0].Scenes
+	if len(scenes) != 2 || scenes[0].Number != "1" || scenes[0].Location != "车站" || scenes[0].Time != "夜" {
+		t.Fatalf("scenes = %+v", scenes)
+	}
+	if scenes[0].Elements[0].Text != "[近景 | 平视 | 推 | 5秒]" {
+		t.Errorf("shot info = %q", scenes[0].Elements[0].Text)
+	}
+	if el := scenes[1].Elements[0]; el.Character != "旁白" || el.Extension != "V.O." {
+		t.Errorf("narration = %+v", el)
+	}
+
+	data, err := WriteShootingScriptPDF(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "%PDF-") {
+		t.Error("not a pdf")
+	}
+}
@@ -0,0 +1,233 @@
+package screenplay
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"github.com/drama-generator/backend/pkg/pdf"
+	"github.com/drama-generator/backend/pkg/subtitle"
+)
+
+// Shot 分镜表中的一个镜头
+type Shot struct {
+	Number   int
+	Title    string
+	Location string
+	Time     string
+	ShotType string // 景别
+	Angle    string
+	Movement string
+	Action   string
+	Dialogue string
+	Duration int         // 秒
+	Image    image.Image // 首帧图片，没有时为 nil
+}
+
+// ShootingScript 分镜表（拍摄脚本）
+type ShootingScript struct {
+	Title        string // 剧名
+	EpisodeTitle string
+	Shots        []Shot
+}
+
+// Document 转换为剧本文档用于 Fountain / FDX 导出：每个镜头为一场，场景编号为镜号，
+// 镜头参数写在动作描述的第一行，对白按分镜对白格式拆分为角色台词
+func (s *ShootingScript) Document() *Document {
+	ep := &Episode{Title: s.EpisodeTitle}
+	for _, shot := range s.Shots {
+		heading := strings.Join(nonEmpty(shot.Location, shot.Time), " - ")
+		if heading == "" {
+			heading = fmt.Sprintf("镜头 %d", shot.Number)
+		}
+		scene := ParseSceneHeading(heading)
+		scene.Number = strconv.Itoa(shot.Number)
+
+		if info := shot.info(" | "); info != "" {
+			scene.Elements = append(scene.Elements, &Element{Type: ElementAction, Text: "[" + info + "]"})
+		}
+		if action := strings.TrimSpace(strings.Join(nonEmpty(shot.Title, shot.Action), "\n")); action != "" {
+			scene.Elements = append(scene.Elements, &Element{Type: ElementAction, Text: action})
+		}
+		for _, line := range subtitle.ParseDialogue(shot.Dialogue) {
+			el := &Element{Type: ElementDialogue, Character: line.Speaker, Text: line.Text}
+			if line.Narration {
+				if el.Character == "" {
+					el.Character = "旁白"
+				}
+				el.Extension = "V.O."
+			}
+			scene.Elements = append(scene.Elements, el)
+		}
+		ep.Scenes = append(ep.Scenes, scene)
+	}
+	return &Document{Title: s.Title, Episodes: []*Episode{ep}}
+}
+
+// info 镜头参数：景别、角度、运镜、时长
+func (shot *Shot) info(sep string) string {
+	parts := nonEmpty(shot.ShotType, shot.Angle, shot.Movement)
+	if shot.Duration > 0 {
+		parts = append(parts, fmt.Sprintf("%d秒", shot.Duration))
+	}
+	return strings.Join(parts, sep)
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// 分镜表排版（A4 横向，单位 pt）
+const (
+	shootingMargin         = 36.0
+	shootingFontSize       = 9.0
+	shootingLineHeight     = 11.0
+	shootingPadding        = 4.0
+	shootingImageWidth     = 160.0
+	shootingImageMaxHeight = 180.0
+	shootingThumbWidth     = 480 // 嵌入图片的最大像素宽度
+)
+
+// shootingColumn 分镜表的列
+type shootingColumn struct {
+	title string
+	width float64
+}
+
+// WriteShootingScriptPDF 输出分镜表 PDF：每行一个镜头，包含镜号、首帧图片、景别/角度/运镜/时长、画面与对白
+func WriteShootingScriptPDF(s *ShootingScript) ([]byte, error) {
+	d := pdf.New(pdf.A4Height, pdf.A4Width)
+	pageWidth, pageHeight := d.PageSize()
+	tableWidth := pageWidth - shootingMargin*2
+
+	columns := []shootingColumn{
+		{"镜号", 36},
+		{"首帧", shootingImageWidth + shootingPadding*2},
+		{"镜头", 120},
+		{"画面", 0},
+		{"对白", 200},
+	}
+	fixed := 0.0
+	for _, c := range columns {
+		fixed += c.width
+	}
+	columns[3].width = tableWidth - fixed
+
+	title := strings.Join(nonEmpty(s.Title, s.EpisodeTitle), " · ")
+	l := &pageLayout{doc: d, top: shootingMargin, bottom: pageHeight - shootingMargin}
+	l.onNewPage = func(l *pageLayout) {
+		d.SetFont(14, true)
+		d.Text(shootingMargin, l.y+14, title+" 分镜表")
+		d.SetFont(shootingFontSize, false)
+		d.TextRight(pageWidth-shootingMargin, pageHeight-shootingMargin/2, strconv.Itoa(l.pageNumber))
+		l.y += 28
+
+		// 表头
+		d.SetFont(shootingFontSize, true)
+		headerHeight := shootingLineHeight + shootingPadding*2
+		x := shootingMargin
+		for _, c := range columns {
+			d.Rect(x, l.y, c.width, headerHeight, 0.5)
+			d.Text(x+shootingPadding, l.y+shootingPadding+shootingLineHeight*0.8, c.title)
+			x += c.width
+		}
+		l.y += headerHeight
+	}
+
+	for _, shot := range s.Shots {
+		d.SetFont(shootingFontSize, false)
+		cells := [][]string{
+			{strconv.Itoa(shot.Number)},
+			nil,
+			d.WrapText(shot.info("\n"), columns[2].width-shootingPadding*2),
+			d.WrapText(strings.Join(nonEmpty(strings.Join(nonEmpty(shot.Location, shot.Time), " · "), shot.Title, shot.Action), "\n"), columns[3].width-shootingPadding*2),
+			d.WrapText(shot.Dialogue, columns[4].width-shootingPadding*2),
+		}
+
+		imageWidth, imageHeight := shootingImageWidth, shootingImageWidth*9/16
+		var img *pdf.Image
+		if shot.Image != nil {
+			var err error
+			if img, err = d.AddImage(thumbnail(shot.Image, shootingThumbWidth)); err != nil {
+				return nil, err
+			}
+			// 竖版图片按最大高度缩放
+			imageHeight = shootingImageWidth * float64(img.Height) / float64(img.Width)
+			if imageHeight > shootingImageMaxHeight {
+				imageWidth = shootingImageMaxHeight * float64(img.Width) / float64(img.Height)
+				imageHeight = shootingImageMaxHeight
+			}
+		}
+
+		// 行高取图片与最多文本行的较大值，超出一页的文本截断
+		maxLines := int((pageHeight - shootingMargin*2 - 60 - shootingPadding*2) / shootingLineHeight)
+		lineCount := 0
+		for i, cell := range cells {
+			if len(cell) > maxLines {
+				cells[i] = append(cell[:maxLines-1:maxLines-1], "……")
+			}
+			lineCount = max(lineCount, len(cells[i]))
+		}
+		rowHeight := max(imageHeight, float64(lineCount)*shootingLineHeight) + shootingPadding*2
+
+		l.ensure(rowHeight)
+		d.SetFont(shootingFontSize, false)
+		x := shootingMargin
+		for i, c := range columns {
+			d.Rect(x, l.y, c.width, rowHeight, 0.5)
+			if i == 1 {
+				if img != nil {
+					d.DrawImage(img, x+(c.width-imageWidth)/2, l.y+shootingPadding, imageWidth, imageHeight)
+				} else {
+					d.TextCenter(x, x+c.width, l.y+rowHeight/2+shootingFontSize/3, "（无图片）")
+				}
+			}
+			for j, line := range cells[i] {
+				d.Text(x+shootingPadding, l.y+shootingPadding+float64(j)*shootingLineHeight+shootingLineHeight*0.8, line)
+			}
+			x += c.width
+		}
+		l.y += rowHeight
+	}
+
+	if !l.pageStarted {
+		l.newPage()
+	}
+	return d.Bytes()
+}
+
+// thumbnail 按区域平均缩小图片，宽度不超过 maxWidth
+func thumbnail(src image.Image, maxWidth int) image.Image {
+	b := src.Bounds()
+	if b.Dx() <= maxWidth {
+		return src
+	}
+	w := maxWidth
+	h := max(1, b.Dy()*maxWidth/b.Dx())
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/h)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/w)
+			var r, g, bl, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, _ := src.At(sx, sy).RGBA()
+					r, g, bl, n = r+cr, g+cg, bl+cb, n+1
+				}
+			}
+			dst.Set(x, y, color.RGBA{R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(bl / n >> 8), A: 0xff})
+		}
+	}
+	return dst
+}
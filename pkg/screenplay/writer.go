@@ -0,0 +1,179 @@
+package screenplay
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// WriteFountain 输出 Fountain 格式剧本；多集时每集以一级章节（# 标题）开始，非大写的角色名使用 @ 标记
+func WriteFountain(doc *Document) []byte {
+	var sb strings.Builder
+	if doc.Title != "" {
+		fmt.Fprintf(&sb, "Title: %s\n\n", doc.Title)
+	}
+
+	for i, ep := range doc.Episodes {
+		if doc.multiEpisode() {
+			fmt.Fprintf(&sb, "# %s\n\n", episodeHeading(i, ep.Title))
+		}
+		for _, scene := range ep.Scenes {
+			if scene.Heading != "" {
+				heading := scene.Heading
+				if !IsSceneHeading(heading) {
+					heading = "." + heading
+				}
+				if scene.Number != "" {
+					heading += " #" + scene.Number + "#"
+				}
+				sb.WriteString(heading + "\n\n")
+			}
+			for _, el := range scene.Elements {
+				writeFountainElement(&sb, el)
+			}
+		}
+	}
+	return []byte(sb.String())
+}
+
+func writeFountainElement(sb *strings.Builder, el *Element) {
+	switch el.Type {
+	case ElementDialogue:
+		cue := el.Character
+		if el.Extension != "" {
+			cue += " (" + el.Extension + ")"
+		}
+		if !isCharacterCue(cue) {
+			cue = "@" + cue
+		}
+		sb.WriteString(cue + "\n")
+		if el.Parenthetical != "" {
+			sb.WriteString(wrapParenthetical(el.Parenthetical) + "\n")
+		}
+		for _, line := range strings.Split(el.Text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				sb.WriteString(line + "\n")
+			}
+		}
+	case ElementTransition:
+		if isUpperLine(el.Text) && strings.HasSuffix(el.Text, "TO:") {
+			sb.WriteString(el.Text + "\n")
+		} else {
+			sb.WriteString("> " + el.Text + "\n")
+		}
+	default:
+		for _, line := range strings.Split(el.Text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				sb.WriteString(escapeFountainAction(line) + "\n")
+			}
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// escapeFountainAction 会被识别为其他元素的动作行加 ! 强制为动作
+func escapeFountainAction(line string) string {
+	if strings.ContainsAny(line[:1], "#@.>=!~[") || IsSceneHeading(line) || IsEpisodeMarker(line) || isUpperLine(line) {
+		return "!" + line
+	}
+	return line
+}
+
+func wrapParenthetical(s string) string {
+	if strings.HasPrefix(s, "(") || strings.HasPrefix(s, "（") {
+		return s
+	}
+	return "(" + s + ")"
+}
+
+// WriteFDX 输出 Final Draft（.fdx）剧本；多集时每集以 New Act 段落开始
+func WriteFDX(doc *Document) ([]byte, error) {
+	fdx := fdxDocument{DocumentType: "Script", Template: "No", Version: "5"}
+	add := func(typ, text string) *fdxParagraph {
+		fdx.Content.Paragraphs = append(fdx.Content.Paragraphs, fdxParagraph{Type: typ, Texts: []fdxText{{Value: text}}})
+		return &fdx.Content.Paragraphs[len(fdx.Content.Paragraphs)-1]
+	}
+	addLines := func(typ, text string) {
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				add(typ, line)
+			}
+		}
+	}
+
+	if doc.Title != "" {
+		fdx.TitlePage.Content.Paragraphs = []fdxParagraph{{Type: "Text", Alignment: "Center", Texts: []fdxText{{Value: doc.Title}}}}
+	}
+	for i, ep := range doc.Episodes {
+		if doc.multiEpisode() {
+			title := episodeHeading(i, ep.Title)
+			if !IsEpisodeMarker(title) {
+				title = episodeMarker(i, title)
+			}
+			add("New Act", title)
+		}
+		for _, scene := range ep.Scenes {
+			if scene.Heading != "" {
+				add("Scene Heading", scene.Heading).Number = scene.Number
+			}
+			for _, el := range scene.Elements {
+				switch el.Type {
+				case ElementDialogue:
+					cue := el.Character
+					if el.Extension != "" {
+						cue += " (" + el.Extension + ")"
+					}
+					add("Character", cue)
+					if el.Parenthetical != "" {
+						add("Parenthetical", wrapParenthetical(el.Parenthetical))
+					}
+					addLines("Dialogue", el.Text)
+				case ElementTransition:
+					add("Transition", el.Text)
+				default:
+					add("Action", el.Text)
+				}
+			}
+		}
+	}
+
+	data, err := xml.MarshalIndent(fdx, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(`<?xml version="1.0" encoding="UTF-8" standalone="no" ?>`+"\n"), data...), nil
+}
+
+// multiEpisode 是否需要输出分集标题：多集，或唯一一集的标题与剧名不同
+func (d *Document) multiEpisode() bool {
+	if len(d.Episodes) > 1 {
+		return true
+	}
+	return len(d.Episodes) == 1 && d.Episodes[0].Title != "" && d.Episodes[0].Title != d.Title
+}
+
+func episodeHeading(i int, title string) string {
+	if title != "" {
+		return title
+	}
+	return episodeMarker(i, "")
+}
+
+// episodeMarker 生成可被解析器识别的分集标记，中文标题使用 第N集，其余使用 Episode N
+func episodeMarker(i int, title string) string {
+	marker := fmt.Sprintf("第%d集", i+1)
+	if title != "" && !containsHan(title) {
+		marker = fmt.Sprintf("Episode %d:", i+1)
+	}
+	return strings.TrimSpace(marker + " " + title)
+}
+
+func containsHan(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package subtitle
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DialogueLine 分镜对白中的一句台词
+type DialogueLine struct {
+	Speaker   string // 说话人，旁白/独白为空
+	Text      string
+	Narration bool // 是否为旁白或独白
+	Monologue bool // 是否为独白
+}
+
+var (
+	// 角色名："台词" 或 角色名：“台词”
+	quotedLinePattern = regexp.MustCompile(`([^\s：:"“”「」]{1,20})\s*[：:]\s*["“「]([^"”」]*)["”」]`)
+	// （独白）内容 / （旁白）内容
+	narrationPattern = regexp.MustCompile(`^[（(]\s*(独白|旁白|monologue|narration|voice ?over|V\.?O\.?)\s*[）)]\s*(.*)$`)
+)
+
+const maxSpeakerRunes = 20
+
+// ParseDialogue 解析分镜对白字段
+// 支持的格式：角色A："..." 角色B："..."；（独白）内容；（旁白）内容；角色名：内容
+func ParseDialogue(text string) []DialogueLine {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+
+	if m := narrationPattern.FindStringSubmatch(text); m != nil {
+		kind := strings.ToLower(m[1])
+		content := strings.Trim(strings.TrimSpace(m[2]), `"“”`)
+		if content == "" {
+			return nil
+		}
+		return []DialogueLine{{
+			Text:      content,
+			Narration: true,
+			Monologue: kind == "独白" || kind == "monologue",
+		}}
+	}
+
+	if matches := quotedLinePattern.FindAllStringSubmatch(text, -1); len(matches) > 0 {
+		lines := make([]DialogueLine, 0, len(matches))
+		for _, m := range matches {
+			content := strings.TrimSpace(m[2])
+			if content == "" {
+				continue
+			}
+			lines = append(lines, DialogueLine{Speaker: strings.TrimSpace(m[1]), Text: content})
+		}
+		if len(lines) > 0 {
+			return lines
+		}
+	}
+
+	// 角色名：内容（无引号）
+	if idx := strings.IndexAny(text, "：:"); idx > 0 {
+		speaker := strings.TrimSpace(text[:idx])
+		_, sepSize := utf8.DecodeRuneInString(text[idx:])
+		content := strings.TrimSpace(text[idx+sepSize:])
+		if content != "" && utf8.RuneCountInString(speaker) <= maxSpeakerRunes && !strings.ContainsAny(speaker, " ，。,.!?！？") {
+			return []DialogueLine{{Speaker: speaker, Text: strings.Trim(content, `"“”`)}}
+		}
+	}
+
+	return []DialogueLine{{Text: text, Narration: true}}
+}
+
+// Speakers 返回对白中出现的说话人（去重，保持顺序）
+func Speakers(lines []DialogueLine) []string {
+	seen := make(map[string]bool)
+	var speakers []string
+	for _, line := range lines {
+		if line.Speaker == "" || seen[line.Speaker] {
+			continue
+		}
+		seen[line.Speaker] = true
+		speakers = append(speakers, line.Speaker)
+	}
+	return speakers
+}
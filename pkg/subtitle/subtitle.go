@@ -0,0 +1,173 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Cue 一条字幕
+type Cue struct {
+	Start   time.Duration
+	End     time.Duration
+	Speaker string
+	Text    string
+}
+
+// Format 字幕格式
+type Format string
+
+const (
+	FormatSRT Format = "srt"
+	FormatVTT Format = "vtt"
+)
+
+// ParseFormat 解析字幕格式，默认 srt
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "srt":
+		return FormatSRT, nil
+	case "vtt", "webvtt":
+		return FormatVTT, nil
+	default:
+		return "", fmt.Errorf("unsupported subtitle format: %s", s)
+	}
+}
+
+// Extension 文件扩展名
+func (f Format) Extension() string {
+	return "." + string(f)
+}
+
+// ContentType HTTP Content-Type
+func (f Format) ContentType() string {
+	if f == FormatVTT {
+		return "text/vtt; charset=utf-8"
+	}
+	return "application/x-subrip; charset=utf-8"
+}
+
+// Render 按格式输出字幕文本
+func Render(cues []Cue, format Format) string {
+	if format == FormatVTT {
+		return RenderVTT(cues)
+	}
+	return RenderSRT(cues)
+}
+
+// RenderSRT 输出 SRT 字幕
+func RenderSRT(cues []Cue) string {
+	var b strings.Builder
+	for i, cue := range cues {
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n",
+			i+1, formatTimestamp(cue.Start, ","), formatTimestamp(cue.End, ","), cue.Text)
+	}
+	return b.String()
+}
+
+// RenderVTT 输出 WebVTT 字幕，说话人使用 <v> 标签
+func RenderVTT(cues []Cue) string {
+	var b strings.Builder
+	b.WriteString("WEBVTT\n\n")
+	for i, cue := range cues {
+		text := cue.Text
+		if cue.Speaker != "" {
+			text = fmt.Sprintf("<v %s>%s", cue.Speaker, text)
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n",
+			i+1, formatTimestamp(cue.Start, "."), formatTimestamp(cue.End, "."), text)
+	}
+	return b.String()
+}
+
+func formatTimestamp(d time.Duration, msSep string) string {
+	if d < 0 {
+		d = 0
+	}
+	ms := d.Milliseconds()
+	h := ms / 3600000
+	ms -= h * 3600000
+	m := ms / 60000
+	ms -= m * 60000
+	s := ms / 1000
+	ms -= s * 1000
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", h, m, s, msSep, ms)
+}
+
+// Wrap 按最大字符数折行（中文按字计数，英文尽量在空格处断开）
+func Wrap(text string, maxRunes int) string {
+	text = strings.TrimSpace(text)
+	if maxRunes <= 0 || utf8.RuneCountInString(text) <= maxRunes {
+		return text
+	}
+
+	var lines []string
+	runes := []rune(text)
+	for len(runes) > maxRunes {
+		cut := maxRunes
+		for i := maxRunes; i > maxRunes/2; i-- {
+			if runes[i] == ' ' {
+				cut = i
+				break
+			}
+		}
+		lines = append(lines, strings.TrimSpace(string(runes[:cut])))
+		runes = []rune(strings.TrimSpace(string(runes[cut:])))
+	}
+	if len(runes) > 0 {
+		lines = append(lines, string(runes))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Distribute 将若干对白平均分配到 [start, start+duration) 区间内，时长按字数比例分配
+func Distribute(lines []DialogueLine, start, duration time.Duration, leadIn time.Duration, maxRunes int) []Cue {
+	if len(lines) == 0 || duration <= 0 {
+		return nil
+	}
+	if leadIn*2 >= duration {
+		leadIn = 0
+	}
+
+	total := 0
+	for _, line := range lines {
+		total += utf8.RuneCountInString(line.Text)
+	}
+	if total == 0 {
+		return nil
+	}
+
+	available := duration - leadIn*2
+	cues := make([]Cue, 0, len(lines))
+	cursor := start + leadIn
+	for i, line := range lines {
+		share := time.Duration(int64(available) * int64(utf8.RuneCountInString(line.Text)) / int64(total))
+		end := cursor + share
+		if i == len(lines)-1 {
+			end = start + duration - leadIn
+		}
+		cues = append(cues, Cue{
+			Start:   cursor,
+			End:     end,
+			Speaker: line.Speaker,
+			Text:    Wrap(line.Text, maxRunes),
+		})
+		cursor = end
+	}
+	return cues
+}
+
+// Shift 将全部字幕整体平移 offset，用于正片前插入片头的情况
+func Shift(cues []Cue, offset time.Duration) []Cue {
+	if offset == 0 {
+		return cues
+	}
+	shifted := make([]Cue, len(cues))
+	for i, cue := range cues {
+		cue.Start += offset
+		cue.End += offset
+		shifted[i] = cue
+	}
+	return shifted
+}
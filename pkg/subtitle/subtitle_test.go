@@ -0,0 +1,103 @@
+package subtitle
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDialogue(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		speakers []string
+		texts    []string
+	}{
+		{
+			name:     "two speakers with quotes",
+			input:    `陈峥："我们被耍了，这里根本没有我们要找的东西。" 李芳："现在怎么办？我们的时间不多了。"`,
+			speakers: []string{"陈峥", "李芳"},
+			texts:    []string{"我们被耍了，这里根本没有我们要找的东西。", "现在怎么办？我们的时间不多了。"},
+		},
+		{
+			name:     "monologue",
+			input:    "（独白）这么多年了，里面到底藏着什么秘密？",
+			speakers: []string{""},
+			texts:    []string{"这么多年了，里面到底藏着什么秘密？"},
+		},
+		{
+			name:     "speaker without quotes",
+			input:    "Alice: We need to leave now.",
+			speakers: []string{"Alice"},
+			texts:    []string{"We need to leave now."},
+		},
+		{
+			name:     "empty",
+			input:    "  ",
+			speakers: nil,
+			texts:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := ParseDialogue(tt.input)
+			if len(lines) != len(tt.texts) {
+				t.Fatalf("got %d lines, want %d: %+v", len(lines), len(tt.texts), lines)
+			}
+			for i, line := range lines {
+				if line.Speaker != tt.speakers[i] || line.Text != tt.texts[i] {
+					t.Errorf("line %d = (%q, %q), want (%q, %q)", i, line.Speaker, line.Text, tt.speakers[i], tt.texts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRenderSRTAndVTT(t *testing.T) {
+	cues := []Cue{
+		{Start: 1500 * time.Millisecond, End: 4 * time.Second, Speaker: "陈峥", Text: "我们被耍了"},
+		{Start: time.Hour + 2*time.Minute + 3*time.Second + 45*time.Millisecond, End: time.Hour + 2*time.Minute + 5*time.Second, Text: "旁白"},
+	}
+
+	srt := RenderSRT(cues)
+	if !strings.Contains(srt, "1\n00:00:01,500 --> 00:00:04,000\n我们被耍了\n") {
+		t.Errorf("unexpected SRT output:\n%s", srt)
+	}
+	if !strings.Contains(srt, "2\n01:02:03,045 --> 01:02:05,000\n旁白\n") {
+		t.Errorf("unexpected SRT output:\n%s", srt)
+	}
+
+	vtt := RenderVTT(cues)
+	if !strings.HasPrefix(vtt, "WEBVTT\n\n") {
+		t.Errorf("VTT output missing header:\n%s", vtt)
+	}
+	if !strings.Contains(vtt, "00:00:01.500 --> 00:00:04.000\n<v 陈峥>我们被耍了\n") {
+		t.Errorf("unexpected VTT output:\n%s", vtt)
+	}
+}
+
+func TestDistribute(t *testing.T) {
+	lines := []DialogueLine{{Speaker: "A", Text: "一二三"}, {Speaker: "B", Text: "一"}}
+	cues := Distribute(lines, 10*time.Second, 4*time.Second+400*time.Millisecond, 200*time.Millisecond, 16)
+	if len(cues) != 2 {
+		t.Fatalf("got %d cues, want 2", len(cues))
+	}
+	if cues[0].Start != 10200*time.Millisecond || cues[0].End != 13200*time.Millisecond {
+		t.Errorf("cue 0 = %v-%v", cues[0].Start, cues[0].End)
+	}
+	if cues[1].Start != cues[0].End || cues[1].End != 14200*time.Millisecond {
+		t.Errorf("cue 1 = %v-%v", cues[1].Start, cues[1].End)
+	}
+}
+
+func TestShift(t *testing.T) {
+	cues := []Cue{{Start: time.Second, End: 2 * time.Second, Text: "a"}}
+	shifted := Shift(cues, 5*time.Second)
+	if shifted[0].Start != 6*time.Second || shifted[0].End != 7*time.Second {
+		t.Fatalf("unexpected shifted cue: %+v", shifted[0])
+	}
+	if cues[0].Start != time.Second {
+		t.Fatalf("input cues modified: %+v", cues[0])
+	}
+}
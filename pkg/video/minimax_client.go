@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -25,6 +26,10 @@ const (
 	// 支持：文生视频、图生视频、首尾帧模式
 	// 时长：768P(6s/10s), 1080P(6s)
 	ModelHailuo02 = "MiniMax-Hailuo-02"
+
+	// ModelS2V01 主体参考视频生成模型，根据一张人物参考图保持人物面部一致
+	// 支持：文生视频 + 主体参考（不支持首尾帧）
+	ModelS2V01 = "S2V-01"
 )
 
 // MiniMax Hailuo 支持的分辨率
@@ -100,6 +105,11 @@ type MinimaxFileResponse struct {
 	} `json:"base_resp"`
 }
 
+// SupportsSubjectReference 模型是否支持主体参考（S2V 系列）
+func SupportsSubjectReference(model string) bool {
+	return strings.HasPrefix(strings.ToUpper(model), "S2V")
+}
+
 func NewMinimaxClient(baseURL, apiKey, model string) *MinimaxClient {
 	return &MinimaxClient{
 		BaseURL: baseURL,
@@ -151,6 +161,15 @@ func (c *MinimaxClient) GenerateVideo(imageURL, prompt string, opts ...VideoOpti
 		reqBody.LastFrameImage = options.LastFrameURL
 	}
 
+	// 主体参考仅 S2V 系列模型支持，且只接受一张人物图，该模式下不使用首尾帧
+	if SupportsSubjectReference(model) && len(options.SubjectReferenceURLs) > 0 {
+		reqBody.SubjectReference = []MinimaxSubjectReference{
+			{Type: "character", Image: options.SubjectReferenceURLs[:1]},
+		}
+		reqBody.FirstFrameImage = ""
+		reqBody.LastFrameImage = ""
+	}
+
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("marshal request: %w", err)
@@ -39,6 +39,8 @@ type VideoOptions struct {
 	FirstFrameURL      string
 	LastFrameURL       string
 	ReferenceImageURLs []string
+	// SubjectReferenceURLs 角色主体参考图，用于保持人物一致，仅部分服务商支持（如 Minimax S2V）
+	SubjectReferenceURLs []string
 }
 
 type VideoOption func(*VideoOptions)
@@ -115,6 +117,12 @@ func WithReferenceImages(urls []string) VideoOption {
 	}
 }
 
+func WithSubjectReference(urls []string) VideoOption {
+	return func(o *VideoOptions) {
+		o.SubjectReferenceURLs = urls
+	}
+}
+
 type RunwayClient struct {
 	BaseURL    string
 	APIKey     string
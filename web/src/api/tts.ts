@@ -10,12 +10,14 @@ export interface TTSGenerateParams {
   pitch?: number
   format?: string
   save_to_file?: boolean
+  storyboard_id?: number // 指定后保存为该分镜的对白素材
 }
 
 export interface TTSGenerateResponse {
   success: boolean
   audio_data?: string
   file_path?: string
+  asset_id?: number
   duration?: number
   provider: string
   voice: string